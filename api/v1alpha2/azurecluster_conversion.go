@@ -44,6 +44,37 @@ func (src *AzureCluster) ConvertTo(dstRaw conversion.Hub) error { // nolint
 		return err
 	}
 
+	dst.Spec.Environment = restored.Spec.Environment
+	dst.Spec.CustomEnvironment = restored.Spec.CustomEnvironment
+	dst.Spec.SubscriptionID = restored.Spec.SubscriptionID
+	dst.Spec.IdentityRef = restored.Spec.IdentityRef
+	dst.Spec.APIServerLB = restored.Spec.APIServerLB
+	dst.Spec.Bastion = restored.Spec.Bastion
+	dst.Spec.NodeOutboundLB = restored.Spec.NodeOutboundLB
+	dst.Spec.SSHAccess = restored.Spec.SSHAccess
+	dst.Status.Bastion.OSDisk.DiffDiskSettings = restored.Status.Bastion.OSDisk.DiffDiskSettings
+	dst.Status.Bastion.OSDisk.CachingType = restored.Status.Bastion.OSDisk.CachingType
+	dst.Status.Bastion.OSDisk.ManagedDisk.DiskEncryptionSetID = restored.Status.Bastion.OSDisk.ManagedDisk.DiskEncryptionSetID
+	dst.Status.Bastion.PrincipalID = restored.Status.Bastion.PrincipalID
+	dst.Status.Bastion.Evicted = restored.Status.Bastion.Evicted
+	dst.Status.FailureDomains = restored.Status.FailureDomains
+	dst.Status.Network.APIServerIPv6 = restored.Status.Network.APIServerIPv6
+	for role, securityGroup := range dst.Status.Network.SecurityGroups {
+		if restoredSecurityGroup, ok := restored.Status.Network.SecurityGroups[role]; ok {
+			securityGroup.IngressRules = restoredSecurityGroup.IngressRules
+			dst.Status.Network.SecurityGroups[role] = securityGroup
+		}
+	}
+	dst.Spec.NetworkSpec.Vnet.CIDRBlocks = restored.Spec.NetworkSpec.Vnet.CIDRBlocks
+	dst.Spec.NetworkSpec.Routes = restored.Spec.NetworkSpec.Routes
+	for i, subnet := range dst.Spec.NetworkSpec.Subnets {
+		if subnet != nil && i < len(restored.Spec.NetworkSpec.Subnets) && restored.Spec.NetworkSpec.Subnets[i] != nil {
+			subnet.CIDRBlocks = restored.Spec.NetworkSpec.Subnets[i].CIDRBlocks
+			subnet.NatGateway = restored.Spec.NetworkSpec.Subnets[i].NatGateway
+			subnet.SecurityGroup.IngressRules = restored.Spec.NetworkSpec.Subnets[i].SecurityGroup.IngressRules
+		}
+	}
+
 	return nil
 }
 
@@ -120,3 +151,140 @@ func Convert_v1alpha3_AzureClusterStatus_To_v1alpha2_AzureClusterStatus(in *infr
 
 	return nil
 }
+
+// Convert_v1alpha3_VM_To_v1alpha2_VM converts from the Hub version (v1alpha3) of the VM to this version.
+func Convert_v1alpha3_VM_To_v1alpha2_VM(in *infrav1alpha3.VM, out *VM, s apiconversion.Scope) error { // nolint
+	if err := autoConvert_v1alpha3_VM_To_v1alpha2_VM(in, out, s); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Convert_v1alpha3_Network_To_v1alpha2_Network converts from the Hub version (v1alpha3) of the Network to this version.
+func Convert_v1alpha3_Network_To_v1alpha2_Network(in *infrav1alpha3.Network, out *Network, s apiconversion.Scope) error { // nolint
+	if err := autoConvert_v1alpha3_Network_To_v1alpha2_Network(in, out, s); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Convert_v1alpha3_VnetSpec_To_v1alpha2_VnetSpec converts from the Hub version (v1alpha3) of the VnetSpec to this version.
+func Convert_v1alpha3_VnetSpec_To_v1alpha2_VnetSpec(in *infrav1alpha3.VnetSpec, out *VnetSpec, s apiconversion.Scope) error { // nolint
+	if err := autoConvert_v1alpha3_VnetSpec_To_v1alpha2_VnetSpec(in, out, s); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Convert_v1alpha3_SubnetSpec_To_v1alpha2_SubnetSpec converts from the Hub version (v1alpha3) of the SubnetSpec to this version.
+func Convert_v1alpha3_SubnetSpec_To_v1alpha2_SubnetSpec(in *infrav1alpha3.SubnetSpec, out *SubnetSpec, s apiconversion.Scope) error { // nolint
+	if err := autoConvert_v1alpha3_SubnetSpec_To_v1alpha2_SubnetSpec(in, out, s); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Convert_v1alpha3_IngressRule_To_v1alpha2_IngressRule converts from the Hub version (v1alpha3) of the IngressRule to this version.
+func Convert_v1alpha3_IngressRule_To_v1alpha2_IngressRule(in *infrav1alpha3.IngressRule, out *IngressRule, s apiconversion.Scope) error { // nolint
+	if err := autoConvert_v1alpha3_IngressRule_To_v1alpha2_IngressRule(in, out, s); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Convert_v1alpha2_SecurityGroup_To_v1alpha3_SecurityGroup converts SecurityGroup from v1alpha2 to v1alpha3, including the ingress rules.
+func Convert_v1alpha2_SecurityGroup_To_v1alpha3_SecurityGroup(in *SecurityGroup, out *infrav1alpha3.SecurityGroup, s apiconversion.Scope) error { // nolint
+	out.ID = in.ID
+	out.Name = in.Name
+	out.Tags = infrav1alpha3.Tags(in.Tags)
+
+	out.IngressRules = nil
+	if in.IngressRules != nil {
+		out.IngressRules = make(infrav1alpha3.IngressRules, len(in.IngressRules))
+		for i, rule := range in.IngressRules {
+			if rule == nil {
+				continue
+			}
+			out.IngressRules[i] = &infrav1alpha3.IngressRule{}
+			if err := Convert_v1alpha2_IngressRule_To_v1alpha3_IngressRule(rule, out.IngressRules[i], s); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// Convert_v1alpha3_SecurityGroup_To_v1alpha2_SecurityGroup converts from the Hub version (v1alpha3) of the SecurityGroup to this version, including the ingress rules.
+func Convert_v1alpha3_SecurityGroup_To_v1alpha2_SecurityGroup(in *infrav1alpha3.SecurityGroup, out *SecurityGroup, s apiconversion.Scope) error { // nolint
+	out.ID = in.ID
+	out.Name = in.Name
+	out.Tags = Tags(in.Tags)
+
+	out.IngressRules = nil
+	if in.IngressRules != nil {
+		out.IngressRules = make(IngressRules, len(in.IngressRules))
+		for i, rule := range in.IngressRules {
+			if rule == nil {
+				continue
+			}
+			out.IngressRules[i] = &IngressRule{}
+			if err := Convert_v1alpha3_IngressRule_To_v1alpha2_IngressRule(rule, out.IngressRules[i], s); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// Convert_v1alpha2_NetworkSpec_To_v1alpha3_NetworkSpec converts NetworkSpec from v1alpha2 to v1alpha3, including the subnets.
+func Convert_v1alpha2_NetworkSpec_To_v1alpha3_NetworkSpec(in *NetworkSpec, out *infrav1alpha3.NetworkSpec, s apiconversion.Scope) error { // nolint
+	if err := Convert_v1alpha2_VnetSpec_To_v1alpha3_VnetSpec(&in.Vnet, &out.Vnet, s); err != nil {
+		return err
+	}
+
+	out.Subnets = nil
+	if in.Subnets != nil {
+		out.Subnets = make(infrav1alpha3.Subnets, len(in.Subnets))
+		for i, subnet := range in.Subnets {
+			if subnet == nil {
+				continue
+			}
+			out.Subnets[i] = &infrav1alpha3.SubnetSpec{}
+			if err := Convert_v1alpha2_SubnetSpec_To_v1alpha3_SubnetSpec(subnet, out.Subnets[i], s); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// Convert_v1alpha3_NetworkSpec_To_v1alpha2_NetworkSpec converts from the Hub version (v1alpha3) of the NetworkSpec to this version, including the subnets.
+func Convert_v1alpha3_NetworkSpec_To_v1alpha2_NetworkSpec(in *infrav1alpha3.NetworkSpec, out *NetworkSpec, s apiconversion.Scope) error { // nolint
+	if err := Convert_v1alpha3_VnetSpec_To_v1alpha2_VnetSpec(&in.Vnet, &out.Vnet, s); err != nil {
+		return err
+	}
+
+	out.Subnets = nil
+	if in.Subnets != nil {
+		out.Subnets = make(Subnets, len(in.Subnets))
+		for i, subnet := range in.Subnets {
+			if subnet == nil {
+				continue
+			}
+			out.Subnets[i] = &SubnetSpec{}
+			if err := Convert_v1alpha3_SubnetSpec_To_v1alpha2_SubnetSpec(subnet, out.Subnets[i], s); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
@@ -37,6 +37,20 @@ func (src *AzureMachine) ConvertTo(dstRaw conversion.Hub) error { // nolint
 		return err
 	}
 
+	dst.Spec.OSDisk.DiffDiskSettings = restored.Spec.OSDisk.DiffDiskSettings
+	dst.Spec.OSDisk.CachingType = restored.Spec.OSDisk.CachingType
+	dst.Spec.OSDisk.ManagedDisk.DiskEncryptionSetID = restored.Spec.OSDisk.ManagedDisk.DiskEncryptionSetID
+	dst.Spec.DataDisks = restored.Spec.DataDisks
+	dst.Spec.Identity = restored.Spec.Identity
+	dst.Spec.UserAssignedIdentities = restored.Spec.UserAssignedIdentities
+	dst.Spec.RoleAssignments = restored.Spec.RoleAssignments
+	dst.Spec.SecurityProfile = restored.Spec.SecurityProfile
+	dst.Spec.SpotVMOptions = restored.Spec.SpotVMOptions
+	dst.Spec.AcceleratedNetworking = restored.Spec.AcceleratedNetworking
+	dst.Spec.NetworkInterfaces = restored.Spec.NetworkInterfaces
+	dst.Spec.PrivateIPAddress = restored.Spec.PrivateIPAddress
+	dst.Status.PrincipalID = restored.Status.PrincipalID
+
 	return nil
 }
 
@@ -110,6 +124,24 @@ func Convert_v1alpha3_AzureMachineStatus_To_v1alpha2_AzureMachineStatus(in *infr
 	return nil
 }
 
+// Convert_v1alpha3_OSDisk_To_v1alpha2_OSDisk converts from the Hub version (v1alpha3) of the OSDisk to this version.
+func Convert_v1alpha3_OSDisk_To_v1alpha2_OSDisk(in *infrav1alpha3.OSDisk, out *OSDisk, s apiconversion.Scope) error { // nolint
+	if err := autoConvert_v1alpha3_OSDisk_To_v1alpha2_OSDisk(in, out, s); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Convert_v1alpha3_ManagedDisk_To_v1alpha2_ManagedDisk converts from the Hub version (v1alpha3) of the ManagedDisk to this version.
+func Convert_v1alpha3_ManagedDisk_To_v1alpha2_ManagedDisk(in *infrav1alpha3.ManagedDisk, out *ManagedDisk, s apiconversion.Scope) error { // nolint
+	if err := autoConvert_v1alpha3_ManagedDisk_To_v1alpha2_ManagedDisk(in, out, s); err != nil {
+		return err
+	}
+
+	return nil
+}
+
 // Convert_v1alpha2_Image_To_v1alpha3_Image converts from an Images between v1alpha2 and v1alpha3
 func Convert_v1alpha2_Image_To_v1alpha3_Image(in *Image, out *infrav1alpha3.Image, s apiconversion.Scope) error { //nolint
 	if isImageByID(in) {
@@ -35,6 +35,19 @@ func (src *AzureMachineTemplate) ConvertTo(dstRaw conversion.Hub) error { // nol
 		return err
 	}
 
+	dst.Spec.Template.Spec.OSDisk.DiffDiskSettings = restored.Spec.Template.Spec.OSDisk.DiffDiskSettings
+	dst.Spec.Template.Spec.OSDisk.CachingType = restored.Spec.Template.Spec.OSDisk.CachingType
+	dst.Spec.Template.Spec.OSDisk.ManagedDisk.DiskEncryptionSetID = restored.Spec.Template.Spec.OSDisk.ManagedDisk.DiskEncryptionSetID
+	dst.Spec.Template.Spec.DataDisks = restored.Spec.Template.Spec.DataDisks
+	dst.Spec.Template.Spec.Identity = restored.Spec.Template.Spec.Identity
+	dst.Spec.Template.Spec.UserAssignedIdentities = restored.Spec.Template.Spec.UserAssignedIdentities
+	dst.Spec.Template.Spec.RoleAssignments = restored.Spec.Template.Spec.RoleAssignments
+	dst.Spec.Template.Spec.SecurityProfile = restored.Spec.Template.Spec.SecurityProfile
+	dst.Spec.Template.Spec.SpotVMOptions = restored.Spec.Template.Spec.SpotVMOptions
+	dst.Spec.Template.Spec.AcceleratedNetworking = restored.Spec.Template.Spec.AcceleratedNetworking
+	dst.Spec.Template.Spec.NetworkInterfaces = restored.Spec.Template.Spec.NetworkInterfaces
+	dst.Spec.Template.Spec.PrivateIPAddress = restored.Spec.Template.Spec.PrivateIPAddress
+
 	return nil
 }
 
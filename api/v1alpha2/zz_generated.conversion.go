@@ -182,11 +182,6 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
-	if err := s.AddGeneratedConversionFunc((*v1alpha3.IngressRule)(nil), (*IngressRule)(nil), func(a, b interface{}, scope conversion.Scope) error {
-		return Convert_v1alpha3_IngressRule_To_v1alpha2_IngressRule(a.(*v1alpha3.IngressRule), b.(*IngressRule), scope)
-	}); err != nil {
-		return err
-	}
 	if err := s.AddGeneratedConversionFunc((*LoadBalancer)(nil), (*v1alpha3.LoadBalancer)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha2_LoadBalancer_To_v1alpha3_LoadBalancer(a.(*LoadBalancer), b.(*v1alpha3.LoadBalancer), scope)
 	}); err != nil {
@@ -222,41 +217,16 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
-	if err := s.AddGeneratedConversionFunc((*v1alpha3.ManagedDisk)(nil), (*ManagedDisk)(nil), func(a, b interface{}, scope conversion.Scope) error {
-		return Convert_v1alpha3_ManagedDisk_To_v1alpha2_ManagedDisk(a.(*v1alpha3.ManagedDisk), b.(*ManagedDisk), scope)
-	}); err != nil {
-		return err
-	}
 	if err := s.AddGeneratedConversionFunc((*Network)(nil), (*v1alpha3.Network)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha2_Network_To_v1alpha3_Network(a.(*Network), b.(*v1alpha3.Network), scope)
 	}); err != nil {
 		return err
 	}
-	if err := s.AddGeneratedConversionFunc((*v1alpha3.Network)(nil), (*Network)(nil), func(a, b interface{}, scope conversion.Scope) error {
-		return Convert_v1alpha3_Network_To_v1alpha2_Network(a.(*v1alpha3.Network), b.(*Network), scope)
-	}); err != nil {
-		return err
-	}
-	if err := s.AddGeneratedConversionFunc((*NetworkSpec)(nil), (*v1alpha3.NetworkSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
-		return Convert_v1alpha2_NetworkSpec_To_v1alpha3_NetworkSpec(a.(*NetworkSpec), b.(*v1alpha3.NetworkSpec), scope)
-	}); err != nil {
-		return err
-	}
-	if err := s.AddGeneratedConversionFunc((*v1alpha3.NetworkSpec)(nil), (*NetworkSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
-		return Convert_v1alpha3_NetworkSpec_To_v1alpha2_NetworkSpec(a.(*v1alpha3.NetworkSpec), b.(*NetworkSpec), scope)
-	}); err != nil {
-		return err
-	}
 	if err := s.AddGeneratedConversionFunc((*OSDisk)(nil), (*v1alpha3.OSDisk)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha2_OSDisk_To_v1alpha3_OSDisk(a.(*OSDisk), b.(*v1alpha3.OSDisk), scope)
 	}); err != nil {
 		return err
 	}
-	if err := s.AddGeneratedConversionFunc((*v1alpha3.OSDisk)(nil), (*OSDisk)(nil), func(a, b interface{}, scope conversion.Scope) error {
-		return Convert_v1alpha3_OSDisk_To_v1alpha2_OSDisk(a.(*v1alpha3.OSDisk), b.(*OSDisk), scope)
-	}); err != nil {
-		return err
-	}
 	if err := s.AddGeneratedConversionFunc((*PublicIP)(nil), (*v1alpha3.PublicIP)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha2_PublicIP_To_v1alpha3_PublicIP(a.(*PublicIP), b.(*v1alpha3.PublicIP), scope)
 	}); err != nil {
@@ -267,46 +237,21 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
-	if err := s.AddGeneratedConversionFunc((*SecurityGroup)(nil), (*v1alpha3.SecurityGroup)(nil), func(a, b interface{}, scope conversion.Scope) error {
-		return Convert_v1alpha2_SecurityGroup_To_v1alpha3_SecurityGroup(a.(*SecurityGroup), b.(*v1alpha3.SecurityGroup), scope)
-	}); err != nil {
-		return err
-	}
-	if err := s.AddGeneratedConversionFunc((*v1alpha3.SecurityGroup)(nil), (*SecurityGroup)(nil), func(a, b interface{}, scope conversion.Scope) error {
-		return Convert_v1alpha3_SecurityGroup_To_v1alpha2_SecurityGroup(a.(*v1alpha3.SecurityGroup), b.(*SecurityGroup), scope)
-	}); err != nil {
-		return err
-	}
 	if err := s.AddGeneratedConversionFunc((*SubnetSpec)(nil), (*v1alpha3.SubnetSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha2_SubnetSpec_To_v1alpha3_SubnetSpec(a.(*SubnetSpec), b.(*v1alpha3.SubnetSpec), scope)
 	}); err != nil {
 		return err
 	}
-	if err := s.AddGeneratedConversionFunc((*v1alpha3.SubnetSpec)(nil), (*SubnetSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
-		return Convert_v1alpha3_SubnetSpec_To_v1alpha2_SubnetSpec(a.(*v1alpha3.SubnetSpec), b.(*SubnetSpec), scope)
-	}); err != nil {
-		return err
-	}
 	if err := s.AddGeneratedConversionFunc((*VM)(nil), (*v1alpha3.VM)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha2_VM_To_v1alpha3_VM(a.(*VM), b.(*v1alpha3.VM), scope)
 	}); err != nil {
 		return err
 	}
-	if err := s.AddGeneratedConversionFunc((*v1alpha3.VM)(nil), (*VM)(nil), func(a, b interface{}, scope conversion.Scope) error {
-		return Convert_v1alpha3_VM_To_v1alpha2_VM(a.(*v1alpha3.VM), b.(*VM), scope)
-	}); err != nil {
-		return err
-	}
 	if err := s.AddGeneratedConversionFunc((*VnetSpec)(nil), (*v1alpha3.VnetSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha2_VnetSpec_To_v1alpha3_VnetSpec(a.(*VnetSpec), b.(*v1alpha3.VnetSpec), scope)
 	}); err != nil {
 		return err
 	}
-	if err := s.AddGeneratedConversionFunc((*v1alpha3.VnetSpec)(nil), (*VnetSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
-		return Convert_v1alpha3_VnetSpec_To_v1alpha2_VnetSpec(a.(*v1alpha3.VnetSpec), b.(*VnetSpec), scope)
-	}); err != nil {
-		return err
-	}
 	if err := s.AddConversionFunc((*AzureClusterSpec)(nil), (*v1alpha3.AzureClusterSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha2_AzureClusterSpec_To_v1alpha3_AzureClusterSpec(a.(*AzureClusterSpec), b.(*v1alpha3.AzureClusterSpec), scope)
 	}); err != nil {
@@ -332,6 +277,16 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddConversionFunc((*NetworkSpec)(nil), (*v1alpha3.NetworkSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha2_NetworkSpec_To_v1alpha3_NetworkSpec(a.(*NetworkSpec), b.(*v1alpha3.NetworkSpec), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddConversionFunc((*SecurityGroup)(nil), (*v1alpha3.SecurityGroup)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha2_SecurityGroup_To_v1alpha3_SecurityGroup(a.(*SecurityGroup), b.(*v1alpha3.SecurityGroup), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddConversionFunc((*v1alpha3.AzureClusterSpec)(nil), (*AzureClusterSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha3_AzureClusterSpec_To_v1alpha2_AzureClusterSpec(a.(*v1alpha3.AzureClusterSpec), b.(*AzureClusterSpec), scope)
 	}); err != nil {
@@ -357,6 +312,51 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddConversionFunc((*v1alpha3.IngressRule)(nil), (*IngressRule)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha3_IngressRule_To_v1alpha2_IngressRule(a.(*v1alpha3.IngressRule), b.(*IngressRule), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddConversionFunc((*v1alpha3.ManagedDisk)(nil), (*ManagedDisk)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha3_ManagedDisk_To_v1alpha2_ManagedDisk(a.(*v1alpha3.ManagedDisk), b.(*ManagedDisk), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddConversionFunc((*v1alpha3.NetworkSpec)(nil), (*NetworkSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha3_NetworkSpec_To_v1alpha2_NetworkSpec(a.(*v1alpha3.NetworkSpec), b.(*NetworkSpec), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddConversionFunc((*v1alpha3.Network)(nil), (*Network)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha3_Network_To_v1alpha2_Network(a.(*v1alpha3.Network), b.(*Network), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddConversionFunc((*v1alpha3.OSDisk)(nil), (*OSDisk)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha3_OSDisk_To_v1alpha2_OSDisk(a.(*v1alpha3.OSDisk), b.(*OSDisk), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddConversionFunc((*v1alpha3.SecurityGroup)(nil), (*SecurityGroup)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha3_SecurityGroup_To_v1alpha2_SecurityGroup(a.(*v1alpha3.SecurityGroup), b.(*SecurityGroup), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddConversionFunc((*v1alpha3.SubnetSpec)(nil), (*SubnetSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha3_SubnetSpec_To_v1alpha2_SubnetSpec(a.(*v1alpha3.SubnetSpec), b.(*SubnetSpec), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddConversionFunc((*v1alpha3.VM)(nil), (*VM)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha3_VM_To_v1alpha2_VM(a.(*v1alpha3.VM), b.(*VM), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddConversionFunc((*v1alpha3.VnetSpec)(nil), (*VnetSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha3_VnetSpec_To_v1alpha2_VnetSpec(a.(*v1alpha3.VnetSpec), b.(*VnetSpec), scope)
+	}); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -470,8 +470,16 @@ func autoConvert_v1alpha3_AzureClusterSpec_To_v1alpha2_AzureClusterSpec(in *v1al
 	if err := Convert_v1alpha3_NetworkSpec_To_v1alpha2_NetworkSpec(&in.NetworkSpec, &out.NetworkSpec, s); err != nil {
 		return err
 	}
+	// WARNING: in.APIServerLB requires manual conversion: does not exist in peer-type
+	// WARNING: in.Bastion requires manual conversion: does not exist in peer-type
+	// WARNING: in.NodeOutboundLB requires manual conversion: does not exist in peer-type
+	// WARNING: in.SSHAccess requires manual conversion: does not exist in peer-type
 	out.ResourceGroup = in.ResourceGroup
 	out.Location = in.Location
+	// WARNING: in.Environment requires manual conversion: does not exist in peer-type
+	// WARNING: in.CustomEnvironment requires manual conversion: does not exist in peer-type
+	// WARNING: in.SubscriptionID requires manual conversion: does not exist in peer-type
+	// WARNING: in.IdentityRef requires manual conversion: does not exist in peer-type
 	// WARNING: in.ControlPlaneEndpoint requires manual conversion: does not exist in peer-type
 	out.AdditionalTags = *(*Tags)(unsafe.Pointer(&in.AdditionalTags))
 	return nil
@@ -496,6 +504,7 @@ func autoConvert_v1alpha3_AzureClusterStatus_To_v1alpha2_AzureClusterStatus(in *
 	if err := Convert_v1alpha3_VM_To_v1alpha2_VM(&in.Bastion, &out.Bastion, s); err != nil {
 		return err
 	}
+	// WARNING: in.FailureDomains requires manual conversion: does not exist in peer-type
 	out.Ready = in.Ready
 	return nil
 }
@@ -647,10 +656,19 @@ func autoConvert_v1alpha3_AzureMachineSpec_To_v1alpha2_AzureMachineSpec(in *v1al
 	if err := Convert_v1alpha3_OSDisk_To_v1alpha2_OSDisk(&in.OSDisk, &out.OSDisk, s); err != nil {
 		return err
 	}
+	// WARNING: in.DataDisks requires manual conversion: does not exist in peer-type
 	out.Location = in.Location
 	out.SSHPublicKey = in.SSHPublicKey
 	out.AdditionalTags = *(*Tags)(unsafe.Pointer(&in.AdditionalTags))
 	out.AllocatePublicIP = in.AllocatePublicIP
+	// WARNING: in.AcceleratedNetworking requires manual conversion: does not exist in peer-type
+	// WARNING: in.NetworkInterfaces requires manual conversion: does not exist in peer-type
+	// WARNING: in.PrivateIPAddress requires manual conversion: does not exist in peer-type
+	// WARNING: in.Identity requires manual conversion: does not exist in peer-type
+	// WARNING: in.UserAssignedIdentities requires manual conversion: does not exist in peer-type
+	// WARNING: in.SecurityProfile requires manual conversion: does not exist in peer-type
+	// WARNING: in.RoleAssignments requires manual conversion: does not exist in peer-type
+	// WARNING: in.SpotVMOptions requires manual conversion: does not exist in peer-type
 	return nil
 }
 
@@ -667,6 +685,7 @@ func autoConvert_v1alpha3_AzureMachineStatus_To_v1alpha2_AzureMachineStatus(in *
 	out.Ready = in.Ready
 	out.Addresses = *(*[]v1.NodeAddress)(unsafe.Pointer(&in.Addresses))
 	out.VMState = (*VMState)(unsafe.Pointer(in.VMState))
+	// WARNING: in.PrincipalID requires manual conversion: does not exist in peer-type
 	// WARNING: in.FailureReason requires manual conversion: does not exist in peer-type
 	// WARNING: in.FailureMessage requires manual conversion: does not exist in peer-type
 	return nil
@@ -914,8 +933,11 @@ func Convert_v1alpha2_IngressRule_To_v1alpha3_IngressRule(in *IngressRule, out *
 }
 
 func autoConvert_v1alpha3_IngressRule_To_v1alpha2_IngressRule(in *v1alpha3.IngressRule, out *IngressRule, s conversion.Scope) error {
+	// WARNING: in.Name requires manual conversion: does not exist in peer-type
 	out.Description = in.Description
 	out.Protocol = SecurityGroupProtocol(in.Protocol)
+	// WARNING: in.Priority requires manual conversion: does not exist in peer-type
+	// WARNING: in.Direction requires manual conversion: does not exist in peer-type
 	out.SourcePorts = (*string)(unsafe.Pointer(in.SourcePorts))
 	out.DestinationPorts = (*string)(unsafe.Pointer(in.DestinationPorts))
 	out.Source = (*string)(unsafe.Pointer(in.Source))
@@ -923,11 +945,6 @@ func autoConvert_v1alpha3_IngressRule_To_v1alpha2_IngressRule(in *v1alpha3.Ingre
 	return nil
 }
 
-// Convert_v1alpha3_IngressRule_To_v1alpha2_IngressRule is an autogenerated conversion function.
-func Convert_v1alpha3_IngressRule_To_v1alpha2_IngressRule(in *v1alpha3.IngressRule, out *IngressRule, s conversion.Scope) error {
-	return autoConvert_v1alpha3_IngressRule_To_v1alpha2_IngressRule(in, out, s)
-}
-
 func autoConvert_v1alpha2_LoadBalancer_To_v1alpha3_LoadBalancer(in *LoadBalancer, out *v1alpha3.LoadBalancer, s conversion.Scope) error {
 	out.ID = in.ID
 	out.Name = in.Name
@@ -1032,16 +1049,24 @@ func Convert_v1alpha2_ManagedDisk_To_v1alpha3_ManagedDisk(in *ManagedDisk, out *
 
 func autoConvert_v1alpha3_ManagedDisk_To_v1alpha2_ManagedDisk(in *v1alpha3.ManagedDisk, out *ManagedDisk, s conversion.Scope) error {
 	out.StorageAccountType = in.StorageAccountType
+	// WARNING: in.DiskEncryptionSetID requires manual conversion: does not exist in peer-type
 	return nil
 }
 
-// Convert_v1alpha3_ManagedDisk_To_v1alpha2_ManagedDisk is an autogenerated conversion function.
-func Convert_v1alpha3_ManagedDisk_To_v1alpha2_ManagedDisk(in *v1alpha3.ManagedDisk, out *ManagedDisk, s conversion.Scope) error {
-	return autoConvert_v1alpha3_ManagedDisk_To_v1alpha2_ManagedDisk(in, out, s)
-}
-
 func autoConvert_v1alpha2_Network_To_v1alpha3_Network(in *Network, out *v1alpha3.Network, s conversion.Scope) error {
-	out.SecurityGroups = *(*map[v1alpha3.SecurityGroupRole]v1alpha3.SecurityGroup)(unsafe.Pointer(&in.SecurityGroups))
+	if in.SecurityGroups != nil {
+		in, out := &in.SecurityGroups, &out.SecurityGroups
+		*out = make(map[v1alpha3.SecurityGroupRole]v1alpha3.SecurityGroup, len(*in))
+		for key, val := range *in {
+			newVal := new(v1alpha3.SecurityGroup)
+			if err := Convert_v1alpha2_SecurityGroup_To_v1alpha3_SecurityGroup(&val, newVal, s); err != nil {
+				return err
+			}
+			(*out)[v1alpha3.SecurityGroupRole(key)] = *newVal
+		}
+	} else {
+		out.SecurityGroups = nil
+	}
 	if err := Convert_v1alpha2_LoadBalancer_To_v1alpha3_LoadBalancer(&in.APIServerLB, &out.APIServerLB, s); err != nil {
 		return err
 	}
@@ -1057,47 +1082,68 @@ func Convert_v1alpha2_Network_To_v1alpha3_Network(in *Network, out *v1alpha3.Net
 }
 
 func autoConvert_v1alpha3_Network_To_v1alpha2_Network(in *v1alpha3.Network, out *Network, s conversion.Scope) error {
-	out.SecurityGroups = *(*map[SecurityGroupRole]SecurityGroup)(unsafe.Pointer(&in.SecurityGroups))
+	if in.SecurityGroups != nil {
+		in, out := &in.SecurityGroups, &out.SecurityGroups
+		*out = make(map[SecurityGroupRole]SecurityGroup, len(*in))
+		for key, val := range *in {
+			newVal := new(SecurityGroup)
+			if err := Convert_v1alpha3_SecurityGroup_To_v1alpha2_SecurityGroup(&val, newVal, s); err != nil {
+				return err
+			}
+			(*out)[SecurityGroupRole(key)] = *newVal
+		}
+	} else {
+		out.SecurityGroups = nil
+	}
 	if err := Convert_v1alpha3_LoadBalancer_To_v1alpha2_LoadBalancer(&in.APIServerLB, &out.APIServerLB, s); err != nil {
 		return err
 	}
 	if err := Convert_v1alpha3_PublicIP_To_v1alpha2_PublicIP(&in.APIServerIP, &out.APIServerIP, s); err != nil {
 		return err
 	}
+	// WARNING: in.APIServerIPv6 requires manual conversion: does not exist in peer-type
 	return nil
 }
 
-// Convert_v1alpha3_Network_To_v1alpha2_Network is an autogenerated conversion function.
-func Convert_v1alpha3_Network_To_v1alpha2_Network(in *v1alpha3.Network, out *Network, s conversion.Scope) error {
-	return autoConvert_v1alpha3_Network_To_v1alpha2_Network(in, out, s)
-}
-
 func autoConvert_v1alpha2_NetworkSpec_To_v1alpha3_NetworkSpec(in *NetworkSpec, out *v1alpha3.NetworkSpec, s conversion.Scope) error {
 	if err := Convert_v1alpha2_VnetSpec_To_v1alpha3_VnetSpec(&in.Vnet, &out.Vnet, s); err != nil {
 		return err
 	}
-	out.Subnets = *(*v1alpha3.Subnets)(unsafe.Pointer(&in.Subnets))
+	if in.Subnets != nil {
+		in, out := &in.Subnets, &out.Subnets
+		*out = make(v1alpha3.Subnets, len(*in))
+		for i := range *in {
+			// TODO: Inefficient conversion - can we improve it?
+			if err := s.Convert(&(*in)[i], &(*out)[i], 0); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.Subnets = nil
+	}
 	return nil
 }
 
-// Convert_v1alpha2_NetworkSpec_To_v1alpha3_NetworkSpec is an autogenerated conversion function.
-func Convert_v1alpha2_NetworkSpec_To_v1alpha3_NetworkSpec(in *NetworkSpec, out *v1alpha3.NetworkSpec, s conversion.Scope) error {
-	return autoConvert_v1alpha2_NetworkSpec_To_v1alpha3_NetworkSpec(in, out, s)
-}
-
 func autoConvert_v1alpha3_NetworkSpec_To_v1alpha2_NetworkSpec(in *v1alpha3.NetworkSpec, out *NetworkSpec, s conversion.Scope) error {
 	if err := Convert_v1alpha3_VnetSpec_To_v1alpha2_VnetSpec(&in.Vnet, &out.Vnet, s); err != nil {
 		return err
 	}
-	out.Subnets = *(*Subnets)(unsafe.Pointer(&in.Subnets))
+	if in.Subnets != nil {
+		in, out := &in.Subnets, &out.Subnets
+		*out = make(Subnets, len(*in))
+		for i := range *in {
+			// TODO: Inefficient conversion - can we improve it?
+			if err := s.Convert(&(*in)[i], &(*out)[i], 0); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.Subnets = nil
+	}
+	// WARNING: in.Routes requires manual conversion: does not exist in peer-type
 	return nil
 }
 
-// Convert_v1alpha3_NetworkSpec_To_v1alpha2_NetworkSpec is an autogenerated conversion function.
-func Convert_v1alpha3_NetworkSpec_To_v1alpha2_NetworkSpec(in *v1alpha3.NetworkSpec, out *NetworkSpec, s conversion.Scope) error {
-	return autoConvert_v1alpha3_NetworkSpec_To_v1alpha2_NetworkSpec(in, out, s)
-}
-
 func autoConvert_v1alpha2_OSDisk_To_v1alpha3_OSDisk(in *OSDisk, out *v1alpha3.OSDisk, s conversion.Scope) error {
 	out.OSType = in.OSType
 	out.DiskSizeGB = in.DiskSizeGB
@@ -1118,14 +1164,11 @@ func autoConvert_v1alpha3_OSDisk_To_v1alpha2_OSDisk(in *v1alpha3.OSDisk, out *OS
 	if err := Convert_v1alpha3_ManagedDisk_To_v1alpha2_ManagedDisk(&in.ManagedDisk, &out.ManagedDisk, s); err != nil {
 		return err
 	}
+	// WARNING: in.DiffDiskSettings requires manual conversion: does not exist in peer-type
+	// WARNING: in.CachingType requires manual conversion: does not exist in peer-type
 	return nil
 }
 
-// Convert_v1alpha3_OSDisk_To_v1alpha2_OSDisk is an autogenerated conversion function.
-func Convert_v1alpha3_OSDisk_To_v1alpha2_OSDisk(in *v1alpha3.OSDisk, out *OSDisk, s conversion.Scope) error {
-	return autoConvert_v1alpha3_OSDisk_To_v1alpha2_OSDisk(in, out, s)
-}
-
 func autoConvert_v1alpha2_PublicIP_To_v1alpha3_PublicIP(in *PublicIP, out *v1alpha3.PublicIP, s conversion.Scope) error {
 	out.ID = in.ID
 	out.Name = in.Name
@@ -1155,29 +1198,41 @@ func Convert_v1alpha3_PublicIP_To_v1alpha2_PublicIP(in *v1alpha3.PublicIP, out *
 func autoConvert_v1alpha2_SecurityGroup_To_v1alpha3_SecurityGroup(in *SecurityGroup, out *v1alpha3.SecurityGroup, s conversion.Scope) error {
 	out.ID = in.ID
 	out.Name = in.Name
-	out.IngressRules = *(*v1alpha3.IngressRules)(unsafe.Pointer(&in.IngressRules))
+	if in.IngressRules != nil {
+		in, out := &in.IngressRules, &out.IngressRules
+		*out = make(v1alpha3.IngressRules, len(*in))
+		for i := range *in {
+			// TODO: Inefficient conversion - can we improve it?
+			if err := s.Convert(&(*in)[i], &(*out)[i], 0); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.IngressRules = nil
+	}
 	out.Tags = *(*v1alpha3.Tags)(unsafe.Pointer(&in.Tags))
 	return nil
 }
 
-// Convert_v1alpha2_SecurityGroup_To_v1alpha3_SecurityGroup is an autogenerated conversion function.
-func Convert_v1alpha2_SecurityGroup_To_v1alpha3_SecurityGroup(in *SecurityGroup, out *v1alpha3.SecurityGroup, s conversion.Scope) error {
-	return autoConvert_v1alpha2_SecurityGroup_To_v1alpha3_SecurityGroup(in, out, s)
-}
-
 func autoConvert_v1alpha3_SecurityGroup_To_v1alpha2_SecurityGroup(in *v1alpha3.SecurityGroup, out *SecurityGroup, s conversion.Scope) error {
 	out.ID = in.ID
 	out.Name = in.Name
-	out.IngressRules = *(*IngressRules)(unsafe.Pointer(&in.IngressRules))
+	if in.IngressRules != nil {
+		in, out := &in.IngressRules, &out.IngressRules
+		*out = make(IngressRules, len(*in))
+		for i := range *in {
+			// TODO: Inefficient conversion - can we improve it?
+			if err := s.Convert(&(*in)[i], &(*out)[i], 0); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.IngressRules = nil
+	}
 	out.Tags = *(*Tags)(unsafe.Pointer(&in.Tags))
 	return nil
 }
 
-// Convert_v1alpha3_SecurityGroup_To_v1alpha2_SecurityGroup is an autogenerated conversion function.
-func Convert_v1alpha3_SecurityGroup_To_v1alpha2_SecurityGroup(in *v1alpha3.SecurityGroup, out *SecurityGroup, s conversion.Scope) error {
-	return autoConvert_v1alpha3_SecurityGroup_To_v1alpha2_SecurityGroup(in, out, s)
-}
-
 func autoConvert_v1alpha2_SubnetSpec_To_v1alpha3_SubnetSpec(in *SubnetSpec, out *v1alpha3.SubnetSpec, s conversion.Scope) error {
 	out.Role = v1alpha3.SubnetRole(in.Role)
 	out.ID = in.ID
@@ -1200,18 +1255,15 @@ func autoConvert_v1alpha3_SubnetSpec_To_v1alpha2_SubnetSpec(in *v1alpha3.SubnetS
 	out.ID = in.ID
 	out.Name = in.Name
 	out.CidrBlock = in.CidrBlock
+	// WARNING: in.CIDRBlocks requires manual conversion: does not exist in peer-type
 	out.InternalLBIPAddress = in.InternalLBIPAddress
 	if err := Convert_v1alpha3_SecurityGroup_To_v1alpha2_SecurityGroup(&in.SecurityGroup, &out.SecurityGroup, s); err != nil {
 		return err
 	}
+	// WARNING: in.NatGateway requires manual conversion: does not exist in peer-type
 	return nil
 }
 
-// Convert_v1alpha3_SubnetSpec_To_v1alpha2_SubnetSpec is an autogenerated conversion function.
-func Convert_v1alpha3_SubnetSpec_To_v1alpha2_SubnetSpec(in *v1alpha3.SubnetSpec, out *SubnetSpec, s conversion.Scope) error {
-	return autoConvert_v1alpha3_SubnetSpec_To_v1alpha2_SubnetSpec(in, out, s)
-}
-
 func autoConvert_v1alpha2_VM_To_v1alpha3_VM(in *VM, out *v1alpha3.VM, s conversion.Scope) error {
 	out.ID = in.ID
 	out.Name = in.Name
@@ -1250,16 +1302,13 @@ func autoConvert_v1alpha3_VM_To_v1alpha2_VM(in *v1alpha3.VM, out *VM, s conversi
 	out.StartupScript = in.StartupScript
 	out.State = VMState(in.State)
 	out.Identity = VMIdentity(in.Identity)
+	// WARNING: in.PrincipalID requires manual conversion: does not exist in peer-type
+	// WARNING: in.Evicted requires manual conversion: does not exist in peer-type
 	out.Tags = *(*Tags)(unsafe.Pointer(&in.Tags))
 	out.Addresses = *(*[]v1.NodeAddress)(unsafe.Pointer(&in.Addresses))
 	return nil
 }
 
-// Convert_v1alpha3_VM_To_v1alpha2_VM is an autogenerated conversion function.
-func Convert_v1alpha3_VM_To_v1alpha2_VM(in *v1alpha3.VM, out *VM, s conversion.Scope) error {
-	return autoConvert_v1alpha3_VM_To_v1alpha2_VM(in, out, s)
-}
-
 func autoConvert_v1alpha2_VnetSpec_To_v1alpha3_VnetSpec(in *VnetSpec, out *v1alpha3.VnetSpec, s conversion.Scope) error {
 	out.ResourceGroup = in.ResourceGroup
 	out.ID = in.ID
@@ -1279,11 +1328,7 @@ func autoConvert_v1alpha3_VnetSpec_To_v1alpha2_VnetSpec(in *v1alpha3.VnetSpec, o
 	out.ID = in.ID
 	out.Name = in.Name
 	out.CidrBlock = in.CidrBlock
+	// WARNING: in.CIDRBlocks requires manual conversion: does not exist in peer-type
 	out.Tags = *(*Tags)(unsafe.Pointer(&in.Tags))
 	return nil
 }
-
-// Convert_v1alpha3_VnetSpec_To_v1alpha2_VnetSpec is an autogenerated conversion function.
-func Convert_v1alpha3_VnetSpec_To_v1alpha2_VnetSpec(in *v1alpha3.VnetSpec, out *VnetSpec, s conversion.Scope) error {
-	return autoConvert_v1alpha3_VnetSpec_To_v1alpha2_VnetSpec(in, out, s)
-}
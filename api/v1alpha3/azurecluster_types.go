@@ -17,6 +17,7 @@ limitations under the License.
 package v1alpha3
 
 import (
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
 )
@@ -32,10 +33,55 @@ type AzureClusterSpec struct {
 	// NetworkSpec encapsulates all things related to Azure network.
 	NetworkSpec NetworkSpec `json:"networkSpec,omitempty"`
 
+	// APIServerLB describes the load balancer in front of the control plane API servers.
+	// +optional
+	APIServerLB LoadBalancerSpec `json:"apiServerLB,omitempty"`
+
+	// Bastion is the bastion host that gives SSH access to the machines of the cluster.
+	// No bastion host is created when unset.
+	// +optional
+	Bastion *BastionSpec `json:"bastion,omitempty"`
+
+	// NodeOutboundLB is the public load balancer that gives worker machines outbound internet access
+	// through its outbound rule. No node outbound load balancer is created when unset.
+	// The cloud provider of the workload cluster must run with disableOutboundSNAT, since it adds the rules
+	// of Services of type LoadBalancer to the same load balancer.
+	// +optional
+	NodeOutboundLB *OutboundLBSpec `json:"nodeOutboundLB,omitempty"`
+
+	// SSHAccess defines how the control plane machines can be reached through SSH.
+	// Defaults to NAT rules of the API server load balancer, from any source.
+	// +optional
+	SSHAccess *SSHAccessSpec `json:"sshAccess,omitempty"`
+
 	ResourceGroup string `json:"resourceGroup"`
 
 	Location string `json:"location"`
 
+	// Environment is the name of the Azure cloud environment the cluster is deployed to:
+	// AzurePublicCloud, AzureChinaCloud, AzureUSGovernmentCloud or AzureGermanCloud.
+	// It selects the Azure Resource Manager and Active Directory endpoints used to manage
+	// the cluster, as well as the DNS zone of its public IP addresses. Defaults to AzureChinaCloud.
+	// +kubebuilder:validation:Enum=AzurePublicCloud;AzureChinaCloud;AzureUSGovernmentCloud;AzureGermanCloud
+	// +optional
+	Environment string `json:"environment,omitempty"`
+
+	// CustomEnvironment defines the endpoints of an Azure cloud that is not one of the well-known
+	// environments, such as Azure Stack. When set, it takes precedence over Environment.
+	// +optional
+	CustomEnvironment *AzureEnvironmentEndpoints `json:"customEnvironment,omitempty"`
+
+	// SubscriptionID is the Azure subscription the cluster's resources are created in.
+	// Defaults to the AZURE_SUBSCRIPTION_ID environment variable of the controller.
+	// +optional
+	SubscriptionID string `json:"subscriptionID,omitempty"`
+
+	// IdentityRef is a reference to the AzureClusterIdentity used to manage the cluster's resources.
+	// The namespace defaults to that of the AzureCluster. When unset, the credentials in the
+	// environment variables of the controller are used.
+	// +optional
+	IdentityRef *corev1.ObjectReference `json:"identityRef,omitempty"`
+
 	// ControlPlaneEndpoint represents the endpoint used to communicate with the control plane.
 	// +optional
 	ControlPlaneEndpoint clusterv1.APIEndpoint `json:"controlPlaneEndpoint"`
@@ -50,8 +96,15 @@ type AzureClusterSpec struct {
 type AzureClusterStatus struct {
 	Network Network `json:"network,omitempty"`
 
+	// Bastion is the observed state of the bastion host, including its addresses.
 	Bastion VM `json:"bastion,omitempty"`
 
+	// FailureDomains specifies the list of unique failure domains for the location/region of the cluster.
+	// A FailureDomain maps to an availability zone, which is a separated group of datacenters within a region.
+	// It is empty in regions without availability zones.
+	// +optional
+	FailureDomains clusterv1.FailureDomains `json:"failureDomains,omitempty"`
+
 	// Ready is true when the provider resource is ready.
 	// +optional
 	Ready bool `json:"ready"`
@@ -0,0 +1,371 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha3
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net"
+
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// ValidateNetworkSpec validates the network spec
+func ValidateNetworkSpec(networkSpec NetworkSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	allErrs = append(allErrs, validateCIDRBlocks(networkSpec.Vnet.CidrBlock, networkSpec.Vnet.CIDRBlocks, fldPath.Child("vnet"))...)
+
+	for i, subnet := range networkSpec.Subnets {
+		if subnet == nil {
+			continue
+		}
+		subnetPath := fldPath.Child("subnets").Index(i)
+		allErrs = append(allErrs, validateCIDRBlocks(subnet.CidrBlock, subnet.CIDRBlocks, subnetPath)...)
+
+		ipv4, ipv6 := countCIDRBlocks(subnet.CIDRBlocks)
+		if ipv4 > 1 || ipv6 > 1 {
+			allErrs = append(allErrs, field.Invalid(subnetPath.Child("cidrBlocks"), subnet.CIDRBlocks, "a subnet can have at most one IPv4 and one IPv6 CIDR block"))
+		}
+		if subnet.IsIPv6Enabled() && !networkSpec.Vnet.IsIPv6Enabled() {
+			allErrs = append(allErrs, field.Invalid(subnetPath.Child("cidrBlocks"), subnet.CIDRBlocks, "an IPv6 CIDR block requires an IPv6 CIDR block on the vnet"))
+		}
+		if subnet.NatGateway != nil && subnet.Role != SubnetNode && subnet.Role != SubnetControlPlane {
+			allErrs = append(allErrs, field.Forbidden(subnetPath.Child("natGateway"), "only node and control plane subnets can have a NAT gateway"))
+		}
+		allErrs = append(allErrs, validateSecurityRules(subnet.SecurityGroup.IngressRules, subnetPath.Child("securityGroup", "ingressRule"))...)
+	}
+
+	allErrs = append(allErrs, validateRoutes(networkSpec.Routes, fldPath.Child("routes"))...)
+
+	return allErrs
+}
+
+// ValidateBastionSpec validates the bastion spec, whose subnet must be in the CIDR block of the vnet if it has one
+func ValidateBastionSpec(bastion *BastionSpec, vnet VnetSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if bastion == nil {
+		return allErrs
+	}
+
+	subnetPath := fldPath.Child("subnet")
+	allErrs = append(allErrs, validateCIDRBlocks(bastion.Subnet.CidrBlock, bastion.Subnet.CIDRBlocks, subnetPath)...)
+	if bastion.Subnet.NatGateway != nil {
+		allErrs = append(allErrs, field.Forbidden(subnetPath.Child("natGateway"), "only node and control plane subnets can have a NAT gateway"))
+	}
+	allErrs = append(allErrs, validateSecurityRules(bastion.Subnet.SecurityGroup.IngressRules, subnetPath.Child("securityGroup", "ingressRule"))...)
+
+	subnetCIDR := bastion.Subnet.CidrBlock
+	if subnetCIDR == "" {
+		subnetCIDR = IPv4CIDRBlock(bastion.Subnet.CIDRBlocks)
+	}
+	if subnetCIDR == "" {
+		subnetCIDR = DefaultBastionSubnetCIDR
+	}
+	vnetCIDR := vnet.CidrBlock
+	if vnetCIDR == "" {
+		vnetCIDR = IPv4CIDRBlock(vnet.CIDRBlocks)
+	}
+	if vnetCIDR != "" && !cidrContains(vnetCIDR, subnetCIDR) {
+		allErrs = append(allErrs, field.Invalid(subnetPath.Child("cidrBlock"), subnetCIDR, fmt.Sprintf("must be in the CIDR block %s of the vnet", vnetCIDR)))
+	}
+
+	if bastion.SSHPublicKey != "" {
+		if _, err := base64.StdEncoding.DecodeString(bastion.SSHPublicKey); err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("sshPublicKey"), bastion.SSHPublicKey, "must be base64-encoded"))
+		}
+	}
+
+	if bastion.Type != BastionTypeAzureBastion {
+		if bastion.SSHPublicKey == "" {
+			allErrs = append(allErrs, field.Required(fldPath.Child("sshPublicKey"), "jumpboxes require an SSH public key"))
+		}
+		return allErrs
+	}
+
+	if bastion.Subnet.Name != "" && bastion.Subnet.Name != AzureBastionSubnetName {
+		allErrs = append(allErrs, field.Invalid(subnetPath.Child("name"), bastion.Subnet.Name, "the subnet of an Azure Bastion host must be named "+AzureBastionSubnetName))
+	}
+	if _, ipNet, err := net.ParseCIDR(subnetCIDR); err == nil {
+		if ones, _ := ipNet.Mask.Size(); ones > 27 {
+			allErrs = append(allErrs, field.Invalid(subnetPath.Child("cidrBlock"), subnetCIDR, "the subnet of an Azure Bastion host must have a prefix of /27 or larger"))
+		}
+	}
+	if bastion.VMSize != "" {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("vmSize"), "only jumpboxes have a VM size"))
+	}
+	if bastion.SSHPublicKey != "" {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("sshPublicKey"), "only jumpboxes have an SSH public key"))
+	}
+
+	return allErrs
+}
+
+// ValidateSSHAccessSpec validates the SSH access spec
+func ValidateSSHAccessSpec(sshAccess *SSHAccessSpec, bastion *BastionSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if sshAccess == nil {
+		return allErrs
+	}
+
+	for i, cidr := range sshAccess.SourceCIDRs {
+		if _, _, err := net.ParseCIDR(cidr); err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("sourceCIDRs").Index(i), cidr, "must be a valid CIDR block"))
+		}
+	}
+	if len(sshAccess.SourceCIDRs) > 0 && sshAccess.Mode != "" && sshAccess.Mode != SSHAccessNAT {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("sourceCIDRs"), "source CIDR blocks only apply to the NAT mode"))
+	}
+	if sshAccess.Mode == SSHAccessBastionOnly && bastion == nil {
+		allErrs = append(allErrs, field.Required(field.NewPath("spec", "bastion"), "the BastionOnly mode requires a bastion host"))
+	}
+
+	return allErrs
+}
+
+// ValidateSecurityRulePriorities validates that the security rules of the control plane and bastion subnets don't
+// take the inbound priority of a default rule the controller adds to their security group, unless they replace it.
+func ValidateSecurityRulePriorities(networkSpec NetworkSpec, bastion *BastionSpec, sshAccess *SSHAccessSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	mode := SSHAccessNAT
+	if sshAccess != nil && sshAccess.Mode != "" {
+		mode = sshAccess.Mode
+	}
+	controlPlaneRules := map[int32]string{101: "allow_6443"}
+	if mode != SSHAccessDisabled {
+		controlPlaneRules[100] = "allow_ssh"
+	}
+	if mode == SSHAccessBastionOnly {
+		controlPlaneRules[4096] = "deny_ssh"
+	}
+
+	for i, subnet := range networkSpec.Subnets {
+		if subnet == nil || subnet.Role != SubnetControlPlane {
+			continue
+		}
+		rulesPath := fldPath.Child("networkSpec", "subnets").Index(i).Child("securityGroup", "ingressRule")
+		allErrs = append(allErrs, validateReservedPriorities(subnet.SecurityGroup.IngressRules, controlPlaneRules, rulesPath)...)
+	}
+	if bastion != nil {
+		rulesPath := fldPath.Child("bastion", "subnet", "securityGroup", "ingressRule")
+		allErrs = append(allErrs, validateReservedPriorities(bastion.Subnet.SecurityGroup.IngressRules, map[int32]string{100: "allow_ssh"}, rulesPath)...)
+	}
+
+	return allErrs
+}
+
+// validateReservedPriorities validates that the inbound security rules don't take the priority of a default rule,
+// given by the names of the default rules by priority, other than the default rule they replace.
+func validateReservedPriorities(rules IngressRules, defaultRules map[int32]string, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	for i, rule := range rules {
+		if rule == nil || rule.Direction == SecurityRuleDirectionOutbound {
+			continue
+		}
+		if name, ok := defaultRules[rule.Priority]; ok && rule.Name != name {
+			allErrs = append(allErrs, field.Invalid(fldPath.Index(i).Child("priority"), rule.Priority, fmt.Sprintf("is the priority of the default rule %s", name)))
+		}
+	}
+
+	return allErrs
+}
+
+// ValidateNatGatewaysUpdate validates that the NAT gateways of the control plane and node subnets are neither
+// removed nor renamed, since the controller would leave the old NAT gateway and its public IP associated with the subnet.
+func ValidateNatGatewaysUpdate(oldSubnets, subnets Subnets, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	for _, role := range []SubnetRole{SubnetControlPlane, SubnetNode} {
+		oldSubnet, _ := subnetByRole(oldSubnets, role)
+		if oldSubnet == nil || oldSubnet.NatGateway == nil {
+			continue
+		}
+		subnet, i := subnetByRole(subnets, role)
+		if subnet == nil {
+			continue
+		}
+		natGatewayPath := fldPath.Index(i).Child("natGateway")
+		if subnet.NatGateway == nil {
+			allErrs = append(allErrs, field.Forbidden(natGatewayPath, "cannot be removed from the subnet, delete the cluster to delete the NAT gateway"))
+			continue
+		}
+		if oldSubnet.NatGateway.Name != "" && subnet.NatGateway.Name != oldSubnet.NatGateway.Name {
+			allErrs = append(allErrs, field.Invalid(natGatewayPath.Child("name"), subnet.NatGateway.Name, "field is immutable"))
+		}
+		if oldSubnet.NatGateway.PublicIP.Name != "" && subnet.NatGateway.PublicIP.Name != oldSubnet.NatGateway.PublicIP.Name {
+			allErrs = append(allErrs, field.Invalid(natGatewayPath.Child("publicIP", "name"), subnet.NatGateway.PublicIP.Name, "field is immutable"))
+		}
+	}
+
+	return allErrs
+}
+
+// subnetByRole returns the first subnet with the given role and its index, or nil if there is none.
+func subnetByRole(subnets Subnets, role SubnetRole) (*SubnetSpec, int) {
+	for i, subnet := range subnets {
+		if subnet != nil && subnet.Role == role {
+			return subnet, i
+		}
+	}
+	return nil, -1
+}
+
+// cidrContains returns true if the CIDR block outer contains the CIDR block inner. Invalid CIDR blocks are
+// reported by validateCIDRBlocks, so they are considered to be contained.
+func cidrContains(outer, inner string) bool {
+	_, outerNet, err := net.ParseCIDR(outer)
+	if err != nil {
+		return true
+	}
+	_, innerNet, err := net.ParseCIDR(inner)
+	if err != nil {
+		return true
+	}
+	outerOnes, _ := outerNet.Mask.Size()
+	innerOnes, _ := innerNet.Mask.Size()
+	return outerNet.Contains(innerNet.IP) && innerOnes >= outerOnes
+}
+
+// validateCIDRBlocks validates the CIDR blocks of a vnet or subnet, which need an IPv4 CIDR block
+// since Azure doesn't support IPv6-only networks, and must contain the single CIDR block if both are set.
+func validateCIDRBlocks(cidrBlock string, cidrBlocks []string, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if cidrBlock != "" {
+		if _, _, err := net.ParseCIDR(cidrBlock); err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("cidrBlock"), cidrBlock, "must be a valid CIDR block"))
+		}
+	}
+
+	if len(cidrBlocks) == 0 {
+		return allErrs
+	}
+	for i, cidr := range cidrBlocks {
+		if _, _, err := net.ParseCIDR(cidr); err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("cidrBlocks").Index(i), cidr, "must be a valid CIDR block"))
+		}
+	}
+	if IPv4CIDRBlock(cidrBlocks) == "" {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("cidrBlocks"), cidrBlocks, "must contain an IPv4 CIDR block"))
+	}
+	if cidrBlock != "" {
+		found := false
+		for _, cidr := range cidrBlocks {
+			if cidr == cidrBlock {
+				found = true
+				break
+			}
+		}
+		if !found {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("cidrBlock"), cidrBlock, "must be one of the cidrBlocks"))
+		}
+	}
+
+	return allErrs
+}
+
+// validateSecurityRules validates the security rules of a security group, which need a unique name
+// and a priority that is unique among the rules in the same direction.
+func validateSecurityRules(rules IngressRules, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	names := make(map[string]bool)
+	priorities := make(map[SecurityRuleDirection]map[int32]bool)
+	for i, rule := range rules {
+		if rule == nil {
+			continue
+		}
+		rulePath := fldPath.Index(i)
+		if rule.Name == "" {
+			allErrs = append(allErrs, field.Required(rulePath.Child("name"), "security rules must have a name"))
+		} else if names[rule.Name] {
+			allErrs = append(allErrs, field.Duplicate(rulePath.Child("name"), rule.Name))
+		}
+		names[rule.Name] = true
+
+		if rule.Priority < 100 || rule.Priority > 4096 {
+			allErrs = append(allErrs, field.Invalid(rulePath.Child("priority"), rule.Priority, "must be between 100 and 4096"))
+			continue
+		}
+		direction := rule.Direction
+		if direction == "" {
+			direction = SecurityRuleDirectionInbound
+		}
+		if priorities[direction] == nil {
+			priorities[direction] = make(map[int32]bool)
+		}
+		if priorities[direction][rule.Priority] {
+			allErrs = append(allErrs, field.Duplicate(rulePath.Child("priority"), rule.Priority))
+		}
+		priorities[direction][rule.Priority] = true
+	}
+
+	return allErrs
+}
+
+// validateRoutes validates the routes of a route table, which need a unique name and destination,
+// and an IP address when the next hop is a virtual appliance.
+func validateRoutes(routes Routes, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	names := make(map[string]bool)
+	prefixes := make(map[string]bool)
+	for i, route := range routes {
+		routePath := fldPath.Index(i)
+		if route.Name == "" {
+			allErrs = append(allErrs, field.Required(routePath.Child("name"), "routes must have a name"))
+		} else if names[route.Name] {
+			allErrs = append(allErrs, field.Duplicate(routePath.Child("name"), route.Name))
+		}
+		names[route.Name] = true
+
+		if _, _, err := net.ParseCIDR(route.AddressPrefix); err != nil {
+			allErrs = append(allErrs, field.Invalid(routePath.Child("addressPrefix"), route.AddressPrefix, "must be a valid CIDR block"))
+		} else if prefixes[route.AddressPrefix] {
+			allErrs = append(allErrs, field.Duplicate(routePath.Child("addressPrefix"), route.AddressPrefix))
+		}
+		prefixes[route.AddressPrefix] = true
+
+		if route.NextHopType == RouteNextHopTypeVirtualAppliance {
+			if net.ParseIP(route.NextHopIPAddress) == nil {
+				allErrs = append(allErrs, field.Invalid(routePath.Child("nextHopIPAddress"), route.NextHopIPAddress, "must be a valid IP address"))
+			}
+		} else if route.NextHopIPAddress != "" {
+			allErrs = append(allErrs, field.Forbidden(routePath.Child("nextHopIPAddress"), "only routes to a virtual appliance have a next hop IP address"))
+		}
+	}
+
+	return allErrs
+}
+
+// countCIDRBlocks returns the number of valid IPv4 and IPv6 CIDR blocks.
+func countCIDRBlocks(cidrBlocks []string) (ipv4 int, ipv6 int) {
+	for _, cidr := range cidrBlocks {
+		ip, _, err := net.ParseCIDR(cidr)
+		if err != nil {
+			continue
+		}
+		if ip.To4() != nil {
+			ipv4++
+		} else {
+			ipv6++
+		}
+	}
+	return ipv4, ipv6
+}
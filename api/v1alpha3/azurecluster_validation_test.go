@@ -0,0 +1,547 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha3
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+func TestValidateNetworkSpec(t *testing.T) {
+	g := NewWithT(t)
+
+	tests := []struct {
+		name        string
+		networkSpec NetworkSpec
+		wantErr     bool
+	}{
+		{
+			name:    "empty network spec",
+			wantErr: false,
+		},
+		{
+			name: "single CIDR blocks",
+			networkSpec: NetworkSpec{
+				Vnet:    VnetSpec{CidrBlock: "10.0.0.0/8"},
+				Subnets: Subnets{{Name: "cp-subnet", CidrBlock: "10.0.0.0/16"}},
+			},
+			wantErr: false,
+		},
+		{
+			name: "dual-stack CIDR blocks",
+			networkSpec: NetworkSpec{
+				Vnet: VnetSpec{CIDRBlocks: []string{"10.0.0.0/8", "2001:1234:5678:9a00::/56"}},
+				Subnets: Subnets{
+					{Name: "cp-subnet", CidrBlock: "10.0.0.0/16", CIDRBlocks: []string{"10.0.0.0/16", "2001:1234:5678:9abc::/64"}},
+					{Name: "node-subnet", CIDRBlocks: []string{"10.1.0.0/16", "2001:1234:5678:9abd::/64"}},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid CIDR block",
+			networkSpec: NetworkSpec{
+				Vnet: VnetSpec{CIDRBlocks: []string{"10.0.0.0/8", "10.1.0.0"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "IPv6-only vnet",
+			networkSpec: NetworkSpec{
+				Vnet: VnetSpec{CIDRBlocks: []string{"2001:1234:5678:9a00::/56"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "CIDR block missing from the CIDR blocks",
+			networkSpec: NetworkSpec{
+				Vnet: VnetSpec{CidrBlock: "10.0.0.0/8", CIDRBlocks: []string{"192.168.0.0/16"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "node subnet with a NAT gateway",
+			networkSpec: NetworkSpec{
+				Subnets: Subnets{{Name: "node-subnet", Role: SubnetNode, NatGateway: &NatGateway{}}},
+			},
+			wantErr: false,
+		},
+		{
+			name: "control plane subnet with a NAT gateway",
+			networkSpec: NetworkSpec{
+				Subnets: Subnets{{Name: "cp-subnet", Role: SubnetControlPlane, NatGateway: &NatGateway{}}},
+			},
+			wantErr: false,
+		},
+		{
+			name: "subnet without a role with a NAT gateway",
+			networkSpec: NetworkSpec{
+				Subnets: Subnets{{Name: "other-subnet", NatGateway: &NatGateway{}}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "subnet with two IPv4 CIDR blocks",
+			networkSpec: NetworkSpec{
+				Vnet:    VnetSpec{CIDRBlocks: []string{"10.0.0.0/8"}},
+				Subnets: Subnets{{Name: "cp-subnet", CIDRBlocks: []string{"10.0.0.0/16", "10.1.0.0/16"}}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "IPv6 subnet in an IPv4 vnet",
+			networkSpec: NetworkSpec{
+				Vnet:    VnetSpec{CIDRBlocks: []string{"10.0.0.0/8"}},
+				Subnets: Subnets{{Name: "cp-subnet", CIDRBlocks: []string{"10.0.0.0/16", "2001:1234:5678:9abc::/64"}}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "security rules with the same priority in different directions",
+			networkSpec: NetworkSpec{
+				Subnets: Subnets{{Name: "node-subnet", SecurityGroup: SecurityGroup{IngressRules: IngressRules{
+					{Name: "allow_node_ports", Protocol: SecurityGroupProtocolTCP, Priority: 200},
+					{Name: "deny_internet", Protocol: SecurityGroupProtocolAll, Priority: 200, Direction: SecurityRuleDirectionOutbound},
+				}}}},
+			},
+			wantErr: false,
+		},
+		{
+			name: "security rule without a name",
+			networkSpec: NetworkSpec{
+				Subnets: Subnets{{Name: "node-subnet", SecurityGroup: SecurityGroup{IngressRules: IngressRules{
+					{Protocol: SecurityGroupProtocolTCP, Priority: 200},
+				}}}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "security rule without a priority",
+			networkSpec: NetworkSpec{
+				Subnets: Subnets{{Name: "node-subnet", SecurityGroup: SecurityGroup{IngressRules: IngressRules{
+					{Name: "allow_node_ports", Protocol: SecurityGroupProtocolTCP},
+				}}}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "security rules with the same name",
+			networkSpec: NetworkSpec{
+				Subnets: Subnets{{Name: "node-subnet", SecurityGroup: SecurityGroup{IngressRules: IngressRules{
+					{Name: "allow_node_ports", Protocol: SecurityGroupProtocolTCP, Priority: 200},
+					{Name: "allow_node_ports", Protocol: SecurityGroupProtocolUDP, Priority: 201},
+				}}}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "security rules with the same priority",
+			networkSpec: NetworkSpec{
+				Subnets: Subnets{{Name: "node-subnet", SecurityGroup: SecurityGroup{IngressRules: IngressRules{
+					{Name: "allow_node_ports", Protocol: SecurityGroupProtocolTCP, Priority: 200},
+					{Name: "allow_vxlan", Protocol: SecurityGroupProtocolUDP, Priority: 200},
+				}}}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "routes",
+			networkSpec: NetworkSpec{
+				Routes: Routes{
+					{Name: "default-egress", AddressPrefix: "0.0.0.0/0", NextHopType: RouteNextHopTypeVirtualAppliance, NextHopIPAddress: "10.100.0.4"},
+					{Name: "blackhole", AddressPrefix: "192.168.0.0/16", NextHopType: RouteNextHopTypeNone},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "route without a name",
+			networkSpec: NetworkSpec{
+				Routes: Routes{{AddressPrefix: "0.0.0.0/0", NextHopType: RouteNextHopTypeInternet}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "route with an invalid address prefix",
+			networkSpec: NetworkSpec{
+				Routes: Routes{{Name: "default-egress", AddressPrefix: "0.0.0.0", NextHopType: RouteNextHopTypeInternet}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "routes with the same address prefix",
+			networkSpec: NetworkSpec{
+				Routes: Routes{
+					{Name: "default-egress", AddressPrefix: "0.0.0.0/0", NextHopType: RouteNextHopTypeInternet},
+					{Name: "firewall-egress", AddressPrefix: "0.0.0.0/0", NextHopType: RouteNextHopTypeVirtualAppliance, NextHopIPAddress: "10.100.0.4"},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "route to a virtual appliance without a next hop IP address",
+			networkSpec: NetworkSpec{
+				Routes: Routes{{Name: "default-egress", AddressPrefix: "0.0.0.0/0", NextHopType: RouteNextHopTypeVirtualAppliance}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "route to the internet with a next hop IP address",
+			networkSpec: NetworkSpec{
+				Routes: Routes{{Name: "default-egress", AddressPrefix: "0.0.0.0/0", NextHopType: RouteNextHopTypeInternet, NextHopIPAddress: "10.100.0.4"}},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			errs := ValidateNetworkSpec(tc.networkSpec, field.NewPath("spec", "networkSpec"))
+			if tc.wantErr {
+				g.Expect(errs).NotTo(BeEmpty())
+			} else {
+				g.Expect(errs).To(BeEmpty())
+			}
+		})
+	}
+}
+
+func TestValidateBastionSpec(t *testing.T) {
+	g := NewWithT(t)
+
+	tests := []struct {
+		name    string
+		bastion *BastionSpec
+		vnet    VnetSpec
+		wantErr bool
+	}{
+		{
+			name:    "no bastion",
+			wantErr: false,
+		},
+		{
+			name:    "azure bastion with defaults",
+			bastion: &BastionSpec{Type: BastionTypeAzureBastion},
+			wantErr: false,
+		},
+		{
+			name: "azure bastion with a custom subnet",
+			bastion: &BastionSpec{
+				Type:   BastionTypeAzureBastion,
+				Subnet: SubnetSpec{Name: AzureBastionSubnetName, CidrBlock: "10.2.0.0/26"},
+			},
+			wantErr: false,
+		},
+		{
+			name: "azure bastion with a wrong subnet name",
+			bastion: &BastionSpec{
+				Type:   BastionTypeAzureBastion,
+				Subnet: SubnetSpec{Name: "bastion-subnet"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "azure bastion with a small subnet",
+			bastion: &BastionSpec{
+				Type:   BastionTypeAzureBastion,
+				Subnet: SubnetSpec{CidrBlock: "10.2.0.0/28"},
+			},
+			wantErr: true,
+		},
+		{
+			name:    "azure bastion with a VM size",
+			bastion: &BastionSpec{Type: BastionTypeAzureBastion, VMSize: "Standard_B1s"},
+			wantErr: true,
+		},
+		{
+			name: "jumpbox",
+			bastion: &BastionSpec{
+				Type:         BastionTypeJumpbox,
+				Subnet:       SubnetSpec{Name: "bastion-subnet", CidrBlock: "10.2.0.0/29"},
+				VMSize:       "Standard_B2s",
+				SSHPublicKey: "c3NoLXJzYSBBQUFB",
+			},
+			wantErr: false,
+		},
+		{
+			name:    "jumpbox without an SSH public key",
+			bastion: &BastionSpec{Type: BastionTypeJumpbox},
+			wantErr: true,
+		},
+		{
+			name:    "jumpbox with an invalid SSH public key",
+			bastion: &BastionSpec{Type: BastionTypeJumpbox, SSHPublicKey: "ssh-rsa AAAA"},
+			wantErr: true,
+		},
+		{
+			name:    "jumpbox with an invalid subnet CIDR block",
+			bastion: &BastionSpec{Type: BastionTypeJumpbox, Subnet: SubnetSpec{CidrBlock: "10.2.0.0"}, SSHPublicKey: "c3NoLXJzYSBBQUFB"},
+			wantErr: true,
+		},
+		{
+			name:    "default subnet in a vnet with a custom CIDR block",
+			bastion: &BastionSpec{Type: BastionTypeJumpbox, SSHPublicKey: "c3NoLXJzYSBBQUFB"},
+			vnet:    VnetSpec{CidrBlock: "10.0.0.0/16"},
+			wantErr: true,
+		},
+		{
+			name: "custom subnet in a vnet with a custom CIDR block",
+			bastion: &BastionSpec{
+				Type:         BastionTypeJumpbox,
+				Subnet:       SubnetSpec{CIDRBlocks: []string{"10.0.255.224/27"}},
+				SSHPublicKey: "c3NoLXJzYSBBQUFB",
+			},
+			vnet:    VnetSpec{CIDRBlocks: []string{"10.0.0.0/16", "2001:1234:5678:9a00::/56"}},
+			wantErr: false,
+		},
+		{
+			name: "subnet larger than the vnet",
+			bastion: &BastionSpec{
+				Type:   BastionTypeAzureBastion,
+				Subnet: SubnetSpec{CidrBlock: "10.0.0.0/24"},
+			},
+			vnet:    VnetSpec{CidrBlock: "10.0.0.0/26"},
+			wantErr: true,
+		},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			errs := ValidateBastionSpec(tc.bastion, tc.vnet, field.NewPath("spec", "bastion"))
+			if tc.wantErr {
+				g.Expect(errs).NotTo(BeEmpty())
+			} else {
+				g.Expect(errs).To(BeEmpty())
+			}
+		})
+	}
+}
+
+func TestValidateSSHAccessSpec(t *testing.T) {
+	g := NewWithT(t)
+
+	tests := []struct {
+		name      string
+		sshAccess *SSHAccessSpec
+		bastion   *BastionSpec
+		wantErr   bool
+	}{
+		{
+			name:    "default SSH access",
+			wantErr: false,
+		},
+		{
+			name:      "NAT from source CIDR blocks",
+			sshAccess: &SSHAccessSpec{Mode: SSHAccessNAT, SourceCIDRs: []string{"203.0.113.0/24", "198.51.100.7/32"}},
+			wantErr:   false,
+		},
+		{
+			name:      "NAT from an invalid source CIDR block",
+			sshAccess: &SSHAccessSpec{Mode: SSHAccessNAT, SourceCIDRs: []string{"203.0.113.0"}},
+			wantErr:   true,
+		},
+		{
+			name:      "disabled with source CIDR blocks",
+			sshAccess: &SSHAccessSpec{Mode: SSHAccessDisabled, SourceCIDRs: []string{"203.0.113.0/24"}},
+			wantErr:   true,
+		},
+		{
+			name:      "bastion only",
+			sshAccess: &SSHAccessSpec{Mode: SSHAccessBastionOnly},
+			bastion:   &BastionSpec{Type: BastionTypeJumpbox},
+			wantErr:   false,
+		},
+		{
+			name:      "bastion only without a bastion",
+			sshAccess: &SSHAccessSpec{Mode: SSHAccessBastionOnly},
+			wantErr:   true,
+		},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			errs := ValidateSSHAccessSpec(tc.sshAccess, tc.bastion, field.NewPath("spec", "sshAccess"))
+			if tc.wantErr {
+				g.Expect(errs).NotTo(BeEmpty())
+			} else {
+				g.Expect(errs).To(BeEmpty())
+			}
+		})
+	}
+}
+
+func TestValidateSecurityRulePriorities(t *testing.T) {
+	g := NewWithT(t)
+
+	controlPlaneSubnet := func(rules ...*IngressRule) NetworkSpec {
+		return NetworkSpec{
+			Subnets: Subnets{
+				{Role: SubnetControlPlane, Name: "cp-subnet", SecurityGroup: SecurityGroup{IngressRules: rules}},
+				{Role: SubnetNode, Name: "node-subnet", SecurityGroup: SecurityGroup{IngressRules: IngressRules{
+					{Name: "allow_node_ports", Priority: 100},
+				}}},
+			},
+		}
+	}
+
+	tests := []struct {
+		name           string
+		networkSpec    NetworkSpec
+		bastion        *BastionSpec
+		sshAccess      *SSHAccessSpec
+		expectedFields []string
+	}{
+		{
+			name:        "rules with free priorities",
+			networkSpec: controlPlaneSubnet(&IngressRule{Name: "allow_cni", Priority: 200}),
+		},
+		{
+			name:           "rule with the priority of allow_ssh",
+			networkSpec:    controlPlaneSubnet(&IngressRule{Name: "allow_cni", Priority: 100}),
+			expectedFields: []string{"spec.networkSpec.subnets[0].securityGroup.ingressRule[0].priority"},
+		},
+		{
+			name:        "rule replacing allow_ssh",
+			networkSpec: controlPlaneSubnet(&IngressRule{Name: "allow_ssh", Priority: 100}),
+		},
+		{
+			name:        "rule with the priority of allow_ssh when SSH is disabled",
+			networkSpec: controlPlaneSubnet(&IngressRule{Name: "allow_cni", Priority: 100}),
+			sshAccess:   &SSHAccessSpec{Mode: SSHAccessDisabled},
+		},
+		{
+			name:           "rule with the priority of allow_6443",
+			networkSpec:    controlPlaneSubnet(&IngressRule{Name: "allow_cni", Priority: 101}),
+			sshAccess:      &SSHAccessSpec{Mode: SSHAccessDisabled},
+			expectedFields: []string{"spec.networkSpec.subnets[0].securityGroup.ingressRule[0].priority"},
+		},
+		{
+			name:        "outbound rule with the priority of allow_6443",
+			networkSpec: controlPlaneSubnet(&IngressRule{Name: "allow_cni", Priority: 101, Direction: SecurityRuleDirectionOutbound}),
+		},
+		{
+			name:        "rule with the priority of deny_ssh in NAT mode",
+			networkSpec: controlPlaneSubnet(&IngressRule{Name: "deny_all", Priority: 4096}),
+		},
+		{
+			name:           "rule with the priority of deny_ssh in BastionOnly mode",
+			networkSpec:    controlPlaneSubnet(&IngressRule{Name: "deny_all", Priority: 4096}),
+			sshAccess:      &SSHAccessSpec{Mode: SSHAccessBastionOnly},
+			expectedFields: []string{"spec.networkSpec.subnets[0].securityGroup.ingressRule[0].priority"},
+		},
+		{
+			name:        "bastion rule with a free priority",
+			networkSpec: controlPlaneSubnet(),
+			bastion: &BastionSpec{Subnet: SubnetSpec{SecurityGroup: SecurityGroup{IngressRules: IngressRules{
+				{Name: "allow_rdp", Priority: 101},
+			}}}},
+		},
+		{
+			name:        "bastion rule with the priority of allow_ssh",
+			networkSpec: controlPlaneSubnet(),
+			bastion: &BastionSpec{Subnet: SubnetSpec{SecurityGroup: SecurityGroup{IngressRules: IngressRules{
+				{Name: "allow_rdp", Priority: 100},
+			}}}},
+			expectedFields: []string{"spec.bastion.subnet.securityGroup.ingressRule[0].priority"},
+		},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			errs := ValidateSecurityRulePriorities(tc.networkSpec, tc.bastion, tc.sshAccess, field.NewPath("spec"))
+			fields := []string{}
+			for _, e := range errs {
+				fields = append(fields, e.Field)
+			}
+			g.Expect(fields).To(ConsistOf(tc.expectedFields))
+		})
+	}
+}
+
+func TestValidateNatGatewaysUpdate(t *testing.T) {
+	g := NewWithT(t)
+
+	subnets := func(natGateway *NatGateway) Subnets {
+		return Subnets{
+			{Role: SubnetControlPlane, Name: "control-plane-subnet"},
+			{Role: SubnetNode, Name: "node-subnet", NatGateway: natGateway},
+		}
+	}
+	defaulted := &NatGateway{Name: "my-cluster-node-natgw", PublicIP: PublicIP{Name: "my-cluster-node-natgw-pip"}}
+	idleTimeout := int32(10)
+
+	tests := []struct {
+		name           string
+		oldSubnets     Subnets
+		subnets        Subnets
+		expectedFields []string
+	}{
+		{
+			name:       "NAT gateway added",
+			oldSubnets: subnets(nil),
+			subnets:    subnets(&NatGateway{}),
+		},
+		{
+			name:       "NAT gateway names defaulted",
+			oldSubnets: subnets(&NatGateway{}),
+			subnets:    subnets(defaulted),
+		},
+		{
+			name:       "NAT gateway idle timeout changed",
+			oldSubnets: subnets(defaulted),
+			subnets: subnets(&NatGateway{
+				Name:                 "my-cluster-node-natgw",
+				IdleTimeoutInMinutes: &idleTimeout,
+				PublicIP:             PublicIP{Name: "my-cluster-node-natgw-pip"},
+			}),
+		},
+		{
+			name:           "NAT gateway removed",
+			oldSubnets:     subnets(defaulted),
+			subnets:        subnets(nil),
+			expectedFields: []string{"spec.networkSpec.subnets[1].natGateway"},
+		},
+		{
+			name:           "NAT gateway renamed",
+			oldSubnets:     subnets(defaulted),
+			subnets:        subnets(&NatGateway{Name: "my-natgw", PublicIP: PublicIP{Name: "my-natgw-pip"}}),
+			expectedFields: []string{"spec.networkSpec.subnets[1].natGateway.name", "spec.networkSpec.subnets[1].natGateway.publicIP.name"},
+		},
+		{
+			name: "control plane NAT gateway removed",
+			oldSubnets: Subnets{
+				{Role: SubnetControlPlane, Name: "control-plane-subnet", NatGateway: &NatGateway{Name: "my-cluster-controlplane-natgw"}},
+			},
+			subnets: Subnets{
+				{Role: SubnetControlPlane, Name: "control-plane-subnet"},
+			},
+			expectedFields: []string{"spec.networkSpec.subnets[0].natGateway"},
+		},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			errs := ValidateNatGatewaysUpdate(tc.oldSubnets, tc.subnets, field.NewPath("spec", "networkSpec", "subnets"))
+			fields := []string{}
+			for _, e := range errs {
+				fields = append(fields, e.Field)
+			}
+			g.Expect(fields).To(ConsistOf(tc.expectedFields))
+		})
+	}
+}
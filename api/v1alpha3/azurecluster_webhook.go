@@ -17,15 +17,78 @@ limitations under the License.
 package v1alpha3
 
 import (
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 	ctrl "sigs.k8s.io/controller-runtime"
 	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
 )
 
 // log is for logging in this package.
-var _ = logf.Log.WithName("azurecluster-resource")
+var clusterlog = logf.Log.WithName("azurecluster-resource")
 
 func (r *AzureCluster) SetupWebhookWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewWebhookManagedBy(mgr).
 		For(r).
 		Complete()
 }
+
+// +kubebuilder:webhook:verbs=create;update,path=/validate-infrastructure-cluster-x-k8s-io-v1alpha3-azurecluster,mutating=false,failurePolicy=fail,matchPolicy=Equivalent,groups=infrastructure.cluster.x-k8s.io,resources=azureclusters,versions=v1alpha3,name=validation.azurecluster.infrastructure.cluster.x-k8s.io
+
+var _ webhook.Validator = &AzureCluster{}
+
+// ValidateCreate implements webhook.Validator so a webhook will be registered for the type
+func (r *AzureCluster) ValidateCreate() error {
+	clusterlog.Info("validate create", "name", r.Name)
+
+	return r.validateCluster()
+}
+
+// ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
+func (r *AzureCluster) ValidateUpdate(old runtime.Object) error {
+	clusterlog.Info("validate update", "name", r.Name)
+
+	var allErrs field.ErrorList
+
+	oldCluster := old.(*AzureCluster)
+	if r.Spec.APIServerLB.Type != oldCluster.Spec.APIServerLB.Type {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("spec", "apiServerLB", "type"), r.Spec.APIServerLB.Type, "field is immutable"))
+	}
+	allErrs = append(allErrs, ValidateNatGatewaysUpdate(oldCluster.Spec.NetworkSpec.Subnets, r.Spec.NetworkSpec.Subnets, field.NewPath("spec", "networkSpec", "subnets"))...)
+
+	if len(allErrs) > 0 {
+		return apierrors.NewInvalid(
+			GroupVersion.WithKind("AzureCluster").GroupKind(),
+			r.Name, allErrs)
+	}
+
+	return r.validateCluster()
+}
+
+// ValidateDelete implements webhook.Validator so a webhook will be registered for the type
+func (r *AzureCluster) ValidateDelete() error {
+	clusterlog.Info("validate delete", "name", r.Name)
+
+	return nil
+}
+
+func (r *AzureCluster) validateCluster() error {
+	var allErrs field.ErrorList
+
+	allErrs = append(allErrs, ValidateNetworkSpec(r.Spec.NetworkSpec, field.NewPath("spec", "networkSpec"))...)
+	allErrs = append(allErrs, ValidateBastionSpec(r.Spec.Bastion, r.Spec.NetworkSpec.Vnet, field.NewPath("spec", "bastion"))...)
+	allErrs = append(allErrs, ValidateSSHAccessSpec(r.Spec.SSHAccess, r.Spec.Bastion, field.NewPath("spec", "sshAccess"))...)
+	allErrs = append(allErrs, ValidateSecurityRulePriorities(r.Spec.NetworkSpec, r.Spec.Bastion, r.Spec.SSHAccess, field.NewPath("spec"))...)
+	if r.Spec.IdentityRef != nil && r.Spec.IdentityRef.Kind != "" && r.Spec.IdentityRef.Kind != "AzureClusterIdentity" {
+		allErrs = append(allErrs, field.NotSupported(field.NewPath("spec", "identityRef", "kind"), r.Spec.IdentityRef.Kind, []string{"AzureClusterIdentity"}))
+	}
+
+	if len(allErrs) > 0 {
+		return apierrors.NewInvalid(
+			GroupVersion.WithKind("AzureCluster").GroupKind(),
+			r.Name, allErrs)
+	}
+
+	return nil
+}
@@ -0,0 +1,90 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha3
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestAzureCluster_ValidateUpdate(t *testing.T) {
+	g := NewWithT(t)
+
+	tests := []struct {
+		name       string
+		oldCluster *AzureCluster
+		cluster    *AzureCluster
+		wantErr    bool
+	}{
+		{
+			name:       "azurecluster with unchanged API server load balancer type",
+			oldCluster: createClusterWithAPIServerLBType(LBTypeInternal),
+			cluster:    createClusterWithAPIServerLBType(LBTypeInternal),
+			wantErr:    false,
+		},
+		{
+			name:       "azurecluster with API server load balancer type changed to internal",
+			oldCluster: createClusterWithAPIServerLBType(""),
+			cluster:    createClusterWithAPIServerLBType(LBTypeInternal),
+			wantErr:    true,
+		},
+		{
+			name:       "azurecluster with API server load balancer type changed to public",
+			oldCluster: createClusterWithAPIServerLBType(LBTypeInternal),
+			cluster:    createClusterWithAPIServerLBType(LBTypePublic),
+			wantErr:    true,
+		},
+		{
+			name:       "azurecluster with the NAT gateway removed from the node subnet",
+			oldCluster: createClusterWithNodeNatGateway(&NatGateway{Name: "my-cluster-node-natgw"}),
+			cluster:    createClusterWithNodeNatGateway(nil),
+			wantErr:    true,
+		},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.cluster.ValidateUpdate(tc.oldCluster)
+			if tc.wantErr {
+				g.Expect(err).To(HaveOccurred())
+			} else {
+				g.Expect(err).NotTo(HaveOccurred())
+			}
+		})
+	}
+}
+
+func createClusterWithAPIServerLBType(lbType LBType) *AzureCluster {
+	return &AzureCluster{
+		Spec: AzureClusterSpec{
+			APIServerLB: LoadBalancerSpec{Type: lbType},
+		},
+	}
+}
+
+func createClusterWithNodeNatGateway(natGateway *NatGateway) *AzureCluster {
+	return &AzureCluster{
+		Spec: AzureClusterSpec{
+			NetworkSpec: NetworkSpec{
+				Subnets: Subnets{
+					{Role: SubnetNode, Name: "node-subnet", NatGateway: natGateway},
+				},
+			},
+		},
+	}
+}
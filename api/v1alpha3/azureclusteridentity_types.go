@@ -0,0 +1,108 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha3
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// IdentityType represents the kind of credential an AzureClusterIdentity authenticates with.
+type IdentityType string
+
+const (
+	// ServicePrincipal authenticates with the client ID and client secret of an Azure AD application.
+	ServicePrincipal = IdentityType("ServicePrincipal")
+	// ServicePrincipalCertificate authenticates with the client ID and a PKCS#12 certificate of an Azure AD application.
+	ServicePrincipalCertificate = IdentityType("ServicePrincipalCertificate")
+	// ManagedIdentity authenticates with the managed identity of the host the controller runs on.
+	ManagedIdentity = IdentityType("ManagedIdentity")
+)
+
+const (
+	// ClientIDKey is the key of the client ID in an AzureClusterIdentity Secret.
+	// For a ManagedIdentity it selects a user-assigned identity; the system-assigned identity is used when unset.
+	ClientIDKey = "clientID"
+	// ClientSecretKey is the key of the client secret in an AzureClusterIdentity Secret.
+	ClientSecretKey = "clientSecret"
+	// CertificateKey is the key of the PKCS#12 certificate in an AzureClusterIdentity Secret.
+	CertificateKey = "certificate"
+	// CertificatePasswordKey is the key of the optional certificate password in an AzureClusterIdentity Secret.
+	CertificatePasswordKey = "certificatePassword"
+)
+
+// AzureClusterIdentitySpec defines the credentials used to manage the Azure resources of a cluster.
+type AzureClusterIdentitySpec struct {
+	// Type is the kind of credential stored in the Secret.
+	// +kubebuilder:validation:Enum=ServicePrincipal;ServicePrincipalCertificate;ManagedIdentity
+	Type IdentityType `json:"type"`
+
+	// TenantID is the Azure Active Directory tenant the identity belongs to.
+	// Required for ServicePrincipal and ServicePrincipalCertificate.
+	// +optional
+	TenantID string `json:"tenantID,omitempty"`
+
+	// SecretRef is a reference to the Secret holding the credentials, under the clientID, clientSecret,
+	// certificate and certificatePassword keys. The Secret must be in the namespace of the AzureClusterIdentity.
+	// Optional for a system-assigned ManagedIdentity.
+	// +optional
+	SecretRef *corev1.SecretReference `json:"secretRef,omitempty"`
+
+	// AllowedNamespaces are the namespaces, besides the namespace of the AzureClusterIdentity,
+	// whose AzureClusters may use the identity. When empty, only the AzureClusters in the namespace
+	// of the AzureClusterIdentity may use it.
+	// +optional
+	AllowedNamespaces []string `json:"allowedNamespaces,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:path=azureclusteridentities,scope=Namespaced,categories=cluster-api
+// +kubebuilder:storageversion
+
+// AzureClusterIdentity is the Schema for the azureclusteridentities API
+type AzureClusterIdentity struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec AzureClusterIdentitySpec `json:"spec,omitempty"`
+}
+
+// IsAllowedNamespace returns true if the AzureClusters in the given namespace may use the identity.
+func (i *AzureClusterIdentity) IsAllowedNamespace(namespace string) bool {
+	if namespace == i.Namespace {
+		return true
+	}
+	for _, allowed := range i.Spec.AllowedNamespaces {
+		if allowed == namespace {
+			return true
+		}
+	}
+	return false
+}
+
+// +kubebuilder:object:root=true
+
+// AzureClusterIdentityList contains a list of AzureClusterIdentity
+type AzureClusterIdentityList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []AzureClusterIdentity `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&AzureClusterIdentity{}, &AzureClusterIdentityList{})
+}
@@ -0,0 +1,83 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha3
+
+import (
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+)
+
+// log is for logging in this package.
+var clusteridentitylog = logf.Log.WithName("azureclusteridentity-resource")
+
+func (r *AzureClusterIdentity) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(r).
+		Complete()
+}
+
+// +kubebuilder:webhook:verbs=create;update,path=/validate-infrastructure-cluster-x-k8s-io-v1alpha3-azureclusteridentity,mutating=false,failurePolicy=fail,matchPolicy=Equivalent,groups=infrastructure.cluster.x-k8s.io,resources=azureclusteridentities,versions=v1alpha3,name=validation.azureclusteridentity.infrastructure.cluster.x-k8s.io
+
+var _ webhook.Validator = &AzureClusterIdentity{}
+
+// ValidateCreate implements webhook.Validator so a webhook will be registered for the type
+func (r *AzureClusterIdentity) ValidateCreate() error {
+	clusteridentitylog.Info("validate create", "name", r.Name)
+
+	return r.validateClusterIdentity()
+}
+
+// ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
+func (r *AzureClusterIdentity) ValidateUpdate(old runtime.Object) error {
+	clusteridentitylog.Info("validate update", "name", r.Name)
+
+	return r.validateClusterIdentity()
+}
+
+// ValidateDelete implements webhook.Validator so a webhook will be registered for the type
+func (r *AzureClusterIdentity) ValidateDelete() error {
+	clusteridentitylog.Info("validate delete", "name", r.Name)
+
+	return nil
+}
+
+func (r *AzureClusterIdentity) validateClusterIdentity() error {
+	var allErrs field.ErrorList
+
+	// the identity must not give access to the secrets of other namespaces
+	if r.Spec.SecretRef != nil && r.Spec.SecretRef.Namespace != "" && r.Spec.SecretRef.Namespace != r.Namespace {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("spec", "secretRef", "namespace"), r.Spec.SecretRef.Namespace, "must be the namespace of the AzureClusterIdentity"))
+	}
+	for i, namespace := range r.Spec.AllowedNamespaces {
+		for _, msg := range validation.IsDNS1123Label(namespace) {
+			allErrs = append(allErrs, field.Invalid(field.NewPath("spec", "allowedNamespaces").Index(i), namespace, msg))
+		}
+	}
+
+	if len(allErrs) > 0 {
+		return apierrors.NewInvalid(
+			GroupVersion.WithKind("AzureClusterIdentity").GroupKind(),
+			r.Name, allErrs)
+	}
+
+	return nil
+}
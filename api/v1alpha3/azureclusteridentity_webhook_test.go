@@ -0,0 +1,93 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha3
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestAzureClusterIdentity_ValidateCreate(t *testing.T) {
+	g := NewWithT(t)
+
+	tests := []struct {
+		name     string
+		identity *AzureClusterIdentity
+		wantErr  bool
+	}{
+		{
+			name:     "secret in the namespace of the identity by default",
+			identity: createClusterIdentity(&corev1.SecretReference{Name: "credentials"}, nil),
+			wantErr:  false,
+		},
+		{
+			name:     "secret in the namespace of the identity",
+			identity: createClusterIdentity(&corev1.SecretReference{Name: "credentials", Namespace: "team-a"}, nil),
+			wantErr:  false,
+		},
+		{
+			name:     "secret in another namespace",
+			identity: createClusterIdentity(&corev1.SecretReference{Name: "credentials", Namespace: "team-b"}, nil),
+			wantErr:  true,
+		},
+		{
+			name:     "allowed namespaces",
+			identity: createClusterIdentity(nil, []string{"team-b", "team-c"}),
+			wantErr:  false,
+		},
+		{
+			name:     "invalid allowed namespace",
+			identity: createClusterIdentity(nil, []string{"Team_B"}),
+			wantErr:  true,
+		},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.identity.ValidateCreate()
+			if tc.wantErr {
+				g.Expect(err).To(HaveOccurred())
+			} else {
+				g.Expect(err).NotTo(HaveOccurred())
+			}
+		})
+	}
+}
+
+func TestAzureClusterIdentity_IsAllowedNamespace(t *testing.T) {
+	g := NewWithT(t)
+
+	identity := createClusterIdentity(nil, []string{"team-b"})
+	g.Expect(identity.IsAllowedNamespace("team-a")).To(BeTrue())
+	g.Expect(identity.IsAllowedNamespace("team-b")).To(BeTrue())
+	g.Expect(identity.IsAllowedNamespace("team-c")).To(BeFalse())
+}
+
+func createClusterIdentity(secretRef *corev1.SecretReference, allowedNamespaces []string) *AzureClusterIdentity {
+	return &AzureClusterIdentity{
+		ObjectMeta: metav1.ObjectMeta{Name: "team-a", Namespace: "team-a"},
+		Spec: AzureClusterIdentitySpec{
+			Type:              ServicePrincipal,
+			TenantID:          "tenant",
+			SecretRef:         secretRef,
+			AllowedNamespaces: allowedNamespaces,
+		},
+	}
+}
@@ -26,6 +26,10 @@ const (
 	// MachineFinalizer allows ReconcileAzureMachine to clean up Azure resources associated with AzureMachine before
 	// removing it from the apiserver.
 	MachineFinalizer = "azuremachine.infrastructure.cluster.x-k8s.io"
+
+	// SpotVMEvictedMachineError is the failure reason of a machine whose spot VM was evicted by Azure.
+	// The machine can't recover from it and must be replaced, for example by a MachineHealthCheck.
+	SpotVMEvictedMachineError errors.MachineStatusError = "SpotVMEvicted"
 )
 
 // AzureMachineSpec defines the desired state of AzureMachine
@@ -46,6 +50,11 @@ type AzureMachineSpec struct {
 
 	OSDisk OSDisk `json:"osDisk"`
 
+	// DataDisks specifies the list of data disks to be created for the machine.
+	// They are created with the virtual machine and deleted with it.
+	// +optional
+	DataDisks []DataDisk `json:"dataDisks,omitempty"`
+
 	Location string `json:"location"`
 
 	SSHPublicKey string `json:"sshPublicKey"`
@@ -59,6 +68,52 @@ type AzureMachineSpec struct {
 	// AllocatePublicIP allows the ability to create dynamic public ips for machines where this value is true.
 	// +optional
 	AllocatePublicIP bool `json:"allocatePublicIP,omitempty"`
+
+	// AcceleratedNetworking enables accelerated networking on the network interfaces of the machine,
+	// which lowers their latency and CPU usage. Defaults to true when the VM size supports it.
+	// +optional
+	AcceleratedNetworking *bool `json:"acceleratedNetworking,omitempty"`
+
+	// NetworkInterfaces are the network interfaces of the machine, created in order.
+	// When empty, the machine gets a single network interface in the subnet of its role.
+	// The public IP and the load balancers of the control plane are attached to the primary network interface.
+	// +optional
+	NetworkInterfaces []NetworkInterface `json:"networkInterfaces,omitempty"`
+
+	// PrivateIPAddress is the static private IP address of the machine in the subnet of its role.
+	// A dynamic IP address is allocated when unset. It can't be set together with NetworkInterfaces,
+	// whose StaticIPAddress serves the same purpose.
+	// +optional
+	PrivateIPAddress string `json:"privateIPAddress,omitempty"`
+
+	// Identity is the type of identity used for the virtual machine.
+	// The type 'SystemAssigned' is an implicitly created identity.
+	// The type 'UserAssigned' is a set of user-assigned identities listed in UserAssignedIdentities.
+	// The generated identities can be used by the cloud provider and workloads to access Azure resources.
+	// Defaults to None.
+	// +kubebuilder:validation:Enum=None;SystemAssigned;UserAssigned
+	// +optional
+	Identity VMIdentity `json:"identity,omitempty"`
+
+	// UserAssignedIdentities is a list of standalone Azure identities provided by the user.
+	// It is required when Identity is 'UserAssigned' and forbidden otherwise.
+	// +optional
+	UserAssignedIdentities []UserAssignedIdentity `json:"userAssignedIdentities,omitempty"`
+
+	// SecurityProfile specifies the security settings of the virtual machine.
+	// +optional
+	SecurityProfile *SecurityProfile `json:"securityProfile,omitempty"`
+
+	// RoleAssignments is a list of roles granted to the system-assigned identity of the virtual machine.
+	// The role assignments are removed when the machine is deleted. Requires Identity to be 'SystemAssigned'.
+	// This field is immutable.
+	// +optional
+	RoleAssignments []RoleAssignment `json:"roleAssignments,omitempty"`
+
+	// SpotVMOptions makes the virtual machine a spot VM, which uses spare Azure capacity at a discount
+	// but can be evicted at any time. An evicted machine is marked as failed and is not recreated.
+	// +optional
+	SpotVMOptions *SpotVMOptions `json:"spotVMOptions,omitempty"`
 }
 
 // AzureMachineStatus defines the observed state of AzureMachine
@@ -74,6 +129,10 @@ type AzureMachineStatus struct {
 	// +optional
 	VMState *VMState `json:"vmState,omitempty"`
 
+	// PrincipalID is the principal ID of the system-assigned identity of the Azure virtual machine.
+	// +optional
+	PrincipalID string `json:"principalID,omitempty"`
+
 	// ErrorReason will be set in the event that there is a terminal problem
 	// reconciling the Machine and will contain a succinct value suitable
 	// for machine interpretation.
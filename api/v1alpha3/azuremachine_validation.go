@@ -0,0 +1,258 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha3
+
+import (
+	"fmt"
+	"math/big"
+	"net"
+	"regexp"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// ValidateUserAssignedIdentity validates the user-assigned identities list
+func ValidateUserAssignedIdentity(identityType VMIdentity, userAssignedIdentities []UserAssignedIdentity, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if identityType != VMIdentityUserAssigned {
+		if len(userAssignedIdentities) > 0 {
+			allErrs = append(allErrs, field.Forbidden(fldPath, "user-assigned identities can only be set when identity is UserAssigned"))
+		}
+		return allErrs
+	}
+
+	if len(userAssignedIdentities) == 0 {
+		allErrs = append(allErrs, field.Required(fldPath, "must specify at least one user-assigned identity when identity is UserAssigned"))
+	}
+	for i, identity := range userAssignedIdentities {
+		if !strings.HasPrefix(identity.ProviderID, "/subscriptions/") || !strings.Contains(identity.ProviderID, "/providers/Microsoft.ManagedIdentity/userAssignedIdentities/") {
+			allErrs = append(allErrs, field.Invalid(fldPath.Index(i).Child("providerID"), identity.ProviderID, "must be the resource ID of a user-assigned identity"))
+		}
+	}
+
+	return allErrs
+}
+
+// ValidateRoleAssignments validates the role assignments of the system-assigned identity
+func ValidateRoleAssignments(identityType VMIdentity, roleAssignments []RoleAssignment, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if len(roleAssignments) > 0 && identityType != VMIdentitySystemAssigned {
+		allErrs = append(allErrs, field.Forbidden(fldPath, "role assignments can only be set when identity is SystemAssigned"))
+	}
+	for i, roleAssignment := range roleAssignments {
+		if roleAssignment.RoleDefinitionID == "" {
+			allErrs = append(allErrs, field.Required(fldPath.Index(i).Child("roleDefinitionID"), "a role definition must be specified"))
+		}
+		if roleAssignment.Scope != "" && !strings.HasPrefix(roleAssignment.Scope, "/subscriptions/") {
+			allErrs = append(allErrs, field.Invalid(fldPath.Index(i).Child("scope"), roleAssignment.Scope, "must be an Azure resource ID"))
+		}
+	}
+
+	return allErrs
+}
+
+// ValidateDataDisks validates the data disks
+func ValidateDataDisks(dataDisks []DataDisk, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	nameSuffixes := map[string]struct{}{}
+	luns := map[int32]struct{}{}
+	for i, disk := range dataDisks {
+		if disk.NameSuffix == "" {
+			allErrs = append(allErrs, field.Required(fldPath.Index(i).Child("nameSuffix"), "a name suffix must be specified"))
+		} else if _, ok := nameSuffixes[disk.NameSuffix]; ok {
+			allErrs = append(allErrs, field.Duplicate(fldPath.Index(i).Child("nameSuffix"), disk.NameSuffix))
+		}
+		nameSuffixes[disk.NameSuffix] = struct{}{}
+
+		if disk.DiskSizeGB < 4 || disk.DiskSizeGB > 32767 {
+			allErrs = append(allErrs, field.Invalid(fldPath.Index(i).Child("diskSizeGB"), disk.DiskSizeGB, "the disk size should be a value between 4 and 32767"))
+		}
+
+		lun := int32(i)
+		if disk.Lun != nil {
+			lun = *disk.Lun
+		}
+		if _, ok := luns[lun]; ok {
+			allErrs = append(allErrs, field.Duplicate(fldPath.Index(i).Child("lun"), fmt.Sprintf("LUN %d is used by more than one data disk", lun)))
+		}
+		luns[lun] = struct{}{}
+
+		if disk.ManagedDisk != nil {
+			allErrs = append(allErrs, validateDiskEncryptionSetID(disk.ManagedDisk.DiskEncryptionSetID, fldPath.Index(i).Child("managedDisk", "diskEncryptionSetID"))...)
+		}
+	}
+
+	return allErrs
+}
+
+// ValidateNetworkInterfaces validates the network interfaces
+func ValidateNetworkInterfaces(networkInterfaces []NetworkInterface, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	primary := false
+	for i, nic := range networkInterfaces {
+		if nic.SubnetName == "" {
+			allErrs = append(allErrs, field.Required(fldPath.Index(i).Child("subnetName"), "a subnet name must be specified"))
+		}
+		if nic.StaticIPAddress != "" {
+			if ip := net.ParseIP(nic.StaticIPAddress); ip == nil || ip.To4() == nil {
+				allErrs = append(allErrs, field.Invalid(fldPath.Index(i).Child("staticIPAddress"), nic.StaticIPAddress, "must be a valid IPv4 address"))
+			}
+		}
+		if nic.Primary {
+			if primary {
+				allErrs = append(allErrs, field.Invalid(fldPath.Index(i).Child("primary"), nic.Primary, "only one network interface can be primary"))
+			}
+			primary = true
+		}
+	}
+
+	return allErrs
+}
+
+// ValidatePrivateIPAddress validates the static private IP address of the machine
+func ValidatePrivateIPAddress(privateIPAddress string, networkInterfaces []NetworkInterface, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if privateIPAddress == "" {
+		return allErrs
+	}
+	if ip := net.ParseIP(privateIPAddress); ip == nil || ip.To4() == nil {
+		allErrs = append(allErrs, field.Invalid(fldPath, privateIPAddress, "must be a valid IPv4 address"))
+	}
+	if len(networkInterfaces) > 0 {
+		allErrs = append(allErrs, field.Forbidden(fldPath, "cannot be set together with network interfaces, set the staticIPAddress of the primary network interface instead"))
+	}
+
+	return allErrs
+}
+
+// ValidateOSDisk validates the OS disk. Whether the VM size supports ephemeral OS disks is checked
+// by the controllers against the capabilities of the VM size.
+func ValidateOSDisk(osDisk OSDisk, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	allErrs = append(allErrs, validateDiskEncryptionSetID(osDisk.ManagedDisk.DiskEncryptionSetID, fldPath.Child("managedDisk", "diskEncryptionSetID"))...)
+
+	if osDisk.DiffDiskSettings == nil {
+		return allErrs
+	}
+
+	if osDisk.DiffDiskSettings.Option != "Local" {
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("diffDiskSettings", "option"), osDisk.DiffDiskSettings.Option, []string{"Local"}))
+	}
+	if osDisk.CachingType != "" && osDisk.CachingType != "ReadOnly" {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("cachingType"), osDisk.CachingType, "ephemeral OS disks only support ReadOnly caching"))
+	}
+
+	return allErrs
+}
+
+// diskEncryptionSetIDRE matches the resource ID of a disk encryption set.
+var diskEncryptionSetIDRE = regexp.MustCompile(`(?i)^/subscriptions/[^/]+/resourceGroups/[^/]+/providers/Microsoft\.Compute/diskEncryptionSets/[^/]+$`)
+
+func validateDiskEncryptionSetID(id string, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if id != "" && !diskEncryptionSetIDRE.MatchString(id) {
+		allErrs = append(allErrs, field.Invalid(fldPath, id, "must be the resource ID of a disk encryption set"))
+	}
+
+	return allErrs
+}
+
+// controlPlaneSubnet returns the control plane subnet, falling back to the first subnet like the controllers do.
+func controlPlaneSubnet(subnets Subnets) *SubnetSpec {
+	if subnet, _ := subnetByRole(subnets, SubnetControlPlane); subnet != nil {
+		return subnet
+	}
+	if len(subnets) > 0 {
+		return subnets[0]
+	}
+	return nil
+}
+
+// nodeSubnet returns the node subnet, falling back to the second subnet like the controllers do.
+func nodeSubnet(subnets Subnets) *SubnetSpec {
+	if subnet, _ := subnetByRole(subnets, SubnetNode); subnet != nil {
+		return subnet
+	}
+	if len(subnets) > 1 {
+		return subnets[1]
+	}
+	return nil
+}
+
+// subnetByName returns the subnet with the given name, or nil if the AzureCluster doesn't define it.
+func subnetByName(subnets Subnets, name string) *SubnetSpec {
+	for _, subnet := range subnets {
+		if subnet != nil && subnet.Name == name {
+			return subnet
+		}
+	}
+	return nil
+}
+
+// validateIPAddressInSubnet validates that an IP address is a usable address of the subnet CIDR of its IP family.
+// Azure reserves the first four and the last address of every subnet.
+func validateIPAddressInSubnet(address string, subnet *SubnetSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	ip := net.ParseIP(address)
+	if ip == nil || subnet == nil {
+		return allErrs
+	}
+	cidrBlocks := subnet.CIDRBlocks
+	if len(cidrBlocks) == 0 && subnet.CidrBlock != "" {
+		cidrBlocks = []string{subnet.CidrBlock}
+	}
+	if len(cidrBlocks) == 0 {
+		return allErrs
+	}
+
+	family, cidrBlock := "IPv4", IPv4CIDRBlock(cidrBlocks)
+	if ip.To4() == nil {
+		family, cidrBlock = "IPv6", IPv6CIDRBlock(cidrBlocks)
+	} else {
+		ip = ip.To4()
+	}
+	if cidrBlock == "" {
+		allErrs = append(allErrs, field.Invalid(fldPath, address, fmt.Sprintf("subnet %s has no %s CIDR block", subnet.Name, family)))
+		return allErrs
+	}
+	_, cidr, err := net.ParseCIDR(cidrBlock)
+	if err != nil {
+		return allErrs
+	}
+
+	if !cidr.Contains(ip) {
+		allErrs = append(allErrs, field.Invalid(fldPath, address, fmt.Sprintf("must be in the CIDR %s of subnet %s", cidrBlock, subnet.Name)))
+		return allErrs
+	}
+	ones, bits := cidr.Mask.Size()
+	offset := new(big.Int).Sub(new(big.Int).SetBytes(ip), new(big.Int).SetBytes(cidr.IP))
+	last := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), uint(bits-ones)), big.NewInt(1))
+	if offset.Cmp(big.NewInt(4)) < 0 || offset.Cmp(last) == 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath, address, fmt.Sprintf("is reserved by Azure in subnet %s", subnet.Name)))
+	}
+
+	return allErrs
+}
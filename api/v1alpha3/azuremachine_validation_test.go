@@ -0,0 +1,420 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha3
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+func TestValidateUserAssignedIdentity(t *testing.T) {
+	g := NewWithT(t)
+
+	validID := "/subscriptions/123/resourceGroups/rg/providers/Microsoft.ManagedIdentity/userAssignedIdentities/id1"
+
+	tests := []struct {
+		name       string
+		identity   VMIdentity
+		identities []UserAssignedIdentity
+		wantErr    bool
+	}{
+		{
+			name:     "no identity",
+			identity: "",
+			wantErr:  false,
+		},
+		{
+			name:     "system-assigned identity",
+			identity: VMIdentitySystemAssigned,
+			wantErr:  false,
+		},
+		{
+			name:       "user-assigned identities without UserAssigned identity type",
+			identity:   VMIdentitySystemAssigned,
+			identities: []UserAssignedIdentity{{ProviderID: validID}},
+			wantErr:    true,
+		},
+		{
+			name:       "user-assigned identity",
+			identity:   VMIdentityUserAssigned,
+			identities: []UserAssignedIdentity{{ProviderID: validID}},
+			wantErr:    false,
+		},
+		{
+			name:     "user-assigned identity type without identities",
+			identity: VMIdentityUserAssigned,
+			wantErr:  true,
+		},
+		{
+			name:       "user-assigned identity with invalid ID",
+			identity:   VMIdentityUserAssigned,
+			identities: []UserAssignedIdentity{{ProviderID: "id1"}},
+			wantErr:    true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			errs := ValidateUserAssignedIdentity(tc.identity, tc.identities, field.NewPath("userAssignedIdentities"))
+			if tc.wantErr {
+				g.Expect(errs).NotTo(BeEmpty())
+			} else {
+				g.Expect(errs).To(BeEmpty())
+			}
+		})
+	}
+}
+
+func TestValidateRoleAssignments(t *testing.T) {
+	g := NewWithT(t)
+
+	tests := []struct {
+		name            string
+		identity        VMIdentity
+		roleAssignments []RoleAssignment
+		wantErr         bool
+	}{
+		{
+			name:     "no role assignments",
+			identity: VMIdentityNone,
+			wantErr:  false,
+		},
+		{
+			name:            "role assignment with system-assigned identity",
+			identity:        VMIdentitySystemAssigned,
+			roleAssignments: []RoleAssignment{{RoleDefinitionID: "b24988ac-6180-42a0-ab88-20f7382dd24c"}},
+			wantErr:         false,
+		},
+		{
+			name:            "role assignment with scope",
+			identity:        VMIdentitySystemAssigned,
+			roleAssignments: []RoleAssignment{{RoleDefinitionID: "b24988ac-6180-42a0-ab88-20f7382dd24c", Scope: "/subscriptions/123/resourceGroups/rg"}},
+			wantErr:         false,
+		},
+		{
+			name:            "role assignment without system-assigned identity",
+			identity:        VMIdentityNone,
+			roleAssignments: []RoleAssignment{{RoleDefinitionID: "b24988ac-6180-42a0-ab88-20f7382dd24c"}},
+			wantErr:         true,
+		},
+		{
+			name:            "role assignment without role definition",
+			identity:        VMIdentitySystemAssigned,
+			roleAssignments: []RoleAssignment{{}},
+			wantErr:         true,
+		},
+		{
+			name:            "role assignment with invalid scope",
+			identity:        VMIdentitySystemAssigned,
+			roleAssignments: []RoleAssignment{{RoleDefinitionID: "b24988ac-6180-42a0-ab88-20f7382dd24c", Scope: "rg"}},
+			wantErr:         true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			errs := ValidateRoleAssignments(tc.identity, tc.roleAssignments, field.NewPath("roleAssignments"))
+			if tc.wantErr {
+				g.Expect(errs).NotTo(BeEmpty())
+			} else {
+				g.Expect(errs).To(BeEmpty())
+			}
+		})
+	}
+}
+
+func TestValidateDataDisks(t *testing.T) {
+	g := NewWithT(t)
+
+	lun0 := int32(0)
+	lun1 := int32(1)
+
+	tests := []struct {
+		name      string
+		dataDisks []DataDisk
+		wantErr   bool
+	}{
+		{
+			name:    "no data disks",
+			wantErr: false,
+		},
+		{
+			name:      "valid data disks",
+			dataDisks: []DataDisk{{NameSuffix: "disk1", DiskSizeGB: 128}, {NameSuffix: "disk2", DiskSizeGB: 64}},
+			wantErr:   false,
+		},
+		{
+			name:      "duplicate name suffix",
+			dataDisks: []DataDisk{{NameSuffix: "disk1", DiskSizeGB: 128}, {NameSuffix: "disk1", DiskSizeGB: 64}},
+			wantErr:   true,
+		},
+		{
+			name:      "missing name suffix",
+			dataDisks: []DataDisk{{DiskSizeGB: 128}},
+			wantErr:   true,
+		},
+		{
+			name:      "invalid disk size",
+			dataDisks: []DataDisk{{NameSuffix: "disk1", DiskSizeGB: 0}},
+			wantErr:   true,
+		},
+		{
+			name:      "duplicate LUN",
+			dataDisks: []DataDisk{{NameSuffix: "disk1", DiskSizeGB: 128, Lun: &lun1}, {NameSuffix: "disk2", DiskSizeGB: 64}},
+			wantErr:   true,
+		},
+		{
+			name:      "invalid disk encryption set",
+			dataDisks: []DataDisk{{NameSuffix: "disk1", DiskSizeGB: 128, ManagedDisk: &ManagedDisk{StorageAccountType: "Premium_LRS", DiskEncryptionSetID: "/subscriptions/123/resourceGroups/rg/providers/Microsoft.KeyVault/vaults/kv"}}},
+			wantErr:   true,
+		},
+		{
+			name:      "explicit LUNs",
+			dataDisks: []DataDisk{{NameSuffix: "disk1", DiskSizeGB: 128, Lun: &lun1}, {NameSuffix: "disk2", DiskSizeGB: 64, Lun: &lun0}},
+			wantErr:   false,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			errs := ValidateDataDisks(tc.dataDisks, field.NewPath("dataDisks"))
+			if tc.wantErr {
+				g.Expect(errs).NotTo(BeEmpty())
+			} else {
+				g.Expect(errs).To(BeEmpty())
+			}
+		})
+	}
+}
+
+func TestValidateNetworkInterfaces(t *testing.T) {
+	g := NewWithT(t)
+
+	tests := []struct {
+		name              string
+		networkInterfaces []NetworkInterface
+		wantErr           bool
+	}{
+		{
+			name:    "no network interfaces",
+			wantErr: false,
+		},
+		{
+			name:              "valid network interfaces",
+			networkInterfaces: []NetworkInterface{{SubnetName: "node-subnet"}, {SubnetName: "storage-subnet", StaticIPAddress: "10.2.0.10", Primary: true}},
+			wantErr:           false,
+		},
+		{
+			name:              "missing subnet name",
+			networkInterfaces: []NetworkInterface{{StaticIPAddress: "10.2.0.10"}},
+			wantErr:           true,
+		},
+		{
+			name:              "invalid static IP address",
+			networkInterfaces: []NetworkInterface{{SubnetName: "node-subnet", StaticIPAddress: "10.2.0.300"}},
+			wantErr:           true,
+		},
+		{
+			name:              "more than one primary",
+			networkInterfaces: []NetworkInterface{{SubnetName: "node-subnet", Primary: true}, {SubnetName: "storage-subnet", Primary: true}},
+			wantErr:           true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			errs := ValidateNetworkInterfaces(tc.networkInterfaces, field.NewPath("networkInterfaces"))
+			if tc.wantErr {
+				g.Expect(errs).NotTo(BeEmpty())
+			} else {
+				g.Expect(errs).To(BeEmpty())
+			}
+		})
+	}
+}
+
+func TestValidatePrivateIPAddress(t *testing.T) {
+	g := NewWithT(t)
+
+	tests := []struct {
+		name              string
+		privateIPAddress  string
+		networkInterfaces []NetworkInterface
+		wantErr           bool
+	}{
+		{
+			name:    "no private IP address",
+			wantErr: false,
+		},
+		{
+			name:             "valid private IP address",
+			privateIPAddress: "10.0.0.10",
+			wantErr:          false,
+		},
+		{
+			name:             "invalid private IP address",
+			privateIPAddress: "10.0.0",
+			wantErr:          true,
+		},
+		{
+			name:             "IPv6 private IP address",
+			privateIPAddress: "fd00::10",
+			wantErr:          true,
+		},
+		{
+			name:              "private IP address with network interfaces",
+			privateIPAddress:  "10.0.0.10",
+			networkInterfaces: []NetworkInterface{{SubnetName: "node-subnet"}},
+			wantErr:           true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			errs := ValidatePrivateIPAddress(tc.privateIPAddress, tc.networkInterfaces, field.NewPath("privateIPAddress"))
+			if tc.wantErr {
+				g.Expect(errs).NotTo(BeEmpty())
+			} else {
+				g.Expect(errs).To(BeEmpty())
+			}
+		})
+	}
+}
+
+func TestValidateOSDisk(t *testing.T) {
+	g := NewWithT(t)
+
+	tests := []struct {
+		name    string
+		osDisk  OSDisk
+		wantErr bool
+	}{
+		{
+			name:    "managed OS disk",
+			osDisk:  OSDisk{OSType: "Linux", DiskSizeGB: 30, CachingType: "ReadWrite"},
+			wantErr: false,
+		},
+		{
+			name:    "ephemeral OS disk",
+			osDisk:  OSDisk{OSType: "Linux", DiskSizeGB: 30, DiffDiskSettings: &DiffDiskSettings{Option: "Local"}},
+			wantErr: false,
+		},
+		{
+			name:    "ephemeral OS disk with read-only caching",
+			osDisk:  OSDisk{OSType: "Linux", DiskSizeGB: 30, DiffDiskSettings: &DiffDiskSettings{Option: "Local"}, CachingType: "ReadOnly"},
+			wantErr: false,
+		},
+		{
+			name:    "ephemeral OS disk with read-write caching",
+			osDisk:  OSDisk{OSType: "Linux", DiskSizeGB: 30, DiffDiskSettings: &DiffDiskSettings{Option: "Local"}, CachingType: "ReadWrite"},
+			wantErr: true,
+		},
+		{
+			name:    "ephemeral OS disk with an unsupported option",
+			osDisk:  OSDisk{OSType: "Linux", DiskSizeGB: 30, DiffDiskSettings: &DiffDiskSettings{Option: "Remote"}},
+			wantErr: true,
+		},
+		{
+			name:    "OS disk with disk encryption set",
+			osDisk:  OSDisk{OSType: "Linux", DiskSizeGB: 30, ManagedDisk: ManagedDisk{StorageAccountType: "Premium_LRS", DiskEncryptionSetID: "/subscriptions/123/resourceGroups/rg/providers/Microsoft.Compute/diskEncryptionSets/des"}},
+			wantErr: false,
+		},
+		{
+			name:    "OS disk with invalid disk encryption set",
+			osDisk:  OSDisk{OSType: "Linux", DiskSizeGB: 30, ManagedDisk: ManagedDisk{StorageAccountType: "Premium_LRS", DiskEncryptionSetID: "des"}},
+			wantErr: true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			errs := ValidateOSDisk(tc.osDisk, field.NewPath("osDisk"))
+			if tc.wantErr {
+				g.Expect(errs).NotTo(BeEmpty())
+			} else {
+				g.Expect(errs).To(BeEmpty())
+			}
+		})
+	}
+}
+
+func TestValidateIPAddressInSubnet(t *testing.T) {
+	g := NewWithT(t)
+
+	ipv4Subnet := &SubnetSpec{Name: "node-subnet", CidrBlock: "10.1.0.0/16"}
+	dualStackSubnet := &SubnetSpec{Name: "node-subnet", CIDRBlocks: []string{"10.1.0.0/16", "2001:1234:5678:9abd::/64"}}
+
+	tests := []struct {
+		name          string
+		address       string
+		subnet        *SubnetSpec
+		expectedError string
+	}{
+		{
+			name:    "IPv4 address in the CIDR block",
+			address: "10.1.0.10",
+			subnet:  ipv4Subnet,
+		},
+		{
+			name:    "IPv4 address in the CIDR blocks",
+			address: "10.1.0.10",
+			subnet:  dualStackSubnet,
+		},
+		{
+			name:          "IPv4 address outside of the CIDR blocks",
+			address:       "10.2.0.10",
+			subnet:        dualStackSubnet,
+			expectedError: "privateIPAddress: Invalid value: \"10.2.0.10\": must be in the CIDR 10.1.0.0/16 of subnet node-subnet",
+		},
+		{
+			name:    "IPv6 address in the IPv6 CIDR block",
+			address: "2001:1234:5678:9abd::10",
+			subnet:  dualStackSubnet,
+		},
+		{
+			name:          "IPv6 address outside of the IPv6 CIDR block",
+			address:       "2001:1234:5678:9abe::10",
+			subnet:        dualStackSubnet,
+			expectedError: "privateIPAddress: Invalid value: \"2001:1234:5678:9abe::10\": must be in the CIDR 2001:1234:5678:9abd::/64 of subnet node-subnet",
+		},
+		{
+			name:          "IPv6 address reserved by Azure",
+			address:       "2001:1234:5678:9abd::3",
+			subnet:        dualStackSubnet,
+			expectedError: "privateIPAddress: Invalid value: \"2001:1234:5678:9abd::3\": is reserved by Azure in subnet node-subnet",
+		},
+		{
+			name:          "IPv6 address in an IPv4 subnet",
+			address:       "2001:1234:5678:9abd::10",
+			subnet:        ipv4Subnet,
+			expectedError: "privateIPAddress: Invalid value: \"2001:1234:5678:9abd::10\": subnet node-subnet has no IPv6 CIDR block",
+		},
+		{
+			name:    "subnet without CIDR blocks",
+			address: "10.1.0.10",
+			subnet:  &SubnetSpec{Name: "node-subnet"},
+		},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			errs := validateIPAddressInSubnet(tc.address, tc.subnet, field.NewPath("privateIPAddress"))
+			if tc.expectedError != "" {
+				g.Expect(errs.ToAggregate()).To(MatchError(tc.expectedError))
+			} else {
+				g.Expect(errs).To(BeEmpty())
+			}
+		})
+	}
+}
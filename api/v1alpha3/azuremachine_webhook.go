@@ -17,10 +17,15 @@ limitations under the License.
 package v1alpha3
 
 import (
+	"context"
+	"reflect"
+
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/validation/field"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 )
@@ -28,8 +33,13 @@ import (
 // log is for logging in this package.
 var machinelog = logf.Log.WithName("azuremachine-resource")
 
+// clusterReader reads the AzureCluster of an AzureMachine in the webhook.
+// It is nil when the webhook is not registered with a manager, which skips the validations that need it.
+var clusterReader client.Reader
+
 // SetupWebhookWithManager will setup and register the webhook with the controller mnager
 func (m *AzureMachine) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	clusterReader = mgr.GetAPIReader()
 	return ctrl.NewWebhookManagedBy(mgr).
 		For(m).
 		Complete()
@@ -43,10 +53,28 @@ var _ webhook.Validator = &AzureMachine{}
 func (m *AzureMachine) ValidateCreate() error {
 	machinelog.Info("validate create", "name", m.Name)
 
-	if errs := ValidateImage(m.Spec.Image, field.NewPath("image")); len(errs) > 0 {
+	var allErrs field.ErrorList
+
+	allErrs = append(allErrs, ValidateImage(m.Spec.Image, field.NewPath("image"))...)
+	allErrs = append(allErrs, ValidateOSDisk(m.Spec.OSDisk, field.NewPath("osDisk"))...)
+	allErrs = append(allErrs, ValidateDataDisks(m.Spec.DataDisks, field.NewPath("dataDisks"))...)
+	allErrs = append(allErrs, ValidateNetworkInterfaces(m.Spec.NetworkInterfaces, field.NewPath("networkInterfaces"))...)
+	allErrs = append(allErrs, ValidatePrivateIPAddress(m.Spec.PrivateIPAddress, m.Spec.NetworkInterfaces, field.NewPath("privateIPAddress"))...)
+
+	if clusterReader != nil {
+		ipErrs, err := m.validateStaticIPAddresses(context.Background(), clusterReader)
+		if err != nil {
+			return apierrors.NewInternalError(err)
+		}
+		allErrs = append(allErrs, ipErrs...)
+	}
+	allErrs = append(allErrs, ValidateUserAssignedIdentity(m.Spec.Identity, m.Spec.UserAssignedIdentities, field.NewPath("userAssignedIdentities"))...)
+	allErrs = append(allErrs, ValidateRoleAssignments(m.Spec.Identity, m.Spec.RoleAssignments, field.NewPath("roleAssignments"))...)
+
+	if len(allErrs) > 0 {
 		return apierrors.NewInvalid(
 			GroupVersion.WithKind("AzureMachine").GroupKind(),
-			m.Name, errs)
+			m.Name, allErrs)
 	}
 
 	return nil
@@ -56,6 +84,20 @@ func (m *AzureMachine) ValidateCreate() error {
 func (m *AzureMachine) ValidateUpdate(old runtime.Object) error {
 	machinelog.Info("validate update", "name", m.Name)
 
+	var allErrs field.ErrorList
+
+	// role assignments are only deleted with the machine, so removing one from the list would leave its grant behind.
+	oldMachine := old.(*AzureMachine)
+	if !reflect.DeepEqual(m.Spec.RoleAssignments, oldMachine.Spec.RoleAssignments) {
+		allErrs = append(allErrs, field.Forbidden(field.NewPath("spec", "roleAssignments"), "field is immutable"))
+	}
+
+	if len(allErrs) > 0 {
+		return apierrors.NewInvalid(
+			GroupVersion.WithKind("AzureMachine").GroupKind(),
+			m.Name, allErrs)
+	}
+
 	return nil
 }
 
@@ -65,3 +107,70 @@ func (m *AzureMachine) ValidateDelete() error {
 
 	return nil
 }
+
+// validateStaticIPAddresses validates the static private IP addresses of the machine against the subnets of its
+// AzureCluster. The validations are skipped while the machine doesn't belong to a cluster or the AzureCluster
+// doesn't exist yet. Conflicts with the addresses of other machines are checked by the controller.
+func (m *AzureMachine) validateStaticIPAddresses(ctx context.Context, c client.Reader) (field.ErrorList, error) {
+	allErrs := field.ErrorList{}
+
+	clusterName, ok := m.Labels[clusterv1.ClusterLabelName]
+	if !ok || (m.Spec.PrivateIPAddress == "" && !hasStaticIPAddress(m.Spec.NetworkInterfaces)) {
+		return allErrs, nil
+	}
+	azureCluster, err := getAzureCluster(ctx, c, m.Namespace, clusterName)
+	if err != nil || azureCluster == nil {
+		return allErrs, err
+	}
+
+	subnets := azureCluster.Spec.NetworkSpec.Subnets
+	if m.Spec.PrivateIPAddress != "" {
+		subnet := nodeSubnet(subnets)
+		if _, ok := m.Labels[clusterv1.MachineControlPlaneLabelName]; ok {
+			subnet = controlPlaneSubnet(subnets)
+		}
+		allErrs = append(allErrs, validateIPAddressInSubnet(m.Spec.PrivateIPAddress, subnet, field.NewPath("privateIPAddress"))...)
+	}
+	for i, nic := range m.Spec.NetworkInterfaces {
+		if nic.StaticIPAddress != "" {
+			allErrs = append(allErrs, validateIPAddressInSubnet(nic.StaticIPAddress, subnetByName(subnets, nic.SubnetName),
+				field.NewPath("networkInterfaces").Index(i).Child("staticIPAddress"))...)
+		}
+	}
+
+	return allErrs, nil
+}
+
+// hasStaticIPAddress returns true if any of the network interfaces has a static IP address.
+func hasStaticIPAddress(networkInterfaces []NetworkInterface) bool {
+	for _, nic := range networkInterfaces {
+		if nic.StaticIPAddress != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// getAzureCluster returns the AzureCluster of a cluster, or nil if the cluster or its AzureCluster doesn't exist yet.
+func getAzureCluster(ctx context.Context, c client.Reader, namespace, clusterName string) (*AzureCluster, error) {
+	cluster := &clusterv1.Cluster{}
+	if err := c.Get(ctx, client.ObjectKey{Namespace: namespace, Name: clusterName}, cluster); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	ref := cluster.Spec.InfrastructureRef
+	if ref == nil || ref.Kind != "AzureCluster" {
+		return nil, nil
+	}
+
+	azureCluster := &AzureCluster{}
+	if err := c.Get(ctx, client.ObjectKey{Namespace: namespace, Name: ref.Name}, azureCluster); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return azureCluster, nil
+}
@@ -17,9 +17,15 @@ limitations under the License.
 package v1alpha3
 
 import (
+	"context"
 	"testing"
 
 	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
 
 func TestAzureMachine_ValidateCreate(t *testing.T) {
@@ -120,3 +126,151 @@ func createMachineWithImageByID(t *testing.T, imageID string) *AzureMachine {
 		},
 	}
 }
+
+func TestAzureMachine_ValidateUpdate(t *testing.T) {
+	g := NewWithT(t)
+
+	roleAssignment := RoleAssignment{RoleDefinitionID: "b24988ac-6180-42a0-ab88-20f7382dd24c"}
+
+	tests := []struct {
+		name       string
+		oldMachine *AzureMachine
+		machine    *AzureMachine
+		wantErr    bool
+	}{
+		{
+			name:       "unchanged role assignments",
+			oldMachine: &AzureMachine{Spec: AzureMachineSpec{Identity: VMIdentitySystemAssigned, RoleAssignments: []RoleAssignment{roleAssignment}}},
+			machine:    &AzureMachine{Spec: AzureMachineSpec{Identity: VMIdentitySystemAssigned, RoleAssignments: []RoleAssignment{roleAssignment}}},
+			wantErr:    false,
+		},
+		{
+			name:       "role assignment added",
+			oldMachine: &AzureMachine{Spec: AzureMachineSpec{Identity: VMIdentitySystemAssigned}},
+			machine:    &AzureMachine{Spec: AzureMachineSpec{Identity: VMIdentitySystemAssigned, RoleAssignments: []RoleAssignment{roleAssignment}}},
+			wantErr:    true,
+		},
+		{
+			name:       "role assignment removed",
+			oldMachine: &AzureMachine{Spec: AzureMachineSpec{Identity: VMIdentitySystemAssigned, RoleAssignments: []RoleAssignment{roleAssignment}}},
+			machine:    &AzureMachine{Spec: AzureMachineSpec{Identity: VMIdentitySystemAssigned}},
+			wantErr:    true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.machine.ValidateUpdate(tc.oldMachine)
+			if tc.wantErr {
+				g.Expect(err).To(HaveOccurred())
+			} else {
+				g.Expect(err).NotTo(HaveOccurred())
+			}
+		})
+	}
+}
+
+func TestAzureMachine_ValidateStaticIPAddresses(t *testing.T) {
+	g := NewWithT(t)
+
+	scheme := runtime.NewScheme()
+	g.Expect(AddToScheme(scheme)).To(Succeed())
+	g.Expect(clusterv1.AddToScheme(scheme)).To(Succeed())
+
+	cluster := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-cluster", Namespace: "default"},
+		Spec: clusterv1.ClusterSpec{
+			InfrastructureRef: &corev1.ObjectReference{Kind: "AzureCluster", Name: "my-azure-cluster"},
+		},
+	}
+	azureCluster := &AzureCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-azure-cluster", Namespace: "default"},
+		Spec: AzureClusterSpec{
+			NetworkSpec: NetworkSpec{
+				Subnets: Subnets{
+					{Role: SubnetControlPlane, Name: "cp-subnet", CidrBlock: "10.0.0.0/16"},
+					{Role: SubnetNode, Name: "node-subnet", CidrBlock: "10.1.0.0/16"},
+					{Name: "storage-subnet", CidrBlock: "10.2.0.0/24"},
+				},
+			},
+		},
+	}
+
+	controlPlaneLabels := map[string]string{clusterv1.ClusterLabelName: "my-cluster", clusterv1.MachineControlPlaneLabelName: ""}
+	nodeLabels := map[string]string{clusterv1.ClusterLabelName: "my-cluster"}
+
+	tests := []struct {
+		name           string
+		machine        *AzureMachine
+		expectedFields []string
+	}{
+		{
+			name:    "control plane IP address in the control plane subnet",
+			machine: withPrivateIPAddress(newMachineWithLabels("cp-0", controlPlaneLabels), "10.0.0.10"),
+		},
+		{
+			name:           "control plane IP address outside of the control plane subnet",
+			machine:        withPrivateIPAddress(newMachineWithLabels("cp-0", controlPlaneLabels), "10.1.0.30"),
+			expectedFields: []string{"privateIPAddress"},
+		},
+		{
+			name:    "node IP address in the node subnet",
+			machine: withPrivateIPAddress(newMachineWithLabels("node-0", nodeLabels), "10.1.0.10"),
+		},
+		{
+			name:           "IP address reserved by Azure",
+			machine:        withPrivateIPAddress(newMachineWithLabels("node-0", nodeLabels), "10.1.0.3"),
+			expectedFields: []string{"privateIPAddress"},
+		},
+		{
+			name:           "broadcast address of the subnet",
+			machine:        withPrivateIPAddress(newMachineWithLabels("node-0", nodeLabels), "10.1.255.255"),
+			expectedFields: []string{"privateIPAddress"},
+		},
+		{
+			name: "static IP addresses of network interfaces",
+			machine: withNetworkInterfaces(newMachineWithLabels("node-0", nodeLabels), []NetworkInterface{
+				{SubnetName: "node-subnet", StaticIPAddress: "10.1.0.10"},
+				{SubnetName: "storage-subnet", StaticIPAddress: "10.2.1.10"},
+				{SubnetName: "unknown-subnet", StaticIPAddress: "10.3.0.10"},
+			}),
+			expectedFields: []string{"networkInterfaces[1].staticIPAddress"},
+		},
+		{
+			name:    "machine without a cluster",
+			machine: withPrivateIPAddress(newMachineWithLabels("node-0", nil), "10.5.0.1"),
+		},
+		{
+			name:    "cluster that doesn't exist yet",
+			machine: withPrivateIPAddress(newMachineWithLabels("node-0", map[string]string{clusterv1.ClusterLabelName: "new-cluster"}), "10.5.0.1"),
+		},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			c := fake.NewFakeClientWithScheme(scheme, cluster.DeepCopy(), azureCluster.DeepCopy())
+			errs, err := tc.machine.validateStaticIPAddresses(context.TODO(), c)
+			g.Expect(err).NotTo(HaveOccurred())
+			fields := []string{}
+			for _, e := range errs {
+				fields = append(fields, e.Field)
+			}
+			g.Expect(fields).To(ConsistOf(tc.expectedFields))
+		})
+	}
+}
+
+func newMachineWithLabels(name string, labels map[string]string) *AzureMachine {
+	return &AzureMachine{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Labels: labels},
+	}
+}
+
+func withPrivateIPAddress(m *AzureMachine, privateIPAddress string) *AzureMachine {
+	m.Spec.PrivateIPAddress = privateIPAddress
+	return m
+}
+
+func withNetworkInterfaces(m *AzureMachine, networkInterfaces []NetworkInterface) *AzureMachine {
+	m.Spec.NetworkInterfaces = networkInterfaces
+	return m
+}
@@ -17,9 +17,11 @@ limitations under the License.
 package v1alpha3
 
 import (
+	"net"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -39,6 +41,25 @@ type AzureResourceReference struct {
 
 // TODO: Investigate resource filters
 
+// AzureEnvironmentEndpoints defines the endpoints of a custom Azure cloud environment.
+type AzureEnvironmentEndpoints struct {
+	// Name is the name of the environment, as written to the "cloud" field of the cloud provider configuration.
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// ResourceManagerEndpoint is the Azure Resource Manager endpoint, e.g. https://management.azure.com/.
+	// +kubebuilder:validation:MinLength=1
+	ResourceManagerEndpoint string `json:"resourceManagerEndpoint"`
+
+	// ActiveDirectoryEndpoint is the Azure Active Directory endpoint used to authenticate, e.g. https://login.microsoftonline.com/.
+	// +kubebuilder:validation:MinLength=1
+	ActiveDirectoryEndpoint string `json:"activeDirectoryEndpoint"`
+
+	// ResourceManagerVMDNSSuffix is the DNS zone of public IP addresses, e.g. cloudapp.azure.com.
+	// +kubebuilder:validation:MinLength=1
+	ResourceManagerVMDNSSuffix string `json:"resourceManagerVMDNSSuffix"`
+}
+
 // AzureMachineProviderConditionType is a valid value for AzureMachineProviderCondition.Type
 type AzureMachineProviderConditionType string
 
@@ -86,6 +107,9 @@ type Network struct {
 
 	// APIServerIP is the Kubernetes API server public IP address.
 	APIServerIP PublicIP `json:"apiServerIp,omitempty"`
+
+	// APIServerIPv6 is the Kubernetes API server public IPv6 address of dual-stack clusters.
+	APIServerIPv6 PublicIP `json:"apiServerIpv6,omitempty"`
 }
 
 // NetworkSpec encapsulates all things related to Azure network.
@@ -97,8 +121,52 @@ type NetworkSpec struct {
 	// Subnets is the configuration for the control-plane subnet and the node subnet.
 	// +optional
 	Subnets Subnets `json:"subnets,omitempty"`
+
+	// Routes are the routes of the node route table, which both the control-plane subnet and the node subnet use.
+	// Routes that aren't listed, such as those the cloud provider adds, are left in place.
+	// A default route to a virtual appliance makes the replies of a public API server load balancer
+	// leave through the appliance, so the API server is unreachable from outside the vnet.
+	// +optional
+	Routes Routes `json:"routes,omitempty"`
 }
 
+// RouteNextHopType defines the type of the next hop of a route.
+type RouteNextHopType string
+
+const (
+	// RouteNextHopTypeVirtualNetworkGateway forwards traffic to the virtual network gateway.
+	RouteNextHopTypeVirtualNetworkGateway = RouteNextHopType("VirtualNetworkGateway")
+	// RouteNextHopTypeVnetLocal keeps traffic within the virtual network.
+	RouteNextHopTypeVnetLocal = RouteNextHopType("VnetLocal")
+	// RouteNextHopTypeInternet forwards traffic to the internet.
+	RouteNextHopTypeInternet = RouteNextHopType("Internet")
+	// RouteNextHopTypeVirtualAppliance forwards traffic to a network virtual appliance, such as a firewall.
+	RouteNextHopTypeVirtualAppliance = RouteNextHopType("VirtualAppliance")
+	// RouteNextHopTypeNone drops traffic.
+	RouteNextHopTypeNone = RouteNextHopType("None")
+)
+
+// Route defines a user-defined route of a route table.
+type Route struct {
+	// Name is the name of the route, unique within the route table.
+	Name string `json:"name"`
+
+	// AddressPrefix is the destination CIDR block the route applies to.
+	AddressPrefix string `json:"addressPrefix"`
+
+	// NextHopType is the type of Azure hop traffic is forwarded to.
+	// +kubebuilder:validation:Enum=VirtualNetworkGateway;VnetLocal;Internet;VirtualAppliance;None
+	NextHopType RouteNextHopType `json:"nextHopType"`
+
+	// NextHopIPAddress is the IP address traffic is forwarded to.
+	// For the VirtualAppliance next hop type only.
+	// +optional
+	NextHopIPAddress string `json:"nextHopIPAddress,omitempty"`
+}
+
+// Routes is a slice of routes.
+type Routes []Route
+
 // VnetSpec configures an Azure virtual network.
 type VnetSpec struct {
 	// ResourceGroup is the name of the resource group of the existing virtual network
@@ -114,6 +182,11 @@ type VnetSpec struct {
 	// CidrBlock is the CIDR block to be used when the provider creates a managed virtual network.
 	CidrBlock string `json:"cidrBlock,omitempty"`
 
+	// CIDRBlocks are the CIDR blocks to be used when the provider creates a managed virtual network.
+	// Add an IPv6 CIDR block to create a dual-stack virtual network. Defaults to CidrBlock.
+	// +optional
+	CIDRBlocks []string `json:"cidrBlocks,omitempty"`
+
 	// Tags is a collection of tags describing the resource.
 	Tags Tags `json:"tags,omitempty"`
 }
@@ -123,6 +196,11 @@ func (v *VnetSpec) IsManaged(clusterName string) bool {
 	return v.ID == "" || v.Tags.HasOwned(clusterName)
 }
 
+// IsIPv6Enabled returns true if the vnet has an IPv6 CIDR block.
+func (v *VnetSpec) IsIPv6Enabled() bool {
+	return IPv6CIDRBlock(v.CIDRBlocks) != ""
+}
+
 // Subnets is a slice of Subnet.
 type Subnets []*SubnetSpec
 
@@ -179,11 +257,37 @@ var (
 	SecurityGroupProtocolUDP = SecurityGroupProtocol("Udp")
 )
 
-// IngressRule defines an Azure ingress rule for security groups.
+// SecurityRuleDirection defines the direction of traffic a security group rule applies to.
+type SecurityRuleDirection string
+
+var (
+	// SecurityRuleDirectionInbound applies a security group rule to incoming traffic
+	SecurityRuleDirectionInbound = SecurityRuleDirection("Inbound")
+
+	// SecurityRuleDirectionOutbound applies a security group rule to outgoing traffic
+	SecurityRuleDirectionOutbound = SecurityRuleDirection("Outbound")
+)
+
+// IngressRule defines an Azure security rule for security groups.
+// Despite its name, it can also apply to outgoing traffic.
 type IngressRule struct {
+	// Name - The name of the security rule, unique within the security group.
+	Name string `json:"name"`
+
 	Description string                `json:"description"`
 	Protocol    SecurityGroupProtocol `json:"protocol"`
 
+	// Priority - The priority of the rule, unique per direction within the security group.
+	// Rules with a lower priority are evaluated first.
+	// +kubebuilder:validation:Minimum=100
+	// +kubebuilder:validation:Maximum=4096
+	Priority int32 `json:"priority"`
+
+	// Direction - Whether the rule applies to incoming or outgoing traffic. Defaults to Inbound.
+	// +kubebuilder:validation:Enum=Inbound;Outbound
+	// +optional
+	Direction SecurityRuleDirection `json:"direction,omitempty"`
+
 	// SourcePorts - The source port or range. Integer or range between 0 and 65535. Asterix '*' can also be used to match all ports.
 	SourcePorts *string `json:"sourcePorts,omitempty"`
 
@@ -273,6 +377,30 @@ type LoadBalancer struct {
 	*/
 }
 
+// LoadBalancerSpec defines the load balancer of the cluster API server.
+type LoadBalancerSpec struct {
+	// Type is the type of the API server load balancer. A Public load balancer exposes the API server
+	// through a public IP, while an Internal load balancer only exposes it inside the vnet. Defaults to Public.
+	// +kubebuilder:validation:Enum=Public;Internal
+	// +optional
+	Type LBType `json:"type,omitempty"`
+}
+
+// LBType defines the type of a load balancer.
+type LBType string
+
+const (
+	// LBTypePublic is a load balancer with a public frontend IP.
+	LBTypePublic = LBType("Public")
+	// LBTypeInternal is a load balancer with a private frontend IP in the control plane subnet.
+	LBTypeInternal = LBType("Internal")
+)
+
+// IsInternal returns true if the load balancer is internal.
+func (l LoadBalancerSpec) IsInternal() bool {
+	return l.Type == LBTypeInternal
+}
+
 // LoadBalancerSKU enumerates the values for load balancer sku name.
 type SKU string
 
@@ -367,7 +495,11 @@ type VM struct {
 	// State - The provisioning state, which only appears in the response.
 	State    VMState    `json:"vmState,omitempty"`
 	Identity VMIdentity `json:"identity,omitempty"`
-	Tags     Tags       `json:"tags,omitempty"`
+	// PrincipalID is the principal ID of the system-assigned identity of the virtual machine.
+	PrincipalID string `json:"principalID,omitempty"`
+	// Evicted is true when the virtual machine is a spot VM that was evicted and deallocated by Azure.
+	Evicted bool `json:"evicted,omitempty"`
+	Tags    Tags `json:"tags,omitempty"`
 
 	// Addresses contains the Azure instance associated addresses.
 	Addresses []corev1.NodeAddress `json:"addresses,omitempty"`
@@ -459,14 +591,231 @@ type AvailabilityZone struct {
 // VMIdentity defines the identity of the virtual machine, if configured.
 type VMIdentity string
 
+const (
+	// VMIdentityNone ...
+	VMIdentityNone = VMIdentity("None")
+	// VMIdentitySystemAssigned ...
+	VMIdentitySystemAssigned = VMIdentity("SystemAssigned")
+	// VMIdentityUserAssigned ...
+	VMIdentityUserAssigned = VMIdentity("UserAssigned")
+)
+
+// UserAssignedIdentity defines a user-assigned identity to be assigned to a virtual machine.
+type UserAssignedIdentity struct {
+	// ProviderID is the resource ID of the user-assigned identity, in the format
+	// /subscriptions/{subscriptionId}/resourceGroups/{resourceGroupName}/providers/Microsoft.ManagedIdentity/userAssignedIdentities/{identityName}
+	ProviderID string `json:"providerID"`
+}
+
+// RoleAssignment defines a role granted to the system-assigned identity of a virtual machine.
+type RoleAssignment struct {
+	// RoleDefinitionID is the role definition to assign, either as the GUID of the role,
+	// such as b24988ac-6180-42a0-ab88-20f7382dd24c for Contributor, or as its full resource ID.
+	RoleDefinitionID string `json:"roleDefinitionID"`
+
+	// Scope is the resource ID the role is assigned at.
+	// Defaults to the resource group of the cluster.
+	// +optional
+	Scope string `json:"scope,omitempty"`
+}
+
 type OSDisk struct {
 	OSType      string      `json:"osType"`
 	DiskSizeGB  int32       `json:"diskSizeGB"`
 	ManagedDisk ManagedDisk `json:"managedDisk"`
+
+	// DiffDiskSettings describe ephemeral disk settings for the OS disk.
+	// +optional
+	DiffDiskSettings *DiffDiskSettings `json:"diffDiskSettings,omitempty"`
+
+	// CachingType specifies the caching requirements of the OS disk.
+	// Defaults to ReadWrite, or to ReadOnly for ephemeral OS disks, which support no other value.
+	// +kubebuilder:validation:Enum=None;ReadOnly;ReadWrite
+	// +optional
+	CachingType string `json:"cachingType,omitempty"`
+}
+
+// DiffDiskSettings describe ephemeral disk settings for the OS disk.
+type DiffDiskSettings struct {
+	// Option enables ephemeral OS when set to "Local".
+	// The OS disk is then stored on the local VM storage and is lost when the VM is reimaged or deallocated.
+	// +kubebuilder:validation:Enum=Local
+	Option string `json:"option"`
 }
 
 type ManagedDisk struct {
 	StorageAccountType string `json:"storageAccountType"`
+
+	// DiskEncryptionSetID is the resource ID of the disk encryption set used to encrypt the disk
+	// with a customer-managed key, in the format
+	// /subscriptions/{subscriptionId}/resourceGroups/{resourceGroupName}/providers/Microsoft.Compute/diskEncryptionSets/{diskEncryptionSetName}
+	// +optional
+	DiskEncryptionSetID string `json:"diskEncryptionSetID,omitempty"`
+}
+
+// SecurityProfile specifies the security settings of a virtual machine.
+type SecurityProfile struct {
+	// EncryptionAtHost enables the encryption at the VM host of all the disks of the virtual machine,
+	// including its temporary disk and caches. Requires the EncryptionAtHost feature on the subscription.
+	// +optional
+	EncryptionAtHost *bool `json:"encryptionAtHost,omitempty"`
+}
+
+// SpotEvictionPolicy defines what happens to a spot virtual machine when it is evicted.
+type SpotEvictionPolicy string
+
+var (
+	// SpotEvictionPolicyDeallocate stops and deallocates the VM on eviction, keeping its disks.
+	SpotEvictionPolicyDeallocate = SpotEvictionPolicy("Deallocate")
+	// SpotEvictionPolicyDelete deletes the VM and its disks on eviction.
+	SpotEvictionPolicyDelete = SpotEvictionPolicy("Delete")
+)
+
+// SpotVMOptions defines the options of a spot virtual machine.
+type SpotVMOptions struct {
+	// MaxPrice is the maximum price per hour, in US dollars, the user is willing to pay for the VM.
+	// The VM is evicted when the spot price rises above it. When unset, the VM is billed up to the
+	// on-demand price and is only evicted for capacity reasons.
+	// +optional
+	MaxPrice *resource.Quantity `json:"maxPrice,omitempty"`
+
+	// EvictionPolicy defines what happens to the VM when it is evicted. Defaults to Deallocate.
+	// +kubebuilder:validation:Enum=Deallocate;Delete
+	// +optional
+	EvictionPolicy SpotEvictionPolicy `json:"evictionPolicy,omitempty"`
+}
+
+// DataDisk specifies the parameters that are used to add a data disk to the machine.
+type DataDisk struct {
+	// NameSuffix is the suffix appended to the machine name to generate the disk name,
+	// in the format <machineName>_<nameSuffix>.
+	// +kubebuilder:validation:MinLength=1
+	NameSuffix string `json:"nameSuffix"`
+
+	// DiskSizeGB is the size in GB to assign to the data disk.
+	// +kubebuilder:validation:Minimum=4
+	// +kubebuilder:validation:Maximum=32767
+	DiskSizeGB int32 `json:"diskSizeGB"`
+
+	// Lun specifies the logical unit number of the data disk, which identifies it within the VM
+	// and must therefore be unique for each data disk attached to a VM.
+	// Defaults to the position of the disk in the list.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=63
+	// +optional
+	Lun *int32 `json:"lun,omitempty"`
+
+	// CachingType specifies the caching requirements of the data disk. Defaults to None.
+	// +kubebuilder:validation:Enum=None;ReadOnly;ReadWrite
+	// +optional
+	CachingType string `json:"cachingType,omitempty"`
+
+	// ManagedDisk specifies the managed disk parameters of the data disk.
+	// The storage account type defaults to that of the VM size when unset.
+	// +optional
+	ManagedDisk *ManagedDisk `json:"managedDisk,omitempty"`
+}
+
+// NetworkInterface specifies the parameters of a network interface of the machine.
+type NetworkInterface struct {
+	// SubnetName is the name of the subnet of the cluster virtual network that the network interface joins.
+	// +kubebuilder:validation:MinLength=1
+	SubnetName string `json:"subnetName"`
+
+	// StaticIPAddress is the static private IP address of the network interface.
+	// A dynamic IP address is allocated when unset.
+	// +optional
+	StaticIPAddress string `json:"staticIPAddress,omitempty"`
+
+	// AcceleratedNetworking enables accelerated networking on the network interface.
+	// Defaults to the acceleratedNetworking setting of the machine.
+	// +optional
+	AcceleratedNetworking *bool `json:"acceleratedNetworking,omitempty"`
+
+	// Primary makes the network interface the primary network interface of the machine.
+	// At most one network interface can be primary; the first one is primary when none is.
+	// +optional
+	Primary bool `json:"primary,omitempty"`
+}
+
+// OutboundLBSpec defines the outbound rule of a load balancer that gives machines outbound internet access.
+type OutboundLBSpec struct {
+	// AllocatedOutboundPorts is the number of SNAT ports allocated to each machine, in multiples of 8.
+	// Azure allocates ports based on the size of the backend pool when unset or 0.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=64000
+	// +kubebuilder:validation:MultipleOf=8
+	// +optional
+	AllocatedOutboundPorts *int32 `json:"allocatedOutboundPorts,omitempty"`
+
+	// IdleTimeoutInMinutes is the idle timeout of outbound connections. Defaults to 4 minutes.
+	// +kubebuilder:validation:Minimum=4
+	// +kubebuilder:validation:Maximum=120
+	// +optional
+	IdleTimeoutInMinutes *int32 `json:"idleTimeoutInMinutes,omitempty"`
+}
+
+// BastionSpec defines the bastion host that gives SSH access to the machines of a cluster.
+type BastionSpec struct {
+	// Type is the type of the bastion host: an AzureBastion host managed by Azure, or a Jumpbox VM.
+	// +kubebuilder:validation:Enum=AzureBastion;Jumpbox
+	Type BastionType `json:"type"`
+
+	// Subnet is the subnet of the bastion host in the cluster vnet. Azure Bastion hosts require a subnet
+	// named AzureBastionSubnet with a prefix of /27 or larger. The name defaults to AzureBastionSubnet for
+	// Azure Bastion hosts and to <cluster>-bastion-subnet for jumpboxes, and the CIDR block to 10.255.255.224/27.
+	// +optional
+	Subnet SubnetSpec `json:"subnet,omitempty"`
+
+	// VMSize is the size of the jumpbox VM. Defaults to Standard_B1s.
+	// +optional
+	VMSize string `json:"vmSize,omitempty"`
+
+	// SSHPublicKey is the base64-encoded SSH public key of the jumpbox VM, required for jumpboxes.
+	// +optional
+	SSHPublicKey string `json:"sshPublicKey,omitempty"`
+}
+
+const (
+	// AzureBastionSubnetName is the name Azure requires for the subnet of an Azure Bastion host.
+	AzureBastionSubnetName = "AzureBastionSubnet"
+	// DefaultBastionSubnetCIDR is the default CIDR block of the subnet of a bastion host.
+	DefaultBastionSubnetCIDR = "10.255.255.224/27"
+)
+
+// BastionType defines the type of a bastion host.
+type BastionType string
+
+const (
+	// BastionTypeAzureBastion is an Azure Bastion host, reached through the Azure portal or CLI.
+	BastionTypeAzureBastion = BastionType("AzureBastion")
+	// BastionTypeJumpbox is a small VM with a public IP, reached through SSH.
+	BastionTypeJumpbox = BastionType("Jumpbox")
+)
+
+// SSHAccessMode defines how the control plane machines of a cluster can be reached through SSH.
+type SSHAccessMode string
+
+const (
+	// SSHAccessDisabled doesn't allow SSH access to the control plane machines.
+	SSHAccessDisabled = SSHAccessMode("Disabled")
+	// SSHAccessNAT allows SSH access to the control plane machines through NAT rules of the API server load balancer.
+	SSHAccessNAT = SSHAccessMode("NAT")
+	// SSHAccessBastionOnly only allows SSH access to the control plane machines from the bastion subnet.
+	SSHAccessBastionOnly = SSHAccessMode("BastionOnly")
+)
+
+// SSHAccessSpec defines how the control plane machines of a cluster can be reached through SSH.
+type SSHAccessSpec struct {
+	// Mode is the SSH access mode: Disabled, NAT or BastionOnly. Defaults to NAT.
+	// +kubebuilder:validation:Enum=Disabled;NAT;BastionOnly
+	// +optional
+	Mode SSHAccessMode `json:"mode,omitempty"`
+
+	// SourceCIDRs are the CIDR blocks SSH connections are allowed from in NAT mode.
+	// Connections are allowed from any source when unset.
+	// +optional
+	SourceCIDRs []string `json:"sourceCIDRs,omitempty"`
 }
 
 // SubnetRole defines the unique role of a subnet.
@@ -478,6 +827,9 @@ var (
 
 	// SubnetControlPlane defines a Kubernetes control plane node role
 	SubnetControlPlane = SubnetRole(ControlPlane)
+
+	// SubnetBastion defines a bastion host role
+	SubnetBastion = SubnetRole(BastionRoleTagValue)
 )
 
 // SubnetSpec configures an Azure subnet.
@@ -494,14 +846,80 @@ type SubnetSpec struct {
 	// CidrBlock is the CIDR block to be used when the provider creates a managed Vnet.
 	CidrBlock string `json:"cidrBlock,omitempty"`
 
+	// CIDRBlocks are the CIDR blocks to be used when the provider creates a managed Vnet,
+	// at most one IPv4 and one IPv6 CIDR block for a dual-stack subnet. Defaults to CidrBlock.
+	// +optional
+	CIDRBlocks []string `json:"cidrBlocks,omitempty"`
+
 	// InternalLBIPAddress is the IP address that will be used as the internal LB private IP.
 	// For the control plane subnet only.
 	InternalLBIPAddress string `json:"internalLBIPAddress,omitempty"`
 
 	// SecurityGroup defines the NSG (network security group) that should be attached to this subnet.
 	SecurityGroup SecurityGroup `json:"securityGroup,omitempty"`
+
+	// NatGateway is the NAT gateway that gives the machines of the subnet outbound internet access.
+	// For the node and control plane subnets only. The control plane subnet of a private cluster gets one by default.
+	// Once set, it can't be removed and its names can't be changed.
+	// +optional
+	NatGateway *NatGateway `json:"natGateway,omitempty"`
+}
+
+// NatGateway defines a NAT gateway and the public IP it uses for outbound connections.
+type NatGateway struct {
+	// ID is the Azure resource ID of the NAT gateway.
+	ID string `json:"id,omitempty"`
+
+	// Name is the name of the NAT gateway. Defaults to <cluster>-node-natgw.
+	// +optional
+	Name string `json:"name,omitempty"`
+
+	// IdleTimeoutInMinutes is the idle timeout of outbound connections. Azure defaults it to 4 minutes.
+	// +kubebuilder:validation:Minimum=4
+	// +kubebuilder:validation:Maximum=120
+	// +optional
+	IdleTimeoutInMinutes *int32 `json:"idleTimeoutInMinutes,omitempty"`
+
+	// PublicIP is the public IP of the NAT gateway. Its name defaults to <nat gateway name>-pip.
+	// +optional
+	PublicIP PublicIP `json:"publicIP,omitempty"`
 }
 
+// IsIPv6Enabled returns true if the subnet has an IPv6 CIDR block.
+func (s *SubnetSpec) IsIPv6Enabled() bool {
+	return IPv6CIDRBlock(s.CIDRBlocks) != ""
+}
+
+// IPv4CIDRBlock returns the first IPv4 CIDR block of a list of CIDR blocks, or an empty string if there is none.
+func IPv4CIDRBlock(cidrBlocks []string) string {
+	for _, cidr := range cidrBlocks {
+		if ip, _, err := net.ParseCIDR(cidr); err == nil && ip.To4() != nil {
+			return cidr
+		}
+	}
+	return ""
+}
+
+// IPv6CIDRBlock returns the first IPv6 CIDR block of a list of CIDR blocks, or an empty string if there is none.
+func IPv6CIDRBlock(cidrBlocks []string) string {
+	for _, cidr := range cidrBlocks {
+		if ip, _, err := net.ParseCIDR(cidr); err == nil && ip.To4() == nil {
+			return cidr
+		}
+	}
+	return ""
+}
+
+const (
+	// AnnotationSecurityRules records, as a JSON map of security group names to rule names,
+	// the security rules last applied to the security groups of an AzureCluster.
+	AnnotationSecurityRules = "azure.cluster.sigs.k8s.io/security-rules"
+
+	// AnnotationRoutes records, as a JSON map of route table names to route names,
+	// the routes last applied to the route tables of an AzureCluster.
+	AnnotationRoutes = "azure.cluster.sigs.k8s.io/routes"
+)
+
 const (
 	AnnotationClusterInfrastructureReady = "azure.cluster.sigs.k8s.io/infrastructure-ready"
 	ValueReady                           = "true"
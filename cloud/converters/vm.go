@@ -17,7 +17,7 @@ limitations under the License.
 package converters
 
 import (
-	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2019-12-01/compute"
+	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2020-06-01/compute"
 	"github.com/Azure/go-autorest/autorest/to"
 	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1alpha3"
 )
@@ -38,9 +38,43 @@ func SDKToVM(v compute.VirtualMachine) (*infrav1.VM, error) {
 		vm.AvailabilityZone = to.StringSlice(v.Zones)[0]
 	}
 
+	if v.Identity != nil {
+		switch v.Identity.Type {
+		case compute.ResourceIdentityTypeSystemAssigned:
+			vm.Identity = infrav1.VMIdentitySystemAssigned
+		case compute.ResourceIdentityTypeUserAssigned:
+			vm.Identity = infrav1.VMIdentityUserAssigned
+		case compute.ResourceIdentityTypeNone:
+			vm.Identity = infrav1.VMIdentityNone
+		}
+		vm.PrincipalID = to.String(v.Identity.PrincipalID)
+	}
+
+	vm.Evicted = isSpotVMEvicted(v)
+
 	if len(v.Tags) > 0 {
 		vm.Tags = MapToTags(v.Tags)
 	}
 
 	return vm, nil
 }
+
+// isSpotVMEvicted returns true when the VM is a spot VM deallocated by an eviction.
+// Azure reports an evicted spot VM as deallocated, with no status specific to the eviction. The controller never
+// stops or deallocates a VM, so a deallocated spot VM is considered evicted.
+// Spot VMs evicted with the Delete policy are removed instead, so they can't be detected from the VM itself.
+func isSpotVMEvicted(v compute.VirtualMachine) bool {
+	if v.VirtualMachineProperties == nil || v.Priority != compute.Spot {
+		return false
+	}
+	if v.InstanceView == nil || v.InstanceView.Statuses == nil {
+		return false
+	}
+	for _, status := range *v.InstanceView.Statuses {
+		switch to.String(status.Code) {
+		case "PowerState/deallocating", "PowerState/deallocated":
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,91 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package converters
+
+import (
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2020-06-01/compute"
+	"github.com/Azure/go-autorest/autorest/to"
+	. "github.com/onsi/gomega"
+)
+
+func TestSDKToVMEvicted(t *testing.T) {
+	g := NewWithT(t)
+
+	testcases := []struct {
+		name     string
+		priority compute.VirtualMachinePriorityTypes
+		statuses []compute.InstanceViewStatus
+		expected bool
+	}{
+		{
+			name:     "running spot VM",
+			priority: compute.Spot,
+			statuses: []compute.InstanceViewStatus{
+				{Code: to.StringPtr("ProvisioningState/succeeded")},
+				{Code: to.StringPtr("PowerState/running")},
+			},
+			expected: false,
+		},
+		{
+			name:     "spot VM deallocated by an eviction",
+			priority: compute.Spot,
+			statuses: []compute.InstanceViewStatus{
+				{Code: to.StringPtr("ProvisioningState/succeeded"), DisplayStatus: to.StringPtr("Provisioning succeeded")},
+				{Code: to.StringPtr("PowerState/deallocated"), DisplayStatus: to.StringPtr("VM deallocated")},
+			},
+			expected: true,
+		},
+		{
+			name:     "spot VM being deallocated by an eviction",
+			priority: compute.Spot,
+			statuses: []compute.InstanceViewStatus{
+				{Code: to.StringPtr("ProvisioningState/updating"), DisplayStatus: to.StringPtr("Updating")},
+				{Code: to.StringPtr("PowerState/deallocating"), DisplayStatus: to.StringPtr("VM deallocating")},
+			},
+			expected: true,
+		},
+		{
+			name:     "spot VM without an instance view",
+			priority: compute.Spot,
+			expected: false,
+		},
+		{
+			name:     "regular VM deallocated",
+			priority: compute.Regular,
+			statuses: []compute.InstanceViewStatus{
+				{Code: to.StringPtr("ProvisioningState/succeeded")},
+				{Code: to.StringPtr("PowerState/deallocated")},
+			},
+			expected: false,
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			properties := &compute.VirtualMachineProperties{Priority: tc.priority}
+			if tc.statuses != nil {
+				properties.InstanceView = &compute.VirtualMachineInstanceView{Statuses: &tc.statuses}
+			}
+			vm, err := SDKToVM(compute.VirtualMachine{VirtualMachineProperties: properties})
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(vm.Evicted).To(Equal(tc.expected))
+		})
+	}
+}
@@ -33,15 +33,16 @@ const (
 	DefaultControlPlaneSubnetCIDR = "10.0.0.0/16"
 	// DefaultNodeSubnetCIDR is the default Node Subnet CIDR
 	DefaultNodeSubnetCIDR = "10.1.0.0/16"
+	// DefaultBastionSubnetCIDR is the default Bastion Subnet CIDR
+	DefaultBastionSubnetCIDR = infrav1.DefaultBastionSubnetCIDR
+	// DefaultBastionVMSize is the default size of jumpbox VMs
+	DefaultBastionVMSize = "Standard_B1s"
 	// DefaultInternalLBIPAddress is the default internal load balancer ip address
 	DefaultInternalLBIPAddress = "10.0.0.100"
-	// DefaultAzureDNSZone is the default provided azure dns zone
-	DefaultAzureDNSZone = "cloudapp.chinacloudapi.cn"
+	// DefaultAzureEnvironment is the Azure cloud environment used when the AzureCluster does not specify one
+	DefaultAzureEnvironment = "AzureChinaCloud"
 	// UserAgent used for communicating with azure
 	UserAgent = "cluster-api-azure-services"
-
-	// https://docs.azure.cn/zh-cn/articles/guidance/developerdifferences
-	DefaultBaseURI = "https://management.chinacloudapi.cn"
 )
 
 const (
@@ -53,27 +54,6 @@ const (
 	LatestVersion = "latest"
 )
 
-// SupportedAvailabilityZoneLocations is a slice of the locations where Availability Zones are supported.
-// This is used to validate whether a virtual machine should leverage an Availability Zone.
-// Based on the Availability Zones listed in https://docs.microsoft.com/en-us/azure/availability-zones/az-overview
-var SupportedAvailabilityZoneLocations = []string{
-	// Americas
-	"centralus",
-	"eastus",
-	"eastus2",
-	"westus2",
-
-	// Europe
-	"francecentral",
-	"northeurope",
-	"uksouth",
-	"westeurope",
-
-	// Asia Pacific
-	"japaneast",
-	"southeastasia",
-}
-
 // GenerateVnetName generates a virtual network name, based on the cluster name.
 func GenerateVnetName(clusterName string) string {
 	return fmt.Sprintf("%s-%s", clusterName, "vnet")
@@ -104,6 +84,60 @@ func GenerateNodeSubnetName(clusterName string) string {
 	return fmt.Sprintf("%s-%s", clusterName, "node-subnet")
 }
 
+// GenerateBastionSecurityGroupName generates a bastion security group name, based on the cluster name.
+func GenerateBastionSecurityGroupName(clusterName string) string {
+	return fmt.Sprintf("%s-%s", clusterName, "bastion-nsg")
+}
+
+// GenerateBastionSubnetName generates a jumpbox subnet name, based on the cluster name.
+// Azure Bastion hosts use the AzureBastionSubnet subnet instead.
+func GenerateBastionSubnetName(clusterName string) string {
+	return fmt.Sprintf("%s-%s", clusterName, "bastion-subnet")
+}
+
+// GenerateBastionName generates the name of an Azure Bastion host or jumpbox VM, based on the cluster name.
+func GenerateBastionName(clusterName string) string {
+	return fmt.Sprintf("%s-%s", clusterName, "bastion")
+}
+
+// GenerateBastionPublicIPName generates a bastion public IP name, based on the cluster name.
+func GenerateBastionPublicIPName(clusterName string) string {
+	return fmt.Sprintf("%s-%s", clusterName, "bastion-pip")
+}
+
+// GenerateNatGatewayName generates a node subnet NAT gateway name, based on the cluster name.
+func GenerateNatGatewayName(clusterName string) string {
+	return fmt.Sprintf("%s-%s", clusterName, "node-natgw")
+}
+
+// GenerateControlPlaneNatGatewayName generates a control plane subnet NAT gateway name, based on the cluster name.
+func GenerateControlPlaneNatGatewayName(clusterName string) string {
+	return fmt.Sprintf("%s-%s", clusterName, "controlplane-natgw")
+}
+
+// GenerateNatGatewayPublicIPName generates the public IP name of a NAT gateway, based on the NAT gateway name.
+func GenerateNatGatewayPublicIPName(natGatewayName string) string {
+	return fmt.Sprintf("%s-pip", natGatewayName)
+}
+
+// GenerateNodeOutboundLBName generates the node outbound load balancer name, based on the cluster name.
+// It is the cluster name, which is the name of the load balancer the cloud provider uses for Services
+// of type LoadBalancer, so that the cloud provider reuses it instead of creating another one.
+func GenerateNodeOutboundLBName(clusterName string) string {
+	return clusterName
+}
+
+// GenerateNodeOutboundBackendPoolName generates the backend pool name of the node outbound load balancer,
+// based on the load balancer name. The cloud provider names its backend pool after the cluster too.
+func GenerateNodeOutboundBackendPoolName(lbName string) string {
+	return lbName
+}
+
+// GenerateNodeOutboundPublicIPName generates the node outbound load balancer public IP name, based on the cluster name.
+func GenerateNodeOutboundPublicIPName(clusterName string) string {
+	return fmt.Sprintf("%s-%s", clusterName, "node-outbound-pip")
+}
+
 // GenerateInternalLBName generates a internal load balancer name, based on the cluster name.
 func GenerateInternalLBName(clusterName string) string {
 	return fmt.Sprintf("%s-%s", clusterName, "internal-lb")
@@ -119,9 +153,14 @@ func GeneratePublicIPName(clusterName, hash string) string {
 	return fmt.Sprintf("%s-%s", clusterName, hash)
 }
 
-// GenerateFQDN generates a fully qualified domain name, based on the public IP name and cluster location.
-func GenerateFQDN(publicIPName, location string) string {
-	return fmt.Sprintf("%s.%s.%s", publicIPName, location, DefaultAzureDNSZone)
+// GeneratePublicIPv6Name generates the name of the IPv6 public IP paired with an IPv4 public IP.
+func GeneratePublicIPv6Name(publicIPName string) string {
+	return fmt.Sprintf("%s-ipv6", publicIPName)
+}
+
+// GenerateFQDN generates a fully qualified domain name, based on the public IP name, cluster location and DNS zone of the cloud environment.
+func GenerateFQDN(publicIPName, location, dnsZone string) string {
+	return fmt.Sprintf("%s.%s.%s", publicIPName, location, dnsZone)
 }
 
 // GenerateNICName generates the name of a network interface based on the name of a VM.
@@ -129,11 +168,41 @@ func GenerateNICName(machineName string) string {
 	return fmt.Sprintf("%s-nic", machineName)
 }
 
+// GenerateIndexedNICName generates the name of the network interface at the given index of a VM.
+// The first network interface keeps the name generated by GenerateNICName.
+func GenerateIndexedNICName(machineName string, index int) string {
+	if index == 0 {
+		return GenerateNICName(machineName)
+	}
+	return fmt.Sprintf("%s-nic-%d", machineName, index)
+}
+
 // GenerateOSDiskName generates the name of an OS disk based on the name of a VM.
 func GenerateOSDiskName(machineName string) string {
 	return fmt.Sprintf("%s_OSDisk", machineName)
 }
 
+// GenerateDataDiskName generates the name of a data disk based on the name of a VM.
+func GenerateDataDiskName(machineName, nameSuffix string) string {
+	return fmt.Sprintf("%s_%s", machineName, nameSuffix)
+}
+
+// GenerateAvailabilitySetName generates the name of the availability set of a group of machines,
+// such as the control plane or a MachineDeployment.
+func GenerateAvailabilitySetName(clusterName, groupName string) string {
+	return fmt.Sprintf("%s_%s-as", clusterName, groupName)
+}
+
+// AvailabilitySetID returns the azure resource ID for a given availability set.
+func AvailabilitySetID(subscriptionID, resourceGroup, availabilitySetName string) string {
+	return fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.Compute/availabilitySets/%s", subscriptionID, resourceGroup, availabilitySetName)
+}
+
+// SubnetID returns the azure resource ID for a given subnet.
+func SubnetID(subscriptionID, resourceGroup, vnetName, subnetName string) string {
+	return fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.Network/virtualNetworks/%s/subnets/%s", subscriptionID, resourceGroup, vnetName, subnetName)
+}
+
 // GetDefaultImageSKUID gets the SKU ID of the image to use for the provided version of Kubernetes.
 func getDefaultImageSKUID(k8sVersion string) (string, error) {
 	version, err := semver.ParseTolerant(k8sVersion)
@@ -161,3 +230,15 @@ func GetDefaultUbuntuImage(k8sVersion string) (*infrav1.Image, error) {
 
 	return defaultImage, nil
 }
+
+// GetBastionImage returns the image spec of bastion jumpboxes, which is the latest Ubuntu 18.04 LTS.
+func GetBastionImage() *infrav1.Image {
+	return &infrav1.Image{
+		Marketplace: &infrav1.AzureMarketplaceImage{
+			Publisher: "Canonical",
+			Offer:     "UbuntuServer",
+			SKU:       "18.04-LTS",
+			Version:   LatestVersion,
+		},
+	}
+}
@@ -27,3 +27,11 @@ func ResourceNotFound(err error) bool {
 	}
 	return false
 }
+
+// ResourceConflict parses the error to check if it's a resource conflict error
+func ResourceConflict(err error) bool {
+	if derr, ok := err.(autorest.DetailedError); ok && derr.StatusCode == 409 {
+		return true
+	}
+	return false
+}
@@ -20,17 +20,22 @@ import (
 	"os"
 
 	"github.com/Azure/go-autorest/autorest"
+	"github.com/Azure/go-autorest/autorest/azure"
 	"github.com/Azure/go-autorest/autorest/azure/auth"
 	"github.com/pkg/errors"
+	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1alpha3"
+	azurecloud "sigs.k8s.io/cluster-api-provider-azure/cloud"
 )
 
 // AzureClients contains all the Azure clients used by the scopes.
 type AzureClients struct {
-	SubscriptionID string
-	Authorizer     autorest.Authorizer
+	SubscriptionID             string
+	ResourceManagerEndpoint    string
+	ResourceManagerVMDNSSuffix string
+	Authorizer                 autorest.Authorizer
 }
 
-func (c *AzureClients) setCredentials() error {
+func (c *AzureClients) setCredentials(env azure.Environment) error {
 	if c.SubscriptionID == "" {
 		subID, err := getSubscriptionID()
 		if err != nil {
@@ -38,8 +43,14 @@ func (c *AzureClients) setCredentials() error {
 		}
 		c.SubscriptionID = subID
 	}
+	if c.ResourceManagerEndpoint == "" {
+		c.ResourceManagerEndpoint = env.ResourceManagerEndpoint
+	}
+	if c.ResourceManagerVMDNSSuffix == "" {
+		c.ResourceManagerVMDNSSuffix = env.ResourceManagerVMDNSSuffix
+	}
 	if c.Authorizer == nil {
-		auth, err := getAuthorizer()
+		auth, err := getAuthorizer(env)
 		if err != nil {
 			return err
 		}
@@ -56,6 +67,36 @@ func getSubscriptionID() (string, error) {
 	return subscriptionID, nil
 }
 
-func getAuthorizer() (autorest.Authorizer, error) {
-	return auth.NewAuthorizerFromEnvironment()
+// getAuthorizer creates an authorizer from the credentials in the environment variables,
+// issuing tokens against the Active Directory and Resource Manager endpoints of env.
+func getAuthorizer(env azure.Environment) (autorest.Authorizer, error) {
+	settings, err := auth.GetSettingsFromEnvironment()
+	if err != nil {
+		return nil, err
+	}
+	settings.Environment = env
+	settings.Values[auth.Resource] = env.ResourceManagerEndpoint
+	return settings.GetAuthorizer()
+}
+
+// getEnvironment returns the Azure cloud environment an AzureCluster is deployed to.
+func getEnvironment(spec infrav1.AzureClusterSpec) (azure.Environment, error) {
+	if custom := spec.CustomEnvironment; custom != nil {
+		return azure.Environment{
+			Name:                       custom.Name,
+			ResourceManagerEndpoint:    custom.ResourceManagerEndpoint,
+			ActiveDirectoryEndpoint:    custom.ActiveDirectoryEndpoint,
+			ResourceManagerVMDNSSuffix: custom.ResourceManagerVMDNSSuffix,
+			TokenAudience:              custom.ResourceManagerEndpoint,
+		}, nil
+	}
+	name := spec.Environment
+	if name == "" {
+		name = azurecloud.DefaultAzureEnvironment
+	}
+	env, err := azure.EnvironmentFromName(name)
+	if err != nil {
+		return azure.Environment{}, errors.Wrapf(err, "unknown Azure environment %q", name)
+	}
+	return env, nil
 }
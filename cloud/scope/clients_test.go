@@ -0,0 +1,175 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scope
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/Azure/go-autorest/autorest"
+	"github.com/Azure/go-autorest/autorest/azure"
+	. "github.com/onsi/gomega"
+	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1alpha3"
+)
+
+func TestGetEnvironment(t *testing.T) {
+	g := NewWithT(t)
+
+	cases := []struct {
+		name          string
+		spec          infrav1.AzureClusterSpec
+		expected      azure.Environment
+		expectedError string
+	}{
+		{
+			name:     "default environment",
+			spec:     infrav1.AzureClusterSpec{},
+			expected: azure.ChinaCloud,
+		},
+		{
+			name:     "public cloud",
+			spec:     infrav1.AzureClusterSpec{Environment: "AzurePublicCloud"},
+			expected: azure.PublicCloud,
+		},
+		{
+			name:     "China cloud",
+			spec:     infrav1.AzureClusterSpec{Environment: "AzureChinaCloud"},
+			expected: azure.ChinaCloud,
+		},
+		{
+			name:     "US government cloud",
+			spec:     infrav1.AzureClusterSpec{Environment: "AzureUSGovernmentCloud"},
+			expected: azure.USGovernmentCloud,
+		},
+		{
+			name:     "German cloud",
+			spec:     infrav1.AzureClusterSpec{Environment: "AzureGermanCloud"},
+			expected: azure.GermanCloud,
+		},
+		{
+			name:          "unknown environment",
+			spec:          infrav1.AzureClusterSpec{Environment: "AzureMoonCloud"},
+			expectedError: "unknown Azure environment \"AzureMoonCloud\": autorest/azure: There is no cloud environment matching the name \"AZUREMOONCLOUD\"",
+		},
+		{
+			name: "custom environment",
+			spec: infrav1.AzureClusterSpec{
+				CustomEnvironment: &infrav1.AzureEnvironmentEndpoints{
+					Name:                       "AzureStackCloud",
+					ResourceManagerEndpoint:    "https://management.local.azurestack.external/",
+					ActiveDirectoryEndpoint:    "https://login.microsoftonline.com/",
+					ResourceManagerVMDNSSuffix: "cloudapp.local.azurestack.external",
+				},
+			},
+			expected: azure.Environment{
+				Name:                       "AzureStackCloud",
+				ResourceManagerEndpoint:    "https://management.local.azurestack.external/",
+				ActiveDirectoryEndpoint:    "https://login.microsoftonline.com/",
+				ResourceManagerVMDNSSuffix: "cloudapp.local.azurestack.external",
+				TokenAudience:              "https://management.local.azurestack.external/",
+			},
+		},
+		{
+			name: "custom environment takes precedence over the environment name",
+			spec: infrav1.AzureClusterSpec{
+				Environment: "AzurePublicCloud",
+				CustomEnvironment: &infrav1.AzureEnvironmentEndpoints{
+					Name:                       "AzureStackCloud",
+					ResourceManagerEndpoint:    "https://management.local.azurestack.external/",
+					ActiveDirectoryEndpoint:    "https://login.microsoftonline.com/",
+					ResourceManagerVMDNSSuffix: "cloudapp.local.azurestack.external",
+				},
+			},
+			expected: azure.Environment{
+				Name:                       "AzureStackCloud",
+				ResourceManagerEndpoint:    "https://management.local.azurestack.external/",
+				ActiveDirectoryEndpoint:    "https://login.microsoftonline.com/",
+				ResourceManagerVMDNSSuffix: "cloudapp.local.azurestack.external",
+				TokenAudience:              "https://management.local.azurestack.external/",
+			},
+		},
+	}
+
+	for _, c := range cases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			env, err := getEnvironment(c.spec)
+			if c.expectedError != "" {
+				g.Expect(err).To(MatchError(c.expectedError))
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(env).To(Equal(c.expected))
+		})
+	}
+}
+
+func TestGetAuthorizer(t *testing.T) {
+	g := NewWithT(t)
+
+	var tokenPath, tokenResource string
+	aad := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		g.Expect(r.ParseForm()).To(Succeed())
+		tokenPath = r.URL.Path
+		tokenResource = r.PostForm.Get("resource")
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"access_token":"my-token","token_type":"Bearer","expires_in":"3600","expires_on":"%d","resource":"%s"}`,
+			time.Now().Add(time.Hour).Unix(), tokenResource)
+	}))
+	defer aad.Close()
+
+	for key, value := range map[string]string{
+		"AZURE_TENANT_ID":     "my-tenant",
+		"AZURE_CLIENT_ID":     "my-client",
+		"AZURE_CLIENT_SECRET": "my-client-secret",
+		"AZURE_ENVIRONMENT":   "",
+	} {
+		old, ok := os.LookupEnv(key)
+		g.Expect(os.Setenv(key, value)).To(Succeed())
+		defer func(key, old string, ok bool) {
+			if ok {
+				os.Setenv(key, old)
+			} else {
+				os.Unsetenv(key)
+			}
+		}(key, old, ok)
+	}
+
+	env, err := getEnvironment(infrav1.AzureClusterSpec{
+		CustomEnvironment: &infrav1.AzureEnvironmentEndpoints{
+			Name:                       "AzureStackCloud",
+			ResourceManagerEndpoint:    "https://management.local.azurestack.external/",
+			ActiveDirectoryEndpoint:    aad.URL + "/",
+			ResourceManagerVMDNSSuffix: "cloudapp.local.azurestack.external",
+		},
+	})
+	g.Expect(err).NotTo(HaveOccurred())
+
+	authorizer, err := getAuthorizer(env)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	// the token is requested from the Active Directory endpoint of the environment for its Resource Manager endpoint
+	req, err := autorest.Prepare(&http.Request{Header: http.Header{}}, authorizer.WithAuthorization())
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(req.Header.Get("Authorization")).To(Equal("Bearer my-token"))
+	g.Expect(tokenPath).To(Equal("/my-tenant/oauth2/token"))
+	g.Expect(tokenResource).To(Equal("https://management.local.azurestack.external/"))
+}
@@ -18,6 +18,7 @@ package scope
 
 import (
 	"context"
+	"encoding/json"
 
 	"github.com/go-logr/logr"
 	"github.com/pkg/errors"
@@ -52,7 +53,23 @@ func NewClusterScope(params ClusterScopeParams) (*ClusterScope, error) {
 		params.Logger = klogr.New()
 	}
 
-	err := params.AzureClients.setCredentials()
+	env, err := getEnvironment(params.AzureCluster.Spec)
+	if err != nil {
+		return nil, err
+	}
+
+	if params.AzureClients.SubscriptionID == "" {
+		params.AzureClients.SubscriptionID = params.AzureCluster.Spec.SubscriptionID
+	}
+	if params.AzureClients.Authorizer == nil && params.AzureCluster.Spec.IdentityRef != nil {
+		authorizer, err := getIdentityAuthorizer(params.Client, params.AzureCluster, env)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to create Azure session")
+		}
+		params.AzureClients.Authorizer = authorizer
+	}
+
+	err = params.AzureClients.setCredentials(env)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to create Azure session")
 	}
@@ -94,6 +111,38 @@ func (s *ClusterScope) Vnet() *infrav1.VnetSpec {
 	return &s.AzureCluster.Spec.NetworkSpec.Vnet
 }
 
+// APIServerLB returns the cluster API server load balancer.
+func (s *ClusterScope) APIServerLB() *infrav1.LoadBalancerSpec {
+	return &s.AzureCluster.Spec.APIServerLB
+}
+
+// NodeOutboundLB returns the outbound rule of the cluster node outbound load balancer, or nil when the cluster has none.
+func (s *ClusterScope) NodeOutboundLB() *infrav1.OutboundLBSpec {
+	return s.AzureCluster.Spec.NodeOutboundLB
+}
+
+// SSHAccess returns the SSH access spec of the cluster, defaulting to NAT mode.
+func (s *ClusterScope) SSHAccess() infrav1.SSHAccessSpec {
+	sshAccess := infrav1.SSHAccessSpec{}
+	if s.AzureCluster.Spec.SSHAccess != nil {
+		sshAccess = *s.AzureCluster.Spec.SSHAccess
+	}
+	if sshAccess.Mode == "" {
+		sshAccess.Mode = infrav1.SSHAccessNAT
+	}
+	return sshAccess
+}
+
+// Bastion returns the cluster bastion host, or nil when the cluster has none.
+func (s *ClusterScope) Bastion() *infrav1.BastionSpec {
+	return s.AzureCluster.Spec.Bastion
+}
+
+// Routes returns the routes of the cluster node route table.
+func (s *ClusterScope) Routes() infrav1.Routes {
+	return s.AzureCluster.Spec.NetworkSpec.Routes
+}
+
 // Subnets returns the cluster subnets.
 func (s *ClusterScope) Subnets() infrav1.Subnets {
 	return s.AzureCluster.Spec.NetworkSpec.Subnets
@@ -150,6 +199,14 @@ func (s *ClusterScope) Location() string {
 	return s.AzureCluster.Spec.Location
 }
 
+// SetFailureDomain sets a failure domain of the cluster.
+func (s *ClusterScope) SetFailureDomain(id string, spec clusterv1.FailureDomainSpec) {
+	if s.AzureCluster.Status.FailureDomains == nil {
+		s.AzureCluster.Status.FailureDomains = make(clusterv1.FailureDomains)
+	}
+	s.AzureCluster.Status.FailureDomains[id] = spec
+}
+
 // ListOptionsLabelSelector returns a ListOptions with a label selector for clusterName.
 func (s *ClusterScope) ListOptionsLabelSelector() client.ListOption {
 	return client.MatchingLabels(map[string]string{
@@ -183,3 +240,69 @@ func (s *ClusterScope) APIServerPort() int32 {
 	}
 	return 6443
 }
+
+// LastAppliedSecurityRules returns the names of the security rules last applied to a network security group.
+func (s *ClusterScope) LastAppliedSecurityRules(nsgName string) ([]string, error) {
+	return s.lastApplied(infrav1.AnnotationSecurityRules, nsgName)
+}
+
+// SetLastAppliedSecurityRules records the names of the security rules applied to a network security group.
+// Recording no rules forgets the security group.
+func (s *ClusterScope) SetLastAppliedSecurityRules(nsgName string, ruleNames []string) error {
+	return s.setLastApplied(infrav1.AnnotationSecurityRules, nsgName, ruleNames)
+}
+
+// LastAppliedRoutes returns the names of the routes last applied to a route table.
+func (s *ClusterScope) LastAppliedRoutes(routeTableName string) ([]string, error) {
+	return s.lastApplied(infrav1.AnnotationRoutes, routeTableName)
+}
+
+// SetLastAppliedRoutes records the names of the routes applied to a route table.
+// Recording no routes forgets the route table.
+func (s *ClusterScope) SetLastAppliedRoutes(routeTableName string, routeNames []string) error {
+	return s.setLastApplied(infrav1.AnnotationRoutes, routeTableName, routeNames)
+}
+
+// lastApplied returns the names of the child resources last applied to a resource, as recorded in an annotation.
+func (s *ClusterScope) lastApplied(annotation, resourceName string) ([]string, error) {
+	applied, err := s.lastAppliedAnnotation(annotation)
+	if err != nil {
+		return nil, err
+	}
+	return applied[resourceName], nil
+}
+
+// setLastApplied records the names of the child resources applied to a resource in an annotation.
+func (s *ClusterScope) setLastApplied(annotation, resourceName string, names []string) error {
+	applied, err := s.lastAppliedAnnotation(annotation)
+	if err != nil {
+		return err
+	}
+	if len(names) == 0 {
+		delete(applied, resourceName)
+	} else {
+		applied[resourceName] = names
+	}
+	value, err := json.Marshal(applied)
+	if err != nil {
+		return errors.Wrapf(err, "failed to marshal annotation %s", annotation)
+	}
+	if s.AzureCluster.Annotations == nil {
+		s.AzureCluster.Annotations = make(map[string]string)
+	}
+	s.AzureCluster.Annotations[annotation] = string(value)
+	return nil
+}
+
+// lastAppliedAnnotation returns the content of an annotation of the AzureCluster recording applied child resources.
+func (s *ClusterScope) lastAppliedAnnotation(annotation string) (map[string][]string, error) {
+	applied := make(map[string][]string)
+	value, ok := s.AzureCluster.Annotations[annotation]
+	if !ok {
+		return applied, nil
+	}
+	if err := json.Unmarshal([]byte(value), &applied); err != nil {
+		return nil, errors.Wrapf(err, "failed to unmarshal annotation %s", annotation)
+	}
+	return applied, nil
+}
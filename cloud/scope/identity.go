@@ -0,0 +1,128 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scope
+
+import (
+	"context"
+	"crypto/rsa"
+	"crypto/x509"
+
+	"github.com/Azure/go-autorest/autorest"
+	"github.com/Azure/go-autorest/autorest/adal"
+	"github.com/Azure/go-autorest/autorest/azure"
+	"github.com/Azure/go-autorest/autorest/azure/auth"
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/pkcs12"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1alpha3"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// getIdentityAuthorizer creates an authorizer from the AzureClusterIdentity referenced by an AzureCluster,
+// issuing tokens against the Active Directory and Resource Manager endpoints of env.
+func getIdentityAuthorizer(c client.Client, azureCluster *infrav1.AzureCluster, env azure.Environment) (autorest.Authorizer, error) {
+	identity, err := getIdentity(c, azureCluster)
+	if err != nil {
+		return nil, err
+	}
+
+	data := map[string][]byte{}
+	if identity.Spec.SecretRef != nil {
+		secret := &corev1.Secret{}
+		key := types.NamespacedName{Namespace: identity.Spec.SecretRef.Namespace, Name: identity.Spec.SecretRef.Name}
+		if key.Namespace == "" {
+			key.Namespace = identity.Namespace
+		}
+		if key.Namespace != identity.Namespace {
+			return nil, errors.Errorf("secret %s/%s of AzureClusterIdentity %s/%s must be in the namespace of the identity", key.Namespace, key.Name, identity.Namespace, identity.Name)
+		}
+		if err := c.Get(context.TODO(), key, secret); err != nil {
+			return nil, errors.Wrapf(err, "failed to retrieve secret %s/%s of AzureClusterIdentity %s", key.Namespace, key.Name, identity.Name)
+		}
+		data = secret.Data
+	}
+	clientID := string(data[infrav1.ClientIDKey])
+
+	switch identity.Spec.Type {
+	case infrav1.ServicePrincipal:
+		clientSecret := string(data[infrav1.ClientSecretKey])
+		if clientID == "" || clientSecret == "" {
+			return nil, errors.Errorf("AzureClusterIdentity %s requires the %s and %s keys in its secret", identity.Name, infrav1.ClientIDKey, infrav1.ClientSecretKey)
+		}
+		config := auth.NewClientCredentialsConfig(clientID, clientSecret, identity.Spec.TenantID)
+		config.AADEndpoint = env.ActiveDirectoryEndpoint
+		config.Resource = env.ResourceManagerEndpoint
+		return config.Authorizer()
+	case infrav1.ServicePrincipalCertificate:
+		if clientID == "" || len(data[infrav1.CertificateKey]) == 0 {
+			return nil, errors.Errorf("AzureClusterIdentity %s requires the %s and %s keys in its secret", identity.Name, infrav1.ClientIDKey, infrav1.CertificateKey)
+		}
+		certificate, privateKey, err := decodePkcs12(data[infrav1.CertificateKey], string(data[infrav1.CertificatePasswordKey]))
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to decode certificate of AzureClusterIdentity %s", identity.Name)
+		}
+		oauthConfig, err := adal.NewOAuthConfig(env.ActiveDirectoryEndpoint, identity.Spec.TenantID)
+		if err != nil {
+			return nil, err
+		}
+		token, err := adal.NewServicePrincipalTokenFromCertificate(*oauthConfig, clientID, certificate, privateKey, env.ResourceManagerEndpoint)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get oauth token from certificate of AzureClusterIdentity %s", identity.Name)
+		}
+		return autorest.NewBearerAuthorizer(token), nil
+	case infrav1.ManagedIdentity:
+		config := auth.NewMSIConfig()
+		config.Resource = env.ResourceManagerEndpoint
+		config.ClientID = clientID
+		return config.Authorizer()
+	default:
+		return nil, errors.Errorf("AzureClusterIdentity %s has unsupported type %q", identity.Name, identity.Spec.Type)
+	}
+}
+
+// getIdentity returns the AzureClusterIdentity referenced by an AzureCluster.
+func getIdentity(c client.Client, azureCluster *infrav1.AzureCluster) (*infrav1.AzureClusterIdentity, error) {
+	ref := azureCluster.Spec.IdentityRef
+	if ref.Kind != "" && ref.Kind != "AzureClusterIdentity" {
+		return nil, errors.Errorf("identityRef of AzureCluster %s/%s has unsupported kind %q", azureCluster.Namespace, azureCluster.Name, ref.Kind)
+	}
+	identity := &infrav1.AzureClusterIdentity{}
+	key := types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}
+	if key.Namespace == "" {
+		key.Namespace = azureCluster.Namespace
+	}
+	if err := c.Get(context.TODO(), key, identity); err != nil {
+		return nil, errors.Wrapf(err, "failed to retrieve AzureClusterIdentity %s/%s", key.Namespace, key.Name)
+	}
+	if !identity.IsAllowedNamespace(azureCluster.Namespace) {
+		return nil, errors.Errorf("AzureClusterIdentity %s/%s does not allow AzureClusters in namespace %s", key.Namespace, key.Name, azureCluster.Namespace)
+	}
+	return identity, nil
+}
+
+func decodePkcs12(data []byte, password string) (*x509.Certificate, *rsa.PrivateKey, error) {
+	privateKey, certificate, err := pkcs12.Decode(data, password)
+	if err != nil {
+		return nil, nil, err
+	}
+	rsaPrivateKey, ok := privateKey.(*rsa.PrivateKey)
+	if !ok {
+		return nil, nil, errors.New("PKCS#12 certificate must contain an RSA private key")
+	}
+	return certificate, rsaPrivateKey, nil
+}
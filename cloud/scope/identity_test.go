@@ -0,0 +1,203 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scope
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/Azure/go-autorest/autorest/azure"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1alpha3"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestGetIdentityAuthorizer(t *testing.T) {
+	g := NewWithT(t)
+
+	certificate, err := ioutil.ReadFile("testdata/certificate.p12")
+	g.Expect(err).NotTo(HaveOccurred())
+
+	cases := []struct {
+		name             string
+		clusterNamespace string
+		identityRef      corev1.ObjectReference
+		identity         infrav1.AzureClusterIdentitySpec
+		secretNamespace  string
+		secretData       map[string][]byte
+		expectedError    string
+	}{
+		{
+			name:        "service principal with a client secret",
+			identityRef: corev1.ObjectReference{Name: "my-identity"},
+			identity: infrav1.AzureClusterIdentitySpec{
+				Type:      infrav1.ServicePrincipal,
+				TenantID:  "my-tenant",
+				SecretRef: &corev1.SecretReference{Name: "my-secret"},
+			},
+			secretData: map[string][]byte{
+				infrav1.ClientIDKey:     []byte("my-client"),
+				infrav1.ClientSecretKey: []byte("my-client-secret"),
+			},
+		},
+		{
+			name:        "service principal without a client secret",
+			identityRef: corev1.ObjectReference{Name: "my-identity"},
+			identity: infrav1.AzureClusterIdentitySpec{
+				Type:      infrav1.ServicePrincipal,
+				TenantID:  "my-tenant",
+				SecretRef: &corev1.SecretReference{Name: "my-secret"},
+			},
+			secretData: map[string][]byte{
+				infrav1.ClientIDKey: []byte("my-client"),
+			},
+			expectedError: "AzureClusterIdentity my-identity requires the clientID and clientSecret keys in its secret",
+		},
+		{
+			name:        "service principal with a certificate",
+			identityRef: corev1.ObjectReference{Name: "my-identity"},
+			identity: infrav1.AzureClusterIdentitySpec{
+				Type:      infrav1.ServicePrincipalCertificate,
+				TenantID:  "my-tenant",
+				SecretRef: &corev1.SecretReference{Name: "my-secret"},
+			},
+			secretData: map[string][]byte{
+				infrav1.ClientIDKey:            []byte("my-client"),
+				infrav1.CertificateKey:         certificate,
+				infrav1.CertificatePasswordKey: []byte("secret"),
+			},
+		},
+		{
+			name:        "service principal with a certificate and a wrong password",
+			identityRef: corev1.ObjectReference{Name: "my-identity"},
+			identity: infrav1.AzureClusterIdentitySpec{
+				Type:      infrav1.ServicePrincipalCertificate,
+				TenantID:  "my-tenant",
+				SecretRef: &corev1.SecretReference{Name: "my-secret"},
+			},
+			secretData: map[string][]byte{
+				infrav1.ClientIDKey:            []byte("my-client"),
+				infrav1.CertificateKey:         certificate,
+				infrav1.CertificatePasswordKey: []byte("wrong"),
+			},
+			expectedError: "failed to decode certificate of AzureClusterIdentity my-identity: pkcs12: decryption password incorrect",
+		},
+		{
+			name:        "system-assigned managed identity",
+			identityRef: corev1.ObjectReference{Name: "my-identity"},
+			identity: infrav1.AzureClusterIdentitySpec{
+				Type: infrav1.ManagedIdentity,
+			},
+		},
+		{
+			name:        "user-assigned managed identity",
+			identityRef: corev1.ObjectReference{Name: "my-identity"},
+			identity: infrav1.AzureClusterIdentitySpec{
+				Type:      infrav1.ManagedIdentity,
+				SecretRef: &corev1.SecretReference{Name: "my-secret"},
+			},
+			secretData: map[string][]byte{
+				infrav1.ClientIDKey: []byte("my-client"),
+			},
+		},
+		{
+			name:             "identity in another namespace that allows the cluster",
+			clusterNamespace: "team-b",
+			identityRef:      corev1.ObjectReference{Name: "my-identity", Namespace: "default"},
+			identity: infrav1.AzureClusterIdentitySpec{
+				Type:              infrav1.ManagedIdentity,
+				AllowedNamespaces: []string{"team-b"},
+			},
+		},
+		{
+			name:             "identity in another namespace that doesn't allow the cluster",
+			clusterNamespace: "team-b",
+			identityRef:      corev1.ObjectReference{Name: "my-identity", Namespace: "default"},
+			identity: infrav1.AzureClusterIdentitySpec{
+				Type:              infrav1.ManagedIdentity,
+				AllowedNamespaces: []string{"team-c"},
+			},
+			expectedError: "AzureClusterIdentity default/my-identity does not allow AzureClusters in namespace team-b",
+		},
+		{
+			name:        "secret in another namespace",
+			identityRef: corev1.ObjectReference{Name: "my-identity"},
+			identity: infrav1.AzureClusterIdentitySpec{
+				Type:      infrav1.ServicePrincipal,
+				TenantID:  "my-tenant",
+				SecretRef: &corev1.SecretReference{Name: "my-secret", Namespace: "team-b"},
+			},
+			secretNamespace: "team-b",
+			secretData: map[string][]byte{
+				infrav1.ClientIDKey:     []byte("my-client"),
+				infrav1.ClientSecretKey: []byte("my-client-secret"),
+			},
+			expectedError: "secret team-b/my-secret of AzureClusterIdentity default/my-identity must be in the namespace of the identity",
+		},
+		{
+			name:          "unsupported kind",
+			identityRef:   corev1.ObjectReference{Kind: "Secret", Name: "my-identity"},
+			expectedError: "identityRef of AzureCluster default/my-cluster has unsupported kind \"Secret\"",
+		},
+	}
+
+	for _, c := range cases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			scheme := runtime.NewScheme()
+			g.Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+			g.Expect(infrav1.AddToScheme(scheme)).To(Succeed())
+
+			clusterNamespace := c.clusterNamespace
+			if clusterNamespace == "" {
+				clusterNamespace = "default"
+			}
+			secretNamespace := c.secretNamespace
+			if secretNamespace == "" {
+				secretNamespace = "default"
+			}
+			objects := []runtime.Object{
+				&infrav1.AzureClusterIdentity{
+					ObjectMeta: metav1.ObjectMeta{Name: "my-identity", Namespace: "default"},
+					Spec:       c.identity,
+				},
+				&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{Name: "my-secret", Namespace: secretNamespace},
+					Data:       c.secretData,
+				},
+			}
+			azureCluster := &infrav1.AzureCluster{
+				ObjectMeta: metav1.ObjectMeta{Name: "my-cluster", Namespace: clusterNamespace},
+				Spec: infrav1.AzureClusterSpec{
+					IdentityRef: &c.identityRef,
+				},
+			}
+
+			authorizer, err := getIdentityAuthorizer(fake.NewFakeClientWithScheme(scheme, objects...), azureCluster, azure.PublicCloud)
+			if c.expectedError != "" {
+				g.Expect(err).To(MatchError(c.expectedError))
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(authorizer).NotTo(BeNil())
+		})
+	}
+}
@@ -27,6 +27,7 @@ import (
 	"k8s.io/klog/klogr"
 	"k8s.io/utils/pointer"
 	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1alpha3"
+	azure "sigs.k8s.io/cluster-api-provider-azure/cloud"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
 	"sigs.k8s.io/cluster-api/controllers/noderefutil"
 	capierrors "sigs.k8s.io/cluster-api/errors"
@@ -101,9 +102,16 @@ func (m *MachineScope) Location() string {
 	return m.AzureCluster.Spec.Location
 }
 
-// AvailabilityZone returns the AzureMachine Availability Zone.
+// AvailabilityZone returns the availability zone of the machine, if any. The failure domain of
+// the Machine takes precedence over the availability zone of the AzureMachine.
 func (m *MachineScope) AvailabilityZone() string {
-	return *m.AzureMachine.Spec.AvailabilityZone.ID
+	if m.Machine.Spec.FailureDomain != nil && *m.Machine.Spec.FailureDomain != "" {
+		return *m.Machine.Spec.FailureDomain
+	}
+	if m.AzureMachine.Spec.AvailabilityZone.ID != nil {
+		return *m.AzureMachine.Spec.AvailabilityZone.ID
+	}
+	return ""
 }
 
 // Name returns the AzureMachine name.
@@ -129,6 +137,18 @@ func (m *MachineScope) Role() string {
 	return infrav1.Node
 }
 
+// AvailabilitySet returns the name of the availability set of the machine. Control plane machines share
+// one availability set and the machines of a MachineDeployment share another. Other machines have none.
+func (m *MachineScope) AvailabilitySet() (string, bool) {
+	if m.IsControlPlane() {
+		return azure.GenerateAvailabilitySetName(m.Cluster.Name, "control-plane"), true
+	}
+	if mdName, ok := m.Machine.Labels[clusterv1.MachineDeploymentLabelName]; ok {
+		return azure.GenerateAvailabilitySetName(m.Cluster.Name, mdName), true
+	}
+	return "", false
+}
+
 // GetVMID returns the AzureMachine instance id by parsing Spec.ProviderID.
 func (m *MachineScope) GetVMID() *string {
 	parsed, err := noderefutil.NewProviderID(m.GetProviderID())
@@ -166,6 +186,11 @@ func (m *MachineScope) SetReady() {
 	m.AzureMachine.Status.Ready = true
 }
 
+// SetNotReady sets the AzureMachine Ready Status to false
+func (m *MachineScope) SetNotReady() {
+	m.AzureMachine.Status.Ready = false
+}
+
 // SetFailureMessage sets the AzureMachine status failure message.
 func (m *MachineScope) SetFailureMessage(v error) {
 	m.AzureMachine.Status.FailureMessage = pointer.StringPtr(v.Error())
@@ -189,6 +214,11 @@ func (m *MachineScope) SetAddresses(addrs []corev1.NodeAddress) {
 	m.AzureMachine.Status.Addresses = addrs
 }
 
+// SetPrincipalID sets the principal ID of the system-assigned identity of the AzureMachine VM.
+func (m *MachineScope) SetPrincipalID(v string) {
+	m.AzureMachine.Status.PrincipalID = v
+}
+
 // PatchObject persists the machine spec and status.
 func (m *MachineScope) PatchObject() error {
 	return m.patchHelper.Patch(context.TODO(), m.AzureMachine)
@@ -0,0 +1,207 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scope
+
+import (
+	"context"
+	"encoding/base64"
+
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/klogr"
+	"k8s.io/utils/pointer"
+	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1alpha3"
+	infrav1exp "sigs.k8s.io/cluster-api-provider-azure/exp/api/v1alpha3"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+	capierrors "sigs.k8s.io/cluster-api/errors"
+	expv1 "sigs.k8s.io/cluster-api/exp/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/util/patch"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// MachinePoolScopeParams defines the input parameters used to create a new MachinePoolScope.
+type MachinePoolScopeParams struct {
+	Client           client.Client
+	Logger           logr.Logger
+	Cluster          *clusterv1.Cluster
+	MachinePool      *expv1.MachinePool
+	AzureCluster     *infrav1.AzureCluster
+	AzureMachinePool *infrav1exp.AzureMachinePool
+}
+
+// NewMachinePoolScope creates a new MachinePoolScope from the supplied parameters.
+// This is meant to be called for each reconcile iteration.
+func NewMachinePoolScope(params MachinePoolScopeParams) (*MachinePoolScope, error) {
+	if params.Client == nil {
+		return nil, errors.New("client is required when creating a MachinePoolScope")
+	}
+	if params.MachinePool == nil {
+		return nil, errors.New("machine pool is required when creating a MachinePoolScope")
+	}
+	if params.Cluster == nil {
+		return nil, errors.New("cluster is required when creating a MachinePoolScope")
+	}
+	if params.AzureCluster == nil {
+		return nil, errors.New("azure cluster is required when creating a MachinePoolScope")
+	}
+	if params.AzureMachinePool == nil {
+		return nil, errors.New("azure machine pool is required when creating a MachinePoolScope")
+	}
+
+	if params.Logger == nil {
+		params.Logger = klogr.New()
+	}
+
+	helper, err := patch.NewHelper(params.AzureMachinePool, params.Client)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to init patch helper")
+	}
+	return &MachinePoolScope{
+		client:           params.Client,
+		Cluster:          params.Cluster,
+		MachinePool:      params.MachinePool,
+		AzureCluster:     params.AzureCluster,
+		AzureMachinePool: params.AzureMachinePool,
+		Logger:           params.Logger,
+		patchHelper:      helper,
+	}, nil
+}
+
+// MachinePoolScope defines a scope defined around a machine pool and its cluster.
+type MachinePoolScope struct {
+	logr.Logger
+	client      client.Client
+	patchHelper *patch.Helper
+
+	Cluster          *clusterv1.Cluster
+	MachinePool      *expv1.MachinePool
+	AzureCluster     *infrav1.AzureCluster
+	AzureMachinePool *infrav1exp.AzureMachinePool
+}
+
+// Location returns the AzureMachinePool location.
+func (m *MachinePoolScope) Location() string {
+	return m.AzureCluster.Spec.Location
+}
+
+// Name returns the AzureMachinePool name.
+func (m *MachinePoolScope) Name() string {
+	return m.AzureMachinePool.Name
+}
+
+// Namespace returns the namespace name.
+func (m *MachinePoolScope) Namespace() string {
+	return m.AzureMachinePool.Namespace
+}
+
+// Role returns the machine role of the machine pool, which is always a node.
+func (m *MachinePoolScope) Role() string {
+	return infrav1.Node
+}
+
+// Replicas returns the number of replicas of the MachinePool, defaulting to 1.
+func (m *MachinePoolScope) Replicas() int64 {
+	if m.MachinePool.Spec.Replicas != nil {
+		return int64(*m.MachinePool.Spec.Replicas)
+	}
+	return 1
+}
+
+// SetProviderID sets the AzureMachinePool providerID in spec.
+func (m *MachinePoolScope) SetProviderID(v string) {
+	m.AzureMachinePool.Spec.ProviderID = v
+}
+
+// SetProviderIDList sets the providerIDs of the AzureMachinePool instances in spec.
+func (m *MachinePoolScope) SetProviderIDList(v []string) {
+	m.AzureMachinePool.Spec.ProviderIDList = v
+}
+
+// SetInstances sets the status of the AzureMachinePool instances and the number of replicas.
+func (m *MachinePoolScope) SetInstances(v []*infrav1exp.AzureMachinePoolInstanceStatus) {
+	m.AzureMachinePool.Status.Instances = v
+	m.AzureMachinePool.Status.Replicas = int32(len(v))
+}
+
+// SetProvisioningState sets the AzureMachinePool scale set provisioning state.
+func (m *MachinePoolScope) SetProvisioningState(v infrav1.VMState) {
+	m.AzureMachinePool.Status.ProvisioningState = &v
+}
+
+// SetReady sets the AzureMachinePool Ready Status
+func (m *MachinePoolScope) SetReady() {
+	m.AzureMachinePool.Status.Ready = true
+}
+
+// SetNotReady sets the AzureMachinePool Ready Status to false
+func (m *MachinePoolScope) SetNotReady() {
+	m.AzureMachinePool.Status.Ready = false
+}
+
+// SetFailureMessage sets the AzureMachinePool status failure message.
+func (m *MachinePoolScope) SetFailureMessage(v error) {
+	m.AzureMachinePool.Status.FailureMessage = pointer.StringPtr(v.Error())
+}
+
+// SetFailureReason sets the AzureMachinePool status failure reason.
+func (m *MachinePoolScope) SetFailureReason(v capierrors.MachineStatusError) {
+	m.AzureMachinePool.Status.FailureReason = &v
+}
+
+// PatchObject persists the machine pool spec and status.
+func (m *MachinePoolScope) PatchObject() error {
+	return m.patchHelper.Patch(context.TODO(), m.AzureMachinePool)
+}
+
+// Close the MachinePoolScope by updating the machine pool spec, machine pool status.
+func (m *MachinePoolScope) Close() error {
+	return m.patchHelper.Patch(context.TODO(), m.AzureMachinePool)
+}
+
+// AdditionalTags merges AdditionalTags from the scope's AzureCluster and AzureMachinePool. If the same key is present in both,
+// the value from AzureMachinePool takes precedence.
+func (m *MachinePoolScope) AdditionalTags() infrav1.Tags {
+	tags := make(infrav1.Tags)
+
+	// Start with the cluster-wide tags...
+	tags.Merge(m.AzureCluster.Spec.AdditionalTags)
+	// ... and merge in the MachinePool's
+	tags.Merge(m.AzureMachinePool.Spec.AdditionalTags)
+
+	return tags
+}
+
+// GetBootstrapData returns the bootstrap data from the secret in the MachinePool's bootstrap.dataSecretName.
+func (m *MachinePoolScope) GetBootstrapData() (string, error) {
+	dataSecretName := m.MachinePool.Spec.Template.Spec.Bootstrap.DataSecretName
+	if dataSecretName == nil {
+		return "", errors.New("error retrieving bootstrap data: linked MachinePool's bootstrap.dataSecretName is nil")
+	}
+	secret := &corev1.Secret{}
+	key := types.NamespacedName{Namespace: m.Namespace(), Name: *dataSecretName}
+	if err := m.client.Get(context.TODO(), key, secret); err != nil {
+		return "", errors.Wrapf(err, "failed to retrieve bootstrap data secret for AzureMachinePool %s/%s", m.Namespace(), m.Name())
+	}
+
+	value, ok := secret.Data["value"]
+	if !ok {
+		return "", errors.New("error retrieving bootstrap data: secret value key is missing")
+	}
+	return base64.StdEncoding.EncodeToString(value), nil
+}
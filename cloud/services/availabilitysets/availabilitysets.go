@@ -0,0 +1,108 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package availabilitysets
+
+import (
+	"context"
+
+	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2020-06-01/compute"
+	"github.com/Azure/go-autorest/autorest/to"
+	"github.com/pkg/errors"
+	"k8s.io/klog"
+	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1alpha3"
+	azure "sigs.k8s.io/cluster-api-provider-azure/cloud"
+	"sigs.k8s.io/cluster-api-provider-azure/cloud/converters"
+)
+
+const (
+	// faultDomainCount is the number of fault domains of an availability set.
+	// Some regions only have 2 fault domains, so it is the largest count supported everywhere.
+	faultDomainCount = 2
+	// updateDomainCount is the number of update domains of an availability set.
+	updateDomainCount = 5
+)
+
+// Spec input specification for Get/CreateOrUpdate/Delete calls
+type Spec struct {
+	Name string
+}
+
+// Reconcile creates or updates an availability set.
+func (s *Service) Reconcile(ctx context.Context, spec interface{}) error {
+	asSpec, ok := spec.(*Spec)
+	if !ok {
+		return errors.New("invalid availability set specification")
+	}
+
+	klog.V(2).Infof("creating availability set %s", asSpec.Name)
+	_, err := s.Client.CreateOrUpdate(ctx, s.Scope.ResourceGroup(), asSpec.Name, compute.AvailabilitySet{
+		Location: to.StringPtr(s.Scope.Location()),
+		// managed disks require aligned availability sets
+		Sku: &compute.Sku{Name: to.StringPtr(string(compute.Aligned))},
+		Tags: converters.TagsToMap(infrav1.Build(infrav1.BuildParams{
+			ClusterName: s.Scope.Name(),
+			Lifecycle:   infrav1.ResourceLifecycleOwned,
+			Name:        to.StringPtr(asSpec.Name),
+			Additional:  s.Scope.AdditionalTags(),
+		})),
+		AvailabilitySetProperties: &compute.AvailabilitySetProperties{
+			PlatformFaultDomainCount:  to.Int32Ptr(faultDomainCount),
+			PlatformUpdateDomainCount: to.Int32Ptr(updateDomainCount),
+		},
+	})
+	if err != nil {
+		return errors.Wrapf(err, "failed to create availability set %s", asSpec.Name)
+	}
+
+	klog.V(2).Infof("successfully created availability set %s", asSpec.Name)
+	return nil
+}
+
+// Delete deletes an availability set when no virtual machine uses it anymore.
+func (s *Service) Delete(ctx context.Context, spec interface{}) error {
+	asSpec, ok := spec.(*Spec)
+	if !ok {
+		return errors.New("invalid availability set specification")
+	}
+
+	as, err := s.Client.Get(ctx, s.Scope.ResourceGroup(), asSpec.Name)
+	if err != nil && azure.ResourceNotFound(err) {
+		// already deleted
+		return nil
+	}
+	if err != nil {
+		return errors.Wrapf(err, "failed to get availability set %s in resource group %s", asSpec.Name, s.Scope.ResourceGroup())
+	}
+
+	if as.AvailabilitySetProperties != nil && as.VirtualMachines != nil && len(*as.VirtualMachines) > 0 {
+		klog.V(2).Infof("availability set %s is still in use by %d virtual machines", asSpec.Name, len(*as.VirtualMachines))
+		return nil
+	}
+
+	klog.V(2).Infof("deleting availability set %s", asSpec.Name)
+	err = s.Client.Delete(ctx, s.Scope.ResourceGroup(), asSpec.Name)
+	if err != nil && azure.ResourceNotFound(err) {
+		// already deleted
+		return nil
+	}
+	if err != nil {
+		return errors.Wrapf(err, "failed to delete availability set %s in resource group %s", asSpec.Name, s.Scope.ResourceGroup())
+	}
+
+	klog.V(2).Infof("successfully deleted availability set %s", asSpec.Name)
+	return nil
+}
@@ -0,0 +1,207 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package availabilitysets
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"sigs.k8s.io/cluster-api-provider-azure/cloud/services/availabilitysets/mock_availabilitysets"
+
+	"github.com/Azure/go-autorest/autorest"
+	"github.com/golang/mock/gomock"
+
+	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2020-06-01/compute"
+	network "github.com/Azure/azure-sdk-for-go/services/network/mgmt/2019-06-01/network"
+	"github.com/Azure/go-autorest/autorest/to"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1alpha3"
+	"sigs.k8s.io/cluster-api-provider-azure/cloud/scope"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func init() {
+	clusterv1.AddToScheme(scheme.Scheme)
+}
+
+func newClusterScope(g *WithT) *scope.ClusterScope {
+	cluster := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-cluster"},
+	}
+
+	clusterScope, err := scope.NewClusterScope(scope.ClusterScopeParams{
+		AzureClients: scope.AzureClients{
+			SubscriptionID: "123",
+			Authorizer:     autorest.NullAuthorizer{},
+		},
+		Client:  fake.NewFakeClient(cluster),
+		Cluster: cluster,
+		AzureCluster: &infrav1.AzureCluster{
+			Spec: infrav1.AzureClusterSpec{
+				Location:      "test-location",
+				ResourceGroup: "my-rg",
+			},
+		},
+	})
+	g.Expect(err).NotTo(HaveOccurred())
+	return clusterScope
+}
+
+func TestInvalidAvailabilitySetSpec(t *testing.T) {
+	g := NewWithT(t)
+
+	mockCtrl := gomock.NewController(t)
+	asMock := mock_availabilitysets.NewMockClient(mockCtrl)
+
+	s := &Service{
+		Scope:  newClusterScope(g),
+		Client: asMock,
+	}
+
+	// Wrong Spec
+	wrongSpec := &network.PublicIPAddress{}
+
+	err := s.Reconcile(context.TODO(), &wrongSpec)
+	g.Expect(err).To(MatchError("invalid availability set specification"))
+
+	err = s.Delete(context.TODO(), &wrongSpec)
+	g.Expect(err).To(MatchError("invalid availability set specification"))
+}
+
+func TestReconcileAvailabilitySet(t *testing.T) {
+	g := NewWithT(t)
+
+	testcases := []struct {
+		name          string
+		expectedError string
+		expect        func(m *mock_availabilitysets.MockClientMockRecorder)
+	}{
+		{
+			name:          "create the availability set",
+			expectedError: "",
+			expect: func(m *mock_availabilitysets.MockClientMockRecorder) {
+				m.CreateOrUpdate(context.TODO(), "my-rg", "my-as", gomock.AssignableToTypeOf(compute.AvailabilitySet{})).
+					Do(func(_ context.Context, _, _ string, as compute.AvailabilitySet) {
+						g.Expect(*as.Sku.Name).To(Equal("Aligned"))
+						g.Expect(*as.PlatformFaultDomainCount).To(Equal(int32(2)))
+						g.Expect(*as.PlatformUpdateDomainCount).To(Equal(int32(5)))
+					})
+			},
+		},
+		{
+			name:          "error while trying to create the availability set",
+			expectedError: "failed to create availability set my-as: #: Internal Server Error: StatusCode=500",
+			expect: func(m *mock_availabilitysets.MockClientMockRecorder) {
+				m.CreateOrUpdate(context.TODO(), "my-rg", "my-as", gomock.AssignableToTypeOf(compute.AvailabilitySet{})).
+					Return(compute.AvailabilitySet{}, autorest.NewErrorWithResponse("", "", &http.Response{StatusCode: 500}, "Internal Server Error"))
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			asMock := mock_availabilitysets.NewMockClient(mockCtrl)
+
+			tc.expect(asMock.EXPECT())
+
+			s := &Service{
+				Scope:  newClusterScope(g),
+				Client: asMock,
+			}
+
+			err := s.Reconcile(context.TODO(), &Spec{Name: "my-as"})
+			if tc.expectedError != "" {
+				g.Expect(err).To(MatchError(tc.expectedError))
+			} else {
+				g.Expect(err).NotTo(HaveOccurred())
+			}
+		})
+	}
+}
+
+func TestDeleteAvailabilitySet(t *testing.T) {
+	g := NewWithT(t)
+
+	testcases := []struct {
+		name          string
+		expectedError string
+		expect        func(m *mock_availabilitysets.MockClientMockRecorder)
+	}{
+		{
+			name:          "delete the availability set",
+			expectedError: "",
+			expect: func(m *mock_availabilitysets.MockClientMockRecorder) {
+				m.Get(context.TODO(), "my-rg", "my-as").Return(compute.AvailabilitySet{
+					AvailabilitySetProperties: &compute.AvailabilitySetProperties{},
+				}, nil)
+				m.Delete(context.TODO(), "my-rg", "my-as")
+			},
+		},
+		{
+			name:          "availability set still in use",
+			expectedError: "",
+			expect: func(m *mock_availabilitysets.MockClientMockRecorder) {
+				m.Get(context.TODO(), "my-rg", "my-as").Return(compute.AvailabilitySet{
+					AvailabilitySetProperties: &compute.AvailabilitySetProperties{
+						VirtualMachines: &[]compute.SubResource{{ID: to.StringPtr("my-vm")}},
+					},
+				}, nil)
+			},
+		},
+		{
+			name:          "availability set already deleted",
+			expectedError: "",
+			expect: func(m *mock_availabilitysets.MockClientMockRecorder) {
+				m.Get(context.TODO(), "my-rg", "my-as").Return(compute.AvailabilitySet{}, autorest.NewErrorWithResponse("", "", &http.Response{StatusCode: 404}, "Not Found"))
+			},
+		},
+		{
+			name:          "error while trying to delete the availability set",
+			expectedError: "failed to delete availability set my-as in resource group my-rg: #: Internal Server Error: StatusCode=500",
+			expect: func(m *mock_availabilitysets.MockClientMockRecorder) {
+				m.Get(context.TODO(), "my-rg", "my-as").Return(compute.AvailabilitySet{}, nil)
+				m.Delete(context.TODO(), "my-rg", "my-as").Return(autorest.NewErrorWithResponse("", "", &http.Response{StatusCode: 500}, "Internal Server Error"))
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			asMock := mock_availabilitysets.NewMockClient(mockCtrl)
+
+			tc.expect(asMock.EXPECT())
+
+			s := &Service{
+				Scope:  newClusterScope(g),
+				Client: asMock,
+			}
+
+			err := s.Delete(context.TODO(), &Spec{Name: "my-as"})
+			if tc.expectedError != "" {
+				g.Expect(err).To(MatchError(tc.expectedError))
+			} else {
+				g.Expect(err).NotTo(HaveOccurred())
+			}
+		})
+	}
+}
@@ -21,12 +21,14 @@ import (
 	"sort"
 	"strings"
 
-	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2019-12-01/compute"
+	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2020-06-01/compute"
 	"github.com/pkg/errors"
 )
 
 // Spec input specification for Get/CreateOrUpdate/Delete calls
 type Spec struct {
+	// VMSize restricts the zones to those the VM size is available in.
+	// When empty, the zones of the location that any VM size is available in are returned.
 	VMSize string
 }
 
@@ -37,52 +39,78 @@ func (s *Service) Get(ctx context.Context, spec interface{}) (interface{}, error
 	if !ok {
 		return zones, errors.New("invalid availability zones specification")
 	}
-	// Prefer ListComplete() over List() to automatically traverse pages via iterator.
-	res, err := s.Client.ListComplete(ctx, "")
+
+	skus, err := s.Cache.Get(ctx, s.Client, s.Scope.SubscriptionID, s.Scope.Location())
 	if err != nil {
 		return zones, err
 	}
 
-	for res.NotDone() {
-		resSku := res.Value()
-		if strings.EqualFold(*resSku.Name, skusSpec.VMSize) {
-			// Use map for easy deletion and iteration
-			availableZones := make(map[string]bool)
-			for _, locationInfo := range *resSku.LocationInfo {
+	// Use map for easy deletion and iteration
+	availableZones := make(map[string]bool)
+	for _, resSku := range skus {
+		if !matchesVMSize(resSku, skusSpec.VMSize) || resSku.LocationInfo == nil {
+			continue
+		}
+		for _, locationInfo := range *resSku.LocationInfo {
+			if locationInfo.Location == nil || !strings.EqualFold(*locationInfo.Location, s.Scope.Location()) {
+				continue
+			}
+			skuZones := make(map[string]bool)
+			if locationInfo.Zones != nil {
 				for _, zone := range *locationInfo.Zones {
-					availableZones[zone] = true
+					skuZones[zone] = true
 				}
-				if strings.EqualFold(*locationInfo.Location, s.Scope.Location()) {
-					for _, restriction := range *resSku.Restrictions {
-						// Can't deploy anything in this subscription in this location. Bail out.
-						if restriction.Type == compute.Location {
-							return []string{}, errors.Errorf("rejecting sku: %s in location: %s due to susbcription restriction", skusSpec.VMSize, s.Scope.Location())
-						}
-						// May be able to deploy one or more zones to this location.
+			}
+			restricted := false
+			if resSku.Restrictions != nil {
+				for _, restriction := range *resSku.Restrictions {
+					// Can't deploy anything in this subscription in this location.
+					if restriction.Type == compute.Location {
+						restricted = true
+						break
+					}
+					// May be able to deploy one or more zones to this location.
+					if restriction.RestrictionInfo != nil && restriction.RestrictionInfo.Zones != nil {
 						for _, restrictedZone := range *restriction.RestrictionInfo.Zones {
-							delete(availableZones, restrictedZone)
+							delete(skuZones, restrictedZone)
 						}
 					}
-					// Back to slice. Empty is fine, and will deploy the VM to some FD/UD (no point in configuring this until supported at higher levels)
-					result := make([]string, 0)
-					for availableZone := range availableZones {
-						result = append(result, availableZone)
-					}
-					// Lexical sort so comparisons work in tests
-					sort.Strings(result)
-					zones = result
 				}
 			}
-		}
-		err = res.NextWithContext(ctx)
-		if err != nil {
-			return zones, errors.Wrap(err, "could not iterate availability zones")
+			if restricted {
+				if skusSpec.VMSize != "" {
+					// Bail out, the requested VM size can't be used at all.
+					return []string{}, errors.Errorf("rejecting sku: %s in location: %s due to susbcription restriction", skusSpec.VMSize, s.Scope.Location())
+				}
+				continue
+			}
+			for zone := range skuZones {
+				availableZones[zone] = true
+			}
 		}
 	}
 
+	// Back to slice. Empty is fine, and will deploy the VM to some FD/UD.
+	zones = make([]string, 0, len(availableZones))
+	for availableZone := range availableZones {
+		zones = append(zones, availableZone)
+	}
+	// Lexical sort so comparisons work in tests
+	sort.Strings(zones)
 	return zones, nil
 }
 
+// matchesVMSize returns true if the resource SKU is the VM size, or any VM size when vmSize is empty.
+func matchesVMSize(sku compute.ResourceSku, vmSize string) bool {
+	if sku.Name == nil {
+		return false
+	}
+	if vmSize == "" {
+		return sku.ResourceType != nil && strings.EqualFold(*sku.ResourceType, "virtualMachines")
+	}
+	return strings.EqualFold(*sku.Name, vmSize)
+}
+
 // Reconcile no-op.
 func (s *Service) Reconcile(ctx context.Context, spec interface{}) error {
 	// Not implemented since there is nothing to reconcile
@@ -24,14 +24,16 @@ import (
 	. "github.com/onsi/gomega"
 	"sigs.k8s.io/cluster-api-provider-azure/cloud/services/availabilityzones/mock_availabilityzones"
 
-	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2019-12-01/compute"
+	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2020-06-01/compute"
 	"github.com/Azure/go-autorest/autorest"
+	"github.com/Azure/go-autorest/autorest/to"
 	"github.com/golang/mock/gomock"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes/scheme"
 	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1alpha3"
 	"sigs.k8s.io/cluster-api-provider-azure/cloud/scope"
+	"sigs.k8s.io/cluster-api-provider-azure/cloud/services/resourceskus"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
@@ -54,7 +56,7 @@ func TestGetAvailabilityZones(t *testing.T) {
 			availabilityZoneSpec: Spec{VMSize: "Standard_B2ms"},
 			expectedError:        "",
 			expect: func(m *mock_availabilityzones.MockClientMockRecorder) {
-				m.ListComplete(context.TODO(), "").Return(compute.ResourceSkusResultIterator{}, nil)
+				m.ListComplete(context.TODO(), "location eq 'test-location'").Return(compute.ResourceSkusResultIterator{}, nil)
 			},
 		},
 		{
@@ -62,7 +64,7 @@ func TestGetAvailabilityZones(t *testing.T) {
 			availabilityZoneSpec: Spec{VMSize: "Standard_B2ms"},
 			expectedError:        "#: Internal Server Error: StatusCode=500",
 			expect: func(m *mock_availabilityzones.MockClientMockRecorder) {
-				m.ListComplete(context.TODO(), "").Return(compute.ResourceSkusResultIterator{}, autorest.NewErrorWithResponse("", "", &http.Response{StatusCode: 500}, "Internal Server Error"))
+				m.ListComplete(context.TODO(), "location eq 'test-location'").Return(compute.ResourceSkusResultIterator{}, autorest.NewErrorWithResponse("", "", &http.Response{StatusCode: 500}, "Internal Server Error"))
 			},
 		},
 		{
@@ -70,7 +72,7 @@ func TestGetAvailabilityZones(t *testing.T) {
 			availabilityZoneSpec: Spec{VMSize: "Standard_B2ms"},
 			expectedError:        "",
 			expect: func(m *mock_availabilityzones.MockClientMockRecorder) {
-				m.ListComplete(context.TODO(), "").Return(compute.NewResourceSkusResultIterator(compute.ResourceSkusResultPage{}), nil)
+				m.ListComplete(context.TODO(), "location eq 'test-location'").Return(compute.NewResourceSkusResultIterator(compute.ResourceSkusResultPage{}), nil)
 			},
 		},
 	}
@@ -97,7 +99,7 @@ func TestGetAvailabilityZones(t *testing.T) {
 				Cluster: cluster,
 				AzureCluster: &infrav1.AzureCluster{
 					Spec: infrav1.AzureClusterSpec{
-						Location: "test-location",
+						Location:      "test-location",
 						ResourceGroup: "my-rg",
 						NetworkSpec: infrav1.NetworkSpec{
 							Vnet: infrav1.VnetSpec{Name: "my-vnet", ResourceGroup: "my-rg"},
@@ -126,3 +128,186 @@ func TestGetAvailabilityZones(t *testing.T) {
 		})
 	}
 }
+
+func TestGetAvailabilityZonesForLocation(t *testing.T) {
+	g := NewWithT(t)
+
+	skus := []compute.ResourceSku{
+		{
+			Name:         to.StringPtr("Standard_D2s_v3"),
+			ResourceType: to.StringPtr("virtualMachines"),
+			LocationInfo: &[]compute.ResourceSkuLocationInfo{
+				{Location: to.StringPtr("test-location"), Zones: &[]string{"1", "2", "3"}},
+			},
+			Restrictions: &[]compute.ResourceSkuRestrictions{
+				{Type: compute.Zone, RestrictionInfo: &compute.ResourceSkuRestrictionInfo{Zones: &[]string{"3"}}},
+			},
+		},
+		{
+			Name:         to.StringPtr("Standard_D4s_v3"),
+			ResourceType: to.StringPtr("virtualMachines"),
+			LocationInfo: &[]compute.ResourceSkuLocationInfo{
+				{Location: to.StringPtr("test-location"), Zones: &[]string{"3"}},
+			},
+		},
+		{
+			Name:         to.StringPtr("Standard_F2"),
+			ResourceType: to.StringPtr("virtualMachines"),
+			LocationInfo: &[]compute.ResourceSkuLocationInfo{
+				{Location: to.StringPtr("test-location"), Zones: &[]string{"4"}},
+			},
+			Restrictions: &[]compute.ResourceSkuRestrictions{
+				{Type: compute.Location, RestrictionInfo: &compute.ResourceSkuRestrictionInfo{Locations: &[]string{"test-location"}}},
+			},
+		},
+		{
+			Name:         to.StringPtr("Standard_D2s_v3"),
+			ResourceType: to.StringPtr("virtualMachines"),
+			LocationInfo: &[]compute.ResourceSkuLocationInfo{
+				{Location: to.StringPtr("other-location"), Zones: &[]string{"5"}},
+			},
+		},
+		{
+			Name:         to.StringPtr("Premium_LRS"),
+			ResourceType: to.StringPtr("disks"),
+			LocationInfo: &[]compute.ResourceSkuLocationInfo{
+				{Location: to.StringPtr("test-location"), Zones: &[]string{"6"}},
+			},
+		},
+	}
+
+	testcases := []struct {
+		name          string
+		vmSize        string
+		expectedZones []string
+		expectedError string
+	}{
+		{
+			name:          "zones of any VM size",
+			vmSize:        "",
+			expectedZones: []string{"1", "2", "3"},
+		},
+		{
+			name:          "zones of a VM size without restricted zones",
+			vmSize:        "Standard_D2s_v3",
+			expectedZones: []string{"1", "2"},
+		},
+		{
+			name:          "VM size restricted in the location",
+			vmSize:        "Standard_F2",
+			expectedError: "rejecting sku: Standard_F2 in location: test-location due to susbcription restriction",
+		},
+		{
+			name:          "unknown VM size",
+			vmSize:        "Standard_Unknown",
+			expectedZones: []string{},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			azMock := mock_availabilityzones.NewMockClient(mockCtrl)
+
+			page := compute.NewResourceSkusResultPage(func(_ context.Context, r compute.ResourceSkusResult) (compute.ResourceSkusResult, error) {
+				if r.Value == nil {
+					return compute.ResourceSkusResult{Value: &skus}, nil
+				}
+				return compute.ResourceSkusResult{}, nil
+			})
+			g.Expect(page.NextWithContext(context.TODO())).To(Succeed())
+			azMock.EXPECT().ListComplete(context.TODO(), "location eq 'test-location'").Return(compute.NewResourceSkusResultIterator(page), nil)
+
+			cluster := &clusterv1.Cluster{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-cluster"},
+			}
+
+			clusterScope, err := scope.NewClusterScope(scope.ClusterScopeParams{
+				AzureClients: scope.AzureClients{
+					SubscriptionID: "123",
+					Authorizer:     autorest.NullAuthorizer{},
+				},
+				Client:  fake.NewFakeClient(cluster),
+				Cluster: cluster,
+				AzureCluster: &infrav1.AzureCluster{
+					Spec: infrav1.AzureClusterSpec{
+						Location:      "test-location",
+						ResourceGroup: "my-rg",
+					},
+				},
+			})
+			g.Expect(err).NotTo(HaveOccurred())
+
+			s := &Service{
+				Scope:  clusterScope,
+				Client: azMock,
+			}
+
+			zones, err := s.Get(context.TODO(), &Spec{VMSize: tc.vmSize})
+			if tc.expectedError != "" {
+				g.Expect(err).To(MatchError(tc.expectedError))
+			} else {
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(zones).To(Equal(tc.expectedZones))
+			}
+		})
+	}
+}
+
+func TestGetAvailabilityZonesCached(t *testing.T) {
+	g := NewWithT(t)
+
+	mockCtrl := gomock.NewController(t)
+	azMock := mock_availabilityzones.NewMockClient(mockCtrl)
+
+	skus := []compute.ResourceSku{
+		{
+			Name:         to.StringPtr("Standard_D2s_v3"),
+			ResourceType: to.StringPtr("virtualMachines"),
+			LocationInfo: &[]compute.ResourceSkuLocationInfo{
+				{Location: to.StringPtr("test-location"), Zones: &[]string{"1", "2"}},
+			},
+		},
+	}
+	page := compute.NewResourceSkusResultPage(func(_ context.Context, r compute.ResourceSkusResult) (compute.ResourceSkusResult, error) {
+		if r.Value == nil {
+			return compute.ResourceSkusResult{Value: &skus}, nil
+		}
+		return compute.ResourceSkusResult{}, nil
+	})
+	g.Expect(page.NextWithContext(context.TODO())).To(Succeed())
+	// The SKUs are only listed once per subscription and location.
+	azMock.EXPECT().ListComplete(context.TODO(), "location eq 'test-location'").Return(compute.NewResourceSkusResultIterator(page), nil).Times(1)
+
+	cluster := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-cluster"},
+	}
+
+	clusterScope, err := scope.NewClusterScope(scope.ClusterScopeParams{
+		AzureClients: scope.AzureClients{
+			SubscriptionID: "123",
+			Authorizer:     autorest.NullAuthorizer{},
+		},
+		Client:  fake.NewFakeClient(cluster),
+		Cluster: cluster,
+		AzureCluster: &infrav1.AzureCluster{
+			Spec: infrav1.AzureClusterSpec{
+				Location:      "test-location",
+				ResourceGroup: "my-rg",
+			},
+		},
+	})
+	g.Expect(err).NotTo(HaveOccurred())
+
+	s := &Service{
+		Scope:  clusterScope,
+		Client: azMock,
+		Cache:  resourceskus.NewCache(resourceskus.DefaultCacheTTL),
+	}
+
+	for _, vmSize := range []string{"", "Standard_D2s_v3"} {
+		zones, err := s.Get(context.TODO(), &Spec{VMSize: vmSize})
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(zones).To(Equal([]string{"1", "2"}))
+	}
+}
@@ -18,18 +18,22 @@ package availabilityzones
 
 import (
 	"sigs.k8s.io/cluster-api-provider-azure/cloud/scope"
+	"sigs.k8s.io/cluster-api-provider-azure/cloud/services/resourceskus"
 )
 
 // Service provides operations on azure resources
 type Service struct {
 	Scope *scope.ClusterScope
 	Client
+	// Cache holds the resource SKUs already listed. The SKUs are listed on every call when it is nil.
+	Cache *resourceskus.Cache
 }
 
 // NewService creates a new service.
-func NewService(scope *scope.ClusterScope) *Service {
+func NewService(scope *scope.ClusterScope, cache *resourceskus.Cache) *Service {
 	return &Service{
 		Scope:  scope,
-		Client: NewClient(scope.SubscriptionID, scope.Authorizer),
+		Client: NewClient(scope.SubscriptionID, scope.ResourceManagerEndpoint, scope.Authorizer),
+		Cache:  cache,
 	}
 }
@@ -0,0 +1,159 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bastionhosts
+
+import (
+	"context"
+
+	"github.com/Azure/azure-sdk-for-go/services/network/mgmt/2019-06-01/network"
+	"github.com/Azure/go-autorest/autorest/to"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/klog"
+	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1alpha3"
+	azure "sigs.k8s.io/cluster-api-provider-azure/cloud"
+	"sigs.k8s.io/cluster-api-provider-azure/cloud/converters"
+)
+
+// Spec specification for an Azure Bastion host
+type Spec struct {
+	Name         string
+	SubnetName   string
+	PublicIPName string
+	VnetName     string
+}
+
+// Get provides information about an Azure Bastion host, including its addresses.
+func (s *Service) Get(ctx context.Context, spec interface{}) (interface{}, error) {
+	bastionSpec, ok := spec.(*Spec)
+	if !ok {
+		return nil, errors.New("invalid bastion host specification")
+	}
+	bastionHost, err := s.Client.Get(ctx, s.Scope.ResourceGroup(), bastionSpec.Name)
+	if err != nil && azure.ResourceNotFound(err) {
+		return nil, errors.Wrapf(err, "bastion host %s not found", bastionSpec.Name)
+	} else if err != nil {
+		return nil, err
+	}
+
+	bastion := &infrav1.VM{
+		ID:        to.String(bastionHost.ID),
+		Name:      to.String(bastionHost.Name),
+		Tags:      converters.MapToTags(bastionHost.Tags),
+		Addresses: []corev1.NodeAddress{},
+	}
+	if bastionHost.BastionHostPropertiesFormat != nil {
+		bastion.State = infrav1.VMState(bastionHost.ProvisioningState)
+		if dnsName := to.String(bastionHost.DNSName); dnsName != "" {
+			bastion.Addresses = append(bastion.Addresses, corev1.NodeAddress{
+				Type:    corev1.NodeExternalDNS,
+				Address: dnsName,
+			})
+		}
+	}
+
+	publicIP, err := s.PublicIPsClient.Get(ctx, s.Scope.ResourceGroup(), bastionSpec.PublicIPName)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get public ip %s of bastion host %s", bastionSpec.PublicIPName, bastionSpec.Name)
+	}
+	if ip := to.String(publicIP.IPAddress); ip != "" {
+		bastion.Addresses = append(bastion.Addresses, corev1.NodeAddress{
+			Type:    corev1.NodeExternalIP,
+			Address: ip,
+		})
+	}
+
+	return bastion, nil
+}
+
+// Reconcile gets/creates/updates an Azure Bastion host.
+func (s *Service) Reconcile(ctx context.Context, spec interface{}) error {
+	bastionSpec, ok := spec.(*Spec)
+	if !ok {
+		return errors.New("invalid bastion host specification")
+	}
+
+	klog.V(2).Infof("getting subnet %s", bastionSpec.SubnetName)
+	subnet, err := s.SubnetsClient.Get(ctx, s.Scope.Vnet().ResourceGroup, bastionSpec.VnetName, bastionSpec.SubnetName)
+	if err != nil {
+		return errors.Wrapf(err, "failed to get subnet %s", bastionSpec.SubnetName)
+	}
+	klog.V(2).Infof("successfully got subnet %s", bastionSpec.SubnetName)
+
+	klog.V(2).Infof("getting public ip %s", bastionSpec.PublicIPName)
+	publicIP, err := s.PublicIPsClient.Get(ctx, s.Scope.ResourceGroup(), bastionSpec.PublicIPName)
+	if err != nil {
+		return errors.Wrapf(err, "failed to get public ip %s", bastionSpec.PublicIPName)
+	}
+	klog.V(2).Infof("successfully got public ip %s", bastionSpec.PublicIPName)
+
+	klog.V(2).Infof("creating bastion host %s", bastionSpec.Name)
+	err = s.Client.CreateOrUpdate(
+		ctx,
+		s.Scope.ResourceGroup(),
+		bastionSpec.Name,
+		network.BastionHost{
+			Name:     to.StringPtr(bastionSpec.Name),
+			Location: to.StringPtr(s.Scope.Location()),
+			Tags: converters.TagsToMap(infrav1.Build(infrav1.BuildParams{
+				ClusterName: s.Scope.Name(),
+				Lifecycle:   infrav1.ResourceLifecycleOwned,
+				Name:        to.StringPtr(bastionSpec.Name),
+				Role:        to.StringPtr(infrav1.BastionRoleTagValue),
+				Additional:  s.Scope.AdditionalTags(),
+			})),
+			BastionHostPropertiesFormat: &network.BastionHostPropertiesFormat{
+				IPConfigurations: &[]network.BastionHostIPConfiguration{
+					{
+						Name: to.StringPtr("bastionIPConfig"),
+						BastionHostIPConfigurationPropertiesFormat: &network.BastionHostIPConfigurationPropertiesFormat{
+							Subnet:                    &network.SubResource{ID: subnet.ID},
+							PublicIPAddress:           &network.SubResource{ID: publicIP.ID},
+							PrivateIPAllocationMethod: network.Dynamic,
+						},
+					},
+				},
+			},
+		},
+	)
+	if err != nil {
+		return errors.Wrapf(err, "cannot create bastion host %s", bastionSpec.Name)
+	}
+
+	klog.V(2).Infof("successfully created bastion host %s", bastionSpec.Name)
+	return nil
+}
+
+// Delete deletes the Azure Bastion host with the provided name.
+func (s *Service) Delete(ctx context.Context, spec interface{}) error {
+	bastionSpec, ok := spec.(*Spec)
+	if !ok {
+		return errors.New("invalid bastion host specification")
+	}
+	klog.V(2).Infof("deleting bastion host %s", bastionSpec.Name)
+	err := s.Client.Delete(ctx, s.Scope.ResourceGroup(), bastionSpec.Name)
+	if err != nil && azure.ResourceNotFound(err) {
+		// already deleted
+		return nil
+	}
+	if err != nil {
+		return errors.Wrapf(err, "failed to delete bastion host %s in resource group %s", bastionSpec.Name, s.Scope.ResourceGroup())
+	}
+
+	klog.V(2).Infof("successfully deleted bastion host %s", bastionSpec.Name)
+	return nil
+}
@@ -0,0 +1,301 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bastionhosts
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/services/network/mgmt/2019-06-01/network"
+	"github.com/Azure/go-autorest/autorest"
+	"github.com/Azure/go-autorest/autorest/to"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1alpha3"
+	"sigs.k8s.io/cluster-api-provider-azure/cloud/scope"
+	"sigs.k8s.io/cluster-api-provider-azure/cloud/services/bastionhosts/mock_bastionhosts"
+	"sigs.k8s.io/cluster-api-provider-azure/cloud/services/publicips/mock_publicips"
+	"sigs.k8s.io/cluster-api-provider-azure/cloud/services/subnets/mock_subnets"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func init() {
+	clusterv1.AddToScheme(scheme.Scheme)
+}
+
+const expectedInvalidSpec = "invalid bastion host specification"
+
+func newTestClusterScope(t *testing.T) *scope.ClusterScope {
+	g := NewWithT(t)
+
+	cluster := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-cluster"},
+	}
+
+	client := fake.NewFakeClient(cluster)
+
+	clusterScope, err := scope.NewClusterScope(scope.ClusterScopeParams{
+		AzureClients: scope.AzureClients{
+			SubscriptionID: "123",
+			Authorizer:     autorest.NullAuthorizer{},
+		},
+		Client:  client,
+		Cluster: cluster,
+		AzureCluster: &infrav1.AzureCluster{
+			Spec: infrav1.AzureClusterSpec{
+				Location:      "test-location",
+				ResourceGroup: "my-rg",
+				NetworkSpec: infrav1.NetworkSpec{
+					Vnet: infrav1.VnetSpec{Name: "my-vnet", ResourceGroup: "my-vnet-rg"},
+				},
+			},
+		},
+	})
+	g.Expect(err).NotTo(HaveOccurred())
+	return clusterScope
+}
+
+func TestInvalidBastionHostSpec(t *testing.T) {
+	g := NewWithT(t)
+
+	mockCtrl := gomock.NewController(t)
+	bastionHostsMock := mock_bastionhosts.NewMockClient(mockCtrl)
+
+	s := &Service{
+		Scope:  newTestClusterScope(t),
+		Client: bastionHostsMock,
+	}
+
+	// Wrong Spec
+	wrongSpec := &network.LoadBalancer{}
+
+	err := s.Reconcile(context.TODO(), &wrongSpec)
+	g.Expect(err).To(MatchError(expectedInvalidSpec))
+
+	_, err = s.Get(context.TODO(), &wrongSpec)
+	g.Expect(err).To(MatchError(expectedInvalidSpec))
+
+	err = s.Delete(context.TODO(), &wrongSpec)
+	g.Expect(err).To(MatchError(expectedInvalidSpec))
+}
+
+func TestGetBastionHost(t *testing.T) {
+	testcases := []struct {
+		name          string
+		expectedError string
+		expected      *infrav1.VM
+		expect        func(m *mock_bastionhosts.MockClientMockRecorder, mPublicIP *mock_publicips.MockClientMockRecorder)
+	}{
+		{
+			name: "get existing bastion host with its addresses",
+			expected: &infrav1.VM{
+				ID:    "bastion-id",
+				Name:  "my-bastion",
+				State: infrav1.VMStateSucceeded,
+				Tags:  infrav1.Tags{},
+				Addresses: []corev1.NodeAddress{
+					{Type: corev1.NodeExternalDNS, Address: "bst-123.bastion.azure.com"},
+					{Type: corev1.NodeExternalIP, Address: "20.1.2.3"},
+				},
+			},
+			expect: func(m *mock_bastionhosts.MockClientMockRecorder, mPublicIP *mock_publicips.MockClientMockRecorder) {
+				m.Get(context.TODO(), "my-rg", "my-bastion").Return(network.BastionHost{
+					ID:   to.StringPtr("bastion-id"),
+					Name: to.StringPtr("my-bastion"),
+					BastionHostPropertiesFormat: &network.BastionHostPropertiesFormat{
+						DNSName:           to.StringPtr("bst-123.bastion.azure.com"),
+						ProvisioningState: network.Succeeded,
+					},
+				}, nil)
+				mPublicIP.Get(context.TODO(), "my-rg", "my-bastion-pip").Return(network.PublicIPAddress{
+					PublicIPAddressPropertiesFormat: &network.PublicIPAddressPropertiesFormat{
+						IPAddress: to.StringPtr("20.1.2.3"),
+					},
+				}, nil)
+			},
+		},
+		{
+			name:          "bastion host not found",
+			expectedError: "bastion host my-bastion not found: #: Not found: StatusCode=404",
+			expect: func(m *mock_bastionhosts.MockClientMockRecorder, mPublicIP *mock_publicips.MockClientMockRecorder) {
+				m.Get(context.TODO(), "my-rg", "my-bastion").Return(network.BastionHost{}, autorest.NewErrorWithResponse("", "", &http.Response{StatusCode: 404}, "Not found"))
+			},
+		},
+		{
+			name:          "public ip retrieval fails",
+			expectedError: "failed to get public ip my-bastion-pip of bastion host my-bastion: #: Internal Server Error: StatusCode=500",
+			expect: func(m *mock_bastionhosts.MockClientMockRecorder, mPublicIP *mock_publicips.MockClientMockRecorder) {
+				m.Get(context.TODO(), "my-rg", "my-bastion").Return(network.BastionHost{}, nil)
+				mPublicIP.Get(context.TODO(), "my-rg", "my-bastion-pip").Return(network.PublicIPAddress{}, autorest.NewErrorWithResponse("", "", &http.Response{StatusCode: 500}, "Internal Server Error"))
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			mockCtrl := gomock.NewController(t)
+			bastionHostsMock := mock_bastionhosts.NewMockClient(mockCtrl)
+			publicIPsMock := mock_publicips.NewMockClient(mockCtrl)
+
+			tc.expect(bastionHostsMock.EXPECT(), publicIPsMock.EXPECT())
+
+			s := &Service{
+				Scope:           newTestClusterScope(t),
+				Client:          bastionHostsMock,
+				PublicIPsClient: publicIPsMock,
+			}
+
+			bastion, err := s.Get(context.TODO(), &Spec{Name: "my-bastion", PublicIPName: "my-bastion-pip"})
+			if tc.expectedError != "" {
+				g.Expect(err).To(MatchError(tc.expectedError))
+			} else {
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(bastion).To(Equal(tc.expected))
+			}
+		})
+	}
+}
+
+func TestReconcileBastionHost(t *testing.T) {
+	testcases := []struct {
+		name          string
+		expectedError string
+		expect        func(g *WithT, m *mock_bastionhosts.MockClientMockRecorder, mSubnet *mock_subnets.MockClientMockRecorder, mPublicIP *mock_publicips.MockClientMockRecorder)
+	}{
+		{
+			name: "can create a bastion host",
+			expect: func(g *WithT, m *mock_bastionhosts.MockClientMockRecorder, mSubnet *mock_subnets.MockClientMockRecorder, mPublicIP *mock_publicips.MockClientMockRecorder) {
+				mSubnet.Get(context.TODO(), "my-vnet-rg", "my-vnet", "AzureBastionSubnet").Return(network.Subnet{ID: to.StringPtr("subnet-id")}, nil)
+				mPublicIP.Get(context.TODO(), "my-rg", "my-bastion-pip").Return(network.PublicIPAddress{ID: to.StringPtr("pip-id")}, nil)
+				m.CreateOrUpdate(context.TODO(), "my-rg", "my-bastion", gomock.AssignableToTypeOf(network.BastionHost{})).
+					Do(func(_ context.Context, _, _ string, bastionHost network.BastionHost) {
+						g.Expect(bastionHost.Location).To(Equal(to.StringPtr("test-location")))
+						g.Expect(*bastionHost.IPConfigurations).To(HaveLen(1))
+						ipConfig := (*bastionHost.IPConfigurations)[0]
+						g.Expect(ipConfig.Subnet.ID).To(Equal(to.StringPtr("subnet-id")))
+						g.Expect(ipConfig.PublicIPAddress.ID).To(Equal(to.StringPtr("pip-id")))
+					})
+			},
+		},
+		{
+			name:          "fail to get the subnet",
+			expectedError: "failed to get subnet AzureBastionSubnet: #: Not found: StatusCode=404",
+			expect: func(g *WithT, m *mock_bastionhosts.MockClientMockRecorder, mSubnet *mock_subnets.MockClientMockRecorder, mPublicIP *mock_publicips.MockClientMockRecorder) {
+				mSubnet.Get(context.TODO(), "my-vnet-rg", "my-vnet", "AzureBastionSubnet").Return(network.Subnet{}, autorest.NewErrorWithResponse("", "", &http.Response{StatusCode: 404}, "Not found"))
+			},
+		},
+		{
+			name:          "fail to create the bastion host",
+			expectedError: "cannot create bastion host my-bastion: #: Internal Server Error: StatusCode=500",
+			expect: func(g *WithT, m *mock_bastionhosts.MockClientMockRecorder, mSubnet *mock_subnets.MockClientMockRecorder, mPublicIP *mock_publicips.MockClientMockRecorder) {
+				mSubnet.Get(context.TODO(), "my-vnet-rg", "my-vnet", "AzureBastionSubnet").Return(network.Subnet{ID: to.StringPtr("subnet-id")}, nil)
+				mPublicIP.Get(context.TODO(), "my-rg", "my-bastion-pip").Return(network.PublicIPAddress{ID: to.StringPtr("pip-id")}, nil)
+				m.CreateOrUpdate(context.TODO(), "my-rg", "my-bastion", gomock.AssignableToTypeOf(network.BastionHost{})).Return(autorest.NewErrorWithResponse("", "", &http.Response{StatusCode: 500}, "Internal Server Error"))
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			mockCtrl := gomock.NewController(t)
+			bastionHostsMock := mock_bastionhosts.NewMockClient(mockCtrl)
+			subnetsMock := mock_subnets.NewMockClient(mockCtrl)
+			publicIPsMock := mock_publicips.NewMockClient(mockCtrl)
+
+			tc.expect(g, bastionHostsMock.EXPECT(), subnetsMock.EXPECT(), publicIPsMock.EXPECT())
+
+			s := &Service{
+				Scope:           newTestClusterScope(t),
+				Client:          bastionHostsMock,
+				SubnetsClient:   subnetsMock,
+				PublicIPsClient: publicIPsMock,
+			}
+
+			err := s.Reconcile(context.TODO(), &Spec{
+				Name:         "my-bastion",
+				SubnetName:   "AzureBastionSubnet",
+				PublicIPName: "my-bastion-pip",
+				VnetName:     "my-vnet",
+			})
+			if tc.expectedError != "" {
+				g.Expect(err).To(MatchError(tc.expectedError))
+			} else {
+				g.Expect(err).NotTo(HaveOccurred())
+			}
+		})
+	}
+}
+
+func TestDeleteBastionHost(t *testing.T) {
+	testcases := []struct {
+		name          string
+		expectedError string
+		expect        func(m *mock_bastionhosts.MockClientMockRecorder)
+	}{
+		{
+			name: "successfully delete an existing bastion host",
+			expect: func(m *mock_bastionhosts.MockClientMockRecorder) {
+				m.Delete(context.TODO(), "my-rg", "my-bastion")
+			},
+		},
+		{
+			name: "bastion host already deleted",
+			expect: func(m *mock_bastionhosts.MockClientMockRecorder) {
+				m.Delete(context.TODO(), "my-rg", "my-bastion").Return(autorest.NewErrorWithResponse("", "", &http.Response{StatusCode: 404}, "Not found"))
+			},
+		},
+		{
+			name:          "bastion host deletion fails",
+			expectedError: "failed to delete bastion host my-bastion in resource group my-rg: #: Internal Server Error: StatusCode=500",
+			expect: func(m *mock_bastionhosts.MockClientMockRecorder) {
+				m.Delete(context.TODO(), "my-rg", "my-bastion").Return(autorest.NewErrorWithResponse("", "", &http.Response{StatusCode: 500}, "Internal Server Error"))
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			mockCtrl := gomock.NewController(t)
+			bastionHostsMock := mock_bastionhosts.NewMockClient(mockCtrl)
+
+			tc.expect(bastionHostsMock.EXPECT())
+
+			s := &Service{
+				Scope:  newTestClusterScope(t),
+				Client: bastionHostsMock,
+			}
+
+			err := s.Delete(context.TODO(), &Spec{Name: "my-bastion"})
+			if tc.expectedError != "" {
+				g.Expect(err).To(MatchError(tc.expectedError))
+			} else {
+				g.Expect(err).NotTo(HaveOccurred())
+			}
+		})
+	}
+}
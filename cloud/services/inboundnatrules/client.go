@@ -39,14 +39,14 @@ type AzureClient struct {
 var _ Client = &AzureClient{}
 
 // NewClient creates a new inbound NAT rules client from subscription ID.
-func NewClient(subscriptionID string, authorizer autorest.Authorizer) *AzureClient {
-	c := newInboundNatRulesClient(subscriptionID, authorizer)
+func NewClient(subscriptionID, baseURI string, authorizer autorest.Authorizer) *AzureClient {
+	c := newInboundNatRulesClient(subscriptionID, baseURI, authorizer)
 	return &AzureClient{c}
 }
 
 // newLoadbalancersClient creates a new inbound NAT rules client from subscription ID.
-func newInboundNatRulesClient(subscriptionID string, authorizer autorest.Authorizer) network.InboundNatRulesClient {
-	inboundNatRulesClient := network.NewInboundNatRulesClientWithBaseURI(azure.DefaultBaseURI, subscriptionID)
+func newInboundNatRulesClient(subscriptionID, baseURI string, authorizer autorest.Authorizer) network.InboundNatRulesClient {
+	inboundNatRulesClient := network.NewInboundNatRulesClientWithBaseURI(baseURI, subscriptionID)
 	inboundNatRulesClient.Authorizer = authorizer
 	inboundNatRulesClient.AddToUserAgent(azure.UserAgent)
 	return inboundNatRulesClient
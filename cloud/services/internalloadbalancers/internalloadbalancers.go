@@ -148,6 +148,11 @@ func (s *Service) Reconcile(ctx context.Context, spec interface{}) error {
 		return errors.Wrap(err, "cannot create load balancer")
 	}
 
+	if subnet := s.Scope.ControlPlaneSubnet(); subnet != nil && subnet.Name == internalLBSpec.SubnetName && privateIP != "" {
+		// keep the frontend IP, which is the API server endpoint of private clusters
+		subnet.InternalLBIPAddress = privateIP
+	}
+
 	klog.V(2).Infof("successfully created internal load balancer %s", internalLBSpec.Name)
 	return err
 }
@@ -101,10 +101,11 @@ func TestReconcileInternalLoadBalancer(t *testing.T) {
 	g := NewWithT(t)
 
 	testcases := []struct {
-		name           string
-		internalLBSpec Spec
-		expectedError  string
-		expect         func(m *mock_internalloadbalancers.MockClientMockRecorder,
+		name              string
+		internalLBSpec    Spec
+		expectedError     string
+		expectedIPAddress string
+		expect            func(m *mock_internalloadbalancers.MockClientMockRecorder,
 			mVnet *mock_virtualnetworks.MockClientMockRecorder,
 			mSubnet *mock_subnets.MockClientMockRecorder)
 	}{
@@ -117,7 +118,8 @@ func TestReconcileInternalLoadBalancer(t *testing.T) {
 				VnetName:   "my-vnet",
 				IPAddress:  "10.0.0.10",
 			},
-			expectedError: "",
+			expectedError:     "",
+			expectedIPAddress: "10.0.0.10",
 			expect: func(m *mock_internalloadbalancers.MockClientMockRecorder,
 				mVnet *mock_virtualnetworks.MockClientMockRecorder,
 				mSubnet *mock_subnets.MockClientMockRecorder) {
@@ -168,6 +170,32 @@ func TestReconcileInternalLoadBalancer(t *testing.T) {
 				m.CreateOrUpdate(context.TODO(), "my-rg", "my-lb", gomock.AssignableToTypeOf(network.LoadBalancer{}))
 			},
 		},
+		{
+			name: "internal load balancer exists with a frontend IP",
+			internalLBSpec: Spec{
+				Name:       "my-lb",
+				SubnetCidr: "10.0.0.0/16",
+				SubnetName: "my-subnet",
+				VnetName:   "my-vnet",
+			},
+			expectedError:     "",
+			expectedIPAddress: "10.0.0.5",
+			expect: func(m *mock_internalloadbalancers.MockClientMockRecorder,
+				mVnet *mock_virtualnetworks.MockClientMockRecorder,
+				mSubnet *mock_subnets.MockClientMockRecorder) {
+				m.Get(context.TODO(), "my-rg", "my-lb").Return(network.LoadBalancer{
+					LoadBalancerPropertiesFormat: &network.LoadBalancerPropertiesFormat{
+						FrontendIPConfigurations: &[]network.FrontendIPConfiguration{
+							{
+								FrontendIPConfigurationPropertiesFormat: &network.FrontendIPConfigurationPropertiesFormat{
+									PrivateIPAddress: to.StringPtr("10.0.0.5"),
+								},
+							},
+						}}}, nil)
+				mSubnet.Get(context.TODO(), "my-rg", "my-vnet", "my-subnet").Return(network.Subnet{}, nil)
+				m.CreateOrUpdate(context.TODO(), "my-rg", "my-lb", gomock.AssignableToTypeOf(network.LoadBalancer{}))
+			},
+		},
 		{
 			name: "internal load balancer does not exist and IP is not available",
 			internalLBSpec: Spec{
@@ -256,6 +284,7 @@ func TestReconcileInternalLoadBalancer(t *testing.T) {
 				g.Expect(err).To(MatchError(tc.expectedError))
 			} else {
 				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(clusterScope.ControlPlaneSubnet().InternalLBIPAddress).To(Equal(tc.expectedIPAddress))
 			}
 		})
 	}
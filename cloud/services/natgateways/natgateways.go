@@ -0,0 +1,171 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package natgateways
+
+import (
+	"context"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/services/network/mgmt/2019-06-01/network"
+	"github.com/Azure/go-autorest/autorest/to"
+	"github.com/pkg/errors"
+	"k8s.io/klog"
+	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1alpha3"
+	azure "sigs.k8s.io/cluster-api-provider-azure/cloud"
+	"sigs.k8s.io/cluster-api-provider-azure/cloud/converters"
+)
+
+// Spec specification for a NAT gateway
+type Spec struct {
+	Name                 string
+	PublicIPName         string
+	IdleTimeoutInMinutes *int32
+	SubnetName           string
+	VnetName             string
+	// Role is the role of the subnet of the NAT gateway.
+	Role infrav1.SubnetRole
+}
+
+// Get provides information about a NAT gateway.
+func (s *Service) Get(ctx context.Context, spec interface{}) (interface{}, error) {
+	natGatewaySpec, ok := spec.(*Spec)
+	if !ok {
+		return network.NatGateway{}, errors.New("invalid NAT gateway specification")
+	}
+	natGateway, err := s.Client.Get(ctx, s.Scope.ResourceGroup(), natGatewaySpec.Name)
+	if err != nil && azure.ResourceNotFound(err) {
+		return nil, errors.Wrapf(err, "NAT gateway %s not found", natGatewaySpec.Name)
+	} else if err != nil {
+		return natGateway, err
+	}
+	return natGateway, nil
+}
+
+// Reconcile gets/creates/updates a NAT gateway and associates it with its subnet.
+func (s *Service) Reconcile(ctx context.Context, spec interface{}) error {
+	if !s.Scope.Vnet().IsManaged(s.Scope.Name()) {
+		s.Scope.V(4).Info("Skipping NAT gateway reconcile in custom vnet mode")
+		return nil
+	}
+	natGatewaySpec, ok := spec.(*Spec)
+	if !ok {
+		return errors.New("invalid NAT gateway specification")
+	}
+
+	klog.V(2).Infof("getting public ip %s", natGatewaySpec.PublicIPName)
+	publicIP, err := s.PublicIPsClient.Get(ctx, s.Scope.ResourceGroup(), natGatewaySpec.PublicIPName)
+	if err != nil {
+		return errors.Wrapf(err, "failed to get public ip %s", natGatewaySpec.PublicIPName)
+	}
+	klog.V(2).Infof("successfully got public ip %s", natGatewaySpec.PublicIPName)
+
+	klog.V(2).Infof("creating NAT gateway %s", natGatewaySpec.Name)
+	err = s.Client.CreateOrUpdate(
+		ctx,
+		s.Scope.ResourceGroup(),
+		natGatewaySpec.Name,
+		network.NatGateway{
+			Sku:      &network.NatGatewaySku{Name: network.Standard},
+			Name:     to.StringPtr(natGatewaySpec.Name),
+			Location: to.StringPtr(s.Scope.Location()),
+			Tags: converters.TagsToMap(infrav1.Build(infrav1.BuildParams{
+				ClusterName: s.Scope.Name(),
+				Lifecycle:   infrav1.ResourceLifecycleOwned,
+				Name:        to.StringPtr(natGatewaySpec.Name),
+				Role:        to.StringPtr(natGatewayRole(natGatewaySpec.Role)),
+				Additional:  s.Scope.AdditionalTags(),
+			})),
+			NatGatewayPropertiesFormat: &network.NatGatewayPropertiesFormat{
+				IdleTimeoutInMinutes: natGatewaySpec.IdleTimeoutInMinutes,
+				PublicIPAddresses:    &[]network.SubResource{{ID: publicIP.ID}},
+			},
+		},
+	)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create NAT gateway %s in resource group %s", natGatewaySpec.Name, s.Scope.ResourceGroup())
+	}
+	klog.V(2).Infof("successfully created NAT gateway %s", natGatewaySpec.Name)
+
+	natGateway, err := s.Client.Get(ctx, s.Scope.ResourceGroup(), natGatewaySpec.Name)
+	if err != nil {
+		return errors.Wrapf(err, "failed to get NAT gateway %s", natGatewaySpec.Name)
+	}
+	for _, subnet := range s.Scope.Subnets() {
+		if subnet != nil && subnet.Name == natGatewaySpec.SubnetName && subnet.NatGateway != nil {
+			subnet.NatGateway.ID = to.String(natGateway.ID)
+			subnet.NatGateway.Name = natGatewaySpec.Name
+		}
+	}
+
+	return s.associateSubnet(ctx, natGatewaySpec, natGateway)
+}
+
+// associateSubnet sets the NAT gateway of the subnet of the spec, unless it already uses it.
+func (s *Service) associateSubnet(ctx context.Context, natGatewaySpec *Spec, natGateway network.NatGateway) error {
+	subnet, err := s.SubnetsClient.Get(ctx, s.Scope.Vnet().ResourceGroup, natGatewaySpec.VnetName, natGatewaySpec.SubnetName)
+	if err != nil {
+		return errors.Wrapf(err, "failed to get subnet %s", natGatewaySpec.SubnetName)
+	}
+	if subnet.SubnetPropertiesFormat == nil {
+		subnet.SubnetPropertiesFormat = &network.SubnetPropertiesFormat{}
+	}
+	if subnet.NatGateway != nil && strings.EqualFold(to.String(subnet.NatGateway.ID), to.String(natGateway.ID)) {
+		return nil
+	}
+
+	klog.V(2).Infof("associating NAT gateway %s with subnet %s", natGatewaySpec.Name, natGatewaySpec.SubnetName)
+	subnet.NatGateway = &network.SubResource{ID: natGateway.ID}
+	err = s.SubnetsClient.CreateOrUpdate(ctx, s.Scope.Vnet().ResourceGroup, natGatewaySpec.VnetName, natGatewaySpec.SubnetName, subnet)
+	if err != nil {
+		return errors.Wrapf(err, "failed to associate NAT gateway %s with subnet %s", natGatewaySpec.Name, natGatewaySpec.SubnetName)
+	}
+
+	klog.V(2).Infof("successfully associated NAT gateway %s with subnet %s", natGatewaySpec.Name, natGatewaySpec.SubnetName)
+	return nil
+}
+
+// Delete deletes the NAT gateway with the provided name. Its subnet must be deleted or dissociated first.
+func (s *Service) Delete(ctx context.Context, spec interface{}) error {
+	if !s.Scope.Vnet().IsManaged(s.Scope.Name()) {
+		s.Scope.V(4).Info("Skipping NAT gateway deletion in custom vnet mode")
+		return nil
+	}
+	natGatewaySpec, ok := spec.(*Spec)
+	if !ok {
+		return errors.New("invalid NAT gateway specification")
+	}
+	klog.V(2).Infof("deleting NAT gateway %s", natGatewaySpec.Name)
+	err := s.Client.Delete(ctx, s.Scope.ResourceGroup(), natGatewaySpec.Name)
+	if err != nil && azure.ResourceNotFound(err) {
+		// already deleted
+		return nil
+	}
+	if err != nil {
+		return errors.Wrapf(err, "failed to delete NAT gateway %s in resource group %s", natGatewaySpec.Name, s.Scope.ResourceGroup())
+	}
+
+	klog.V(2).Infof("successfully deleted NAT gateway %s", natGatewaySpec.Name)
+	return nil
+}
+
+// natGatewayRole returns the role tag of the NAT gateway of a subnet with the given role.
+func natGatewayRole(subnetRole infrav1.SubnetRole) string {
+	if subnetRole == infrav1.SubnetControlPlane {
+		return infrav1.ControlPlane
+	}
+	return infrav1.Node
+}
@@ -0,0 +1,261 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package natgateways
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/services/network/mgmt/2019-06-01/network"
+	"github.com/Azure/go-autorest/autorest"
+	"github.com/Azure/go-autorest/autorest/to"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1alpha3"
+	"sigs.k8s.io/cluster-api-provider-azure/cloud/scope"
+	"sigs.k8s.io/cluster-api-provider-azure/cloud/services/natgateways/mock_natgateways"
+	"sigs.k8s.io/cluster-api-provider-azure/cloud/services/publicips/mock_publicips"
+	"sigs.k8s.io/cluster-api-provider-azure/cloud/services/subnets/mock_subnets"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func init() {
+	clusterv1.AddToScheme(scheme.Scheme)
+}
+
+const expectedInvalidSpec = "invalid NAT gateway specification"
+
+func newTestClusterScope(t *testing.T, vnetSpec infrav1.VnetSpec) *scope.ClusterScope {
+	g := NewWithT(t)
+
+	cluster := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-cluster"},
+	}
+
+	client := fake.NewFakeClient(cluster)
+
+	clusterScope, err := scope.NewClusterScope(scope.ClusterScopeParams{
+		AzureClients: scope.AzureClients{
+			SubscriptionID: "123",
+			Authorizer:     autorest.NullAuthorizer{},
+		},
+		Client:  client,
+		Cluster: cluster,
+		AzureCluster: &infrav1.AzureCluster{
+			Spec: infrav1.AzureClusterSpec{
+				Location:      "test-location",
+				ResourceGroup: "my-rg",
+				NetworkSpec: infrav1.NetworkSpec{
+					Vnet: vnetSpec,
+					Subnets: infrav1.Subnets{
+						{Name: "my-node-subnet", Role: infrav1.SubnetNode, NatGateway: &infrav1.NatGateway{}},
+					},
+				},
+			},
+		},
+	})
+	g.Expect(err).NotTo(HaveOccurred())
+	return clusterScope
+}
+
+func TestInvalidNatGatewaySpec(t *testing.T) {
+	g := NewWithT(t)
+
+	mockCtrl := gomock.NewController(t)
+	natGatewaysMock := mock_natgateways.NewMockClient(mockCtrl)
+
+	s := &Service{
+		Scope:  newTestClusterScope(t, infrav1.VnetSpec{Name: "my-vnet"}),
+		Client: natGatewaysMock,
+	}
+
+	// Wrong Spec
+	wrongSpec := &network.LoadBalancer{}
+
+	err := s.Reconcile(context.TODO(), &wrongSpec)
+	g.Expect(err).To(MatchError(expectedInvalidSpec))
+
+	_, err = s.Get(context.TODO(), &wrongSpec)
+	g.Expect(err).To(MatchError(expectedInvalidSpec))
+
+	err = s.Delete(context.TODO(), &wrongSpec)
+	g.Expect(err).To(MatchError(expectedInvalidSpec))
+}
+
+func TestReconcileNatGateway(t *testing.T) {
+	testcases := []struct {
+		name          string
+		vnetSpec      infrav1.VnetSpec
+		expectedError string
+		expectedID    string
+		expect        func(g *WithT, m *mock_natgateways.MockClientMockRecorder, mSubnet *mock_subnets.MockClientMockRecorder, mPublicIP *mock_publicips.MockClientMockRecorder)
+	}{
+		{
+			name:       "create a NAT gateway and associate it with the subnet",
+			vnetSpec:   infrav1.VnetSpec{Name: "my-vnet", ResourceGroup: "my-vnet-rg"},
+			expectedID: "natgw-id",
+			expect: func(g *WithT, m *mock_natgateways.MockClientMockRecorder, mSubnet *mock_subnets.MockClientMockRecorder, mPublicIP *mock_publicips.MockClientMockRecorder) {
+				mPublicIP.Get(context.TODO(), "my-rg", "my-natgw-pip").Return(network.PublicIPAddress{ID: to.StringPtr("pip-id")}, nil)
+				m.CreateOrUpdate(context.TODO(), "my-rg", "my-natgw", gomock.AssignableToTypeOf(network.NatGateway{})).
+					Do(func(_ context.Context, _, _ string, natGateway network.NatGateway) {
+						g.Expect(natGateway.Sku.Name).To(Equal(network.Standard))
+						g.Expect(natGateway.IdleTimeoutInMinutes).To(Equal(to.Int32Ptr(10)))
+						g.Expect(*natGateway.PublicIPAddresses).To(Equal([]network.SubResource{{ID: to.StringPtr("pip-id")}}))
+					})
+				m.Get(context.TODO(), "my-rg", "my-natgw").Return(network.NatGateway{ID: to.StringPtr("natgw-id")}, nil)
+				mSubnet.Get(context.TODO(), "my-vnet-rg", "my-vnet", "my-node-subnet").Return(network.Subnet{
+					Name:                   to.StringPtr("my-node-subnet"),
+					SubnetPropertiesFormat: &network.SubnetPropertiesFormat{AddressPrefix: to.StringPtr("10.1.0.0/16")},
+				}, nil)
+				mSubnet.CreateOrUpdate(context.TODO(), "my-vnet-rg", "my-vnet", "my-node-subnet", gomock.AssignableToTypeOf(network.Subnet{})).
+					Do(func(_ context.Context, _, _, _ string, subnet network.Subnet) {
+						g.Expect(subnet.NatGateway.ID).To(Equal(to.StringPtr("natgw-id")))
+						g.Expect(subnet.AddressPrefix).To(Equal(to.StringPtr("10.1.0.0/16")))
+					})
+			},
+		},
+		{
+			name:       "subnet already uses the NAT gateway",
+			vnetSpec:   infrav1.VnetSpec{Name: "my-vnet", ResourceGroup: "my-vnet-rg"},
+			expectedID: "natgw-id",
+			expect: func(g *WithT, m *mock_natgateways.MockClientMockRecorder, mSubnet *mock_subnets.MockClientMockRecorder, mPublicIP *mock_publicips.MockClientMockRecorder) {
+				mPublicIP.Get(context.TODO(), "my-rg", "my-natgw-pip").Return(network.PublicIPAddress{ID: to.StringPtr("pip-id")}, nil)
+				m.CreateOrUpdate(context.TODO(), "my-rg", "my-natgw", gomock.AssignableToTypeOf(network.NatGateway{}))
+				m.Get(context.TODO(), "my-rg", "my-natgw").Return(network.NatGateway{ID: to.StringPtr("natgw-id")}, nil)
+				mSubnet.Get(context.TODO(), "my-vnet-rg", "my-vnet", "my-node-subnet").Return(network.Subnet{
+					SubnetPropertiesFormat: &network.SubnetPropertiesFormat{
+						NatGateway: &network.SubResource{ID: to.StringPtr("NATGW-ID")},
+					},
+				}, nil)
+			},
+		},
+		{
+			name:     "skip NAT gateway reconcile in custom vnet mode",
+			vnetSpec: infrav1.VnetSpec{Name: "custom-vnet", ResourceGroup: "custom-vnet-rg", ID: "id1"},
+			expect: func(g *WithT, m *mock_natgateways.MockClientMockRecorder, mSubnet *mock_subnets.MockClientMockRecorder, mPublicIP *mock_publicips.MockClientMockRecorder) {
+			},
+		},
+		{
+			name:          "fail to create the NAT gateway",
+			vnetSpec:      infrav1.VnetSpec{Name: "my-vnet", ResourceGroup: "my-vnet-rg"},
+			expectedError: "failed to create NAT gateway my-natgw in resource group my-rg: #: Internal Server Error: StatusCode=500",
+			expect: func(g *WithT, m *mock_natgateways.MockClientMockRecorder, mSubnet *mock_subnets.MockClientMockRecorder, mPublicIP *mock_publicips.MockClientMockRecorder) {
+				mPublicIP.Get(context.TODO(), "my-rg", "my-natgw-pip").Return(network.PublicIPAddress{ID: to.StringPtr("pip-id")}, nil)
+				m.CreateOrUpdate(context.TODO(), "my-rg", "my-natgw", gomock.AssignableToTypeOf(network.NatGateway{})).Return(autorest.NewErrorWithResponse("", "", &http.Response{StatusCode: 500}, "Internal Server Error"))
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			mockCtrl := gomock.NewController(t)
+			natGatewaysMock := mock_natgateways.NewMockClient(mockCtrl)
+			subnetsMock := mock_subnets.NewMockClient(mockCtrl)
+			publicIPsMock := mock_publicips.NewMockClient(mockCtrl)
+
+			tc.expect(g, natGatewaysMock.EXPECT(), subnetsMock.EXPECT(), publicIPsMock.EXPECT())
+
+			s := &Service{
+				Scope:           newTestClusterScope(t, tc.vnetSpec),
+				Client:          natGatewaysMock,
+				SubnetsClient:   subnetsMock,
+				PublicIPsClient: publicIPsMock,
+			}
+
+			err := s.Reconcile(context.TODO(), &Spec{
+				Name:                 "my-natgw",
+				PublicIPName:         "my-natgw-pip",
+				IdleTimeoutInMinutes: to.Int32Ptr(10),
+				SubnetName:           "my-node-subnet",
+				VnetName:             tc.vnetSpec.Name,
+			})
+			if tc.expectedError != "" {
+				g.Expect(err).To(MatchError(tc.expectedError))
+			} else {
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(s.Scope.NodeSubnet().NatGateway.ID).To(Equal(tc.expectedID))
+			}
+		})
+	}
+}
+
+func TestDeleteNatGateway(t *testing.T) {
+	testcases := []struct {
+		name          string
+		vnetSpec      infrav1.VnetSpec
+		expectedError string
+		expect        func(m *mock_natgateways.MockClientMockRecorder)
+	}{
+		{
+			name:     "successfully delete an existing NAT gateway",
+			vnetSpec: infrav1.VnetSpec{Name: "my-vnet"},
+			expect: func(m *mock_natgateways.MockClientMockRecorder) {
+				m.Delete(context.TODO(), "my-rg", "my-natgw")
+			},
+		},
+		{
+			name:     "NAT gateway already deleted",
+			vnetSpec: infrav1.VnetSpec{Name: "my-vnet"},
+			expect: func(m *mock_natgateways.MockClientMockRecorder) {
+				m.Delete(context.TODO(), "my-rg", "my-natgw").Return(autorest.NewErrorWithResponse("", "", &http.Response{StatusCode: 404}, "Not found"))
+			},
+		},
+		{
+			name:          "NAT gateway deletion fails",
+			vnetSpec:      infrav1.VnetSpec{Name: "my-vnet"},
+			expectedError: "failed to delete NAT gateway my-natgw in resource group my-rg: #: Internal Server Error: StatusCode=500",
+			expect: func(m *mock_natgateways.MockClientMockRecorder) {
+				m.Delete(context.TODO(), "my-rg", "my-natgw").Return(autorest.NewErrorWithResponse("", "", &http.Response{StatusCode: 500}, "Internal Server Error"))
+			},
+		},
+		{
+			name:     "skip NAT gateway deletion in custom vnet mode",
+			vnetSpec: infrav1.VnetSpec{Name: "custom-vnet", ResourceGroup: "custom-vnet-rg", ID: "id1"},
+			expect: func(m *mock_natgateways.MockClientMockRecorder) {
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			mockCtrl := gomock.NewController(t)
+			natGatewaysMock := mock_natgateways.NewMockClient(mockCtrl)
+
+			tc.expect(natGatewaysMock.EXPECT())
+
+			s := &Service{
+				Scope:  newTestClusterScope(t, tc.vnetSpec),
+				Client: natGatewaysMock,
+			}
+
+			err := s.Delete(context.TODO(), &Spec{Name: "my-natgw"})
+			if tc.expectedError != "" {
+				g.Expect(err).To(MatchError(tc.expectedError))
+			} else {
+				g.Expect(err).NotTo(HaveOccurred())
+			}
+		})
+	}
+}
@@ -39,14 +39,14 @@ type AzureClient struct {
 var _ Client = &AzureClient{}
 
 // NewClient creates a new VM client from subscription ID.
-func NewClient(subscriptionID string, authorizer autorest.Authorizer) *AzureClient {
-	c := newInterfacesClient(subscriptionID, authorizer)
+func NewClient(subscriptionID, baseURI string, authorizer autorest.Authorizer) *AzureClient {
+	c := newInterfacesClient(subscriptionID, baseURI, authorizer)
 	return &AzureClient{c}
 }
 
 // newInterfacesClient creates a new network interfaces client from subscription ID.
-func newInterfacesClient(subscriptionID string, authorizer autorest.Authorizer) network.InterfacesClient {
-	nicClient := network.NewInterfacesClientWithBaseURI(azure.DefaultBaseURI, subscriptionID)
+func newInterfacesClient(subscriptionID, baseURI string, authorizer autorest.Authorizer) network.InterfacesClient {
+	nicClient := network.NewInterfacesClientWithBaseURI(baseURI, subscriptionID)
 	nicClient.Authorizer = authorizer
 	nicClient.AddToUserAgent(azure.UserAgent)
 	return nicClient
@@ -19,6 +19,7 @@ package networkinterfaces
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/Azure/azure-sdk-for-go/services/network/mgmt/2019-06-01/network"
 	"github.com/Azure/go-autorest/autorest/to"
@@ -36,6 +37,23 @@ type Spec struct {
 	PublicLoadBalancerName   string
 	InternalLoadBalancerName string
 	PublicIPName             string
+	AcceleratedNetworking    *bool
+	// IPv6Enabled adds an IPv6 ip configuration to the network interface.
+	IPv6Enabled bool
+	// NodeOutboundLBName is the name of the node outbound load balancer whose backend pool the network interface joins.
+	NodeOutboundLBName string
+	// SSHNATRule adds an inbound NAT rule for SSH to the public load balancer. The rule is removed when unset.
+	SSHNATRule bool
+}
+
+// SSHNATRuleSpec specifies the SSH inbound NAT rule of an existing network interface.
+type SSHNATRuleSpec struct {
+	// NICName is the name of the network interface.
+	NICName string
+	// PublicLoadBalancerName is the name of the public load balancer of the NAT rule.
+	PublicLoadBalancerName string
+	// Enabled adds the NAT rule to the primary ip configuration of the network interface, or removes it when unset.
+	Enabled bool
 }
 
 // Get provides information about a network interface.
@@ -52,8 +70,11 @@ func (s *Service) Get(ctx context.Context, spec interface{}) (interface{}, error
 	return nic, err
 }
 
-// Reconcile gets/creates/updates a network interface.
+// Reconcile gets/creates/updates a network interface, or the SSH NAT rule of an existing one.
 func (s *Service) Reconcile(ctx context.Context, spec interface{}) error {
+	if natRuleSpec, ok := spec.(*SSHNATRuleSpec); ok {
+		return s.reconcileSSHNATRule(ctx, natRuleSpec)
+	}
 	nicSpec, ok := spec.(*Spec)
 	if !ok {
 		return errors.New("invalid network interface specification")
@@ -73,7 +94,14 @@ func (s *Service) Reconcile(ctx context.Context, spec interface{}) error {
 		nicConfig.PrivateIPAddress = to.StringPtr(nicSpec.StaticIPAddress)
 	}
 
+	ipv6Config := &network.InterfaceIPConfigurationPropertiesFormat{
+		Subnet:                    nicConfig.Subnet,
+		PrivateIPAllocationMethod: network.Dynamic,
+		PrivateIPAddressVersion:   network.IPv6,
+	}
+
 	backendAddressPools := []network.BackendAddressPool{}
+	var staleNATRuleName string
 	if nicSpec.PublicLoadBalancerName != "" {
 		// only control planes have an attached public LB
 		lb, lberr := s.PublicLoadBalancersClient.Get(ctx, s.Scope.ResourceGroup(), nicSpec.PublicLoadBalancerName)
@@ -85,17 +113,30 @@ func (s *Service) Reconcile(ctx context.Context, spec interface{}) error {
 			network.BackendAddressPool{
 				ID: (*lb.BackendAddressPools)[0].ID,
 			})
+		if nicSpec.IPv6Enabled && len(*lb.BackendAddressPools) > 1 {
+			// dual-stack load balancers have a separate IPv6 backend pool
+			ipv6Config.LoadBalancerBackendAddressPools = &[]network.BackendAddressPool{
+				{
+					ID: (*lb.BackendAddressPools)[1].ID,
+				},
+			}
+		}
 
 		ruleName := s.MachineScope.Name()
-		naterr := s.createInboundNatRule(ctx, lb, ruleName)
-		if naterr != nil {
-			return errors.Wrap(naterr, "failed to create NAT rule")
-		}
+		if nicSpec.SSHNATRule {
+			naterr := s.createInboundNatRule(ctx, lb, ruleName)
+			if naterr != nil {
+				return errors.Wrap(naterr, "failed to create NAT rule")
+			}
 
-		nicConfig.LoadBalancerInboundNatRules = &[]network.InboundNatRule{
-			{
-				ID: to.StringPtr(fmt.Sprintf("%s/inboundNatRules/%s", to.String(lb.ID), ruleName)),
-			},
+			nicConfig.LoadBalancerInboundNatRules = &[]network.InboundNatRule{
+				{
+					ID: to.StringPtr(fmt.Sprintf("%s/inboundNatRules/%s", to.String(lb.ID), ruleName)),
+				},
+			}
+		} else if hasInboundNatRule(lb, ruleName) {
+			// the rule can only be deleted once the network interface no longer references it
+			staleNATRuleName = ruleName
 		}
 	}
 	if nicSpec.InternalLoadBalancerName != "" {
@@ -110,6 +151,31 @@ func (s *Service) Reconcile(ctx context.Context, spec interface{}) error {
 				ID: (*internalLB.BackendAddressPools)[0].ID,
 			})
 	}
+	if nicSpec.NodeOutboundLBName != "" {
+		// only worker machines get outbound access through the node outbound LB
+		lb, lberr := s.PublicLoadBalancersClient.Get(ctx, s.Scope.ResourceGroup(), nicSpec.NodeOutboundLBName)
+		if lberr != nil {
+			return errors.Wrap(lberr, "failed to get node outbound LB")
+		}
+
+		// the cloud provider shares the load balancer, join the pool the outbound rule uses
+		poolName := azure.GenerateNodeOutboundBackendPoolName(nicSpec.NodeOutboundLBName)
+		var poolID *string
+		if lb.BackendAddressPools != nil {
+			for _, pool := range *lb.BackendAddressPools {
+				if to.String(pool.Name) == poolName {
+					poolID = pool.ID
+				}
+			}
+		}
+		if poolID == nil {
+			return errors.Errorf("node outbound LB %s has no backend pool %s", nicSpec.NodeOutboundLBName, poolName)
+		}
+		backendAddressPools = append(backendAddressPools,
+			network.BackendAddressPool{
+				ID: poolID,
+			})
+	}
 	nicConfig.LoadBalancerBackendAddressPools = &backendAddressPools
 
 	if nicSpec.PublicIPName != "" {
@@ -120,18 +186,29 @@ func (s *Service) Reconcile(ctx context.Context, spec interface{}) error {
 		nicConfig.PublicIPAddress = &publicIP
 	}
 
+	ipConfigurations := []network.InterfaceIPConfiguration{
+		{
+			Name:                                     to.StringPtr("pipConfig"),
+			InterfaceIPConfigurationPropertiesFormat: nicConfig,
+		},
+	}
+	if nicSpec.IPv6Enabled {
+		// the IPv4 ip configuration must be primary when there are several
+		nicConfig.Primary = to.BoolPtr(true)
+		ipConfigurations = append(ipConfigurations, network.InterfaceIPConfiguration{
+			Name:                                     to.StringPtr("ipConfigv6"),
+			InterfaceIPConfigurationPropertiesFormat: ipv6Config,
+		})
+	}
+
 	err = s.Client.CreateOrUpdate(ctx,
 		s.Scope.ResourceGroup(),
 		nicSpec.Name,
 		network.Interface{
 			Location: to.StringPtr(s.Scope.Location()),
 			InterfacePropertiesFormat: &network.InterfacePropertiesFormat{
-				IPConfigurations: &[]network.InterfaceIPConfiguration{
-					{
-						Name:                                     to.StringPtr("pipConfig"),
-						InterfaceIPConfigurationPropertiesFormat: nicConfig,
-					},
-				},
+				EnableAcceleratedNetworking: nicSpec.AcceleratedNetworking,
+				IPConfigurations:            &ipConfigurations,
 			},
 		})
 
@@ -140,9 +217,99 @@ func (s *Service) Reconcile(ctx context.Context, spec interface{}) error {
 	}
 
 	klog.V(2).Infof("successfully created network interface %s", nicSpec.Name)
+
+	if staleNATRuleName != "" {
+		klog.V(2).Infof("deleting NAT rule %s", staleNATRuleName)
+		err = s.InboundNATRulesClient.Delete(ctx, s.Scope.ResourceGroup(), nicSpec.PublicLoadBalancerName, staleNATRuleName)
+		if err != nil && !azure.ResourceNotFound(err) {
+			return errors.Wrapf(err, "failed to delete inbound NAT rule %s in load balancer %s", staleNATRuleName, nicSpec.PublicLoadBalancerName)
+		}
+		klog.V(2).Infof("successfully deleted NAT rule %s", staleNATRuleName)
+	}
 	return nil
 }
 
+// reconcileSSHNATRule adds or removes the SSH NAT rule of the machine on the primary ip configuration of its existing
+// network interface. Only the NAT rule reference changes, the rest of the network interface is sent back as it is.
+func (s *Service) reconcileSSHNATRule(ctx context.Context, natRuleSpec *SSHNATRuleSpec) error {
+	nic, err := s.Client.Get(ctx, s.Scope.ResourceGroup(), natRuleSpec.NICName)
+	if err != nil {
+		return errors.Wrapf(err, "failed to get network interface %s", natRuleSpec.NICName)
+	}
+	ipConfig := primaryIPConfiguration(nic)
+	if ipConfig == nil {
+		return errors.Errorf("network interface %s has no ip configuration", natRuleSpec.NICName)
+	}
+
+	lb, err := s.PublicLoadBalancersClient.Get(ctx, s.Scope.ResourceGroup(), natRuleSpec.PublicLoadBalancerName)
+	if err != nil {
+		return errors.Wrap(err, "failed to get publicLB")
+	}
+
+	ruleName := s.MachineScope.Name()
+	ruleID := fmt.Sprintf("%s/inboundNatRules/%s", to.String(lb.ID), ruleName)
+	var natRules []network.InboundNatRule
+	if ipConfig.LoadBalancerInboundNatRules != nil {
+		natRules = *ipConfig.LoadBalancerInboundNatRules
+	}
+	referenced := false
+	otherNATRules := []network.InboundNatRule{}
+	for _, natRule := range natRules {
+		if strings.EqualFold(to.String(natRule.ID), ruleID) {
+			referenced = true
+		} else {
+			otherNATRules = append(otherNATRules, natRule)
+		}
+	}
+
+	if natRuleSpec.Enabled {
+		if err := s.createInboundNatRule(ctx, lb, ruleName); err != nil {
+			return errors.Wrap(err, "failed to create NAT rule")
+		}
+		if referenced {
+			return nil
+		}
+		natRules = append(natRules, network.InboundNatRule{ID: to.StringPtr(ruleID)})
+		ipConfig.LoadBalancerInboundNatRules = &natRules
+	} else if referenced {
+		ipConfig.LoadBalancerInboundNatRules = &otherNATRules
+	}
+
+	if natRuleSpec.Enabled || referenced {
+		klog.V(2).Infof("updating NAT rule %s of network interface %s", ruleName, natRuleSpec.NICName)
+		if err := s.Client.CreateOrUpdate(ctx, s.Scope.ResourceGroup(), natRuleSpec.NICName, nic); err != nil {
+			return errors.Wrapf(err, "failed to update network interface %s in resource group %s", natRuleSpec.NICName, s.Scope.ResourceGroup())
+		}
+		klog.V(2).Infof("successfully updated NAT rule %s of network interface %s", ruleName, natRuleSpec.NICName)
+	}
+
+	if !natRuleSpec.Enabled && hasInboundNatRule(lb, ruleName) {
+		// the rule can only be deleted once the network interface no longer references it
+		klog.V(2).Infof("deleting NAT rule %s", ruleName)
+		err = s.InboundNATRulesClient.Delete(ctx, s.Scope.ResourceGroup(), natRuleSpec.PublicLoadBalancerName, ruleName)
+		if err != nil && !azure.ResourceNotFound(err) {
+			return errors.Wrapf(err, "failed to delete inbound NAT rule %s in load balancer %s", ruleName, natRuleSpec.PublicLoadBalancerName)
+		}
+		klog.V(2).Infof("successfully deleted NAT rule %s", ruleName)
+	}
+	return nil
+}
+
+// primaryIPConfiguration returns the primary ip configuration of a network interface, which is the first one
+// unless another is marked as primary.
+func primaryIPConfiguration(nic network.Interface) *network.InterfaceIPConfigurationPropertiesFormat {
+	if nic.InterfacePropertiesFormat == nil || nic.IPConfigurations == nil || len(*nic.IPConfigurations) == 0 {
+		return nil
+	}
+	ipConfigs := *nic.IPConfigurations
+	for _, ipConfig := range ipConfigs {
+		if ipConfig.InterfaceIPConfigurationPropertiesFormat != nil && to.Bool(ipConfig.Primary) {
+			return ipConfig.InterfaceIPConfigurationPropertiesFormat
+		}
+	}
+	return ipConfigs[0].InterfaceIPConfigurationPropertiesFormat
+}
+
 // Delete deletes the network interface with the provided name.
 func (s *Service) Delete(ctx context.Context, spec interface{}) error {
 	nicSpec, ok := spec.(*Spec)
@@ -154,6 +321,11 @@ func (s *Service) Delete(ctx context.Context, spec interface{}) error {
 	if err != nil && !azure.ResourceNotFound(err) {
 		return errors.Wrapf(err, "failed to delete network interface %s in resource group %s", nicSpec.Name, s.Scope.ResourceGroup())
 	}
+	if nicSpec.PublicLoadBalancerName == "" {
+		// only network interfaces attached to a public LB have a NAT rule
+		klog.V(2).Infof("successfully deleted nic %s", nicSpec.Name)
+		return nil
+	}
 	NATRuleName := s.MachineScope.Name()
 	err = s.InboundNATRulesClient.Delete(ctx, s.Scope.ResourceGroup(), nicSpec.PublicLoadBalancerName, NATRuleName)
 	if err != nil && !azure.ResourceNotFound(err) {
@@ -177,10 +349,18 @@ func (s *Service) createInboundNatRule(ctx context.Context, lb network.LoadBalan
 		}
 		ports[*v.InboundNatRulePropertiesFormat.FrontendPort] = struct{}{}
 	}
+	if lb.LoadBalancingRules != nil {
+		// the frontend ports of the load balancing rules aren't available either
+		for _, v := range *lb.LoadBalancingRules {
+			if v.LoadBalancingRulePropertiesFormat != nil && v.FrontendPort != nil {
+				ports[*v.FrontendPort] = struct{}{}
+			}
+		}
+	}
 	if _, ok := ports[22]; ok {
 		var i int32
 		found := false
-		for i = 2201; i < 2220; i++ {
+		for i = 2201; i <= 65534; i++ {
 			if _, ok := ports[i]; !ok {
 				sshFrontendPort = i
 				found = true
@@ -207,3 +387,16 @@ func (s *Service) createInboundNatRule(ctx context.Context, lb network.LoadBalan
 	klog.V(3).Infof("Creating rule %s using port %d", ruleName, sshFrontendPort)
 	return s.InboundNATRulesClient.CreateOrUpdate(ctx, s.Scope.ResourceGroup(), to.String(lb.Name), ruleName, rule)
 }
+
+// hasInboundNatRule returns whether the load balancer has an inbound NAT rule with the given name.
+func hasInboundNatRule(lb network.LoadBalancer, ruleName string) bool {
+	if lb.LoadBalancerPropertiesFormat == nil || lb.InboundNatRules == nil {
+		return false
+	}
+	for _, v := range *lb.InboundNatRules {
+		if to.String(v.Name) == ruleName {
+			return true
+		}
+	}
+	return false
+}
@@ -18,6 +18,7 @@ package networkinterfaces
 
 import (
 	"context"
+	"fmt"
 	"net/http"
 	"testing"
 
@@ -313,6 +314,83 @@ func TestReconcileNetworkInterface(t *testing.T) {
 					m.CreateOrUpdate(context.TODO(), "my-rg", "my-net-interface", gomock.AssignableToTypeOf(network.Interface{})))
 			},
 		},
+		{
+			name: "node network interface with accelerated networking successfully created",
+			netInterfaceSpec: Spec{
+				Name:                  "my-net-interface",
+				VnetName:              "my-vnet",
+				SubnetName:            "my-subnet",
+				AcceleratedNetworking: to.BoolPtr(true),
+			},
+			expectedError: "",
+			expect: func(m *mock_networkinterfaces.MockClientMockRecorder,
+				mSubnet *mock_subnets.MockClientMockRecorder,
+				mPublicLoadBalancer *mock_publicloadbalancers.MockClientMockRecorder,
+				mInboundNATRules *mock_inboundnatrules.MockClientMockRecorder,
+				mInternalLoadBalancer *mock_internalloadbalancers.MockClientMockRecorder,
+				mPublicIP *mock_publicips.MockClientMockRecorder) {
+				gomock.InOrder(
+					mSubnet.Get(context.TODO(), "my-rg", "my-vnet", "my-subnet").Return(network.Subnet{}, nil),
+					m.CreateOrUpdate(context.TODO(), "my-rg", "my-net-interface", gomock.AssignableToTypeOf(network.Interface{})).
+						Do(func(_ context.Context, _, _ string, nic network.Interface) {
+							g.Expect(nic.EnableAcceleratedNetworking).To(Equal(to.BoolPtr(true)))
+						}))
+			},
+		},
+		{
+			name: "node network interface in the node outbound LB successfully created",
+			netInterfaceSpec: Spec{
+				Name:               "my-net-interface",
+				VnetName:           "my-vnet",
+				SubnetName:         "my-subnet",
+				NodeOutboundLBName: "my-node-outbound-lb",
+			},
+			expectedError: "",
+			expect: func(m *mock_networkinterfaces.MockClientMockRecorder,
+				mSubnet *mock_subnets.MockClientMockRecorder,
+				mPublicLoadBalancer *mock_publicloadbalancers.MockClientMockRecorder,
+				mInboundNATRules *mock_inboundnatrules.MockClientMockRecorder,
+				mInternalLoadBalancer *mock_internalloadbalancers.MockClientMockRecorder,
+				mPublicIP *mock_publicips.MockClientMockRecorder) {
+				gomock.InOrder(
+					mSubnet.Get(context.TODO(), "my-rg", "my-vnet", "my-subnet").Return(network.Subnet{}, nil),
+					mPublicLoadBalancer.Get(context.TODO(), "my-rg", "my-node-outbound-lb").Return(network.LoadBalancer{
+						LoadBalancerPropertiesFormat: &network.LoadBalancerPropertiesFormat{
+							BackendAddressPools: &[]network.BackendAddressPool{
+								{Name: to.StringPtr("my-cluster-service-pool"), ID: to.StringPtr("service-pool-id")},
+								{Name: to.StringPtr("my-node-outbound-lb"), ID: to.StringPtr("node-outbound-pool-id")},
+							},
+						},
+					}, nil),
+					m.CreateOrUpdate(context.TODO(), "my-rg", "my-net-interface", gomock.AssignableToTypeOf(network.Interface{})).
+						Do(func(_ context.Context, _, _ string, nic network.Interface) {
+							ipConfig := (*nic.IPConfigurations)[0]
+							g.Expect(*ipConfig.LoadBalancerBackendAddressPools).To(Equal([]network.BackendAddressPool{{ID: to.StringPtr("node-outbound-pool-id")}}))
+							g.Expect(ipConfig.LoadBalancerInboundNatRules).To(BeNil())
+						}))
+			},
+		},
+		{
+			name: "node network interface fail to get node outbound LB",
+			netInterfaceSpec: Spec{
+				Name:               "my-net-interface",
+				VnetName:           "my-vnet",
+				SubnetName:         "my-subnet",
+				NodeOutboundLBName: "my-node-outbound-lb",
+			},
+			expectedError: "failed to get node outbound LB: #: Internal Server Error: StatusCode=500",
+			expect: func(m *mock_networkinterfaces.MockClientMockRecorder,
+				mSubnet *mock_subnets.MockClientMockRecorder,
+				mPublicLoadBalancer *mock_publicloadbalancers.MockClientMockRecorder,
+				mInboundNATRules *mock_inboundnatrules.MockClientMockRecorder,
+				mInternalLoadBalancer *mock_internalloadbalancers.MockClientMockRecorder,
+				mPublicIP *mock_publicips.MockClientMockRecorder) {
+				gomock.InOrder(
+					mSubnet.Get(context.TODO(), "my-rg", "my-vnet", "my-subnet").Return(network.Subnet{}, nil),
+					mPublicLoadBalancer.Get(context.TODO(), "my-rg", "my-node-outbound-lb").
+						Return(network.LoadBalancer{}, autorest.NewErrorWithResponse("", "", &http.Response{StatusCode: 500}, "Internal Server Error")))
+			},
+		},
 		{
 			name: "control plane network interface successfully created",
 			netInterfaceSpec: Spec{
@@ -320,6 +398,7 @@ func TestReconcileNetworkInterface(t *testing.T) {
 				VnetName:                 "my-vnet",
 				SubnetName:               "my-subnet",
 				PublicLoadBalancerName:   "my-publiclb",
+				SSHNATRule:               true,
 				InternalLoadBalancerName: "my-internal-lb",
 			},
 			expectedError: "",
@@ -389,6 +468,74 @@ func TestReconcileNetworkInterface(t *testing.T) {
 					}))
 			},
 		},
+		{
+			name: "dual-stack control plane network interface successfully created",
+			netInterfaceSpec: Spec{
+				Name:                   "my-net-interface",
+				VnetName:               "my-vnet",
+				SubnetName:             "my-subnet",
+				PublicLoadBalancerName: "my-publiclb",
+				SSHNATRule:             true,
+				IPv6Enabled:            true,
+			},
+			expectedError: "",
+			expect: func(m *mock_networkinterfaces.MockClientMockRecorder,
+				mSubnet *mock_subnets.MockClientMockRecorder,
+				mPublicLoadBalancer *mock_publicloadbalancers.MockClientMockRecorder,
+				mInboundNATRules *mock_inboundnatrules.MockClientMockRecorder,
+				mInternalLoadBalancer *mock_internalloadbalancers.MockClientMockRecorder,
+				mPublicIP *mock_publicips.MockClientMockRecorder) {
+				gomock.InOrder(
+					mSubnet.Get(context.TODO(), "my-rg", "my-vnet", "my-subnet").
+						Return(network.Subnet{ID: to.StringPtr("my-subnet-id")}, nil),
+					mPublicLoadBalancer.Get(context.TODO(), "my-rg", "my-publiclb").Return(network.LoadBalancer{
+						Name: to.StringPtr("my-publiclb"),
+						ID:   pointer.StringPtr("my-publiclb-id"),
+						LoadBalancerPropertiesFormat: &network.LoadBalancerPropertiesFormat{
+							FrontendIPConfigurations: &[]network.FrontendIPConfiguration{
+								{
+									ID: to.StringPtr("frontend-ip-config-id"),
+								},
+							},
+							BackendAddressPools: &[]network.BackendAddressPool{
+								{
+									ID: pointer.StringPtr("my-backend-pool-id"),
+								},
+								{
+									ID: pointer.StringPtr("my-backend-pool-ipv6-id"),
+								},
+							},
+							InboundNatRules: &[]network.InboundNatRule{},
+						}}, nil),
+					mInboundNATRules.CreateOrUpdate(context.TODO(), "my-rg", "my-publiclb", "azure-test1", gomock.AssignableToTypeOf(network.InboundNatRule{})),
+					m.CreateOrUpdate(context.TODO(), "my-rg", "my-net-interface", network.Interface{
+						Location: to.StringPtr("test-location"),
+						InterfacePropertiesFormat: &network.InterfacePropertiesFormat{
+							IPConfigurations: &[]network.InterfaceIPConfiguration{
+								{
+									Name: to.StringPtr("pipConfig"),
+									InterfaceIPConfigurationPropertiesFormat: &network.InterfaceIPConfigurationPropertiesFormat{
+										Subnet:                          &network.Subnet{ID: to.StringPtr("my-subnet-id")},
+										PrivateIPAllocationMethod:       network.Dynamic,
+										Primary:                         to.BoolPtr(true),
+										LoadBalancerInboundNatRules:     &[]network.InboundNatRule{{ID: to.StringPtr("my-publiclb-id/inboundNatRules/azure-test1")}},
+										LoadBalancerBackendAddressPools: &[]network.BackendAddressPool{{ID: to.StringPtr("my-backend-pool-id")}},
+									},
+								},
+								{
+									Name: to.StringPtr("ipConfigv6"),
+									InterfaceIPConfigurationPropertiesFormat: &network.InterfaceIPConfigurationPropertiesFormat{
+										Subnet:                          &network.Subnet{ID: to.StringPtr("my-subnet-id")},
+										PrivateIPAllocationMethod:       network.Dynamic,
+										PrivateIPAddressVersion:         network.IPv6,
+										LoadBalancerBackendAddressPools: &[]network.BackendAddressPool{{ID: to.StringPtr("my-backend-pool-ipv6-id")}},
+									},
+								},
+							},
+						},
+					}))
+			},
+		},
 		{
 			name: "control plane network interface fail to get public LB",
 			netInterfaceSpec: Spec{
@@ -418,6 +565,7 @@ func TestReconcileNetworkInterface(t *testing.T) {
 				VnetName:                 "my-vnet",
 				SubnetName:               "my-subnet",
 				PublicLoadBalancerName:   "my-publiclb",
+				SSHNATRule:               true,
 				InternalLoadBalancerName: "my-internal-lb",
 			},
 			expectedError: "failed to create NAT rule: #: Internal Server Error: StatusCode=500",
@@ -476,6 +624,113 @@ func TestReconcileNetworkInterface(t *testing.T) {
 						Return(autorest.NewErrorWithResponse("", "", &http.Response{StatusCode: 500}, "Internal Server Error")))
 			},
 		},
+		{
+			name: "control plane network interface NAT rule beyond the former port range",
+			netInterfaceSpec: Spec{
+				Name:                   "my-net-interface",
+				VnetName:               "my-vnet",
+				SubnetName:             "my-subnet",
+				PublicLoadBalancerName: "my-publiclb",
+				SSHNATRule:             true,
+			},
+			expectedError: "",
+			expect: func(m *mock_networkinterfaces.MockClientMockRecorder,
+				mSubnet *mock_subnets.MockClientMockRecorder,
+				mPublicLoadBalancer *mock_publicloadbalancers.MockClientMockRecorder,
+				mInboundNATRules *mock_inboundnatrules.MockClientMockRecorder,
+				mInternalLoadBalancer *mock_internalloadbalancers.MockClientMockRecorder,
+				mPublicIP *mock_publicips.MockClientMockRecorder) {
+				gomock.InOrder(
+					mSubnet.Get(context.TODO(), "my-rg", "my-vnet", "my-subnet").Return(network.Subnet{}, nil),
+					mPublicLoadBalancer.Get(context.TODO(), "my-rg", "my-publiclb").Return(network.LoadBalancer{
+						Name: to.StringPtr("my-publiclb"),
+						ID:   pointer.StringPtr("my-publiclb-id"),
+						LoadBalancerPropertiesFormat: &network.LoadBalancerPropertiesFormat{
+							FrontendIPConfigurations: &[]network.FrontendIPConfiguration{
+								{
+									ID: to.StringPtr("frontend-ip-config-id"),
+								},
+							},
+							BackendAddressPools: &[]network.BackendAddressPool{
+								{
+									ID: pointer.StringPtr("my-backend-pool-id"),
+								},
+							},
+							LoadBalancingRules: &[]network.LoadBalancingRule{
+								{
+									Name: to.StringPtr("LBRuleHTTPS"),
+									LoadBalancingRulePropertiesFormat: &network.LoadBalancingRulePropertiesFormat{
+										FrontendPort: to.Int32Ptr(2220),
+									},
+								},
+							},
+							InboundNatRules: func() *[]network.InboundNatRule {
+								// every port of the former 2201-2219 range is taken
+								rules := []network.InboundNatRule{}
+								for port := int32(2200); port < 2220; port++ {
+									frontendPort := port
+									if port == 2200 {
+										frontendPort = 22
+									}
+									rules = append(rules, network.InboundNatRule{
+										Name: to.StringPtr(fmt.Sprintf("other-machine-nat-rule-%d", port)),
+										InboundNatRulePropertiesFormat: &network.InboundNatRulePropertiesFormat{
+											FrontendPort: to.Int32Ptr(frontendPort),
+										},
+									})
+								}
+								return &rules
+							}(),
+						}}, nil),
+					mInboundNATRules.CreateOrUpdate(context.TODO(), "my-rg", "my-publiclb", "azure-test1", gomock.AssignableToTypeOf(network.InboundNatRule{})).
+						Do(func(_ context.Context, _, _, _ string, rule network.InboundNatRule) {
+							g.Expect(rule.FrontendPort).To(Equal(to.Int32Ptr(2221)))
+						}),
+					m.CreateOrUpdate(context.TODO(), "my-rg", "my-net-interface", gomock.AssignableToTypeOf(network.Interface{})))
+			},
+		},
+		{
+			name: "control plane network interface without SSH NAT rule removes the existing rule",
+			netInterfaceSpec: Spec{
+				Name:                   "my-net-interface",
+				VnetName:               "my-vnet",
+				SubnetName:             "my-subnet",
+				PublicLoadBalancerName: "my-publiclb",
+			},
+			expectedError: "",
+			expect: func(m *mock_networkinterfaces.MockClientMockRecorder,
+				mSubnet *mock_subnets.MockClientMockRecorder,
+				mPublicLoadBalancer *mock_publicloadbalancers.MockClientMockRecorder,
+				mInboundNATRules *mock_inboundnatrules.MockClientMockRecorder,
+				mInternalLoadBalancer *mock_internalloadbalancers.MockClientMockRecorder,
+				mPublicIP *mock_publicips.MockClientMockRecorder) {
+				gomock.InOrder(
+					mSubnet.Get(context.TODO(), "my-rg", "my-vnet", "my-subnet").Return(network.Subnet{}, nil),
+					mPublicLoadBalancer.Get(context.TODO(), "my-rg", "my-publiclb").Return(network.LoadBalancer{
+						Name: to.StringPtr("my-publiclb"),
+						ID:   pointer.StringPtr("my-publiclb-id"),
+						LoadBalancerPropertiesFormat: &network.LoadBalancerPropertiesFormat{
+							BackendAddressPools: &[]network.BackendAddressPool{
+								{
+									ID: pointer.StringPtr("my-backend-pool-id"),
+								},
+							},
+							InboundNatRules: &[]network.InboundNatRule{
+								{
+									Name: pointer.StringPtr("azure-test1"),
+									InboundNatRulePropertiesFormat: &network.InboundNatRulePropertiesFormat{
+										FrontendPort: to.Int32Ptr(22),
+									},
+								},
+							},
+						}}, nil),
+					m.CreateOrUpdate(context.TODO(), "my-rg", "my-net-interface", gomock.AssignableToTypeOf(network.Interface{})).
+						Do(func(_ context.Context, _, _ string, nic network.Interface) {
+							g.Expect((*nic.IPConfigurations)[0].LoadBalancerInboundNatRules).To(BeNil())
+						}),
+					mInboundNATRules.Delete(context.TODO(), "my-rg", "my-publiclb", "azure-test1"))
+			},
+		},
 		{
 			name: "control plane network interface fail to get internal LB",
 			netInterfaceSpec: Spec{
@@ -632,6 +887,195 @@ func TestReconcileNetworkInterface(t *testing.T) {
 	}
 }
 
+func TestReconcileSSHNATRule(t *testing.T) {
+	g := NewWithT(t)
+
+	lbID := "/subscriptions/123/resourceGroups/my-rg/providers/Microsoft.Network/loadBalancers/my-publiclb"
+	ruleID := lbID + "/inboundNatRules/azure-test1"
+	otherRuleID := lbID + "/inboundNatRules/azure-test2"
+	backendPoolID := lbID + "/backendAddressPools/my-publiclb-backendPool"
+	existingNIC := func(natRuleIDs ...string) network.Interface {
+		natRules := []network.InboundNatRule{}
+		for _, id := range natRuleIDs {
+			natRules = append(natRules, network.InboundNatRule{ID: to.StringPtr(id)})
+		}
+		return network.Interface{
+			Name: to.StringPtr("my-net-interface"),
+			InterfacePropertiesFormat: &network.InterfacePropertiesFormat{
+				EnableAcceleratedNetworking: to.BoolPtr(true),
+				IPConfigurations: &[]network.InterfaceIPConfiguration{
+					{
+						Name: to.StringPtr("pipConfig"),
+						InterfaceIPConfigurationPropertiesFormat: &network.InterfaceIPConfigurationPropertiesFormat{
+							Primary:                         to.BoolPtr(true),
+							LoadBalancerBackendAddressPools: &[]network.BackendAddressPool{{ID: to.StringPtr(backendPoolID)}},
+							LoadBalancerInboundNatRules:     &natRules,
+						},
+					},
+					{
+						Name: to.StringPtr("ipConfigv6"),
+						InterfaceIPConfigurationPropertiesFormat: &network.InterfaceIPConfigurationPropertiesFormat{
+							PrivateIPAddressVersion: network.IPv6,
+						},
+					},
+				},
+			},
+		}
+	}
+	publicLB := func(natRuleNames ...string) network.LoadBalancer {
+		natRules := []network.InboundNatRule{}
+		for i, name := range natRuleNames {
+			natRules = append(natRules, network.InboundNatRule{
+				Name: to.StringPtr(name),
+				InboundNatRulePropertiesFormat: &network.InboundNatRulePropertiesFormat{
+					FrontendPort: to.Int32Ptr(int32(22 + i)),
+				},
+			})
+		}
+		return network.LoadBalancer{
+			ID:   to.StringPtr(lbID),
+			Name: to.StringPtr("my-publiclb"),
+			LoadBalancerPropertiesFormat: &network.LoadBalancerPropertiesFormat{
+				FrontendIPConfigurations: &[]network.FrontendIPConfiguration{{ID: to.StringPtr(lbID + "/frontendIPConfigurations/my-publiclb-frontEnd")}},
+				InboundNatRules:          &natRules,
+			},
+		}
+	}
+
+	testcases := []struct {
+		name          string
+		enabled       bool
+		expectedError string
+		expect        func(m *mock_networkinterfaces.MockClientMockRecorder,
+			mPublicLoadBalancer *mock_publicloadbalancers.MockClientMockRecorder,
+			mInboundNATRules *mock_inboundnatrules.MockClientMockRecorder)
+	}{
+		{
+			name:    "add the NAT rule to the existing network interface",
+			enabled: true,
+			expect: func(m *mock_networkinterfaces.MockClientMockRecorder,
+				mPublicLoadBalancer *mock_publicloadbalancers.MockClientMockRecorder,
+				mInboundNATRules *mock_inboundnatrules.MockClientMockRecorder) {
+				gomock.InOrder(
+					m.Get(context.TODO(), "my-rg", "my-net-interface").Return(existingNIC(otherRuleID), nil),
+					mPublicLoadBalancer.Get(context.TODO(), "my-rg", "my-publiclb").Return(publicLB("azure-test2"), nil),
+					mInboundNATRules.CreateOrUpdate(context.TODO(), "my-rg", "my-publiclb", "azure-test1", gomock.AssignableToTypeOf(network.InboundNatRule{})),
+					// only the NAT rule reference is added, accelerated networking, backend pools and IPv6 are kept
+					m.CreateOrUpdate(context.TODO(), "my-rg", "my-net-interface", existingNIC(otherRuleID, ruleID)))
+			},
+		},
+		{
+			name:    "keep the NAT rule of the existing network interface",
+			enabled: true,
+			expect: func(m *mock_networkinterfaces.MockClientMockRecorder,
+				mPublicLoadBalancer *mock_publicloadbalancers.MockClientMockRecorder,
+				mInboundNATRules *mock_inboundnatrules.MockClientMockRecorder) {
+				gomock.InOrder(
+					m.Get(context.TODO(), "my-rg", "my-net-interface").Return(existingNIC(ruleID), nil),
+					mPublicLoadBalancer.Get(context.TODO(), "my-rg", "my-publiclb").Return(publicLB("azure-test1"), nil))
+			},
+		},
+		{
+			name: "remove the NAT rule from the existing network interface",
+			expect: func(m *mock_networkinterfaces.MockClientMockRecorder,
+				mPublicLoadBalancer *mock_publicloadbalancers.MockClientMockRecorder,
+				mInboundNATRules *mock_inboundnatrules.MockClientMockRecorder) {
+				gomock.InOrder(
+					m.Get(context.TODO(), "my-rg", "my-net-interface").Return(existingNIC(ruleID, otherRuleID), nil),
+					mPublicLoadBalancer.Get(context.TODO(), "my-rg", "my-publiclb").Return(publicLB("azure-test1", "azure-test2"), nil),
+					m.CreateOrUpdate(context.TODO(), "my-rg", "my-net-interface", existingNIC(otherRuleID)),
+					mInboundNATRules.Delete(context.TODO(), "my-rg", "my-publiclb", "azure-test1"))
+			},
+		},
+		{
+			name: "existing network interface without a NAT rule is left alone",
+			expect: func(m *mock_networkinterfaces.MockClientMockRecorder,
+				mPublicLoadBalancer *mock_publicloadbalancers.MockClientMockRecorder,
+				mInboundNATRules *mock_inboundnatrules.MockClientMockRecorder) {
+				gomock.InOrder(
+					m.Get(context.TODO(), "my-rg", "my-net-interface").Return(existingNIC(), nil),
+					mPublicLoadBalancer.Get(context.TODO(), "my-rg", "my-publiclb").Return(publicLB(), nil))
+			},
+		},
+		{
+			name:          "get network interface fails",
+			enabled:       true,
+			expectedError: "failed to get network interface my-net-interface: #: Internal Server Error: StatusCode=500",
+			expect: func(m *mock_networkinterfaces.MockClientMockRecorder,
+				mPublicLoadBalancer *mock_publicloadbalancers.MockClientMockRecorder,
+				mInboundNATRules *mock_inboundnatrules.MockClientMockRecorder) {
+				m.Get(context.TODO(), "my-rg", "my-net-interface").
+					Return(network.Interface{}, autorest.NewErrorWithResponse("", "", &http.Response{StatusCode: 500}, "Internal Server Error"))
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			netInterfaceMock := mock_networkinterfaces.NewMockClient(mockCtrl)
+			publicLoadBalancerMock := mock_publicloadbalancers.NewMockClient(mockCtrl)
+			inboundNatRulesMock := mock_inboundnatrules.NewMockClient(mockCtrl)
+
+			cluster := &clusterv1.Cluster{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-cluster"},
+			}
+			client := fake.NewFakeClient(cluster)
+			clusterScope, err := scope.NewClusterScope(scope.ClusterScopeParams{
+				AzureClients: scope.AzureClients{
+					SubscriptionID: "123",
+					Authorizer:     autorest.NullAuthorizer{},
+				},
+				Client:  client,
+				Cluster: cluster,
+				AzureCluster: &infrav1.AzureCluster{
+					Spec: infrav1.AzureClusterSpec{
+						Location:      "test-location",
+						ResourceGroup: "my-rg",
+					},
+				},
+			})
+			g.Expect(err).NotTo(HaveOccurred())
+
+			machineScope, err := scope.NewMachineScope(scope.MachineScopeParams{
+				Client:  client,
+				Cluster: cluster,
+				Machine: &clusterv1.Machine{},
+				AzureClients: scope.AzureClients{
+					SubscriptionID: "123",
+					Authorizer:     autorest.NullAuthorizer{},
+				},
+				AzureMachine: &infrav1.AzureMachine{ObjectMeta: metav1.ObjectMeta{Name: "azure-test1"}},
+				AzureCluster: &infrav1.AzureCluster{},
+			})
+			g.Expect(err).NotTo(HaveOccurred())
+
+			tc.expect(netInterfaceMock.EXPECT(), publicLoadBalancerMock.EXPECT(), inboundNatRulesMock.EXPECT())
+
+			s := &Service{
+				Scope:                     clusterScope,
+				MachineScope:              machineScope,
+				Client:                    netInterfaceMock,
+				PublicLoadBalancersClient: publicLoadBalancerMock,
+				InboundNATRulesClient:     inboundNatRulesMock,
+			}
+
+			err = s.Reconcile(context.TODO(), &SSHNATRuleSpec{
+				NICName:                "my-net-interface",
+				PublicLoadBalancerName: "my-publiclb",
+				Enabled:                tc.enabled,
+			})
+			if tc.expectedError != "" {
+				g.Expect(err).To(MatchError(tc.expectedError))
+			} else {
+				g.Expect(err).NotTo(HaveOccurred())
+			}
+		})
+	}
+}
+
 func TestDeleteNetworkInterface(t *testing.T) {
 	g := NewWithT(t)
 
@@ -653,6 +1097,16 @@ func TestDeleteNetworkInterface(t *testing.T) {
 				mInboundNATRules.Delete(context.TODO(), "my-rg", "my-public-lb", "azure-test1")
 			},
 		},
+		{
+			name: "successfully delete a network interface without a public LB",
+			netInterfaceSpec: Spec{
+				Name: "my-net-interface",
+			},
+			expectedError: "",
+			expect: func(m *mock_networkinterfaces.MockClientMockRecorder, mInboundNATRules *mock_inboundnatrules.MockClientMockRecorder) {
+				m.Delete(context.TODO(), "my-rg", "my-net-interface")
+			},
+		},
 		{
 			name: "network interface already deleted",
 			netInterfaceSpec: Spec{
@@ -42,11 +42,11 @@ func NewService(scope *scope.ClusterScope, machineScope *scope.MachineScope) *Se
 	return &Service{
 		Scope:                       scope,
 		MachineScope:                machineScope,
-		Client:                      NewClient(scope.SubscriptionID, scope.Authorizer),
-		SubnetsClient:               subnets.NewClient(scope.SubscriptionID, scope.Authorizer),
-		PublicLoadBalancersClient:   publicloadbalancers.NewClient(scope.SubscriptionID, scope.Authorizer),
-		InternalLoadBalancersClient: internalloadbalancers.NewClient(scope.SubscriptionID, scope.Authorizer),
-		PublicIPsClient:             publicips.NewClient(scope.SubscriptionID, scope.Authorizer),
-		InboundNATRulesClient:       inboundnatrules.NewClient(scope.SubscriptionID, scope.Authorizer),
+		Client:                      NewClient(scope.SubscriptionID, scope.ResourceManagerEndpoint, scope.Authorizer),
+		SubnetsClient:               subnets.NewClient(scope.SubscriptionID, scope.ResourceManagerEndpoint, scope.Authorizer),
+		PublicLoadBalancersClient:   publicloadbalancers.NewClient(scope.SubscriptionID, scope.ResourceManagerEndpoint, scope.Authorizer),
+		InternalLoadBalancersClient: internalloadbalancers.NewClient(scope.SubscriptionID, scope.ResourceManagerEndpoint, scope.Authorizer),
+		PublicIPsClient:             publicips.NewClient(scope.SubscriptionID, scope.ResourceManagerEndpoint, scope.Authorizer),
+		InboundNATRulesClient:       inboundnatrules.NewClient(scope.SubscriptionID, scope.ResourceManagerEndpoint, scope.Authorizer),
 	}
 }
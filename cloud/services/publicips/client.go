@@ -39,14 +39,14 @@ type AzureClient struct {
 var _ Client = &AzureClient{}
 
 // NewClient creates a new public IP client from subscription ID.
-func NewClient(subscriptionID string, authorizer autorest.Authorizer) *AzureClient {
-	c := newPublicIPAddressesClient(subscriptionID, authorizer)
+func NewClient(subscriptionID, baseURI string, authorizer autorest.Authorizer) *AzureClient {
+	c := newPublicIPAddressesClient(subscriptionID, baseURI, authorizer)
 	return &AzureClient{c}
 }
 
 // newPublicIPAddressesClient creates a new public IP client from subscription ID.
-func newPublicIPAddressesClient(subscriptionID string, authorizer autorest.Authorizer) network.PublicIPAddressesClient {
-	publicIPsClient := network.NewPublicIPAddressesClientWithBaseURI(azure.DefaultBaseURI, subscriptionID)
+func newPublicIPAddressesClient(subscriptionID, baseURI string, authorizer autorest.Authorizer) network.PublicIPAddressesClient {
+	publicIPsClient := network.NewPublicIPAddressesClientWithBaseURI(baseURI, subscriptionID)
 	publicIPsClient.Authorizer = authorizer
 	publicIPsClient.AddToUserAgent(azure.UserAgent)
 	return publicIPsClient
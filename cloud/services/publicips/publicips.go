@@ -30,6 +30,8 @@ import (
 // Spec specification for public ip
 type Spec struct {
 	Name string
+	// IsIPv6 creates an IPv6 public ip instead of an IPv4 one.
+	IsIPv6 bool
 }
 
 // Get provides information about a public ip.
@@ -56,6 +58,13 @@ func (s *Service) Reconcile(ctx context.Context, spec interface{}) error {
 	ipName := publicIPSpec.Name
 	klog.V(2).Infof("creating public ip %s", ipName)
 
+	addressVersion := network.IPv4
+	fqdn := s.Scope.Network().APIServerIP.DNSName
+	if publicIPSpec.IsIPv6 {
+		addressVersion = network.IPv6
+		fqdn = s.Scope.Network().APIServerIPv6.DNSName
+	}
+
 	// https://docs.microsoft.com/en-us/azure/load-balancer/load-balancer-standard-availability-zones#zone-redundant-by-default
 	err := s.Client.CreateOrUpdate(
 		ctx,
@@ -66,11 +75,11 @@ func (s *Service) Reconcile(ctx context.Context, spec interface{}) error {
 			Name:     to.StringPtr(ipName),
 			Location: to.StringPtr(s.Scope.Location()),
 			PublicIPAddressPropertiesFormat: &network.PublicIPAddressPropertiesFormat{
-				PublicIPAddressVersion:   network.IPv4,
+				PublicIPAddressVersion:   addressVersion,
 				PublicIPAllocationMethod: network.Static,
 				DNSSettings: &network.PublicIPAddressDNSSettings{
 					DomainNameLabel: to.StringPtr(strings.ToLower(ipName)),
-					Fqdn:            to.StringPtr(s.Scope.Network().APIServerIP.DNSName),
+					Fqdn:            to.StringPtr(fqdn),
 				},
 			},
 		},
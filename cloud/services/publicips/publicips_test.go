@@ -199,6 +199,20 @@ func TestReconcilePublicLoadBalancer(t *testing.T) {
 				m.CreateOrUpdate(context.TODO(), "my-rg", "my-publicip", gomock.AssignableToTypeOf(network.PublicIPAddress{}))
 			},
 		},
+		{
+			name: "can create an IPv6 public IP",
+			publicIPsSpec: Spec{
+				Name:   "my-publicip-ipv6",
+				IsIPv6: true,
+			},
+			expectedError: "",
+			expect: func(m *mock_publicips.MockClientMockRecorder) {
+				m.CreateOrUpdate(context.TODO(), "my-rg", "my-publicip-ipv6", gomock.AssignableToTypeOf(network.PublicIPAddress{})).
+					Do(func(_ context.Context, _, _ string, ip network.PublicIPAddress) {
+						g.Expect(ip.PublicIPAddressVersion).To(Equal(network.IPv6))
+					})
+			},
+		},
 		{
 			name: "fail to create a public IP",
 			publicIPsSpec: Spec{
@@ -19,6 +19,7 @@ package publicloadbalancers
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/Azure/azure-sdk-for-go/services/network/mgmt/2019-06-01/network"
 	"github.com/Azure/go-autorest/autorest/to"
@@ -33,6 +34,14 @@ import (
 type Spec struct {
 	Name         string
 	PublicIPName string
+	// PublicIPv6Name is the name of the IPv6 public ip of a dual-stack load balancer.
+	PublicIPv6Name string
+	// IsNodeOutbound creates a node outbound load balancer, which only has an outbound rule for its backend pool.
+	IsNodeOutbound bool
+	// AllocatedOutboundPorts is the number of SNAT ports per machine of the outbound rule, if any.
+	AllocatedOutboundPorts *int32
+	// IdleTimeoutInMinutes is the idle timeout of the outbound rule, if any.
+	IdleTimeoutInMinutes *int32
 }
 
 // Get provides information about a public load balancer.
@@ -72,6 +81,56 @@ func (s *Service) Reconcile(ctx context.Context, spec interface{}) error {
 	}
 	klog.V(2).Infof("successfully got public ip %s", publicLBSpec.PublicIPName)
 
+	if publicLBSpec.IsNodeOutbound {
+		return s.reconcileNodeOutboundLB(ctx, publicLBSpec, publicIP, idPrefix)
+	}
+
+	frontendIPConfigs := []network.FrontendIPConfiguration{
+		{
+			Name: &frontEndIPConfigName,
+			FrontendIPConfigurationPropertiesFormat: &network.FrontendIPConfigurationPropertiesFormat{
+				PrivateIPAllocationMethod: network.Dynamic,
+				PublicIPAddress:           &publicIP,
+			},
+		},
+	}
+	backendAddressPools := []network.BackendAddressPool{
+		{
+			Name: &backEndAddressPoolName,
+		},
+	}
+	loadBalancingRules := []network.LoadBalancingRule{
+		s.loadBalancingRule("LBRuleHTTPS", idPrefix, lbName, frontEndIPConfigName, backEndAddressPoolName, probeName),
+	}
+
+	if publicLBSpec.PublicIPv6Name != "" {
+		frontEndIPv6ConfigName := "controlplane-lbFrontEnd-ipv6"
+		backEndIPv6AddressPoolName := "controlplane-backEndPool-ipv6"
+
+		klog.V(2).Infof("getting public ip %s", publicLBSpec.PublicIPv6Name)
+		publicIPv6, err := s.PublicIPsClient.Get(ctx, s.Scope.ResourceGroup(), publicLBSpec.PublicIPv6Name)
+		if err != nil && azure.ResourceNotFound(err) {
+			return errors.Wrap(err, fmt.Sprintf("public ip %s not found in RG %s", publicLBSpec.PublicIPv6Name, s.Scope.ResourceGroup()))
+		} else if err != nil {
+			return errors.Wrap(err, "failed to look for existing public IP")
+		}
+		klog.V(2).Infof("successfully got public ip %s", publicLBSpec.PublicIPv6Name)
+
+		frontendIPConfigs = append(frontendIPConfigs, network.FrontendIPConfiguration{
+			Name: &frontEndIPv6ConfigName,
+			FrontendIPConfigurationPropertiesFormat: &network.FrontendIPConfigurationPropertiesFormat{
+				PrivateIPAllocationMethod: network.Dynamic,
+				PublicIPAddress:           &publicIPv6,
+			},
+		})
+		// IPv4 and IPv6 frontends can't share a backend pool
+		backendAddressPools = append(backendAddressPools, network.BackendAddressPool{
+			Name: &backEndIPv6AddressPoolName,
+		})
+		loadBalancingRules = append(loadBalancingRules,
+			s.loadBalancingRule("LBRuleHTTPS-ipv6", idPrefix, lbName, frontEndIPv6ConfigName, backEndIPv6AddressPoolName, probeName))
+	}
+
 	// https://docs.microsoft.com/en-us/azure/load-balancer/load-balancer-standard-availability-zones#zone-redundant-by-default
 	err = s.Client.CreateOrUpdate(ctx,
 		s.Scope.ResourceGroup(),
@@ -86,20 +145,8 @@ func (s *Service) Reconcile(ctx context.Context, spec interface{}) error {
 			Sku:      &network.LoadBalancerSku{Name: network.LoadBalancerSkuNameStandard},
 			Location: to.StringPtr(s.Scope.Location()),
 			LoadBalancerPropertiesFormat: &network.LoadBalancerPropertiesFormat{
-				FrontendIPConfigurations: &[]network.FrontendIPConfiguration{
-					{
-						Name: &frontEndIPConfigName,
-						FrontendIPConfigurationPropertiesFormat: &network.FrontendIPConfigurationPropertiesFormat{
-							PrivateIPAllocationMethod: network.Dynamic,
-							PublicIPAddress:           &publicIP,
-						},
-					},
-				},
-				BackendAddressPools: &[]network.BackendAddressPool{
-					{
-						Name: &backEndAddressPoolName,
-					},
-				},
+				FrontendIPConfigurations: &frontendIPConfigs,
+				BackendAddressPools:      &backendAddressPools,
 				Probes: &[]network.Probe{
 					{
 						Name: &probeName,
@@ -111,28 +158,7 @@ func (s *Service) Reconcile(ctx context.Context, spec interface{}) error {
 						},
 					},
 				},
-				LoadBalancingRules: &[]network.LoadBalancingRule{
-					{
-						Name: to.StringPtr("LBRuleHTTPS"),
-						LoadBalancingRulePropertiesFormat: &network.LoadBalancingRulePropertiesFormat{
-							Protocol:             network.TransportProtocolTCP,
-							FrontendPort:         to.Int32Ptr(s.Scope.APIServerPort()),
-							BackendPort:          to.Int32Ptr(s.Scope.APIServerPort()),
-							IdleTimeoutInMinutes: to.Int32Ptr(4),
-							EnableFloatingIP:     to.BoolPtr(false),
-							LoadDistribution:     network.LoadDistributionDefault,
-							FrontendIPConfiguration: &network.SubResource{
-								ID: to.StringPtr(fmt.Sprintf("/%s/%s/frontendIPConfigurations/%s", idPrefix, lbName, frontEndIPConfigName)),
-							},
-							BackendAddressPool: &network.SubResource{
-								ID: to.StringPtr(fmt.Sprintf("/%s/%s/backendAddressPools/%s", idPrefix, lbName, backEndAddressPoolName)),
-							},
-							Probe: &network.SubResource{
-								ID: to.StringPtr(fmt.Sprintf("/%s/%s/probes/%s", idPrefix, lbName, probeName)),
-							},
-						},
-					},
-				},
+				LoadBalancingRules: &loadBalancingRules,
 			},
 		})
 
@@ -144,6 +170,146 @@ func (s *Service) Reconcile(ctx context.Context, spec interface{}) error {
 	return nil
 }
 
+// reconcileNodeOutboundLB gets/creates/updates a node outbound load balancer, whose outbound rule gives the machines
+// of its backend pool outbound internet access through its public ip.
+// The cloud provider adds the frontends, rules and probes of Services of type LoadBalancer to the same load balancer,
+// so those are kept when it already exists. Azure rejects load balancing rules with outbound SNAT on a backend pool
+// that also has an outbound rule, so SNAT is disabled on the rules of the backend pool, which the cloud provider
+// only keeps when it runs with disableOutboundSNAT.
+func (s *Service) reconcileNodeOutboundLB(ctx context.Context, publicLBSpec *Spec, publicIP network.PublicIPAddress, idPrefix string) error {
+	frontEndIPConfigName := "node-outbound-lbFrontEnd"
+	outboundRuleName := "OutboundNATAllProtocols"
+	lbName := publicLBSpec.Name
+	backEndAddressPoolName := azure.GenerateNodeOutboundBackendPoolName(lbName)
+
+	idleTimeoutInMinutes := publicLBSpec.IdleTimeoutInMinutes
+	if idleTimeoutInMinutes == nil {
+		idleTimeoutInMinutes = to.Int32Ptr(4)
+	}
+
+	lb, err := s.Client.Get(ctx, s.Scope.ResourceGroup(), lbName)
+	if err != nil && !azure.ResourceNotFound(err) {
+		return errors.Wrapf(err, "failed to get node outbound load balancer %s", lbName)
+	}
+	if err != nil || lb.LoadBalancerPropertiesFormat == nil {
+		lb = network.LoadBalancer{
+			Tags: converters.TagsToMap(infrav1.Build(infrav1.BuildParams{
+				ClusterName: s.Scope.Name(),
+				Lifecycle:   infrav1.ResourceLifecycleOwned,
+				Role:        to.StringPtr(infrav1.Node),
+				Additional:  s.Scope.AdditionalTags(),
+			})),
+			LoadBalancerPropertiesFormat: &network.LoadBalancerPropertiesFormat{},
+		}
+	}
+	lb.Sku = &network.LoadBalancerSku{Name: network.LoadBalancerSkuNameStandard}
+	lb.Location = to.StringPtr(s.Scope.Location())
+
+	frontendIPConfigs := []network.FrontendIPConfiguration{}
+	if lb.FrontendIPConfigurations != nil {
+		for _, config := range *lb.FrontendIPConfigurations {
+			if to.String(config.Name) != frontEndIPConfigName {
+				frontendIPConfigs = append(frontendIPConfigs, config)
+			}
+		}
+	}
+	frontendIPConfigs = append(frontendIPConfigs, network.FrontendIPConfiguration{
+		Name: to.StringPtr(frontEndIPConfigName),
+		FrontendIPConfigurationPropertiesFormat: &network.FrontendIPConfigurationPropertiesFormat{
+			PrivateIPAllocationMethod: network.Dynamic,
+			PublicIPAddress:           &publicIP,
+		},
+	})
+	lb.FrontendIPConfigurations = &frontendIPConfigs
+
+	hasBackendPool := false
+	if lb.BackendAddressPools != nil {
+		for _, pool := range *lb.BackendAddressPools {
+			if to.String(pool.Name) == backEndAddressPoolName {
+				hasBackendPool = true
+			}
+		}
+	}
+	if !hasBackendPool {
+		backendAddressPools := []network.BackendAddressPool{}
+		if lb.BackendAddressPools != nil {
+			backendAddressPools = append(backendAddressPools, *lb.BackendAddressPools...)
+		}
+		backendAddressPools = append(backendAddressPools, network.BackendAddressPool{
+			Name: to.StringPtr(backEndAddressPoolName),
+		})
+		lb.BackendAddressPools = &backendAddressPools
+	}
+
+	backEndAddressPoolSuffix := strings.ToLower("/backendAddressPools/" + backEndAddressPoolName)
+	if lb.LoadBalancingRules != nil {
+		for _, rule := range *lb.LoadBalancingRules {
+			if rule.LoadBalancingRulePropertiesFormat == nil || rule.BackendAddressPool == nil {
+				continue
+			}
+			if strings.HasSuffix(strings.ToLower(to.String(rule.BackendAddressPool.ID)), backEndAddressPoolSuffix) {
+				rule.DisableOutboundSnat = to.BoolPtr(true)
+			}
+		}
+	}
+
+	outboundRules := []network.OutboundRule{}
+	if lb.OutboundRules != nil {
+		for _, rule := range *lb.OutboundRules {
+			if to.String(rule.Name) != outboundRuleName {
+				outboundRules = append(outboundRules, rule)
+			}
+		}
+	}
+	outboundRules = append(outboundRules, network.OutboundRule{
+		Name: to.StringPtr(outboundRuleName),
+		OutboundRulePropertiesFormat: &network.OutboundRulePropertiesFormat{
+			Protocol:               network.LoadBalancerOutboundRuleProtocolAll,
+			AllocatedOutboundPorts: publicLBSpec.AllocatedOutboundPorts,
+			IdleTimeoutInMinutes:   idleTimeoutInMinutes,
+			FrontendIPConfigurations: &[]network.SubResource{
+				{ID: to.StringPtr(fmt.Sprintf("/%s/%s/frontendIPConfigurations/%s", idPrefix, lbName, frontEndIPConfigName))},
+			},
+			BackendAddressPool: &network.SubResource{
+				ID: to.StringPtr(fmt.Sprintf("/%s/%s/backendAddressPools/%s", idPrefix, lbName, backEndAddressPoolName)),
+			},
+		},
+	})
+	lb.OutboundRules = &outboundRules
+
+	err = s.Client.CreateOrUpdate(ctx, s.Scope.ResourceGroup(), lbName, lb)
+	if err != nil {
+		return errors.Wrap(err, "cannot create node outbound load balancer")
+	}
+
+	klog.V(2).Infof("successfully created node outbound load balancer %s", lbName)
+	return nil
+}
+
+// loadBalancingRule returns a rule forwarding the API server port of a frontend to a backend pool.
+func (s *Service) loadBalancingRule(name, idPrefix, lbName, frontEndIPConfigName, backEndAddressPoolName, probeName string) network.LoadBalancingRule {
+	return network.LoadBalancingRule{
+		Name: to.StringPtr(name),
+		LoadBalancingRulePropertiesFormat: &network.LoadBalancingRulePropertiesFormat{
+			Protocol:             network.TransportProtocolTCP,
+			FrontendPort:         to.Int32Ptr(s.Scope.APIServerPort()),
+			BackendPort:          to.Int32Ptr(s.Scope.APIServerPort()),
+			IdleTimeoutInMinutes: to.Int32Ptr(4),
+			EnableFloatingIP:     to.BoolPtr(false),
+			LoadDistribution:     network.LoadDistributionDefault,
+			FrontendIPConfiguration: &network.SubResource{
+				ID: to.StringPtr(fmt.Sprintf("/%s/%s/frontendIPConfigurations/%s", idPrefix, lbName, frontEndIPConfigName)),
+			},
+			BackendAddressPool: &network.SubResource{
+				ID: to.StringPtr(fmt.Sprintf("/%s/%s/backendAddressPools/%s", idPrefix, lbName, backEndAddressPoolName)),
+			},
+			Probe: &network.SubResource{
+				ID: to.StringPtr(fmt.Sprintf("/%s/%s/probes/%s", idPrefix, lbName, probeName)),
+			},
+		},
+	}
+}
+
 // Delete deletes the public load balancer with the provided name.
 func (s *Service) Delete(ctx context.Context, spec interface{}) error {
 	publicLBSpec, ok := spec.(*Spec)
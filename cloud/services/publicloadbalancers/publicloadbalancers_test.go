@@ -26,6 +26,7 @@ import (
 	"sigs.k8s.io/cluster-api-provider-azure/cloud/services/publicloadbalancers/mock_publicloadbalancers"
 
 	"github.com/Azure/go-autorest/autorest"
+	"github.com/Azure/go-autorest/autorest/to"
 	"github.com/golang/mock/gomock"
 
 	network "github.com/Azure/azure-sdk-for-go/services/network/mgmt/2019-06-01/network"
@@ -233,6 +234,185 @@ func TestReconcilePublicLoadBalancer(t *testing.T) {
 				publicIP.Get(context.TODO(), "my-rg", "my-publicip").Return(network.PublicIPAddress{}, nil)
 			},
 		},
+		{
+			name: "successfully create a dual-stack public LB",
+			publicLBSpec: Spec{
+				Name:           "my-publiclb",
+				PublicIPName:   "my-publicip",
+				PublicIPv6Name: "my-publicip-ipv6",
+			},
+			expectedError: "",
+			expect: func(m *mock_publicloadbalancers.MockClientMockRecorder,
+				publicIP *mock_publicips.MockClientMockRecorder) {
+				m.CreateOrUpdate(context.TODO(), "my-rg", "my-publiclb", gomock.AssignableToTypeOf(network.LoadBalancer{})).
+					Do(func(_ context.Context, _, _ string, lb network.LoadBalancer) {
+						g.Expect(*lb.FrontendIPConfigurations).To(HaveLen(2))
+						g.Expect(*(*lb.FrontendIPConfigurations)[1].PublicIPAddress.Name).To(Equal("my-publicip-ipv6"))
+						g.Expect(*lb.BackendAddressPools).To(HaveLen(2))
+						g.Expect(*lb.LoadBalancingRules).To(HaveLen(2))
+						g.Expect(*(*lb.LoadBalancingRules)[1].BackendAddressPool.ID).To(HaveSuffix("/backendAddressPools/controlplane-backEndPool-ipv6"))
+					}).Return(nil)
+				publicIP.Get(context.TODO(), "my-rg", "my-publicip").Return(network.PublicIPAddress{Name: to.StringPtr("my-publicip")}, nil)
+				publicIP.Get(context.TODO(), "my-rg", "my-publicip-ipv6").Return(network.PublicIPAddress{Name: to.StringPtr("my-publicip-ipv6")}, nil)
+			},
+		},
+		{
+			name: "successfully create a node outbound LB",
+			publicLBSpec: Spec{
+				Name:                   "my-node-outbound-lb",
+				PublicIPName:           "my-node-outbound-pip",
+				IsNodeOutbound:         true,
+				AllocatedOutboundPorts: to.Int32Ptr(1024),
+			},
+			expectedError: "",
+			expect: func(m *mock_publicloadbalancers.MockClientMockRecorder,
+				publicIP *mock_publicips.MockClientMockRecorder) {
+				m.Get(context.TODO(), "my-rg", "my-node-outbound-lb").Return(network.LoadBalancer{}, autorest.NewErrorWithResponse("", "", &http.Response{StatusCode: 404}, "Not found"))
+				m.CreateOrUpdate(context.TODO(), "my-rg", "my-node-outbound-lb", gomock.AssignableToTypeOf(network.LoadBalancer{})).
+					Do(func(_ context.Context, _, _ string, lb network.LoadBalancer) {
+						g.Expect(*lb.FrontendIPConfigurations).To(HaveLen(1))
+						g.Expect(*lb.BackendAddressPools).To(HaveLen(1))
+						g.Expect(lb.LoadBalancingRules).To(BeNil())
+						g.Expect(lb.Probes).To(BeNil())
+						g.Expect(*lb.OutboundRules).To(HaveLen(1))
+						outboundRule := (*lb.OutboundRules)[0]
+						g.Expect(outboundRule.AllocatedOutboundPorts).To(Equal(to.Int32Ptr(1024)))
+						g.Expect(outboundRule.IdleTimeoutInMinutes).To(Equal(to.Int32Ptr(4)))
+						g.Expect(*outboundRule.BackendAddressPool.ID).To(HaveSuffix("/my-node-outbound-lb/backendAddressPools/my-node-outbound-lb"))
+					}).Return(nil)
+				publicIP.Get(context.TODO(), "my-rg", "my-node-outbound-pip").Return(network.PublicIPAddress{}, nil)
+			},
+		},
+		{
+			name: "node outbound LB keeps the rules of the cloud provider",
+			publicLBSpec: Spec{
+				Name:           "my-node-outbound-lb",
+				PublicIPName:   "my-node-outbound-pip",
+				IsNodeOutbound: true,
+			},
+			expectedError: "",
+			expect: func(m *mock_publicloadbalancers.MockClientMockRecorder,
+				publicIP *mock_publicips.MockClientMockRecorder) {
+				m.Get(context.TODO(), "my-rg", "my-node-outbound-lb").Return(network.LoadBalancer{
+					LoadBalancerPropertiesFormat: &network.LoadBalancerPropertiesFormat{
+						FrontendIPConfigurations: &[]network.FrontendIPConfiguration{
+							{Name: to.StringPtr("node-outbound-lbFrontEnd")},
+							{Name: to.StringPtr("a1b2c3d4")},
+						},
+						BackendAddressPools: &[]network.BackendAddressPool{
+							{Name: to.StringPtr("my-node-outbound-lb")},
+						},
+						LoadBalancingRules: &[]network.LoadBalancingRule{
+							{Name: to.StringPtr("a1b2c3d4-TCP-80")},
+						},
+						Probes: &[]network.Probe{
+							{Name: to.StringPtr("a1b2c3d4-TCP-80")},
+						},
+						OutboundRules: &[]network.OutboundRule{
+							{Name: to.StringPtr("OutboundNATAllProtocols")},
+						},
+					},
+				}, nil)
+				m.CreateOrUpdate(context.TODO(), "my-rg", "my-node-outbound-lb", gomock.AssignableToTypeOf(network.LoadBalancer{})).
+					Do(func(_ context.Context, _, _ string, lb network.LoadBalancer) {
+						g.Expect(*lb.FrontendIPConfigurations).To(HaveLen(2))
+						g.Expect(*(*lb.FrontendIPConfigurations)[0].Name).To(Equal("a1b2c3d4"))
+						g.Expect(*lb.BackendAddressPools).To(HaveLen(1))
+						g.Expect(*lb.LoadBalancingRules).To(HaveLen(1))
+						g.Expect(*lb.Probes).To(HaveLen(1))
+						g.Expect(*lb.OutboundRules).To(HaveLen(1))
+						g.Expect((*lb.OutboundRules)[0].OutboundRulePropertiesFormat).NotTo(BeNil())
+					}).Return(nil)
+				publicIP.Get(context.TODO(), "my-rg", "my-node-outbound-pip").Return(network.PublicIPAddress{}, nil)
+			},
+		},
+		{
+			name: "node outbound LB merges a load balancing rule of the cloud provider",
+			publicLBSpec: Spec{
+				Name:           "my-node-outbound-lb",
+				PublicIPName:   "my-node-outbound-pip",
+				IsNodeOutbound: true,
+			},
+			expectedError: "",
+			expect: func(m *mock_publicloadbalancers.MockClientMockRecorder,
+				publicIP *mock_publicips.MockClientMockRecorder) {
+				lbID := "/subscriptions/123/resourceGroups/my-rg/providers/Microsoft.Network/loadBalancers/my-node-outbound-lb"
+				m.Get(context.TODO(), "my-rg", "my-node-outbound-lb").Return(network.LoadBalancer{
+					ID:   to.StringPtr(lbID),
+					Name: to.StringPtr("my-node-outbound-lb"),
+					LoadBalancerPropertiesFormat: &network.LoadBalancerPropertiesFormat{
+						FrontendIPConfigurations: &[]network.FrontendIPConfiguration{
+							{Name: to.StringPtr("node-outbound-lbFrontEnd"), ID: to.StringPtr(lbID + "/frontendIPConfigurations/node-outbound-lbFrontEnd")},
+							{Name: to.StringPtr("a1b2c3d4"), ID: to.StringPtr(lbID + "/frontendIPConfigurations/a1b2c3d4")},
+						},
+						BackendAddressPools: &[]network.BackendAddressPool{
+							{Name: to.StringPtr("my-node-outbound-lb"), ID: to.StringPtr(lbID + "/backendAddressPools/my-node-outbound-lb")},
+						},
+						// the cloud provider creates its rules with outbound SNAT unless it runs with disableOutboundSNAT
+						LoadBalancingRules: &[]network.LoadBalancingRule{
+							{
+								Name: to.StringPtr("a1b2c3d4-TCP-80"),
+								LoadBalancingRulePropertiesFormat: &network.LoadBalancingRulePropertiesFormat{
+									Protocol:                network.TransportProtocolTCP,
+									FrontendPort:            to.Int32Ptr(80),
+									BackendPort:             to.Int32Ptr(80),
+									EnableFloatingIP:        to.BoolPtr(true),
+									DisableOutboundSnat:     to.BoolPtr(false),
+									FrontendIPConfiguration: &network.SubResource{ID: to.StringPtr(lbID + "/frontendIPConfigurations/a1b2c3d4")},
+									BackendAddressPool:      &network.SubResource{ID: to.StringPtr(lbID + "/backendAddressPools/my-node-outbound-lb")},
+									Probe:                   &network.SubResource{ID: to.StringPtr(lbID + "/probes/a1b2c3d4-TCP-80")},
+								},
+							},
+						},
+						Probes: &[]network.Probe{
+							{Name: to.StringPtr("a1b2c3d4-TCP-80")},
+						},
+					},
+				}, nil)
+				m.CreateOrUpdate(context.TODO(), "my-rg", "my-node-outbound-lb", gomock.AssignableToTypeOf(network.LoadBalancer{})).
+					Do(func(_ context.Context, _, _ string, lb network.LoadBalancer) {
+						g.Expect(*lb.LoadBalancingRules).To(HaveLen(1))
+						rule := (*lb.LoadBalancingRules)[0]
+						g.Expect(*rule.Name).To(Equal("a1b2c3d4-TCP-80"))
+						g.Expect(*rule.FrontendPort).To(Equal(int32(80)))
+						g.Expect(*rule.EnableFloatingIP).To(BeTrue())
+						g.Expect(*rule.FrontendIPConfiguration.ID).To(Equal(lbID + "/frontendIPConfigurations/a1b2c3d4"))
+						g.Expect(*rule.Probe.ID).To(Equal(lbID + "/probes/a1b2c3d4-TCP-80"))
+						g.Expect(*rule.DisableOutboundSnat).To(BeTrue())
+						g.Expect(*lb.OutboundRules).To(HaveLen(1))
+						g.Expect(*(*lb.OutboundRules)[0].BackendAddressPool.ID).To(HaveSuffix("/my-node-outbound-lb/backendAddressPools/my-node-outbound-lb"))
+					}).Return(nil)
+				publicIP.Get(context.TODO(), "my-rg", "my-node-outbound-pip").Return(network.PublicIPAddress{}, nil)
+			},
+		},
+		{
+			name: "fail to get a node outbound LB",
+			publicLBSpec: Spec{
+				Name:           "my-node-outbound-lb",
+				PublicIPName:   "my-node-outbound-pip",
+				IsNodeOutbound: true,
+			},
+			expectedError: "failed to get node outbound load balancer my-node-outbound-lb: #: Internal Server Error: StatusCode=500",
+			expect: func(m *mock_publicloadbalancers.MockClientMockRecorder,
+				publicIP *mock_publicips.MockClientMockRecorder) {
+				m.Get(context.TODO(), "my-rg", "my-node-outbound-lb").Return(network.LoadBalancer{}, autorest.NewErrorWithResponse("", "", &http.Response{StatusCode: 500}, "Internal Server Error"))
+				publicIP.Get(context.TODO(), "my-rg", "my-node-outbound-pip").Return(network.PublicIPAddress{}, nil)
+			},
+		},
+		{
+			name: "IPv6 public IP does not exist",
+			publicLBSpec: Spec{
+				Name:           "my-publiclb",
+				PublicIPName:   "my-publicip",
+				PublicIPv6Name: "my-publicip-ipv6",
+			},
+			expectedError: "public ip my-publicip-ipv6 not found in RG my-rg: #: Not found: StatusCode=404",
+			expect: func(m *mock_publicloadbalancers.MockClientMockRecorder,
+				publicIP *mock_publicips.MockClientMockRecorder) {
+				publicIP.Get(context.TODO(), "my-rg", "my-publicip").Return(network.PublicIPAddress{}, nil)
+				publicIP.Get(context.TODO(), "my-rg", "my-publicip-ipv6").Return(network.PublicIPAddress{}, autorest.NewErrorWithResponse("", "", &http.Response{StatusCode: 404}, "Not found"))
+			},
+		},
 		{
 			name: "fail to create a public LB",
 			publicLBSpec: Spec{
@@ -32,7 +32,7 @@ type Service struct {
 func NewService(scope *scope.ClusterScope) *Service {
 	return &Service{
 		Scope:           scope,
-		Client:          NewClient(scope.SubscriptionID, scope.Authorizer),
-		PublicIPsClient: publicips.NewClient(scope.SubscriptionID, scope.Authorizer),
+		Client:          NewClient(scope.SubscriptionID, scope.ResourceManagerEndpoint, scope.Authorizer),
+		PublicIPsClient: publicips.NewClient(scope.SubscriptionID, scope.ResourceManagerEndpoint, scope.Authorizer),
 	}
 }
@@ -0,0 +1,114 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resourceskus
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2020-06-01/compute"
+	"github.com/pkg/errors"
+	"k8s.io/klog"
+)
+
+// DefaultCacheTTL is the default duration the resource SKUs of a location are cached for.
+const DefaultCacheTTL = time.Hour
+
+// Cache is a TTL cache of the resource SKUs available to a subscription in a location.
+// It is owned by the manager and shared by all the controllers, so that the Resource SKUs API
+// is called once per subscription and location instead of once per reconcile.
+type Cache struct {
+	ttl     time.Duration
+	mu      sync.Mutex
+	entries map[string]*cacheEntry
+	now     func() time.Time
+}
+
+type cacheEntry struct {
+	mu     sync.Mutex
+	skus   []compute.ResourceSku
+	expiry time.Time
+}
+
+// NewCache creates an empty resource SKU cache whose entries expire after the ttl.
+func NewCache(ttl time.Duration) *Cache {
+	return &Cache{
+		ttl:     ttl,
+		entries: make(map[string]*cacheEntry),
+		now:     time.Now,
+	}
+}
+
+// Get returns the resource SKUs available to the subscription in the location. They are listed with
+// the client when they are not cached yet or have expired. A nil cache lists them on every call.
+func (c *Cache) Get(ctx context.Context, client Client, subscriptionID, location string) ([]compute.ResourceSku, error) {
+	if c == nil {
+		return list(ctx, client, location)
+	}
+
+	// Lock the entry while listing, so that concurrent reconciles wait for a single call to the API.
+	e := c.entry(subscriptionID, location)
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.skus != nil && c.now().Before(e.expiry) {
+		return e.skus, nil
+	}
+
+	klog.V(2).Infof("listing resource SKUs of subscription %s in location %s", subscriptionID, location)
+	skus, err := list(ctx, client, location)
+	if err != nil {
+		return nil, err
+	}
+	e.skus = skus
+	e.expiry = c.now().Add(c.ttl)
+	return skus, nil
+}
+
+func (c *Cache) entry(subscriptionID, location string) *cacheEntry {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := fmt.Sprintf("%s/%s", subscriptionID, strings.ToLower(location))
+	e, ok := c.entries[key]
+	if !ok {
+		e = &cacheEntry{}
+		c.entries[key] = e
+	}
+	return e
+}
+
+// list lists the resource SKUs available in the location.
+func list(ctx context.Context, client Client, location string) ([]compute.ResourceSku, error) {
+	// Prefer ListComplete() over List() to automatically traverse pages via iterator.
+	res, err := client.ListComplete(ctx, fmt.Sprintf("location eq '%s'", location))
+	if err != nil {
+		return nil, err
+	}
+
+	skus := make([]compute.ResourceSku, 0)
+	for res.NotDone() {
+		skus = append(skus, res.Value())
+		if err := res.NextWithContext(ctx); err != nil {
+			return nil, errors.Wrap(err, "could not iterate resource skus")
+		}
+	}
+	return skus, nil
+}
@@ -0,0 +1,113 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resourceskus
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2020-06-01/compute"
+	"github.com/Azure/go-autorest/autorest"
+	"github.com/Azure/go-autorest/autorest/to"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/gomega"
+	"sigs.k8s.io/cluster-api-provider-azure/cloud/services/resourceskus/mock_resourceskus"
+)
+
+func newIterator(g *WithT, skus []compute.ResourceSku) compute.ResourceSkusResultIterator {
+	page := compute.NewResourceSkusResultPage(func(_ context.Context, r compute.ResourceSkusResult) (compute.ResourceSkusResult, error) {
+		if r.Value == nil {
+			return compute.ResourceSkusResult{Value: &skus}, nil
+		}
+		return compute.ResourceSkusResult{}, nil
+	})
+	g.Expect(page.NextWithContext(context.TODO())).To(Succeed())
+	return compute.NewResourceSkusResultIterator(page)
+}
+
+func TestCacheGet(t *testing.T) {
+	g := NewWithT(t)
+
+	eastus := []compute.ResourceSku{{Name: to.StringPtr("Standard_D2s_v3")}}
+	westus := []compute.ResourceSku{{Name: to.StringPtr("Standard_D4s_v3")}}
+
+	mockCtrl := gomock.NewController(t)
+	skusMock := mock_resourceskus.NewMockClient(mockCtrl)
+	gomock.InOrder(
+		skusMock.EXPECT().ListComplete(context.TODO(), "location eq 'eastus'").Return(newIterator(g, eastus), nil),
+		skusMock.EXPECT().ListComplete(context.TODO(), "location eq 'westus'").Return(newIterator(g, westus), nil),
+		skusMock.EXPECT().ListComplete(context.TODO(), "location eq 'eastus'").Return(newIterator(g, eastus), nil),
+	)
+
+	now := time.Now()
+	c := NewCache(time.Hour)
+	c.now = func() time.Time { return now }
+
+	// The first call lists the SKUs, the second one is cached.
+	for _, location := range []string{"eastus", "EastUS"} {
+		skus, err := c.Get(context.TODO(), skusMock, "123", location)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(skus).To(Equal(eastus))
+	}
+
+	// Locations are cached separately.
+	skus, err := c.Get(context.TODO(), skusMock, "123", "westus")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(skus).To(Equal(westus))
+
+	// The SKUs are listed again once they expire.
+	now = now.Add(2 * time.Hour)
+	skus, err = c.Get(context.TODO(), skusMock, "123", "eastus")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(skus).To(Equal(eastus))
+}
+
+func TestCacheGetError(t *testing.T) {
+	g := NewWithT(t)
+
+	mockCtrl := gomock.NewController(t)
+	skusMock := mock_resourceskus.NewMockClient(mockCtrl)
+	skusMock.EXPECT().ListComplete(context.TODO(), "location eq 'eastus'").
+		Return(compute.ResourceSkusResultIterator{}, autorest.NewErrorWithResponse("", "", &http.Response{StatusCode: 500}, "Internal Server Error")).Times(2)
+
+	// Errors are not cached.
+	c := NewCache(time.Hour)
+	for i := 0; i < 2; i++ {
+		_, err := c.Get(context.TODO(), skusMock, "123", "eastus")
+		g.Expect(err).To(MatchError("#: Internal Server Error: StatusCode=500"))
+	}
+}
+
+func TestNilCacheGet(t *testing.T) {
+	g := NewWithT(t)
+
+	skus := []compute.ResourceSku{{Name: to.StringPtr("Standard_D2s_v3")}}
+
+	mockCtrl := gomock.NewController(t)
+	skusMock := mock_resourceskus.NewMockClient(mockCtrl)
+	skusMock.EXPECT().ListComplete(context.TODO(), "location eq 'eastus'").Return(newIterator(g, skus), nil)
+	skusMock.EXPECT().ListComplete(context.TODO(), "location eq 'eastus'").Return(newIterator(g, skus), nil)
+
+	var c *Cache
+	for i := 0; i < 2; i++ {
+		result, err := c.Get(context.TODO(), skusMock, "123", "eastus")
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(result).To(Equal(skus))
+	}
+}
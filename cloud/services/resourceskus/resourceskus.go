@@ -0,0 +1,149 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resourceskus
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2020-06-01/compute"
+	"github.com/Azure/go-autorest/autorest/to"
+	"github.com/pkg/errors"
+)
+
+const (
+	// VirtualMachines is the resource type of the virtual machine SKUs.
+	VirtualMachines = "virtualMachines"
+)
+
+// Names of the capabilities of the virtual machine SKUs.
+const (
+	// VCPUs is the number of virtual CPUs of a VM size.
+	VCPUs = "vCPUs"
+	// MemoryGB is the memory of a VM size in GiB.
+	MemoryGB = "MemoryGB"
+	// PremiumIO is true if a VM size supports premium storage.
+	PremiumIO = "PremiumIO"
+	// AcceleratedNetworking is true if a VM size supports accelerated networking.
+	AcceleratedNetworking = "AcceleratedNetworkingEnabled"
+	// EphemeralOSDisk is true if a VM size supports ephemeral OS disks.
+	EphemeralOSDisk = "EphemeralOSDiskSupported"
+	// EncryptionAtHost is true if a VM size supports encryption at host.
+	EncryptionAtHost = "EncryptionAtHostSupported"
+	// MaxDataDiskCount is the maximum number of data disks of a VM size.
+	MaxDataDiskCount = "MaxDataDiskCount"
+	// MaxNetworkInterfaces is the maximum number of network interfaces of a VM size.
+	MaxNetworkInterfaces = "MaxNetworkInterfaces"
+)
+
+// Spec input specification for Get calls
+type Spec struct {
+	// ResourceType is the type of the resource, such as virtualMachines.
+	ResourceType string
+	// Name is the name of the SKU, such as Standard_D2s_v3.
+	Name string
+}
+
+// SKU is a resource SKU with helpers to read its capabilities.
+type SKU compute.ResourceSku
+
+// Get returns the SKU with the name and resource type if it is available in the location of the cluster,
+// or nil if it is not.
+func (s *Service) Get(ctx context.Context, spec interface{}) (interface{}, error) {
+	skuSpec, ok := spec.(*Spec)
+	if !ok {
+		return nil, errors.New("invalid resource sku specification")
+	}
+
+	skus, err := s.Cache.Get(ctx, s.Client, s.Scope.SubscriptionID, s.Scope.Location())
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list resource skus in location %s", s.Scope.Location())
+	}
+
+	for _, sku := range skus {
+		if sku.Name == nil || !strings.EqualFold(*sku.Name, skuSpec.Name) {
+			continue
+		}
+		if sku.ResourceType == nil || !strings.EqualFold(*sku.ResourceType, skuSpec.ResourceType) {
+			continue
+		}
+		if isRestrictedInLocation(sku) {
+			continue
+		}
+		return SKU(sku), nil
+	}
+
+	return nil, nil
+}
+
+// Reconcile no-op.
+func (s *Service) Reconcile(ctx context.Context, spec interface{}) error {
+	// Not implemented since there is nothing to reconcile
+	return nil
+}
+
+// Delete no-op.
+func (s *Service) Delete(ctx context.Context, spec interface{}) error {
+	// Not implemented since there is nothing to delete
+	return nil
+}
+
+// isRestrictedInLocation returns true if the SKU can't be used at all in its location by the subscription.
+func isRestrictedInLocation(sku compute.ResourceSku) bool {
+	if sku.Restrictions == nil {
+		return false
+	}
+	for _, restriction := range *sku.Restrictions {
+		if restriction.Type == compute.Location {
+			return true
+		}
+	}
+	return false
+}
+
+// GetCapability returns the value of the capability with the name, if the SKU has it.
+func (s SKU) GetCapability(name string) (string, bool) {
+	if s.Capabilities == nil {
+		return "", false
+	}
+	for _, capability := range *s.Capabilities {
+		if capability.Name != nil && *capability.Name == name && capability.Value != nil {
+			return *capability.Value, true
+		}
+	}
+	return "", false
+}
+
+// HasCapability returns true if the SKU has the boolean capability with the name.
+func (s SKU) HasCapability(name string) bool {
+	value, ok := s.GetCapability(name)
+	return ok && strings.EqualFold(value, "True")
+}
+
+// GetCapabilityQuantity returns the value of the numeric capability with the name, if the SKU has it.
+func (s SKU) GetCapabilityQuantity(name string) (float64, bool, error) {
+	value, ok := s.GetCapability(name)
+	if !ok {
+		return 0, false, nil
+	}
+	quantity, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, false, errors.Wrapf(err, "invalid value %q of capability %s of sku %s", value, name, to.String(s.Name))
+	}
+	return quantity, true, nil
+}
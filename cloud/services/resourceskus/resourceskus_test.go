@@ -0,0 +1,170 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resourceskus
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2020-06-01/compute"
+	network "github.com/Azure/azure-sdk-for-go/services/network/mgmt/2019-06-01/network"
+	"github.com/Azure/go-autorest/autorest"
+	"github.com/Azure/go-autorest/autorest/to"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1alpha3"
+	"sigs.k8s.io/cluster-api-provider-azure/cloud/scope"
+	"sigs.k8s.io/cluster-api-provider-azure/cloud/services/resourceskus/mock_resourceskus"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func init() {
+	clusterv1.AddToScheme(scheme.Scheme)
+}
+
+func TestGetResourceSku(t *testing.T) {
+	g := NewWithT(t)
+
+	skus := []compute.ResourceSku{
+		{
+			Name:         to.StringPtr("Standard_D2s_v3"),
+			ResourceType: to.StringPtr("virtualMachines"),
+			Capabilities: &[]compute.ResourceSkuCapabilities{
+				{Name: to.StringPtr(VCPUs), Value: to.StringPtr("2")},
+				{Name: to.StringPtr(PremiumIO), Value: to.StringPtr("True")},
+			},
+		},
+		{
+			Name:         to.StringPtr("Standard_F2"),
+			ResourceType: to.StringPtr("virtualMachines"),
+			Restrictions: &[]compute.ResourceSkuRestrictions{{Type: compute.Location}},
+		},
+		{
+			Name:         to.StringPtr("Premium_LRS"),
+			ResourceType: to.StringPtr("disks"),
+		},
+	}
+
+	testcases := []struct {
+		name        string
+		spec        Spec
+		expectedSKU *string
+	}{
+		{
+			name:        "available VM size",
+			spec:        Spec{ResourceType: VirtualMachines, Name: "standard_d2s_v3"},
+			expectedSKU: to.StringPtr("Standard_D2s_v3"),
+		},
+		{
+			name: "VM size restricted in the location",
+			spec: Spec{ResourceType: VirtualMachines, Name: "Standard_F2"},
+		},
+		{
+			name: "SKU of another resource type",
+			spec: Spec{ResourceType: VirtualMachines, Name: "Premium_LRS"},
+		},
+		{
+			name: "unknown VM size",
+			spec: Spec{ResourceType: VirtualMachines, Name: "Standard_Unknown"},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			skusMock := mock_resourceskus.NewMockClient(mockCtrl)
+			skusMock.EXPECT().ListComplete(context.TODO(), "location eq 'test-location'").Return(newIterator(g, skus), nil)
+
+			cluster := &clusterv1.Cluster{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-cluster"},
+			}
+
+			clusterScope, err := scope.NewClusterScope(scope.ClusterScopeParams{
+				AzureClients: scope.AzureClients{
+					SubscriptionID: "123",
+					Authorizer:     autorest.NullAuthorizer{},
+				},
+				Client:  fake.NewFakeClient(cluster),
+				Cluster: cluster,
+				AzureCluster: &infrav1.AzureCluster{
+					Spec: infrav1.AzureClusterSpec{
+						Location:      "test-location",
+						ResourceGroup: "my-rg",
+					},
+				},
+			})
+			g.Expect(err).NotTo(HaveOccurred())
+
+			s := &Service{
+				Scope:  clusterScope,
+				Client: skusMock,
+			}
+
+			result, err := s.Get(context.TODO(), &tc.spec)
+			g.Expect(err).NotTo(HaveOccurred())
+			if tc.expectedSKU == nil {
+				g.Expect(result).To(BeNil())
+				return
+			}
+			sku, ok := result.(SKU)
+			g.Expect(ok).To(BeTrue())
+			g.Expect(sku.Name).To(Equal(tc.expectedSKU))
+		})
+	}
+}
+
+func TestInvalidResourceSkuSpec(t *testing.T) {
+	g := NewWithT(t)
+
+	s := &Service{}
+	_, err := s.Get(context.TODO(), &network.PublicIPAddress{})
+	g.Expect(err).To(MatchError("invalid resource sku specification"))
+}
+
+func TestSKUCapabilities(t *testing.T) {
+	g := NewWithT(t)
+
+	sku := SKU{
+		Name: to.StringPtr("Standard_D2s_v3"),
+		Capabilities: &[]compute.ResourceSkuCapabilities{
+			{Name: to.StringPtr(VCPUs), Value: to.StringPtr("2")},
+			{Name: to.StringPtr(MemoryGB), Value: to.StringPtr("1.75")},
+			{Name: to.StringPtr(PremiumIO), Value: to.StringPtr("True")},
+			{Name: to.StringPtr(EphemeralOSDisk), Value: to.StringPtr("False")},
+			{Name: to.StringPtr(MaxDataDiskCount), Value: to.StringPtr("many")},
+		},
+	}
+
+	g.Expect(sku.HasCapability(PremiumIO)).To(BeTrue())
+	g.Expect(sku.HasCapability(EphemeralOSDisk)).To(BeFalse())
+	g.Expect(sku.HasCapability(AcceleratedNetworking)).To(BeFalse())
+
+	memory, ok, err := sku.GetCapabilityQuantity(MemoryGB)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(ok).To(BeTrue())
+	g.Expect(memory).To(Equal(1.75))
+
+	_, ok, err = sku.GetCapabilityQuantity(EncryptionAtHost)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(ok).To(BeFalse())
+
+	_, _, err = sku.GetCapabilityQuantity(MaxDataDiskCount)
+	g.Expect(err).To(HaveOccurred())
+}
@@ -0,0 +1,63 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package roleassignments
+
+import (
+	"context"
+
+	"github.com/Azure/azure-sdk-for-go/services/authorization/mgmt/2015-07-01/authorization"
+	"github.com/Azure/go-autorest/autorest"
+	azure "sigs.k8s.io/cluster-api-provider-azure/cloud"
+)
+
+// Client wraps go-sdk
+type Client interface {
+	Create(context.Context, string, string, authorization.RoleAssignmentCreateParameters) error
+	Delete(context.Context, string, string) error
+}
+
+// AzureClient contains the Azure go-sdk Client
+type AzureClient struct {
+	roleassignments authorization.RoleAssignmentsClient
+}
+
+var _ Client = &AzureClient{}
+
+// NewClient creates a new role assignments client from subscription ID.
+func NewClient(subscriptionID, baseURI string, authorizer autorest.Authorizer) *AzureClient {
+	c := newRoleAssignmentsClient(subscriptionID, baseURI, authorizer)
+	return &AzureClient{c}
+}
+
+// newRoleAssignmentsClient creates a new role assignments client from subscription ID.
+func newRoleAssignmentsClient(subscriptionID, baseURI string, authorizer autorest.Authorizer) authorization.RoleAssignmentsClient {
+	roleAssignmentsClient := authorization.NewRoleAssignmentsClientWithBaseURI(baseURI, subscriptionID)
+	roleAssignmentsClient.Authorizer = authorizer
+	roleAssignmentsClient.AddToUserAgent(azure.UserAgent)
+	return roleAssignmentsClient
+}
+
+// Create creates a role assignment.
+func (ac *AzureClient) Create(ctx context.Context, scope, roleAssignmentName string, parameters authorization.RoleAssignmentCreateParameters) error {
+	_, err := ac.roleassignments.Create(ctx, scope, roleAssignmentName, parameters)
+	return err
+}
+
+// Delete deletes a role assignment.
+func (ac *AzureClient) Delete(ctx context.Context, scope, roleAssignmentName string) error {
+	_, err := ac.roleassignments.Delete(ctx, scope, roleAssignmentName)
+	return err
+}
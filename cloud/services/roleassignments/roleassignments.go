@@ -0,0 +1,127 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package roleassignments
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/services/authorization/mgmt/2015-07-01/authorization"
+	"github.com/Azure/go-autorest/autorest/to"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"k8s.io/klog"
+	azure "sigs.k8s.io/cluster-api-provider-azure/cloud"
+)
+
+// Spec specification for role assignment
+type Spec struct {
+	// PrincipalID is the principal the role is assigned to.
+	PrincipalID string
+	// RoleDefinitionID is the GUID or the resource ID of the role definition.
+	RoleDefinitionID string
+	// Scope is the resource ID the role is assigned at. Defaults to the cluster resource group.
+	Scope string
+}
+
+// Get on role assignment is currently no-op.
+func (s *Service) Get(ctx context.Context, spec interface{}) (interface{}, error) {
+	return Spec{}, nil
+}
+
+// Reconcile creates the role assignment if it does not exist.
+func (s *Service) Reconcile(ctx context.Context, spec interface{}) error {
+	raSpec, ok := spec.(*Spec)
+	if !ok {
+		return errors.New("invalid role assignment specification")
+	}
+	if raSpec.PrincipalID == "" {
+		return errors.New("cannot create role assignment: principal ID is empty")
+	}
+
+	scope := s.getScope(raSpec)
+	roleDefinitionID := s.getRoleDefinitionID(raSpec)
+	name := generateName(raSpec.PrincipalID, roleDefinitionID, scope)
+
+	klog.V(2).Infof("creating role assignment %s at scope %s", name, scope)
+	err := s.Client.Create(ctx, scope, name, authorization.RoleAssignmentCreateParameters{
+		Properties: &authorization.RoleAssignmentProperties{
+			RoleDefinitionID: to.StringPtr(roleDefinitionID),
+			PrincipalID:      to.StringPtr(raSpec.PrincipalID),
+		},
+	})
+	if err != nil && azure.ResourceConflict(err) {
+		// already exists
+		return nil
+	}
+	if err != nil {
+		return errors.Wrapf(err, "failed to create role assignment %s at scope %s", name, scope)
+	}
+
+	klog.V(2).Infof("successfully created role assignment %s", name)
+	return nil
+}
+
+// Delete deletes the role assignment.
+func (s *Service) Delete(ctx context.Context, spec interface{}) error {
+	raSpec, ok := spec.(*Spec)
+	if !ok {
+		return errors.New("invalid role assignment specification")
+	}
+	if raSpec.PrincipalID == "" {
+		// the VM identity was never created, so neither was the role assignment
+		return nil
+	}
+
+	scope := s.getScope(raSpec)
+	name := generateName(raSpec.PrincipalID, s.getRoleDefinitionID(raSpec), scope)
+
+	klog.V(2).Infof("deleting role assignment %s at scope %s", name, scope)
+	err := s.Client.Delete(ctx, scope, name)
+	if err != nil && azure.ResourceNotFound(err) {
+		// already deleted
+		return nil
+	}
+	if err != nil {
+		return errors.Wrapf(err, "failed to delete role assignment %s at scope %s", name, scope)
+	}
+
+	klog.V(2).Infof("successfully deleted role assignment %s", name)
+	return nil
+}
+
+// getScope returns the scope of the role assignment, defaulting to the cluster resource group.
+func (s *Service) getScope(raSpec *Spec) string {
+	if raSpec.Scope != "" {
+		return raSpec.Scope
+	}
+	return fmt.Sprintf("/subscriptions/%s/resourceGroups/%s", s.Scope.SubscriptionID, s.Scope.ResourceGroup())
+}
+
+// getRoleDefinitionID returns the resource ID of the role definition of the role assignment.
+func (s *Service) getRoleDefinitionID(raSpec *Spec) string {
+	if strings.HasPrefix(raSpec.RoleDefinitionID, "/") {
+		return raSpec.RoleDefinitionID
+	}
+	return fmt.Sprintf("/subscriptions/%s/providers/Microsoft.Authorization/roleDefinitions/%s", s.Scope.SubscriptionID, raSpec.RoleDefinitionID)
+}
+
+// generateName returns a stable role assignment name, which must be a GUID, for a principal, role and scope.
+func generateName(principalID, roleDefinitionID, scope string) string {
+	return uuid.NewSHA1(uuid.NameSpaceURL, []byte(strings.Join([]string{principalID, roleDefinitionID, scope}, "/"))).String()
+}
@@ -0,0 +1,229 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package roleassignments
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/services/authorization/mgmt/2015-07-01/authorization"
+	"github.com/Azure/go-autorest/autorest"
+	"github.com/Azure/go-autorest/autorest/to"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1alpha3"
+	"sigs.k8s.io/cluster-api-provider-azure/cloud/scope"
+	"sigs.k8s.io/cluster-api-provider-azure/cloud/services/roleassignments/mock_roleassignments"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const (
+	contributorRoleID = "b24988ac-6180-42a0-ab88-20f7382dd24c"
+	contributorRole   = "/subscriptions/123/providers/Microsoft.Authorization/roleDefinitions/b24988ac-6180-42a0-ab88-20f7382dd24c"
+	resourceGroupID   = "/subscriptions/123/resourceGroups/my-rg"
+)
+
+func init() {
+	clusterv1.AddToScheme(scheme.Scheme)
+}
+
+func newTestService(t *testing.T, roleAssignmentsMock *mock_roleassignments.MockClient) *Service {
+	g := NewWithT(t)
+
+	cluster := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-cluster"},
+	}
+
+	client := fake.NewFakeClient(cluster)
+
+	clusterScope, err := scope.NewClusterScope(scope.ClusterScopeParams{
+		AzureClients: scope.AzureClients{
+			SubscriptionID: "123",
+			Authorizer:     autorest.NullAuthorizer{},
+		},
+		Client:  client,
+		Cluster: cluster,
+		AzureCluster: &infrav1.AzureCluster{
+			Spec: infrav1.AzureClusterSpec{
+				Location:      "test-location",
+				ResourceGroup: "my-rg",
+			},
+		},
+	})
+	g.Expect(err).NotTo(HaveOccurred())
+
+	return &Service{
+		Scope:  clusterScope,
+		Client: roleAssignmentsMock,
+	}
+}
+
+func TestReconcileRoleAssignment(t *testing.T) {
+	g := NewWithT(t)
+
+	testcases := []struct {
+		name          string
+		spec          Spec
+		expectedError string
+		expect        func(m *mock_roleassignments.MockClientMockRecorder)
+	}{
+		{
+			name: "assign role at the cluster resource group",
+			spec: Spec{
+				PrincipalID:      "principal",
+				RoleDefinitionID: contributorRoleID,
+			},
+			expectedError: "",
+			expect: func(m *mock_roleassignments.MockClientMockRecorder) {
+				m.Create(context.TODO(), resourceGroupID, generateName("principal", contributorRole, resourceGroupID), authorization.RoleAssignmentCreateParameters{
+					Properties: &authorization.RoleAssignmentProperties{
+						RoleDefinitionID: to.StringPtr(contributorRole),
+						PrincipalID:      to.StringPtr("principal"),
+					},
+				})
+			},
+		},
+		{
+			name: "assign role at a custom scope",
+			spec: Spec{
+				PrincipalID:      "principal",
+				RoleDefinitionID: contributorRole,
+				Scope:            "/subscriptions/123/resourceGroups/other-rg",
+			},
+			expectedError: "",
+			expect: func(m *mock_roleassignments.MockClientMockRecorder) {
+				m.Create(context.TODO(), "/subscriptions/123/resourceGroups/other-rg", gomock.Any(), gomock.Any())
+			},
+		},
+		{
+			name: "role already assigned",
+			spec: Spec{
+				PrincipalID:      "principal",
+				RoleDefinitionID: contributorRoleID,
+			},
+			expectedError: "",
+			expect: func(m *mock_roleassignments.MockClientMockRecorder) {
+				m.Create(context.TODO(), resourceGroupID, gomock.Any(), gomock.Any()).Return(autorest.NewErrorWithResponse("", "", &http.Response{StatusCode: 409}, "Conflict"))
+			},
+		},
+		{
+			name: "principal ID is missing",
+			spec: Spec{
+				RoleDefinitionID: contributorRoleID,
+			},
+			expectedError: "cannot create role assignment: principal ID is empty",
+			expect:        func(m *mock_roleassignments.MockClientMockRecorder) {},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			roleAssignmentsMock := mock_roleassignments.NewMockClient(mockCtrl)
+
+			tc.expect(roleAssignmentsMock.EXPECT())
+
+			s := newTestService(t, roleAssignmentsMock)
+
+			err := s.Reconcile(context.TODO(), &tc.spec)
+			if tc.expectedError != "" {
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(err).To(MatchError(tc.expectedError))
+			} else {
+				g.Expect(err).NotTo(HaveOccurred())
+			}
+		})
+	}
+}
+
+func TestDeleteRoleAssignment(t *testing.T) {
+	g := NewWithT(t)
+
+	name := generateName("principal", contributorRole, resourceGroupID)
+
+	testcases := []struct {
+		name          string
+		spec          Spec
+		expectedError string
+		expect        func(m *mock_roleassignments.MockClientMockRecorder)
+	}{
+		{
+			name: "delete the role assignment",
+			spec: Spec{
+				PrincipalID:      "principal",
+				RoleDefinitionID: contributorRoleID,
+			},
+			expectedError: "",
+			expect: func(m *mock_roleassignments.MockClientMockRecorder) {
+				m.Delete(context.TODO(), resourceGroupID, name)
+			},
+		},
+		{
+			name: "role assignment already deleted",
+			spec: Spec{
+				PrincipalID:      "principal",
+				RoleDefinitionID: contributorRoleID,
+			},
+			expectedError: "",
+			expect: func(m *mock_roleassignments.MockClientMockRecorder) {
+				m.Delete(context.TODO(), resourceGroupID, name).Return(autorest.NewErrorWithResponse("", "", &http.Response{StatusCode: 404}, "Not Found"))
+			},
+		},
+		{
+			name: "VM identity was never created",
+			spec: Spec{
+				RoleDefinitionID: contributorRoleID,
+			},
+			expectedError: "",
+			expect:        func(m *mock_roleassignments.MockClientMockRecorder) {},
+		},
+		{
+			name: "error while trying to delete the role assignment",
+			spec: Spec{
+				PrincipalID:      "principal",
+				RoleDefinitionID: contributorRoleID,
+			},
+			expectedError: "failed to delete role assignment " + name + " at scope " + resourceGroupID + ": #: Internal Server Error: StatusCode=500",
+			expect: func(m *mock_roleassignments.MockClientMockRecorder) {
+				m.Delete(context.TODO(), resourceGroupID, name).Return(autorest.NewErrorWithResponse("", "", &http.Response{StatusCode: 500}, "Internal Server Error"))
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			roleAssignmentsMock := mock_roleassignments.NewMockClient(mockCtrl)
+
+			tc.expect(roleAssignmentsMock.EXPECT())
+
+			s := newTestService(t, roleAssignmentsMock)
+
+			err := s.Delete(context.TODO(), &tc.spec)
+			if tc.expectedError != "" {
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(err).To(MatchError(tc.expectedError))
+			} else {
+				g.Expect(err).NotTo(HaveOccurred())
+			}
+		})
+	}
+}
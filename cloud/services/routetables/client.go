@@ -29,29 +29,41 @@ type Client interface {
 	Get(context.Context, string, string) (network.RouteTable, error)
 	CreateOrUpdate(context.Context, string, string, network.RouteTable) error
 	Delete(context.Context, string, string) error
+	CreateOrUpdateRoute(context.Context, string, string, string, network.Route) error
+	DeleteRoute(context.Context, string, string, string) error
 }
 
 // AzureClient contains the Azure go-sdk Client
 type AzureClient struct {
 	routetables network.RouteTablesClient
+	routes      network.RoutesClient
 }
 
 var _ Client = &AzureClient{}
 
 // NewClient creates a new VM client from subscription ID.
-func NewClient(subscriptionID string, authorizer autorest.Authorizer) *AzureClient {
-	c := newRouteTablesClient(subscriptionID, authorizer)
-	return &AzureClient{c}
+func NewClient(subscriptionID, baseURI string, authorizer autorest.Authorizer) *AzureClient {
+	c := newRouteTablesClient(subscriptionID, baseURI, authorizer)
+	r := newRoutesClient(subscriptionID, baseURI, authorizer)
+	return &AzureClient{c, r}
 }
 
 // newRouteTablesClient creates a new route tables client from subscription ID.
-func newRouteTablesClient(subscriptionID string, authorizer autorest.Authorizer) network.RouteTablesClient {
-	routeTablesClient := network.NewRouteTablesClientWithBaseURI(azure.DefaultBaseURI, subscriptionID)
+func newRouteTablesClient(subscriptionID, baseURI string, authorizer autorest.Authorizer) network.RouteTablesClient {
+	routeTablesClient := network.NewRouteTablesClientWithBaseURI(baseURI, subscriptionID)
 	routeTablesClient.Authorizer = authorizer
 	routeTablesClient.AddToUserAgent(azure.UserAgent)
 	return routeTablesClient
 }
 
+// newRoutesClient creates a new routes client from subscription ID.
+func newRoutesClient(subscriptionID, baseURI string, authorizer autorest.Authorizer) network.RoutesClient {
+	routesClient := network.NewRoutesClientWithBaseURI(baseURI, subscriptionID)
+	routesClient.Authorizer = authorizer
+	routesClient.AddToUserAgent(azure.UserAgent)
+	return routesClient
+}
+
 // Get gets the specified route table.
 func (ac *AzureClient) Get(ctx context.Context, resourceGroupName, rtName string) (network.RouteTable, error) {
 	return ac.routetables.Get(ctx, resourceGroupName, rtName, "")
@@ -84,3 +96,31 @@ func (ac *AzureClient) Delete(ctx context.Context, resourceGroupName, rtName str
 	_, err = future.Result(ac.routetables)
 	return err
 }
+
+// CreateOrUpdateRoute creates or updates a route in the specified route table.
+func (ac *AzureClient) CreateOrUpdateRoute(ctx context.Context, resourceGroupName, rtName, routeName string, route network.Route) error {
+	future, err := ac.routes.CreateOrUpdate(ctx, resourceGroupName, rtName, routeName, route)
+	if err != nil {
+		return err
+	}
+	err = future.WaitForCompletionRef(ctx, ac.routes.Client)
+	if err != nil {
+		return err
+	}
+	_, err = future.Result(ac.routes)
+	return err
+}
+
+// DeleteRoute deletes the specified route from the route table.
+func (ac *AzureClient) DeleteRoute(ctx context.Context, resourceGroupName, rtName, routeName string) error {
+	future, err := ac.routes.Delete(ctx, resourceGroupName, rtName, routeName)
+	if err != nil {
+		return err
+	}
+	err = future.WaitForCompletionRef(ctx, ac.routes.Client)
+	if err != nil {
+		return err
+	}
+	_, err = future.Result(ac.routes)
+	return err
+}
@@ -92,3 +92,31 @@ func (mr *MockClientMockRecorder) Delete(arg0, arg1, arg2 interface{}) *gomock.C
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockClient)(nil).Delete), arg0, arg1, arg2)
 }
+
+// CreateOrUpdateRoute mocks base method
+func (m *MockClient) CreateOrUpdateRoute(arg0 context.Context, arg1, arg2, arg3 string, arg4 network.Route) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateOrUpdateRoute", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateOrUpdateRoute indicates an expected call of CreateOrUpdateRoute
+func (mr *MockClientMockRecorder) CreateOrUpdateRoute(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOrUpdateRoute", reflect.TypeOf((*MockClient)(nil).CreateOrUpdateRoute), arg0, arg1, arg2, arg3, arg4)
+}
+
+// DeleteRoute mocks base method
+func (m *MockClient) DeleteRoute(arg0 context.Context, arg1, arg2, arg3 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteRoute", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteRoute indicates an expected call of DeleteRoute
+func (mr *MockClientMockRecorder) DeleteRoute(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteRoute", reflect.TypeOf((*MockClient)(nil).DeleteRoute), arg0, arg1, arg2, arg3)
+}
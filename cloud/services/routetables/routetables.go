@@ -18,17 +18,22 @@ package routetables
 
 import (
 	"context"
+	"strings"
 
 	"github.com/Azure/azure-sdk-for-go/services/network/mgmt/2019-06-01/network"
 	"github.com/Azure/go-autorest/autorest/to"
 	"github.com/pkg/errors"
 	"k8s.io/klog"
+	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1alpha3"
 	azure "sigs.k8s.io/cluster-api-provider-azure/cloud"
 )
 
 // Spec specification for route table.
 type Spec struct {
 	Name string
+	// Routes are the routes the route table must have. Other routes are left in place,
+	// unless they were applied before.
+	Routes infrav1.Routes
 }
 
 // Get provides information about a route table.
@@ -47,6 +52,8 @@ func (s *Service) Get(ctx context.Context, spec interface{}) (interface{}, error
 }
 
 // Reconcile gets/creates/updates a route table.
+// Routes of an existing route table are updated one by one, so that the routes other clients such as the
+// cloud provider add are kept. Only the routes it applied before but that are no longer desired are removed.
 func (s *Service) Reconcile(ctx context.Context, spec interface{}) error {
 	if !s.Scope.Vnet().IsManaged(s.Scope.Name()) {
 		s.Scope.V(4).Info("Skipping route tables reconcile in custom vnet mode")
@@ -56,14 +63,33 @@ func (s *Service) Reconcile(ctx context.Context, spec interface{}) error {
 	if !ok {
 		return errors.New("invalid Route Table Specification")
 	}
+
+	routes := make([]network.Route, 0, len(routeTableSpec.Routes))
+	for _, route := range routeTableSpec.Routes {
+		routes = append(routes, azureRoute(route))
+	}
+
+	existing, err := s.Client.Get(ctx, s.Scope.ResourceGroup(), routeTableSpec.Name)
+	if err != nil && !azure.ResourceNotFound(err) {
+		return errors.Wrapf(err, "failed to get route table %s in resource group %s", routeTableSpec.Name, s.Scope.ResourceGroup())
+	}
+	if err == nil {
+		if err := s.reconcileRoutes(ctx, routeTableSpec.Name, existing, routes); err != nil {
+			return err
+		}
+		return s.Scope.SetLastAppliedRoutes(routeTableSpec.Name, routeNames(routes))
+	}
+
 	klog.V(2).Infof("creating route table %s", routeTableSpec.Name)
-	err := s.Client.CreateOrUpdate(
+	err = s.Client.CreateOrUpdate(
 		ctx,
 		s.Scope.ResourceGroup(),
 		routeTableSpec.Name,
 		network.RouteTable{
-			Location:                   to.StringPtr(s.Scope.Location()),
-			RouteTablePropertiesFormat: &network.RouteTablePropertiesFormat{},
+			Location: to.StringPtr(s.Scope.Location()),
+			RouteTablePropertiesFormat: &network.RouteTablePropertiesFormat{
+				Routes: &routes,
+			},
 		},
 	)
 	if err != nil {
@@ -71,6 +97,51 @@ func (s *Service) Reconcile(ctx context.Context, spec interface{}) error {
 	}
 
 	klog.V(2).Infof("successfully created route table %s", routeTableSpec.Name)
+	return s.Scope.SetLastAppliedRoutes(routeTableSpec.Name, routeNames(routes))
+}
+
+// reconcileRoutes creates the desired routes missing from an existing route table, reverts those that were
+// changed out of band, and deletes the routes it applied before that are no longer desired.
+func (s *Service) reconcileRoutes(ctx context.Context, routeTableName string, existing network.RouteTable, routes []network.Route) error {
+	lastApplied, err := s.Scope.LastAppliedRoutes(routeTableName)
+	if err != nil {
+		return err
+	}
+	existingRoutes := make(map[string]network.Route)
+	if existing.RouteTablePropertiesFormat != nil && existing.Routes != nil {
+		for _, route := range *existing.Routes {
+			existingRoutes[to.String(route.Name)] = route
+		}
+	}
+
+	desired := make(map[string]bool, len(routes))
+	for _, route := range routes {
+		name := to.String(route.Name)
+		desired[name] = true
+		existingRoute, ok := existingRoutes[name]
+		if ok && routeEqual(existingRoute, route) {
+			continue
+		}
+		if ok {
+			klog.V(2).Infof("route %s of route table %s was changed out of band, reverting it", name, routeTableName)
+		} else {
+			klog.V(2).Infof("adding route %s to route table %s", name, routeTableName)
+		}
+		if err := s.Client.CreateOrUpdateRoute(ctx, s.Scope.ResourceGroup(), routeTableName, name, route); err != nil {
+			return errors.Wrapf(err, "failed to create route %s in route table %s", name, routeTableName)
+		}
+	}
+
+	for _, name := range lastApplied {
+		if _, ok := existingRoutes[name]; !ok || desired[name] {
+			continue
+		}
+		klog.V(2).Infof("removing route %s from route table %s", name, routeTableName)
+		err := s.Client.DeleteRoute(ctx, s.Scope.ResourceGroup(), routeTableName, name)
+		if err != nil && !azure.ResourceNotFound(err) {
+			return errors.Wrapf(err, "failed to delete route %s in route table %s", name, routeTableName)
+		}
+	}
 	return nil
 }
 
@@ -88,12 +159,47 @@ func (s *Service) Delete(ctx context.Context, spec interface{}) error {
 	err := s.Client.Delete(ctx, s.Scope.ResourceGroup(), routeTableSpec.Name)
 	if err != nil && azure.ResourceNotFound(err) {
 		// already deleted
-		return nil
+		return s.Scope.SetLastAppliedRoutes(routeTableSpec.Name, nil)
 	}
 	if err != nil {
 		return errors.Wrapf(err, "failed to delete route table %s in resource group %s", routeTableSpec.Name, s.Scope.ResourceGroup())
 	}
 
 	klog.V(2).Infof("successfully deleted route table %s", routeTableSpec.Name)
-	return nil
+	return s.Scope.SetLastAppliedRoutes(routeTableSpec.Name, nil)
+}
+
+// azureRoute converts a route of the cluster spec to an Azure route.
+func azureRoute(route infrav1.Route) network.Route {
+	azureRoute := network.Route{
+		Name: to.StringPtr(route.Name),
+		RoutePropertiesFormat: &network.RoutePropertiesFormat{
+			AddressPrefix: to.StringPtr(route.AddressPrefix),
+			NextHopType:   network.RouteNextHopType(route.NextHopType),
+		},
+	}
+	if route.NextHopIPAddress != "" {
+		azureRoute.NextHopIPAddress = to.StringPtr(route.NextHopIPAddress)
+	}
+	return azureRoute
+}
+
+// routeEqual returns whether an existing route matches the desired one.
+func routeEqual(existing, desired network.Route) bool {
+	a, b := existing.RoutePropertiesFormat, desired.RoutePropertiesFormat
+	if a == nil || b == nil {
+		return a == b
+	}
+	return to.String(a.AddressPrefix) == to.String(b.AddressPrefix) &&
+		strings.EqualFold(string(a.NextHopType), string(b.NextHopType)) &&
+		to.String(a.NextHopIPAddress) == to.String(b.NextHopIPAddress)
+}
+
+// routeNames returns the names of the routes.
+func routeNames(routes []network.Route) []string {
+	names := make([]string, 0, len(routes))
+	for _, route := range routes {
+		names = append(names, to.String(route.Name))
+	}
+	return names
 }
@@ -25,6 +25,7 @@ import (
 	"sigs.k8s.io/cluster-api-provider-azure/cloud/services/routetables/mock_routetables"
 
 	"github.com/Azure/go-autorest/autorest"
+	"github.com/Azure/go-autorest/autorest/to"
 	"github.com/golang/mock/gomock"
 
 	network "github.com/Azure/azure-sdk-for-go/services/network/mgmt/2019-06-01/network"
@@ -190,7 +191,9 @@ func TestReconcileRouteTables(t *testing.T) {
 		name           string
 		routetableSpec Spec
 		tags           infrav1.Tags
+		lastApplied    string
 		expectedError  string
+		expectedRoutes string
 		expect         func(m *mock_routetables.MockClientMockRecorder)
 	}{
 		{
@@ -220,6 +223,7 @@ func TestReconcileRouteTables(t *testing.T) {
 			},
 			expectedError: "",
 			expect: func(m *mock_routetables.MockClientMockRecorder) {
+				m.Get(context.TODO(), "my-rg", "my-routetable").Return(network.RouteTable{}, autorest.NewErrorWithResponse("", "", &http.Response{StatusCode: 404}, "Not found"))
 				m.CreateOrUpdate(context.TODO(), "my-rg", "my-routetable", gomock.AssignableToTypeOf(network.RouteTable{}))
 			},
 		},
@@ -235,9 +239,182 @@ func TestReconcileRouteTables(t *testing.T) {
 			},
 			expectedError: "failed to create route table my-routetable in resource group my-rg: #: Internal Server Error: StatusCode=500",
 			expect: func(m *mock_routetables.MockClientMockRecorder) {
+				m.Get(context.TODO(), "my-rg", "my-routetable").Return(network.RouteTable{}, autorest.NewErrorWithResponse("", "", &http.Response{StatusCode: 404}, "Not found"))
 				m.CreateOrUpdate(context.TODO(), "my-rg", "my-routetable", gomock.AssignableToTypeOf(network.RouteTable{})).Return(autorest.NewErrorWithResponse("", "", &http.Response{StatusCode: 500}, "Internal Server Error"))
 			},
 		},
+		{
+			name: "route table created with routes",
+			routetableSpec: Spec{
+				Name: "my-routetable",
+				Routes: infrav1.Routes{
+					{
+						Name:             "default-egress",
+						AddressPrefix:    "0.0.0.0/0",
+						NextHopType:      infrav1.RouteNextHopTypeVirtualAppliance,
+						NextHopIPAddress: "10.0.0.4",
+					},
+				},
+			},
+			tags: infrav1.Tags{
+				"Name": "my-vnet",
+				"sigs.k8s.io_cluster-api-provider-azure_cluster_test-cluster": "owned",
+				"sigs.k8s.io_cluster-api-provider-azure_role":                 "common",
+			},
+			expectedError:  "",
+			expectedRoutes: `{"my-routetable":["default-egress"]}`,
+			expect: func(m *mock_routetables.MockClientMockRecorder) {
+				m.Get(context.TODO(), "my-rg", "my-routetable").Return(network.RouteTable{}, autorest.NewErrorWithResponse("", "", &http.Response{StatusCode: 404}, "Not found"))
+				m.CreateOrUpdate(context.TODO(), "my-rg", "my-routetable", network.RouteTable{
+					Location: to.StringPtr("test-location"),
+					RouteTablePropertiesFormat: &network.RouteTablePropertiesFormat{
+						Routes: &[]network.Route{firewallRoute("10.0.0.4")},
+					},
+				})
+			},
+		},
+		{
+			name: "existing route table is up to date",
+			routetableSpec: Spec{
+				Name: "my-routetable",
+				Routes: infrav1.Routes{
+					{
+						Name:             "default-egress",
+						AddressPrefix:    "0.0.0.0/0",
+						NextHopType:      infrav1.RouteNextHopTypeVirtualAppliance,
+						NextHopIPAddress: "10.0.0.4",
+					},
+				},
+			},
+			tags: infrav1.Tags{
+				"Name": "my-vnet",
+				"sigs.k8s.io_cluster-api-provider-azure_cluster_test-cluster": "owned",
+				"sigs.k8s.io_cluster-api-provider-azure_role":                 "common",
+			},
+			lastApplied:    `{"my-routetable":["default-egress"]}`,
+			expectedError:  "",
+			expectedRoutes: `{"my-routetable":["default-egress"]}`,
+			expect: func(m *mock_routetables.MockClientMockRecorder) {
+				m.Get(context.TODO(), "my-rg", "my-routetable").Return(network.RouteTable{RouteTablePropertiesFormat: &network.RouteTablePropertiesFormat{Routes: &[]network.Route{firewallRoute("10.0.0.4")}}}, nil)
+			},
+		},
+		{
+			name: "route changed out of band is reverted",
+			routetableSpec: Spec{
+				Name: "my-routetable",
+				Routes: infrav1.Routes{
+					{
+						Name:             "default-egress",
+						AddressPrefix:    "0.0.0.0/0",
+						NextHopType:      infrav1.RouteNextHopTypeVirtualAppliance,
+						NextHopIPAddress: "10.0.0.4",
+					},
+				},
+			},
+			tags: infrav1.Tags{
+				"Name": "my-vnet",
+				"sigs.k8s.io_cluster-api-provider-azure_cluster_test-cluster": "owned",
+				"sigs.k8s.io_cluster-api-provider-azure_role":                 "common",
+			},
+			lastApplied:    `{"my-routetable":["default-egress"]}`,
+			expectedError:  "",
+			expectedRoutes: `{"my-routetable":["default-egress"]}`,
+			expect: func(m *mock_routetables.MockClientMockRecorder) {
+				m.Get(context.TODO(), "my-rg", "my-routetable").Return(network.RouteTable{RouteTablePropertiesFormat: &network.RouteTablePropertiesFormat{Routes: &[]network.Route{firewallRoute("10.0.0.5")}}}, nil)
+				m.CreateOrUpdateRoute(context.TODO(), "my-rg", "my-routetable", "default-egress", firewallRoute("10.0.0.4"))
+			},
+		},
+		{
+			name: "routes not owned by the controller are kept",
+			routetableSpec: Spec{
+				Name: "my-routetable",
+				Routes: infrav1.Routes{
+					{
+						Name:             "default-egress",
+						AddressPrefix:    "0.0.0.0/0",
+						NextHopType:      infrav1.RouteNextHopTypeVirtualAppliance,
+						NextHopIPAddress: "10.0.0.4",
+					},
+				},
+			},
+			tags: infrav1.Tags{
+				"Name": "my-vnet",
+				"sigs.k8s.io_cluster-api-provider-azure_cluster_test-cluster": "owned",
+				"sigs.k8s.io_cluster-api-provider-azure_role":                 "common",
+			},
+			expectedError:  "",
+			expectedRoutes: `{"my-routetable":["default-egress"]}`,
+			expect: func(m *mock_routetables.MockClientMockRecorder) {
+				m.Get(context.TODO(), "my-rg", "my-routetable").Return(network.RouteTable{RouteTablePropertiesFormat: &network.RouteTablePropertiesFormat{Routes: &[]network.Route{
+					{
+						Name: to.StringPtr("aks-node-0"),
+						RoutePropertiesFormat: &network.RoutePropertiesFormat{
+							AddressPrefix:    to.StringPtr("192.168.0.0/24"),
+							NextHopType:      network.RouteNextHopTypeVirtualAppliance,
+							NextHopIPAddress: to.StringPtr("10.1.0.4"),
+						},
+					},
+				}}}, nil)
+				m.CreateOrUpdateRoute(context.TODO(), "my-rg", "my-routetable", "default-egress", firewallRoute("10.0.0.4"))
+			},
+		},
+		{
+			name: "route removed from the spec is deleted",
+			routetableSpec: Spec{
+				Name: "my-routetable",
+			},
+			tags: infrav1.Tags{
+				"Name": "my-vnet",
+				"sigs.k8s.io_cluster-api-provider-azure_cluster_test-cluster": "owned",
+				"sigs.k8s.io_cluster-api-provider-azure_role":                 "common",
+			},
+			lastApplied:    `{"my-routetable":["default-egress"]}`,
+			expectedError:  "",
+			expectedRoutes: `{}`,
+			expect: func(m *mock_routetables.MockClientMockRecorder) {
+				m.Get(context.TODO(), "my-rg", "my-routetable").Return(network.RouteTable{RouteTablePropertiesFormat: &network.RouteTablePropertiesFormat{Routes: &[]network.Route{firewallRoute("10.0.0.4")}}}, nil)
+				m.DeleteRoute(context.TODO(), "my-rg", "my-routetable", "default-egress")
+			},
+		},
+		{
+			name: "fail to get a route table",
+			routetableSpec: Spec{
+				Name: "my-routetable",
+			},
+			tags: infrav1.Tags{
+				"Name": "my-vnet",
+				"sigs.k8s.io_cluster-api-provider-azure_cluster_test-cluster": "owned",
+				"sigs.k8s.io_cluster-api-provider-azure_role":                 "common",
+			},
+			expectedError: "failed to get route table my-routetable in resource group my-rg: #: Internal Server Error: StatusCode=500",
+			expect: func(m *mock_routetables.MockClientMockRecorder) {
+				m.Get(context.TODO(), "my-rg", "my-routetable").Return(network.RouteTable{}, autorest.NewErrorWithResponse("", "", &http.Response{StatusCode: 500}, "Internal Server Error"))
+			},
+		},
+		{
+			name: "fail to revert a route",
+			routetableSpec: Spec{
+				Name: "my-routetable",
+				Routes: infrav1.Routes{
+					{
+						Name:             "default-egress",
+						AddressPrefix:    "0.0.0.0/0",
+						NextHopType:      infrav1.RouteNextHopTypeVirtualAppliance,
+						NextHopIPAddress: "10.0.0.4",
+					},
+				},
+			},
+			tags: infrav1.Tags{
+				"Name": "my-vnet",
+				"sigs.k8s.io_cluster-api-provider-azure_cluster_test-cluster": "owned",
+				"sigs.k8s.io_cluster-api-provider-azure_role":                 "common",
+			},
+			expectedError: "failed to create route default-egress in route table my-routetable: #: Internal Server Error: StatusCode=500",
+			expect: func(m *mock_routetables.MockClientMockRecorder) {
+				m.Get(context.TODO(), "my-rg", "my-routetable").Return(network.RouteTable{RouteTablePropertiesFormat: &network.RouteTablePropertiesFormat{Routes: &[]network.Route{firewallRoute("10.0.0.5")}}}, nil)
+				m.CreateOrUpdateRoute(context.TODO(), "my-rg", "my-routetable", "default-egress", firewallRoute("10.0.0.4")).Return(autorest.NewErrorWithResponse("", "", &http.Response{StatusCode: 500}, "Internal Server Error"))
+			},
+		},
 	}
 
 	for _, tc := range testcases {
@@ -253,27 +430,32 @@ func TestReconcileRouteTables(t *testing.T) {
 
 			tc.expect(routetableMock.EXPECT())
 
+			azureCluster := &infrav1.AzureCluster{
+				Spec: infrav1.AzureClusterSpec{
+					Location: "test-location",
+					ResourceGroup: "my-rg",
+					NetworkSpec: infrav1.NetworkSpec{
+						Vnet: infrav1.VnetSpec{
+							ID:            "my-vnet-id",
+							Name:          "my-vnet",
+							ResourceGroup: "my-rg",
+							Tags:          tc.tags,
+						},
+					},
+				},
+			}
+			if tc.lastApplied != "" {
+				azureCluster.Annotations = map[string]string{infrav1.AnnotationRoutes: tc.lastApplied}
+			}
+
 			clusterScope, err := scope.NewClusterScope(scope.ClusterScopeParams{
 				AzureClients: scope.AzureClients{
 					SubscriptionID: "123",
 					Authorizer:     autorest.NullAuthorizer{},
 				},
-				Client:  client,
-				Cluster: cluster,
-				AzureCluster: &infrav1.AzureCluster{
-					Spec: infrav1.AzureClusterSpec{
-						Location: "test-location",
-						ResourceGroup: "my-rg",
-						NetworkSpec: infrav1.NetworkSpec{
-							Vnet: infrav1.VnetSpec{
-								ID:            "my-vnet-id",
-								Name:          "my-vnet",
-								ResourceGroup: "my-rg",
-								Tags:          tc.tags,
-							},
-						},
-					},
-				},
+				Client:       client,
+				Cluster:      cluster,
+				AzureCluster: azureCluster,
 			})
 			g.Expect(err).NotTo(HaveOccurred())
 
@@ -289,10 +471,24 @@ func TestReconcileRouteTables(t *testing.T) {
 			} else {
 				g.Expect(err).NotTo(HaveOccurred())
 			}
+			if tc.expectedRoutes != "" {
+				g.Expect(azureCluster.Annotations).To(HaveKeyWithValue(infrav1.AnnotationRoutes, tc.expectedRoutes))
+			}
 		})
 	}
 }
 
+func firewallRoute(nextHopIPAddress string) network.Route {
+	return network.Route{
+		Name: to.StringPtr("default-egress"),
+		RoutePropertiesFormat: &network.RoutePropertiesFormat{
+			AddressPrefix:    to.StringPtr("0.0.0.0/0"),
+			NextHopType:      network.RouteNextHopTypeVirtualAppliance,
+			NextHopIPAddress: to.StringPtr(nextHopIPAddress),
+		},
+	}
+}
+
 func TestDeleteRouteTable(t *testing.T) {
 	g := NewWithT(t)
 
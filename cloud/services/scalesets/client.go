@@ -0,0 +1,130 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scalesets
+
+import (
+	"context"
+
+	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2020-06-01/compute"
+	"github.com/Azure/go-autorest/autorest"
+	azure "sigs.k8s.io/cluster-api-provider-azure/cloud"
+)
+
+// Client wraps go-sdk
+type Client interface {
+	Get(context.Context, string, string) (compute.VirtualMachineScaleSet, error)
+	ListInstances(context.Context, string, string) ([]compute.VirtualMachineScaleSetVM, error)
+	CreateOrUpdate(context.Context, string, string, compute.VirtualMachineScaleSet) error
+	Update(context.Context, string, string, compute.VirtualMachineScaleSetUpdate) error
+	Delete(context.Context, string, string) error
+}
+
+// AzureClient contains the Azure go-sdk Client
+type AzureClient struct {
+	scalesets   compute.VirtualMachineScaleSetsClient
+	scalesetvms compute.VirtualMachineScaleSetVMsClient
+}
+
+var _ Client = &AzureClient{}
+
+// NewClient creates a new VMSS client from subscription ID.
+func NewClient(subscriptionID, baseURI string, authorizer autorest.Authorizer) *AzureClient {
+	return &AzureClient{
+		scalesets:   newVirtualMachineScaleSetsClient(subscriptionID, baseURI, authorizer),
+		scalesetvms: newVirtualMachineScaleSetVMsClient(subscriptionID, baseURI, authorizer),
+	}
+}
+
+// newVirtualMachineScaleSetsClient creates a new VMSS client from subscription ID.
+func newVirtualMachineScaleSetsClient(subscriptionID, baseURI string, authorizer autorest.Authorizer) compute.VirtualMachineScaleSetsClient {
+	c := compute.NewVirtualMachineScaleSetsClientWithBaseURI(baseURI, subscriptionID)
+	c.Authorizer = authorizer
+	c.AddToUserAgent(azure.UserAgent)
+	return c
+}
+
+// newVirtualMachineScaleSetVMsClient creates a new VMSS VM client from subscription ID.
+func newVirtualMachineScaleSetVMsClient(subscriptionID, baseURI string, authorizer autorest.Authorizer) compute.VirtualMachineScaleSetVMsClient {
+	c := compute.NewVirtualMachineScaleSetVMsClientWithBaseURI(baseURI, subscriptionID)
+	c.Authorizer = authorizer
+	c.AddToUserAgent(azure.UserAgent)
+	return c
+}
+
+// Get retrieves information about a virtual machine scale set.
+func (ac *AzureClient) Get(ctx context.Context, resourceGroupName, vmssName string) (compute.VirtualMachineScaleSet, error) {
+	return ac.scalesets.Get(ctx, resourceGroupName, vmssName)
+}
+
+// ListInstances lists all the virtual machines of a virtual machine scale set.
+func (ac *AzureClient) ListInstances(ctx context.Context, resourceGroupName, vmssName string) ([]compute.VirtualMachineScaleSetVM, error) {
+	itr, err := ac.scalesetvms.ListComplete(ctx, resourceGroupName, vmssName, "", "", "")
+	if err != nil {
+		return nil, err
+	}
+
+	var instances []compute.VirtualMachineScaleSetVM
+	for ; itr.NotDone(); err = itr.NextWithContext(ctx) {
+		if err != nil {
+			return nil, err
+		}
+		instances = append(instances, itr.Value())
+	}
+	return instances, nil
+}
+
+// CreateOrUpdate creates or updates a virtual machine scale set.
+func (ac *AzureClient) CreateOrUpdate(ctx context.Context, resourceGroupName, vmssName string, vmss compute.VirtualMachineScaleSet) error {
+	future, err := ac.scalesets.CreateOrUpdate(ctx, resourceGroupName, vmssName, vmss)
+	if err != nil {
+		return err
+	}
+	err = future.WaitForCompletionRef(ctx, ac.scalesets.Client)
+	if err != nil {
+		return err
+	}
+	_, err = future.Result(ac.scalesets)
+	return err
+}
+
+// Update updates the properties of a virtual machine scale set, such as its capacity.
+func (ac *AzureClient) Update(ctx context.Context, resourceGroupName, vmssName string, parameters compute.VirtualMachineScaleSetUpdate) error {
+	future, err := ac.scalesets.Update(ctx, resourceGroupName, vmssName, parameters)
+	if err != nil {
+		return err
+	}
+	err = future.WaitForCompletionRef(ctx, ac.scalesets.Client)
+	if err != nil {
+		return err
+	}
+	_, err = future.Result(ac.scalesets)
+	return err
+}
+
+// Delete deletes a virtual machine scale set and all its virtual machines.
+func (ac *AzureClient) Delete(ctx context.Context, resourceGroupName, vmssName string) error {
+	future, err := ac.scalesets.Delete(ctx, resourceGroupName, vmssName)
+	if err != nil {
+		return err
+	}
+	err = future.WaitForCompletionRef(ctx, ac.scalesets.Client)
+	if err != nil {
+		return err
+	}
+	_, err = future.Result(ac.scalesets)
+	return err
+}
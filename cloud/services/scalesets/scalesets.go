@@ -0,0 +1,235 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scalesets
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2020-06-01/compute"
+	"github.com/Azure/go-autorest/autorest/to"
+	"github.com/pkg/errors"
+	"k8s.io/klog"
+	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1alpha3"
+	azure "sigs.k8s.io/cluster-api-provider-azure/cloud"
+	"sigs.k8s.io/cluster-api-provider-azure/cloud/converters"
+	"sigs.k8s.io/cluster-api-provider-azure/cloud/services/virtualmachines"
+)
+
+// Spec input specification for Get/CreateOrUpdate/Delete calls
+type Spec struct {
+	Name       string
+	Sku        string
+	Capacity   int64
+	SSHKeyData string
+	Image      *infrav1.Image
+	OSDisk     infrav1.OSDisk
+	CustomData string
+	SubnetID   string
+}
+
+// Get provides information about a virtual machine scale set and its instances.
+func (s *Service) Get(ctx context.Context, spec interface{}) (interface{}, error) {
+	vmssSpec, ok := spec.(*Spec)
+	if !ok {
+		return nil, errors.New("invalid vmss specification")
+	}
+	vmss, err := s.Client.Get(ctx, s.Scope.ResourceGroup(), vmssSpec.Name)
+	if err != nil && azure.ResourceNotFound(err) {
+		return nil, errors.Wrapf(err, "vmss %s not found", vmssSpec.Name)
+	} else if err != nil {
+		return nil, err
+	}
+
+	instances, err := s.Client.ListInstances(ctx, s.Scope.ResourceGroup(), vmssSpec.Name)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list instances of vmss %s", vmssSpec.Name)
+	}
+
+	return converters.SDKToVMSS(vmss, instances), nil
+}
+
+// Reconcile creates a virtual machine scale set, or updates the model of an existing one with the current
+// capacity, size, image, bootstrap data and tags of the spec. Instances created from then on use the new model.
+func (s *Service) Reconcile(ctx context.Context, spec interface{}) error {
+	vmssSpec, ok := spec.(*Spec)
+	if !ok {
+		return errors.New("invalid vmss specification")
+	}
+
+	existing, err := s.Client.Get(ctx, s.Scope.ResourceGroup(), vmssSpec.Name)
+	if err != nil && !azure.ResourceNotFound(err) {
+		return errors.Wrapf(err, "failed to get vmss %s", vmssSpec.Name)
+	}
+	exists := err == nil
+
+	storageProfile, err := generateStorageProfile(*vmssSpec)
+	if err != nil {
+		return err
+	}
+
+	sshKeyData := vmssSpec.SSHKeyData
+	if sshKeyData == "" && exists {
+		// keep the key generated when the scale set was created, the SSH keys of a scale set can't be changed.
+		sshKeyData = getSSHKeyData(existing)
+	}
+	if sshKeyData == "" {
+		sshKeyData, err = virtualmachines.GenerateSSHKey()
+		if err != nil {
+			return err
+		}
+	}
+
+	// Make sure to use the MachinePoolScope here to get the merger of AzureCluster and AzureMachinePool tags
+	additionalTags := s.MachinePoolScope.AdditionalTags()
+	// Set the cloud provider tag
+	additionalTags[infrav1.ClusterAzureCloudProviderTagKey(s.MachinePoolScope.Name())] = string(infrav1.ResourceLifecycleOwned)
+
+	vmss := compute.VirtualMachineScaleSet{
+		Location: to.StringPtr(s.Scope.Location()),
+		Sku: &compute.Sku{
+			Name:     to.StringPtr(vmssSpec.Sku),
+			Tier:     to.StringPtr("Standard"),
+			Capacity: to.Int64Ptr(vmssSpec.Capacity),
+		},
+		Tags: converters.TagsToMap(infrav1.Build(infrav1.BuildParams{
+			ClusterName: s.Scope.Name(),
+			Lifecycle:   infrav1.ResourceLifecycleOwned,
+			Name:        to.StringPtr(s.MachinePoolScope.Name()),
+			Role:        to.StringPtr(s.MachinePoolScope.Role()),
+			Additional:  additionalTags,
+		})),
+		VirtualMachineScaleSetProperties: &compute.VirtualMachineScaleSetProperties{
+			UpgradePolicy: &compute.UpgradePolicy{
+				Mode: compute.UpgradeModeManual,
+			},
+			VirtualMachineProfile: &compute.VirtualMachineScaleSetVMProfile{
+				OsProfile: &compute.VirtualMachineScaleSetOSProfile{
+					ComputerNamePrefix: to.StringPtr(vmssSpec.Name),
+					AdminUsername:      to.StringPtr(azure.DefaultUserName),
+					CustomData:         to.StringPtr(vmssSpec.CustomData),
+					LinuxConfiguration: &compute.LinuxConfiguration{
+						DisablePasswordAuthentication: to.BoolPtr(true),
+						SSH: &compute.SSHConfiguration{
+							PublicKeys: &[]compute.SSHPublicKey{
+								{
+									Path:    to.StringPtr(fmt.Sprintf("/home/%s/.ssh/authorized_keys", azure.DefaultUserName)),
+									KeyData: to.StringPtr(sshKeyData),
+								},
+							},
+						},
+					},
+				},
+				StorageProfile: storageProfile,
+				NetworkProfile: &compute.VirtualMachineScaleSetNetworkProfile{
+					NetworkInterfaceConfigurations: &[]compute.VirtualMachineScaleSetNetworkConfiguration{
+						{
+							Name: to.StringPtr(vmssSpec.Name + "-netconfig"),
+							VirtualMachineScaleSetNetworkConfigurationProperties: &compute.VirtualMachineScaleSetNetworkConfigurationProperties{
+								Primary:            to.BoolPtr(true),
+								EnableIPForwarding: to.BoolPtr(true),
+								IPConfigurations: &[]compute.VirtualMachineScaleSetIPConfiguration{
+									{
+										Name: to.StringPtr(vmssSpec.Name + "-ipconfig"),
+										VirtualMachineScaleSetIPConfigurationProperties: &compute.VirtualMachineScaleSetIPConfigurationProperties{
+											Subnet: &compute.APIEntityReference{
+												ID: to.StringPtr(vmssSpec.SubnetID),
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	if exists {
+		klog.V(2).Infof("updating vmss %s", vmssSpec.Name)
+	} else {
+		klog.V(2).Infof("creating vmss %s", vmssSpec.Name)
+	}
+	err = s.Client.CreateOrUpdate(ctx, s.Scope.ResourceGroup(), vmssSpec.Name, vmss)
+	if err != nil {
+		return errors.Wrapf(err, "cannot create or update vmss %s", vmssSpec.Name)
+	}
+
+	klog.V(2).Infof("successfully created or updated vmss %s", vmssSpec.Name)
+	return nil
+}
+
+// Delete deletes the virtual machine scale set with the provided name.
+func (s *Service) Delete(ctx context.Context, spec interface{}) error {
+	vmssSpec, ok := spec.(*Spec)
+	if !ok {
+		return errors.New("invalid vmss specification")
+	}
+	klog.V(2).Infof("deleting vmss %s", vmssSpec.Name)
+	err := s.Client.Delete(ctx, s.Scope.ResourceGroup(), vmssSpec.Name)
+	if err != nil && azure.ResourceNotFound(err) {
+		// already deleted
+		return nil
+	}
+	if err != nil {
+		return errors.Wrapf(err, "failed to delete vmss %s in resource group %s", vmssSpec.Name, s.Scope.ResourceGroup())
+	}
+
+	klog.V(2).Infof("successfully deleted vmss %s", vmssSpec.Name)
+	return nil
+}
+
+// generateStorageProfile generates the storage profile of the virtual machines of a scale set.
+func generateStorageProfile(vmssSpec Spec) (*compute.VirtualMachineScaleSetStorageProfile, error) {
+	managedDisk := virtualmachines.GenerateManagedDiskParameters(vmssSpec.OSDisk.ManagedDisk)
+	storageProfile := &compute.VirtualMachineScaleSetStorageProfile{
+		OsDisk: &compute.VirtualMachineScaleSetOSDisk{
+			OsType:       compute.OperatingSystemTypes(vmssSpec.OSDisk.OSType),
+			CreateOption: compute.DiskCreateOptionTypesFromImage,
+			DiskSizeGB:   to.Int32Ptr(vmssSpec.OSDisk.DiskSizeGB),
+			ManagedDisk: &compute.VirtualMachineScaleSetManagedDiskParameters{
+				StorageAccountType: managedDisk.StorageAccountType,
+				DiskEncryptionSet:  managedDisk.DiskEncryptionSet,
+			},
+		},
+	}
+	storageProfile.OsDisk.DiffDiskSettings, storageProfile.OsDisk.Caching = virtualmachines.GenerateOSDiskSettings(vmssSpec.OSDisk)
+
+	imageRef, err := converters.ImageToSDK(vmssSpec.Image)
+	if err != nil {
+		return nil, err
+	}
+	storageProfile.ImageReference = imageRef
+
+	return storageProfile, nil
+}
+
+// getSSHKeyData returns the SSH public key of the instances of an existing scale set, if any.
+func getSSHKeyData(vmss compute.VirtualMachineScaleSet) string {
+	if vmss.VirtualMachineScaleSetProperties == nil || vmss.VirtualMachineProfile == nil ||
+		vmss.VirtualMachineProfile.OsProfile == nil || vmss.VirtualMachineProfile.OsProfile.LinuxConfiguration == nil ||
+		vmss.VirtualMachineProfile.OsProfile.LinuxConfiguration.SSH == nil || vmss.VirtualMachineProfile.OsProfile.LinuxConfiguration.SSH.PublicKeys == nil {
+		return ""
+	}
+	for _, key := range *vmss.VirtualMachineProfile.OsProfile.LinuxConfiguration.SSH.PublicKeys {
+		if key.KeyData != nil {
+			return *key.KeyData
+		}
+	}
+	return ""
+}
@@ -0,0 +1,312 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scalesets
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2020-06-01/compute"
+	"github.com/Azure/go-autorest/autorest"
+	"github.com/Azure/go-autorest/autorest/to"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/klog/klogr"
+	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1alpha3"
+	"sigs.k8s.io/cluster-api-provider-azure/cloud/scope"
+	"sigs.k8s.io/cluster-api-provider-azure/cloud/services/scalesets/mock_scalesets"
+	infrav1exp "sigs.k8s.io/cluster-api-provider-azure/exp/api/v1alpha3"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func init() {
+	clusterv1.AddToScheme(scheme.Scheme)
+}
+
+func newTestService(t *testing.T, scalesetsMock *mock_scalesets.MockClient) *Service {
+	g := NewWithT(t)
+
+	cluster := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-cluster"},
+	}
+	azureCluster := &infrav1.AzureCluster{
+		Spec: infrav1.AzureClusterSpec{
+			Location:      "test-location",
+			ResourceGroup: "my-rg",
+		},
+	}
+
+	clusterScope, err := scope.NewClusterScope(scope.ClusterScopeParams{
+		AzureClients: scope.AzureClients{
+			SubscriptionID: "123",
+			Authorizer:     autorest.NullAuthorizer{},
+		},
+		Client:       fake.NewFakeClient(cluster),
+		Cluster:      cluster,
+		AzureCluster: azureCluster,
+	})
+	g.Expect(err).NotTo(HaveOccurred())
+
+	return &Service{
+		Scope: clusterScope,
+		MachinePoolScope: &scope.MachinePoolScope{
+			Logger:       klogr.New(),
+			Cluster:      cluster,
+			AzureCluster: azureCluster,
+			AzureMachinePool: &infrav1exp.AzureMachinePool{
+				ObjectMeta: metav1.ObjectMeta{Name: "my-vmss"},
+			},
+		},
+		Client: scalesetsMock,
+	}
+}
+
+func TestInvalidVMSSSpec(t *testing.T) {
+	g := NewWithT(t)
+
+	mockCtrl := gomock.NewController(t)
+	s := newTestService(t, mock_scalesets.NewMockClient(mockCtrl))
+
+	_, err := s.Get(context.TODO(), &infrav1.VM{})
+	g.Expect(err).To(MatchError("invalid vmss specification"))
+	err = s.Reconcile(context.TODO(), &infrav1.VM{})
+	g.Expect(err).To(MatchError("invalid vmss specification"))
+	err = s.Delete(context.TODO(), &infrav1.VM{})
+	g.Expect(err).To(MatchError("invalid vmss specification"))
+}
+
+func TestGetVMSS(t *testing.T) {
+	g := NewWithT(t)
+
+	mockCtrl := gomock.NewController(t)
+	scalesetsMock := mock_scalesets.NewMockClient(mockCtrl)
+	s := newTestService(t, scalesetsMock)
+
+	scalesetsMock.EXPECT().Get(context.TODO(), "my-rg", "my-vmss").Return(compute.VirtualMachineScaleSet{
+		ID:   to.StringPtr("vmss-id"),
+		Name: to.StringPtr("my-vmss"),
+		Sku:  &compute.Sku{Name: to.StringPtr("Standard_D2s_v3"), Capacity: to.Int64Ptr(1)},
+		VirtualMachineScaleSetProperties: &compute.VirtualMachineScaleSetProperties{
+			ProvisioningState: to.StringPtr("Succeeded"),
+		},
+	}, nil)
+	scalesetsMock.EXPECT().ListInstances(context.TODO(), "my-rg", "my-vmss").Return([]compute.VirtualMachineScaleSetVM{
+		{
+			ID:         to.StringPtr("vmss-id/virtualMachines/0"),
+			InstanceID: to.StringPtr("0"),
+			Name:       to.StringPtr("my-vmss_0"),
+			VirtualMachineScaleSetVMProperties: &compute.VirtualMachineScaleSetVMProperties{
+				ProvisioningState:  to.StringPtr("Succeeded"),
+				LatestModelApplied: to.BoolPtr(true),
+				OsProfile:          &compute.OSProfile{ComputerName: to.StringPtr("my-vmss000000")},
+			},
+		},
+	}, nil)
+
+	vmss, err := s.Get(context.TODO(), &Spec{Name: "my-vmss"})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(vmss).To(Equal(&infrav1exp.VMSS{
+		ID:       "vmss-id",
+		Name:     "my-vmss",
+		Sku:      "Standard_D2s_v3",
+		Capacity: 1,
+		State:    infrav1.VMStateSucceeded,
+		Instances: []infrav1exp.VMSSVM{
+			{
+				ID:                 "vmss-id/virtualMachines/0",
+				InstanceID:         "0",
+				Name:               "my-vmss000000",
+				State:              infrav1.VMStateSucceeded,
+				LatestModelApplied: true,
+			},
+		},
+	}))
+}
+
+func TestReconcileVMSS(t *testing.T) {
+	g := NewWithT(t)
+
+	notFound := autorest.NewErrorWithResponse("", "", &http.Response{StatusCode: 404}, "Not Found")
+
+	testcases := []struct {
+		name          string
+		withoutSSHKey bool
+		expectedError string
+		expect        func(m *mock_scalesets.MockClientMockRecorder)
+	}{
+		{
+			name: "create a new vmss",
+			expect: func(m *mock_scalesets.MockClientMockRecorder) {
+				m.Get(context.TODO(), "my-rg", "my-vmss").Return(compute.VirtualMachineScaleSet{}, notFound)
+				m.CreateOrUpdate(context.TODO(), "my-rg", "my-vmss", gomock.AssignableToTypeOf(compute.VirtualMachineScaleSet{})).
+					Do(func(_ context.Context, _, _ string, vmss compute.VirtualMachineScaleSet) {
+						g.Expect(*vmss.Sku.Capacity).To(Equal(int64(2)))
+						g.Expect(*vmss.Sku.Name).To(Equal("Standard_D2s_v3"))
+						profile := vmss.VirtualMachineProfile
+						g.Expect(*profile.OsProfile.CustomData).To(Equal("bootstrap-data"))
+						g.Expect(profile.StorageProfile.OsDisk.Caching).To(Equal(compute.CachingTypesReadWrite))
+						g.Expect(*(*(*profile.NetworkProfile.NetworkInterfaceConfigurations)[0].IPConfigurations)[0].Subnet.ID).To(Equal("subnet-id"))
+					})
+			},
+		},
+		{
+			name: "update an existing vmss",
+			expect: func(m *mock_scalesets.MockClientMockRecorder) {
+				m.Get(context.TODO(), "my-rg", "my-vmss").Return(compute.VirtualMachineScaleSet{
+					Sku: &compute.Sku{Name: to.StringPtr("Standard_D4s_v3"), Tier: to.StringPtr("Standard"), Capacity: to.Int64Ptr(3)},
+				}, nil)
+				m.CreateOrUpdate(context.TODO(), "my-rg", "my-vmss", gomock.AssignableToTypeOf(compute.VirtualMachineScaleSet{})).
+					Do(func(_ context.Context, _, _ string, vmss compute.VirtualMachineScaleSet) {
+						g.Expect(*vmss.Sku.Capacity).To(Equal(int64(2)))
+						g.Expect(*vmss.Sku.Name).To(Equal("Standard_D2s_v3"))
+						profile := vmss.VirtualMachineProfile
+						g.Expect(*profile.OsProfile.CustomData).To(Equal("bootstrap-data"))
+						g.Expect(*profile.StorageProfile.ImageReference.Version).To(Equal("1.0.0"))
+						g.Expect((*profile.OsProfile.LinuxConfiguration.SSH.PublicKeys)[0].KeyData).To(Equal(to.StringPtr("ssh-rsa AAAA")))
+					})
+			},
+		},
+		{
+			name:          "keep the SSH key of an existing vmss",
+			withoutSSHKey: true,
+			expect: func(m *mock_scalesets.MockClientMockRecorder) {
+				m.Get(context.TODO(), "my-rg", "my-vmss").Return(compute.VirtualMachineScaleSet{
+					Sku: &compute.Sku{Name: to.StringPtr("Standard_D2s_v3"), Capacity: to.Int64Ptr(2)},
+					VirtualMachineScaleSetProperties: &compute.VirtualMachineScaleSetProperties{
+						VirtualMachineProfile: &compute.VirtualMachineScaleSetVMProfile{
+							OsProfile: &compute.VirtualMachineScaleSetOSProfile{
+								LinuxConfiguration: &compute.LinuxConfiguration{
+									SSH: &compute.SSHConfiguration{
+										PublicKeys: &[]compute.SSHPublicKey{{KeyData: to.StringPtr("ssh-rsa BBBB")}},
+									},
+								},
+							},
+						},
+					},
+				}, nil)
+				m.CreateOrUpdate(context.TODO(), "my-rg", "my-vmss", gomock.AssignableToTypeOf(compute.VirtualMachineScaleSet{})).
+					Do(func(_ context.Context, _, _ string, vmss compute.VirtualMachineScaleSet) {
+						g.Expect((*vmss.VirtualMachineProfile.OsProfile.LinuxConfiguration.SSH.PublicKeys)[0].KeyData).To(Equal(to.StringPtr("ssh-rsa BBBB")))
+					})
+			},
+		},
+		{
+			name:          "fail to get the vmss",
+			expectedError: "failed to get vmss my-vmss: #: Internal Server Error: StatusCode=500",
+			expect: func(m *mock_scalesets.MockClientMockRecorder) {
+				m.Get(context.TODO(), "my-rg", "my-vmss").Return(compute.VirtualMachineScaleSet{}, autorest.NewErrorWithResponse("", "", &http.Response{StatusCode: 500}, "Internal Server Error"))
+			},
+		},
+		{
+			name:          "fail to create the vmss",
+			expectedError: "cannot create or update vmss my-vmss: #: Internal Server Error: StatusCode=500",
+			expect: func(m *mock_scalesets.MockClientMockRecorder) {
+				m.Get(context.TODO(), "my-rg", "my-vmss").Return(compute.VirtualMachineScaleSet{}, notFound)
+				m.CreateOrUpdate(context.TODO(), "my-rg", "my-vmss", gomock.AssignableToTypeOf(compute.VirtualMachineScaleSet{})).
+					Return(autorest.NewErrorWithResponse("", "", &http.Response{StatusCode: 500}, "Internal Server Error"))
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			scalesetsMock := mock_scalesets.NewMockClient(mockCtrl)
+			s := newTestService(t, scalesetsMock)
+
+			tc.expect(scalesetsMock.EXPECT())
+
+			sshKeyData := "ssh-rsa AAAA"
+			if tc.withoutSSHKey {
+				sshKeyData = ""
+			}
+			err := s.Reconcile(context.TODO(), &Spec{
+				Name:       "my-vmss",
+				Sku:        "Standard_D2s_v3",
+				Capacity:   2,
+				SSHKeyData: sshKeyData,
+				Image: &infrav1.Image{
+					Marketplace: &infrav1.AzureMarketplaceImage{Publisher: "pub", Offer: "offer", SKU: "sku", Version: "1.0.0"},
+				},
+				OSDisk: infrav1.OSDisk{
+					OSType:      "Linux",
+					DiskSizeGB:  30,
+					ManagedDisk: infrav1.ManagedDisk{StorageAccountType: "Premium_LRS"},
+					CachingType: "ReadWrite",
+				},
+				CustomData: "bootstrap-data",
+				SubnetID:   "subnet-id",
+			})
+			if tc.expectedError != "" {
+				g.Expect(err).To(MatchError(tc.expectedError))
+			} else {
+				g.Expect(err).NotTo(HaveOccurred())
+			}
+		})
+	}
+}
+
+func TestDeleteVMSS(t *testing.T) {
+	g := NewWithT(t)
+
+	testcases := []struct {
+		name          string
+		expectedError string
+		expect        func(m *mock_scalesets.MockClientMockRecorder)
+	}{
+		{
+			name: "delete the vmss",
+			expect: func(m *mock_scalesets.MockClientMockRecorder) {
+				m.Delete(context.TODO(), "my-rg", "my-vmss")
+			},
+		},
+		{
+			name: "vmss already deleted",
+			expect: func(m *mock_scalesets.MockClientMockRecorder) {
+				m.Delete(context.TODO(), "my-rg", "my-vmss").Return(autorest.NewErrorWithResponse("", "", &http.Response{StatusCode: 404}, "Not Found"))
+			},
+		},
+		{
+			name:          "error while trying to delete the vmss",
+			expectedError: "failed to delete vmss my-vmss in resource group my-rg: #: Internal Server Error: StatusCode=500",
+			expect: func(m *mock_scalesets.MockClientMockRecorder) {
+				m.Delete(context.TODO(), "my-rg", "my-vmss").Return(autorest.NewErrorWithResponse("", "", &http.Response{StatusCode: 500}, "Internal Server Error"))
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			scalesetsMock := mock_scalesets.NewMockClient(mockCtrl)
+			s := newTestService(t, scalesetsMock)
+
+			tc.expect(scalesetsMock.EXPECT())
+
+			err := s.Delete(context.TODO(), &Spec{Name: "my-vmss"})
+			if tc.expectedError != "" {
+				g.Expect(err).To(MatchError(tc.expectedError))
+			} else {
+				g.Expect(err).NotTo(HaveOccurred())
+			}
+		})
+	}
+}
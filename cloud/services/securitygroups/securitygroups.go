@@ -19,11 +19,13 @@ package securitygroups
 import (
 	"context"
 	"strconv"
+	"strings"
 
 	"github.com/Azure/azure-sdk-for-go/services/network/mgmt/2019-06-01/network"
 	"github.com/Azure/go-autorest/autorest/to"
 	"github.com/pkg/errors"
 	"k8s.io/klog"
+	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1alpha3"
 	azure "sigs.k8s.io/cluster-api-provider-azure/cloud"
 )
 
@@ -31,6 +33,14 @@ import (
 type Spec struct {
 	Name           string
 	IsControlPlane bool
+	// IsBastion only allows inbound SSH, for the subnet of a bastion jumpbox.
+	IsBastion bool
+	// IngressRules are added to the default rules, replacing those with the same name.
+	IngressRules infrav1.IngressRules
+	// SSHAccess is the mode of SSH access to the machines of a control plane security group. Defaults to NAT.
+	SSHAccess infrav1.SSHAccessMode
+	// SSHSourceCIDRs restricts inbound SSH to the CIDR blocks, or allows it from any source when empty.
+	SSHSourceCIDRs []string
 }
 
 // Get provides information about a network security group.
@@ -49,6 +59,9 @@ func (s *Service) Get(ctx context.Context, spec interface{}) (interface{}, error
 }
 
 // Reconcile gets/creates/updates a network security group.
+// Rules of an existing security group that were changed or deleted out of band are reverted,
+// and rules it applied before but that are no longer desired are removed. Other rules, such
+// as those the cloud provider adds for services, are left in place.
 func (s *Service) Reconcile(ctx context.Context, spec interface{}) error {
 	if !s.Scope.Vnet().IsManaged(s.Scope.Name()) {
 		s.Scope.V(4).Info("Skipping network security group reconcile in custom vnet mode")
@@ -59,58 +72,44 @@ func (s *Service) Reconcile(ctx context.Context, spec interface{}) error {
 		return errors.New("invalid security groups specification")
 	}
 
-	securityRules := &[]network.SecurityRule{}
+	securityRules := s.securityRules(nsgSpec)
+	lastApplied, err := s.Scope.LastAppliedSecurityRules(nsgSpec.Name)
+	if err != nil {
+		return err
+	}
 
-	if nsgSpec.IsControlPlane {
-		klog.V(2).Infof("using additional rules for control plane %s", nsgSpec.Name)
-		securityRules = &[]network.SecurityRule{
-			{
-				Name: to.StringPtr("allow_ssh"),
-				SecurityRulePropertiesFormat: &network.SecurityRulePropertiesFormat{
-					Protocol:                 network.SecurityRuleProtocolTCP,
-					SourceAddressPrefix:      to.StringPtr("*"),
-					SourcePortRange:          to.StringPtr("*"),
-					DestinationAddressPrefix: to.StringPtr("*"),
-					DestinationPortRange:     to.StringPtr("22"),
-					Access:                   network.SecurityRuleAccessAllow,
-					Direction:                network.SecurityRuleDirectionInbound,
-					Priority:                 to.Int32Ptr(100),
-				},
-			},
-			{
-				Name: to.StringPtr("allow_6443"),
-				SecurityRulePropertiesFormat: &network.SecurityRulePropertiesFormat{
-					Protocol:                 network.SecurityRuleProtocolTCP,
-					SourceAddressPrefix:      to.StringPtr("*"),
-					SourcePortRange:          to.StringPtr("*"),
-					DestinationAddressPrefix: to.StringPtr("*"),
-					DestinationPortRange:     to.StringPtr(strconv.Itoa(int(s.Scope.APIServerPort()))),
-					Access:                   network.SecurityRuleAccessAllow,
-					Direction:                network.SecurityRuleDirectionInbound,
-					Priority:                 to.Int32Ptr(101),
-				},
-			},
+	securityGroup := network.SecurityGroup{
+		Location: to.StringPtr(s.Scope.Location()),
+		SecurityGroupPropertiesFormat: &network.SecurityGroupPropertiesFormat{
+			SecurityRules: &securityRules,
+		},
+	}
+	existing, err := s.Client.Get(ctx, s.Scope.ResourceGroup(), nsgSpec.Name)
+	switch {
+	case err != nil && !azure.ResourceNotFound(err):
+		return errors.Wrapf(err, "failed to get security group %s in resource group %s", nsgSpec.Name, s.Scope.ResourceGroup())
+	case err == nil:
+		var existingRules []network.SecurityRule
+		if existing.SecurityGroupPropertiesFormat != nil && existing.SecurityRules != nil {
+			existingRules = *existing.SecurityRules
+		}
+		rules, changed := diffSecurityRules(nsgSpec.Name, existingRules, securityRules, lastApplied)
+		if !changed {
+			klog.V(2).Infof("security group %s is up to date", nsgSpec.Name)
+			return s.Scope.SetLastAppliedSecurityRules(nsgSpec.Name, securityRuleNames(securityRules))
 		}
+		securityGroup.Tags = existing.Tags
+		securityGroup.SecurityRules = &rules
 	}
 
 	klog.V(2).Infof("creating security group %s", nsgSpec.Name)
-	err := s.Client.CreateOrUpdate(
-		ctx,
-		s.Scope.ResourceGroup(),
-		nsgSpec.Name,
-		network.SecurityGroup{
-			Location: to.StringPtr(s.Scope.Location()),
-			SecurityGroupPropertiesFormat: &network.SecurityGroupPropertiesFormat{
-				SecurityRules: securityRules,
-			},
-		},
-	)
+	err = s.Client.CreateOrUpdate(ctx, s.Scope.ResourceGroup(), nsgSpec.Name, securityGroup)
 	if err != nil {
 		return errors.Wrapf(err, "failed to create security group %s in resource group %s", nsgSpec.Name, s.Scope.ResourceGroup())
 	}
 
 	klog.V(2).Infof("created security group %s", nsgSpec.Name)
-	return err
+	return s.Scope.SetLastAppliedSecurityRules(nsgSpec.Name, securityRuleNames(securityRules))
 }
 
 // Delete deletes the network security group with the provided name.
@@ -123,12 +122,240 @@ func (s *Service) Delete(ctx context.Context, spec interface{}) error {
 	err := s.Client.Delete(ctx, s.Scope.ResourceGroup(), nsgSpec.Name)
 	if err != nil && azure.ResourceNotFound(err) {
 		// already deleted
-		return nil
+		return s.Scope.SetLastAppliedSecurityRules(nsgSpec.Name, nil)
 	}
 	if err != nil {
 		return errors.Wrapf(err, "failed to delete security group %s in resource group %s", nsgSpec.Name, s.Scope.ResourceGroup())
 	}
 
 	klog.V(2).Infof("deleted security group %s", nsgSpec.Name)
-	return nil
+	return s.Scope.SetLastAppliedSecurityRules(nsgSpec.Name, nil)
+}
+
+// sshSecurityRule returns the security rule that allows inbound SSH traffic from the source CIDR blocks,
+// or from any source when there are none.
+func sshSecurityRule(sourceCIDRs []string) network.SecurityRule {
+	rule := network.SecurityRule{
+		Name: to.StringPtr("allow_ssh"),
+		SecurityRulePropertiesFormat: &network.SecurityRulePropertiesFormat{
+			Protocol:                 network.SecurityRuleProtocolTCP,
+			SourceAddressPrefix:      to.StringPtr("*"),
+			SourcePortRange:          to.StringPtr("*"),
+			DestinationAddressPrefix: to.StringPtr("*"),
+			DestinationPortRange:     to.StringPtr("22"),
+			Access:                   network.SecurityRuleAccessAllow,
+			Direction:                network.SecurityRuleDirectionInbound,
+			Priority:                 to.Int32Ptr(100),
+		},
+	}
+	if len(sourceCIDRs) == 1 {
+		rule.SourceAddressPrefix = to.StringPtr(sourceCIDRs[0])
+	} else if len(sourceCIDRs) > 1 {
+		rule.SourceAddressPrefix = nil
+		rule.SourceAddressPrefixes = &sourceCIDRs
+	}
+	return rule
+}
+
+// apiServerSecurityRule returns the security rule that allows inbound traffic to the API server port.
+func apiServerSecurityRule(port int32) network.SecurityRule {
+	return network.SecurityRule{
+		Name: to.StringPtr("allow_6443"),
+		SecurityRulePropertiesFormat: &network.SecurityRulePropertiesFormat{
+			Protocol:                 network.SecurityRuleProtocolTCP,
+			SourceAddressPrefix:      to.StringPtr("*"),
+			SourcePortRange:          to.StringPtr("*"),
+			DestinationAddressPrefix: to.StringPtr("*"),
+			DestinationPortRange:     to.StringPtr(strconv.Itoa(int(port))),
+			Access:                   network.SecurityRuleAccessAllow,
+			Direction:                network.SecurityRuleDirectionInbound,
+			Priority:                 to.Int32Ptr(101),
+		},
+	}
+}
+
+// denySSHSecurityRule returns the security rule that denies the inbound SSH traffic no other rule allows.
+func denySSHSecurityRule() network.SecurityRule {
+	return network.SecurityRule{
+		Name: to.StringPtr("deny_ssh"),
+		SecurityRulePropertiesFormat: &network.SecurityRulePropertiesFormat{
+			Protocol:                 network.SecurityRuleProtocolTCP,
+			SourceAddressPrefix:      to.StringPtr("*"),
+			SourcePortRange:          to.StringPtr("*"),
+			DestinationAddressPrefix: to.StringPtr("*"),
+			DestinationPortRange:     to.StringPtr("22"),
+			Access:                   network.SecurityRuleAccessDeny,
+			Direction:                network.SecurityRuleDirectionInbound,
+			Priority:                 to.Int32Ptr(4096),
+		},
+	}
+}
+
+// securityRules returns the desired security rules of a network security group.
+func (s *Service) securityRules(nsgSpec *Spec) []network.SecurityRule {
+	securityRules := []network.SecurityRule{}
+	if nsgSpec.IsBastion {
+		klog.V(2).Infof("using additional rules for bastion %s", nsgSpec.Name)
+		securityRules = append(securityRules, sshSecurityRule(nil))
+	} else if nsgSpec.IsControlPlane {
+		klog.V(2).Infof("using additional rules for control plane %s", nsgSpec.Name)
+		switch nsgSpec.SSHAccess {
+		case infrav1.SSHAccessDisabled:
+			klog.V(2).Infof("SSH access is disabled for control plane %s", nsgSpec.Name)
+		case infrav1.SSHAccessBastionOnly:
+			// the default rules of Azure allow SSH from the whole vnet
+			securityRules = append(securityRules, sshSecurityRule(nsgSpec.SSHSourceCIDRs), denySSHSecurityRule())
+		default:
+			securityRules = append(securityRules, sshSecurityRule(nsgSpec.SSHSourceCIDRs))
+		}
+		securityRules = append(securityRules, apiServerSecurityRule(s.Scope.APIServerPort()))
+	}
+
+	for _, ingressRule := range nsgSpec.IngressRules {
+		if ingressRule == nil {
+			continue
+		}
+		rule := securityRule(ingressRule)
+		replaced := false
+		for i := range securityRules {
+			if to.String(securityRules[i].Name) == ingressRule.Name {
+				securityRules[i] = rule
+				replaced = true
+			}
+		}
+		if !replaced {
+			securityRules = append(securityRules, rule)
+		}
+	}
+	return securityRules
+}
+
+// securityRule converts a security rule of the cluster spec to an Azure security rule.
+func securityRule(rule *infrav1.IngressRule) network.SecurityRule {
+	protocol := network.SecurityRuleProtocol(rule.Protocol)
+	if protocol == "" {
+		protocol = network.SecurityRuleProtocolAsterisk
+	}
+	direction := network.SecurityRuleDirectionInbound
+	if rule.Direction == infrav1.SecurityRuleDirectionOutbound {
+		direction = network.SecurityRuleDirectionOutbound
+	}
+	var description *string
+	if rule.Description != "" {
+		description = to.StringPtr(rule.Description)
+	}
+	return network.SecurityRule{
+		Name: to.StringPtr(rule.Name),
+		SecurityRulePropertiesFormat: &network.SecurityRulePropertiesFormat{
+			Description:              description,
+			Protocol:                 protocol,
+			SourceAddressPrefix:      wildcardIfEmpty(rule.Source),
+			SourcePortRange:          wildcardIfEmpty(rule.SourcePorts),
+			DestinationAddressPrefix: wildcardIfEmpty(rule.Destination),
+			DestinationPortRange:     wildcardIfEmpty(rule.DestinationPorts),
+			Access:                   network.SecurityRuleAccessAllow,
+			Direction:                direction,
+			Priority:                 to.Int32Ptr(rule.Priority),
+		},
+	}
+}
+
+// wildcardIfEmpty returns the value, or the '*' wildcard when it is unset.
+func wildcardIfEmpty(value *string) *string {
+	if value == nil || *value == "" {
+		return to.StringPtr("*")
+	}
+	return value
+}
+
+// diffSecurityRules compares the existing rules of a network security group with the desired ones and returns
+// the rules to apply, and whether they differ from the existing ones. Desired rules that are missing or were
+// changed out of band are reverted, and rules in lastApplied that are no longer desired are removed.
+func diffSecurityRules(nsgName string, existing, desired []network.SecurityRule, lastApplied []string) ([]network.SecurityRule, bool) {
+	desiredRules := make(map[string]network.SecurityRule, len(desired))
+	for _, rule := range desired {
+		desiredRules[to.String(rule.Name)] = rule
+	}
+	applied := make(map[string]bool, len(lastApplied))
+	for _, name := range lastApplied {
+		applied[name] = true
+	}
+
+	rules := []network.SecurityRule{}
+	found := make(map[string]bool, len(existing))
+	changed := false
+	for _, rule := range existing {
+		name := to.String(rule.Name)
+		found[name] = true
+		desiredRule, ok := desiredRules[name]
+		switch {
+		case ok && !securityRuleEqual(rule, desiredRule):
+			klog.V(2).Infof("security rule %s of security group %s was changed out of band, reverting it", name, nsgName)
+			rules = append(rules, desiredRule)
+			changed = true
+		case ok:
+			rules = append(rules, rule)
+		case applied[name]:
+			klog.V(2).Infof("removing security rule %s from security group %s", name, nsgName)
+			changed = true
+		default:
+			klog.V(4).Infof("leaving unmanaged security rule %s of security group %s in place", name, nsgName)
+			rules = append(rules, rule)
+		}
+	}
+	for _, rule := range desired {
+		name := to.String(rule.Name)
+		if found[name] {
+			continue
+		}
+		if applied[name] {
+			klog.V(2).Infof("security rule %s of security group %s was deleted out of band, restoring it", name, nsgName)
+		} else {
+			klog.V(2).Infof("adding security rule %s to security group %s", name, nsgName)
+		}
+		rules = append(rules, rule)
+		changed = true
+	}
+	return rules, changed
+}
+
+// securityRuleEqual returns whether an existing security rule matches the desired one.
+func securityRuleEqual(existing, desired network.SecurityRule) bool {
+	a, b := existing.SecurityRulePropertiesFormat, desired.SecurityRulePropertiesFormat
+	if a == nil || b == nil {
+		return a == b
+	}
+	return strings.EqualFold(string(a.Protocol), string(b.Protocol)) &&
+		strings.EqualFold(string(a.Access), string(b.Access)) &&
+		strings.EqualFold(string(a.Direction), string(b.Direction)) &&
+		to.Int32(a.Priority) == to.Int32(b.Priority) &&
+		to.String(a.Description) == to.String(b.Description) &&
+		to.String(a.SourceAddressPrefix) == to.String(b.SourceAddressPrefix) &&
+		stringSlicesEqual(to.StringSlice(a.SourceAddressPrefixes), to.StringSlice(b.SourceAddressPrefixes)) &&
+		to.String(a.SourcePortRange) == to.String(b.SourcePortRange) &&
+		to.String(a.DestinationAddressPrefix) == to.String(b.DestinationAddressPrefix) &&
+		to.String(a.DestinationPortRange) == to.String(b.DestinationPortRange)
+}
+
+// securityRuleNames returns the names of the security rules.
+func securityRuleNames(rules []network.SecurityRule) []string {
+	names := make([]string, 0, len(rules))
+	for _, rule := range rules {
+		names = append(names, to.String(rule.Name))
+	}
+	return names
+}
+
+// stringSlicesEqual returns whether two slices have the same elements in the same order,
+// an empty slice being equal to nil.
+func stringSlicesEqual(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
 }
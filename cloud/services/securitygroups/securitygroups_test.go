@@ -25,6 +25,7 @@ import (
 	"sigs.k8s.io/cluster-api-provider-azure/cloud/services/securitygroups/mock_securitygroups"
 
 	"github.com/Azure/go-autorest/autorest"
+	"github.com/Azure/go-autorest/autorest/to"
 	"github.com/golang/mock/gomock"
 
 	"github.com/Azure/azure-sdk-for-go/services/network/mgmt/2019-06-01/network"
@@ -43,11 +44,52 @@ func init() {
 func TestReconcileSecurityGroups(t *testing.T) {
 	g := NewWithT(t)
 
+	notFound := autorest.NewErrorWithResponse("", "", &http.Response{StatusCode: 404}, "Not found")
+	nodePortsRule := &infrav1.IngressRule{
+		Name:             "allow_node_ports",
+		Protocol:         infrav1.SecurityGroupProtocolTCP,
+		DestinationPorts: to.StringPtr("30000-32767"),
+		Priority:         200,
+	}
+	nodePortsSecurityRule := securityRule(nodePortsRule)
+	modifiedNodePortsSecurityRule := securityRule(nodePortsRule)
+	modifiedNodePortsSecurityRule.DestinationPortRange = to.StringPtr("*")
+	unmanagedSecurityRule := network.SecurityRule{
+		Name: to.StringPtr("a1b2c3-TCP-80-Internet"),
+		SecurityRulePropertiesFormat: &network.SecurityRulePropertiesFormat{
+			Protocol:             network.SecurityRuleProtocolTCP,
+			DestinationPortRange: to.StringPtr("80"),
+			Access:               network.SecurityRuleAccessAllow,
+			Direction:            network.SecurityRuleDirectionInbound,
+			Priority:             to.Int32Ptr(500),
+		},
+	}
+	securityGroup := func(rules ...network.SecurityRule) network.SecurityGroup {
+		return network.SecurityGroup{
+			Name: to.StringPtr("my-sg"),
+			SecurityGroupPropertiesFormat: &network.SecurityGroupPropertiesFormat{
+				SecurityRules: &rules,
+			},
+		}
+	}
+	expectRules := func(names ...string) func(context.Context, string, string, network.SecurityGroup) {
+		return func(_ context.Context, _, _ string, sg network.SecurityGroup) {
+			g.Expect(securityRuleNames(*sg.SecurityRules)).To(Equal(names))
+		}
+	}
+
 	testcases := []struct {
 		name           string
 		sgName         string
 		isControlPlane bool
+		isBastion      bool
+		ingressRules   infrav1.IngressRules
+		sshAccess      infrav1.SSHAccessMode
+		sshSourceCIDRs []string
+		lastApplied    string
 		vnetSpec       *infrav1.VnetSpec
+		expectErr      bool
+		expectApplied  []string
 		expect         func(m *mock_securitygroups.MockClientMockRecorder)
 	}{
 		{
@@ -55,8 +97,11 @@ func TestReconcileSecurityGroups(t *testing.T) {
 			sgName:         "my-sg",
 			isControlPlane: true,
 			vnetSpec:       &infrav1.VnetSpec{},
+			expectApplied:  []string{"allow_ssh", "allow_6443"},
 			expect: func(m *mock_securitygroups.MockClientMockRecorder) {
-				m.CreateOrUpdate(context.TODO(), "my-rg", "my-sg", gomock.AssignableToTypeOf(network.SecurityGroup{}))
+				m.Get(context.TODO(), "my-rg", "my-sg").Return(network.SecurityGroup{}, notFound)
+				m.CreateOrUpdate(context.TODO(), "my-rg", "my-sg", gomock.AssignableToTypeOf(network.SecurityGroup{})).
+					Do(expectRules("allow_ssh", "allow_6443"))
 			},
 		}, {
 			name:           "security group does not exist and it's not for a control plane",
@@ -64,8 +109,169 @@ func TestReconcileSecurityGroups(t *testing.T) {
 			isControlPlane: false,
 			vnetSpec:       &infrav1.VnetSpec{},
 			expect: func(m *mock_securitygroups.MockClientMockRecorder) {
-				m.CreateOrUpdate(context.TODO(), "my-rg", "my-sg", gomock.AssignableToTypeOf(network.SecurityGroup{}))
+				m.Get(context.TODO(), "my-rg", "my-sg").Return(network.SecurityGroup{}, notFound)
+				m.CreateOrUpdate(context.TODO(), "my-rg", "my-sg", gomock.AssignableToTypeOf(network.SecurityGroup{})).
+					Do(func(_ context.Context, _, _ string, sg network.SecurityGroup) {
+						g.Expect(*sg.SecurityRules).To(BeEmpty())
+					})
+			},
+		}, {
+			name:      "security group does not exist and it's for a bastion",
+			sgName:    "my-bastion-sg",
+			isBastion: true,
+			vnetSpec:  &infrav1.VnetSpec{},
+			expect: func(m *mock_securitygroups.MockClientMockRecorder) {
+				m.Get(context.TODO(), "my-rg", "my-bastion-sg").Return(network.SecurityGroup{}, notFound)
+				m.CreateOrUpdate(context.TODO(), "my-rg", "my-bastion-sg", gomock.AssignableToTypeOf(network.SecurityGroup{})).
+					Do(func(_ context.Context, _, _ string, sg network.SecurityGroup) {
+						g.Expect(*sg.SecurityRules).To(HaveLen(1))
+						g.Expect((*sg.SecurityRules)[0].Name).To(Equal(to.StringPtr("allow_ssh")))
+					})
+			},
+		}, {
+			name:   "security group does not exist and has user-defined rules",
+			sgName: "my-sg",
+			ingressRules: infrav1.IngressRules{
+				nodePortsRule,
+				{
+					Name:        "deny_internet",
+					Protocol:    infrav1.SecurityGroupProtocolAll,
+					Destination: to.StringPtr("Internet"),
+					Priority:    4000,
+					Direction:   infrav1.SecurityRuleDirectionOutbound,
+				},
+			},
+			vnetSpec:      &infrav1.VnetSpec{},
+			expectApplied: []string{"allow_node_ports", "deny_internet"},
+			expect: func(m *mock_securitygroups.MockClientMockRecorder) {
+				m.Get(context.TODO(), "my-rg", "my-sg").Return(network.SecurityGroup{}, notFound)
+				m.CreateOrUpdate(context.TODO(), "my-rg", "my-sg", gomock.AssignableToTypeOf(network.SecurityGroup{})).
+					Do(func(_ context.Context, _, _ string, sg network.SecurityGroup) {
+						g.Expect(*sg.SecurityRules).To(HaveLen(2))
+						g.Expect((*sg.SecurityRules)[0]).To(Equal(nodePortsSecurityRule))
+						egress := (*sg.SecurityRules)[1].SecurityRulePropertiesFormat
+						g.Expect(egress.Protocol).To(Equal(network.SecurityRuleProtocolAsterisk))
+						g.Expect(egress.DestinationAddressPrefix).To(Equal(to.StringPtr("Internet")))
+						g.Expect(egress.SourceAddressPrefix).To(Equal(to.StringPtr("*")))
+						g.Expect(egress.Direction).To(Equal(network.SecurityRuleDirectionOutbound))
+						g.Expect(egress.Priority).To(Equal(to.Int32Ptr(4000)))
+					})
+			},
+		}, {
+			name:           "user-defined rule replaces a default rule",
+			sgName:         "my-sg",
+			isControlPlane: true,
+			ingressRules: infrav1.IngressRules{
+				{Name: "allow_ssh", Protocol: infrav1.SecurityGroupProtocolTCP, Source: to.StringPtr("10.0.0.0/8"), DestinationPorts: to.StringPtr("22"), Priority: 100},
+			},
+			vnetSpec: &infrav1.VnetSpec{},
+			expect: func(m *mock_securitygroups.MockClientMockRecorder) {
+				m.Get(context.TODO(), "my-rg", "my-sg").Return(network.SecurityGroup{}, notFound)
+				m.CreateOrUpdate(context.TODO(), "my-rg", "my-sg", gomock.AssignableToTypeOf(network.SecurityGroup{})).
+					Do(func(_ context.Context, _, _ string, sg network.SecurityGroup) {
+						g.Expect(securityRuleNames(*sg.SecurityRules)).To(Equal([]string{"allow_ssh", "allow_6443"}))
+						g.Expect((*sg.SecurityRules)[0].SourceAddressPrefix).To(Equal(to.StringPtr("10.0.0.0/8")))
+					})
+			},
+		}, {
+			name:           "control plane security group with SSH access from source CIDR blocks",
+			sgName:         "my-sg",
+			isControlPlane: true,
+			sshAccess:      infrav1.SSHAccessNAT,
+			sshSourceCIDRs: []string{"203.0.113.0/24", "198.51.100.7/32"},
+			vnetSpec:       &infrav1.VnetSpec{},
+			expect: func(m *mock_securitygroups.MockClientMockRecorder) {
+				m.Get(context.TODO(), "my-rg", "my-sg").Return(network.SecurityGroup{}, notFound)
+				m.CreateOrUpdate(context.TODO(), "my-rg", "my-sg", gomock.AssignableToTypeOf(network.SecurityGroup{})).
+					Do(func(_ context.Context, _, _ string, sg network.SecurityGroup) {
+						g.Expect(securityRuleNames(*sg.SecurityRules)).To(Equal([]string{"allow_ssh", "allow_6443"}))
+						g.Expect((*sg.SecurityRules)[0].SourceAddressPrefix).To(BeNil())
+						g.Expect((*sg.SecurityRules)[0].SourceAddressPrefixes).To(Equal(&[]string{"203.0.113.0/24", "198.51.100.7/32"}))
+					})
+			},
+		}, {
+			name:           "control plane security group with SSH access through the bastion only",
+			sgName:         "my-sg",
+			isControlPlane: true,
+			sshAccess:      infrav1.SSHAccessBastionOnly,
+			sshSourceCIDRs: []string{"10.255.255.224/27"},
+			vnetSpec:       &infrav1.VnetSpec{},
+			expect: func(m *mock_securitygroups.MockClientMockRecorder) {
+				m.Get(context.TODO(), "my-rg", "my-sg").Return(network.SecurityGroup{}, notFound)
+				m.CreateOrUpdate(context.TODO(), "my-rg", "my-sg", gomock.AssignableToTypeOf(network.SecurityGroup{})).
+					Do(func(_ context.Context, _, _ string, sg network.SecurityGroup) {
+						g.Expect(securityRuleNames(*sg.SecurityRules)).To(Equal([]string{"allow_ssh", "deny_ssh", "allow_6443"}))
+						g.Expect((*sg.SecurityRules)[0].SourceAddressPrefix).To(Equal(to.StringPtr("10.255.255.224/27")))
+						g.Expect((*sg.SecurityRules)[1].Access).To(Equal(network.SecurityRuleAccessDeny))
+					})
+			},
+		}, {
+			name:           "disabling SSH access removes the SSH rule",
+			sgName:         "my-sg",
+			isControlPlane: true,
+			sshAccess:      infrav1.SSHAccessDisabled,
+			lastApplied:    `{"my-sg":["allow_ssh","allow_6443"]}`,
+			vnetSpec:       &infrav1.VnetSpec{},
+			expectApplied:  []string{"allow_6443"},
+			expect: func(m *mock_securitygroups.MockClientMockRecorder) {
+				m.Get(context.TODO(), "my-rg", "my-sg").Return(securityGroup(sshSecurityRule(nil), apiServerSecurityRule(6443)), nil)
+				m.CreateOrUpdate(context.TODO(), "my-rg", "my-sg", gomock.AssignableToTypeOf(network.SecurityGroup{})).
+					Do(expectRules("allow_6443"))
+			},
+		}, {
+			name:          "security group is up to date",
+			sgName:        "my-sg",
+			ingressRules:  infrav1.IngressRules{nodePortsRule},
+			lastApplied:   `{"my-sg":["allow_node_ports"]}`,
+			vnetSpec:      &infrav1.VnetSpec{},
+			expectApplied: []string{"allow_node_ports"},
+			expect: func(m *mock_securitygroups.MockClientMockRecorder) {
+				m.Get(context.TODO(), "my-rg", "my-sg").Return(securityGroup(nodePortsSecurityRule, unmanagedSecurityRule), nil)
+			},
+		}, {
+			name:          "security rule changed out of band is reverted",
+			sgName:        "my-sg",
+			ingressRules:  infrav1.IngressRules{nodePortsRule},
+			lastApplied:   `{"my-sg":["allow_node_ports"]}`,
+			vnetSpec:      &infrav1.VnetSpec{},
+			expectApplied: []string{"allow_node_ports"},
+			expect: func(m *mock_securitygroups.MockClientMockRecorder) {
+				m.Get(context.TODO(), "my-rg", "my-sg").Return(securityGroup(modifiedNodePortsSecurityRule, unmanagedSecurityRule), nil)
+				m.CreateOrUpdate(context.TODO(), "my-rg", "my-sg", gomock.AssignableToTypeOf(network.SecurityGroup{})).
+					Do(func(_ context.Context, _, _ string, sg network.SecurityGroup) {
+						g.Expect(*sg.SecurityRules).To(Equal([]network.SecurityRule{nodePortsSecurityRule, unmanagedSecurityRule}))
+					})
+			},
+		}, {
+			name:          "security rule deleted out of band is restored",
+			sgName:        "my-sg",
+			ingressRules:  infrav1.IngressRules{nodePortsRule},
+			lastApplied:   `{"my-sg":["allow_node_ports"]}`,
+			vnetSpec:      &infrav1.VnetSpec{},
+			expectApplied: []string{"allow_node_ports"},
+			expect: func(m *mock_securitygroups.MockClientMockRecorder) {
+				m.Get(context.TODO(), "my-rg", "my-sg").Return(securityGroup(unmanagedSecurityRule), nil)
+				m.CreateOrUpdate(context.TODO(), "my-rg", "my-sg", gomock.AssignableToTypeOf(network.SecurityGroup{})).
+					Do(expectRules("a1b2c3-TCP-80-Internet", "allow_node_ports"))
 			},
+		}, {
+			name:        "security rule removed from the spec is deleted",
+			sgName:      "my-sg",
+			lastApplied: `{"my-sg":["allow_node_ports"]}`,
+			vnetSpec:    &infrav1.VnetSpec{},
+			expect: func(m *mock_securitygroups.MockClientMockRecorder) {
+				m.Get(context.TODO(), "my-rg", "my-sg").Return(securityGroup(nodePortsSecurityRule, unmanagedSecurityRule), nil)
+				m.CreateOrUpdate(context.TODO(), "my-rg", "my-sg", gomock.AssignableToTypeOf(network.SecurityGroup{})).
+					Do(expectRules("a1b2c3-TCP-80-Internet"))
+			},
+		}, {
+			name:     "fail to get security group",
+			sgName:   "my-sg",
+			vnetSpec: &infrav1.VnetSpec{},
+			expect: func(m *mock_securitygroups.MockClientMockRecorder) {
+				m.Get(context.TODO(), "my-rg", "my-sg").Return(network.SecurityGroup{}, autorest.NewError("", "", "Internal Server Error"))
+			},
+			expectErr: true,
 		}, {
 			name:           "skipping network security group reconcile in custom vnet mode",
 			sgName:         "my-sg",
@@ -89,22 +295,26 @@ func TestReconcileSecurityGroups(t *testing.T) {
 
 			tc.expect(sgMock.EXPECT())
 
+			azureCluster := &infrav1.AzureCluster{
+				Spec: infrav1.AzureClusterSpec{
+					Location: "test-location",
+					ResourceGroup: "my-rg",
+					NetworkSpec: infrav1.NetworkSpec{
+						Vnet: *tc.vnetSpec,
+					},
+				},
+			}
+			if tc.lastApplied != "" {
+				azureCluster.Annotations = map[string]string{infrav1.AnnotationSecurityRules: tc.lastApplied}
+			}
 			clusterScope, err := scope.NewClusterScope(scope.ClusterScopeParams{
 				AzureClients: scope.AzureClients{
 					SubscriptionID: "123",
 					Authorizer:     autorest.NullAuthorizer{},
 				},
-				Client:  client,
-				Cluster: cluster,
-				AzureCluster: &infrav1.AzureCluster{
-					Spec: infrav1.AzureClusterSpec{
-						Location: "test-location",
-						ResourceGroup: "my-rg",
-						NetworkSpec: infrav1.NetworkSpec{
-							Vnet: *tc.vnetSpec,
-						},
-					},
-				},
+				Client:       client,
+				Cluster:      cluster,
+				AzureCluster: azureCluster,
 			})
 			g.Expect(err).NotTo(HaveOccurred())
 
@@ -116,8 +326,20 @@ func TestReconcileSecurityGroups(t *testing.T) {
 			sgSpec := &Spec{
 				Name:           tc.sgName,
 				IsControlPlane: tc.isControlPlane,
+				IsBastion:      tc.isBastion,
+				IngressRules:   tc.ingressRules,
+				SSHAccess:      tc.sshAccess,
+				SSHSourceCIDRs: tc.sshSourceCIDRs,
+			}
+			err = s.Reconcile(context.TODO(), sgSpec)
+			if tc.expectErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+			if tc.expectApplied != nil {
+				g.Expect(clusterScope.LastAppliedSecurityRules(tc.sgName)).To(Equal(tc.expectApplied))
 			}
-			g.Expect(s.Reconcile(context.TODO(), sgSpec)).To(Succeed())
 		})
 	}
 }
@@ -34,8 +34,8 @@ type Service struct {
 func NewService(scope *scope.ClusterScope) *Service {
 	return &Service{
 		Scope:                scope,
-		Client:               NewClient(scope.SubscriptionID, scope.Authorizer),
-		SecurityGroupsClient: securitygroups.NewClient(scope.SubscriptionID, scope.Authorizer),
-		RouteTablesClient:    routetables.NewClient(scope.SubscriptionID, scope.Authorizer),
+		Client:               NewClient(scope.SubscriptionID, scope.ResourceManagerEndpoint, scope.Authorizer),
+		SecurityGroupsClient: securitygroups.NewClient(scope.SubscriptionID, scope.ResourceManagerEndpoint, scope.Authorizer),
+		RouteTablesClient:    routetables.NewClient(scope.SubscriptionID, scope.ResourceManagerEndpoint, scope.Authorizer),
 	}
 }
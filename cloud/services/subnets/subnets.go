@@ -32,7 +32,7 @@ import (
 // Spec input specification for Get/CreateOrUpdate/Delete calls
 type Spec struct {
 	Name                string
-	CIDR                string
+	CIDRs               []string
 	VnetName            string
 	RouteTableName      string
 	SecurityGroupName   string
@@ -51,6 +51,14 @@ func (s *Service) Get(ctx context.Context, spec interface{}) (*infrav1.SubnetSpe
 		return nil, err
 	}
 	var sg infrav1.SecurityGroup
+	var cidrs []string
+	if subnet.SubnetPropertiesFormat != nil {
+		if subnet.SubnetPropertiesFormat.AddressPrefixes != nil && len(*subnet.SubnetPropertiesFormat.AddressPrefixes) > 0 {
+			cidrs = to.StringSlice(subnet.SubnetPropertiesFormat.AddressPrefixes)
+		} else if subnet.SubnetPropertiesFormat.AddressPrefix != nil {
+			cidrs = []string{to.String(subnet.SubnetPropertiesFormat.AddressPrefix)}
+		}
+	}
 	if subnet.SubnetPropertiesFormat != nil && subnet.SubnetPropertiesFormat.NetworkSecurityGroup != nil {
 		sg = infrav1.SecurityGroup{
 			Name: to.String(subnet.SubnetPropertiesFormat.NetworkSecurityGroup.Name),
@@ -63,7 +71,8 @@ func (s *Service) Get(ctx context.Context, spec interface{}) (*infrav1.SubnetSpe
 		InternalLBIPAddress: subnetSpec.InternalLBIPAddress,
 		Name:                to.String(subnet.Name),
 		ID:                  to.String(subnet.ID),
-		CidrBlock:           to.String(subnet.SubnetPropertiesFormat.AddressPrefix),
+		CidrBlock:           infrav1.IPv4CIDRBlock(cidrs),
+		CIDRBlocks:          cidrs,
 		SecurityGroup:       sg,
 	}, nil
 }
@@ -77,10 +86,20 @@ func (s *Service) Reconcile(ctx context.Context, spec interface{}) error {
 	if subnet, err := s.Get(ctx, subnetSpec); err == nil {
 		// TODO: add validation on existing subnet
 		// subnet already exists, skip creation
+		// the security rules are reconciled by the security groups service
 		if subnetSpec.Role == infrav1.SubnetControlPlane {
+			// the NAT gateway is reconciled by its own service
+			subnet.NatGateway = s.Scope.ControlPlaneSubnet().NatGateway
+			subnet.SecurityGroup.IngressRules = s.Scope.ControlPlaneSubnet().SecurityGroup.IngressRules
 			subnet.DeepCopyInto(s.Scope.ControlPlaneSubnet())
 		} else if subnetSpec.Role == infrav1.SubnetNode {
+			// the NAT gateway is reconciled by its own service
+			subnet.NatGateway = s.Scope.NodeSubnet().NatGateway
+			subnet.SecurityGroup.IngressRules = s.Scope.NodeSubnet().SecurityGroup.IngressRules
 			subnet.DeepCopyInto(s.Scope.NodeSubnet())
+		} else if subnetSpec.Role == infrav1.SubnetBastion && s.Scope.Bastion() != nil {
+			subnet.SecurityGroup.IngressRules = s.Scope.Bastion().Subnet.SecurityGroup.IngressRules
+			subnet.DeepCopyInto(&s.Scope.Bastion().Subnet)
 		}
 		return nil
 	}
@@ -89,8 +108,12 @@ func (s *Service) Reconcile(ctx context.Context, spec interface{}) error {
 		return fmt.Errorf("vnet was provided but subnet %s is missing", subnetSpec.Name)
 	}
 
-	subnetProperties := network.SubnetPropertiesFormat{
-		AddressPrefix: to.StringPtr(subnetSpec.CIDR),
+	subnetProperties := network.SubnetPropertiesFormat{}
+	if len(subnetSpec.CIDRs) == 1 {
+		subnetProperties.AddressPrefix = to.StringPtr(subnetSpec.CIDRs[0])
+	} else {
+		// dual-stack subnets need one address prefix per IP version
+		subnetProperties.AddressPrefixes = &subnetSpec.CIDRs
 	}
 	if subnetSpec.RouteTableName != "" {
 		klog.V(2).Infof("getting route table %s", subnetSpec.RouteTableName)
@@ -102,16 +125,18 @@ func (s *Service) Reconcile(ctx context.Context, spec interface{}) error {
 		subnetProperties.RouteTable = &rt
 	}
 
-	klog.V(2).Infof("getting nsg %s", subnetSpec.SecurityGroupName)
-	nsg, err := s.SecurityGroupsClient.Get(ctx, s.Scope.ResourceGroup(), subnetSpec.SecurityGroupName)
-	if err != nil {
-		return err
+	if subnetSpec.SecurityGroupName != "" {
+		klog.V(2).Infof("getting nsg %s", subnetSpec.SecurityGroupName)
+		nsg, err := s.SecurityGroupsClient.Get(ctx, s.Scope.ResourceGroup(), subnetSpec.SecurityGroupName)
+		if err != nil {
+			return err
+		}
+		klog.V(2).Infof("got nsg %s", subnetSpec.SecurityGroupName)
+		subnetProperties.NetworkSecurityGroup = &nsg
 	}
-	klog.V(2).Infof("got nsg %s", subnetSpec.SecurityGroupName)
-	subnetProperties.NetworkSecurityGroup = &nsg
 
 	klog.V(2).Infof("creating subnet %s in vnet %s", subnetSpec.Name, subnetSpec.VnetName)
-	err = s.Client.CreateOrUpdate(
+	err := s.Client.CreateOrUpdate(
 		ctx,
 		s.Scope.Vnet().ResourceGroup,
 		subnetSpec.VnetName,
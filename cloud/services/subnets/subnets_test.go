@@ -58,7 +58,7 @@ func TestReconcileSubnets(t *testing.T) {
 			name: "subnet does not exist",
 			subnetSpec: Spec{
 				Name:                "my-subnet",
-				CIDR:                "10.0.0.0/16",
+				CIDRs:               []string{"10.0.0.0/16"},
 				VnetName:            "my-vnet",
 				RouteTableName:      "my-subent_route_table",
 				SecurityGroupName:   "my-sg",
@@ -81,11 +81,58 @@ func TestReconcileSubnets(t *testing.T) {
 				m.CreateOrUpdate(context.TODO(), "", "my-vnet", "my-subnet", gomock.AssignableToTypeOf(network.Subnet{}))
 			},
 		},
+		{
+			name: "dual-stack subnet does not exist",
+			subnetSpec: Spec{
+				Name:              "my-subnet",
+				CIDRs:             []string{"10.0.0.0/16", "2001:1234:5678:9abc::/64"},
+				VnetName:          "my-vnet",
+				SecurityGroupName: "my-sg",
+				Role:              infrav1.SubnetNode,
+			},
+			vnetSpec:      &infrav1.VnetSpec{Name: "my-vnet"},
+			subnets:       []*infrav1.SubnetSpec{},
+			expectedError: "",
+			expect: func(m *mock_subnets.MockClientMockRecorder, m1 *mock_routetables.MockClientMockRecorder, m2 *mock_securitygroups.MockClientMockRecorder) {
+				m.Get(context.TODO(), "", "my-vnet", "my-subnet").
+					Return(network.Subnet{}, autorest.NewErrorWithResponse("", "", &http.Response{StatusCode: 404}, "Not found"))
+
+				m2.Get(context.TODO(), "my-rg", "my-sg").
+					Return(network.SecurityGroup{}, nil)
+
+				m.CreateOrUpdate(context.TODO(), "", "my-vnet", "my-subnet", gomock.AssignableToTypeOf(network.Subnet{})).
+					Do(func(_ context.Context, _, _, _ string, subnet network.Subnet) {
+						g.Expect(subnet.AddressPrefix).To(BeNil())
+						g.Expect(*subnet.AddressPrefixes).To(Equal([]string{"10.0.0.0/16", "2001:1234:5678:9abc::/64"}))
+					})
+			},
+		},
+		{
+			name: "subnet without security group does not exist",
+			subnetSpec: Spec{
+				Name:     "AzureBastionSubnet",
+				CIDRs:    []string{"10.255.255.224/27"},
+				VnetName: "my-vnet",
+				Role:     infrav1.SubnetBastion,
+			},
+			vnetSpec:      &infrav1.VnetSpec{Name: "my-vnet"},
+			subnets:       []*infrav1.SubnetSpec{},
+			expectedError: "",
+			expect: func(m *mock_subnets.MockClientMockRecorder, m1 *mock_routetables.MockClientMockRecorder, m2 *mock_securitygroups.MockClientMockRecorder) {
+				m.Get(context.TODO(), "", "my-vnet", "AzureBastionSubnet").
+					Return(network.Subnet{}, autorest.NewErrorWithResponse("", "", &http.Response{StatusCode: 404}, "Not found"))
+
+				m.CreateOrUpdate(context.TODO(), "", "my-vnet", "AzureBastionSubnet", gomock.AssignableToTypeOf(network.Subnet{})).
+					Do(func(_ context.Context, _, _, _ string, subnet network.Subnet) {
+						g.Expect(subnet.NetworkSecurityGroup).To(BeNil())
+					})
+			},
+		},
 		{
 			name: "vnet was provided but subnet is missing",
 			subnetSpec: Spec{
 				Name:                "my-subnet",
-				CIDR:                "10.0.0.0/16",
+				CIDRs:               []string{"10.0.0.0/16"},
 				VnetName:            "custom-vnet",
 				RouteTableName:      "my-subent_route_table",
 				SecurityGroupName:   "my-sg",
@@ -104,7 +151,7 @@ func TestReconcileSubnets(t *testing.T) {
 			name: "vnet was provided and subnet exists",
 			subnetSpec: Spec{
 				Name:                "my-subnet",
-				CIDR:                "10.0.0.0/16",
+				CIDRs:               []string{"10.0.0.0/16"},
 				VnetName:            "my-vnet",
 				RouteTableName:      "my-subent_route_table",
 				SecurityGroupName:   "my-sg",
@@ -204,7 +251,7 @@ func TestDeleteSubnets(t *testing.T) {
 			name: "subnet exists",
 			subnetSpec: Spec{
 				Name:                "my-subnet",
-				CIDR:                "10.0.0.0/16",
+				CIDRs:               []string{"10.0.0.0/16"},
 				VnetName:            "my-vnet",
 				RouteTableName:      "my-subent_route_table",
 				SecurityGroupName:   "my-sg",
@@ -220,7 +267,7 @@ func TestDeleteSubnets(t *testing.T) {
 			name: "subnet already deleted",
 			subnetSpec: Spec{
 				Name:                "my-subnet",
-				CIDR:                "10.0.0.0/16",
+				CIDRs:               []string{"10.0.0.0/16"},
 				VnetName:            "my-vnet",
 				RouteTableName:      "my-subent_route_table",
 				SecurityGroupName:   "my-sg",
@@ -237,7 +284,7 @@ func TestDeleteSubnets(t *testing.T) {
 			name: "skip delete if vnet is managed",
 			subnetSpec: Spec{
 				Name:                "my-subnet",
-				CIDR:                "10.0.0.0/16",
+				CIDRs:               []string{"10.0.0.0/16"},
 				VnetName:            "custom-vnet",
 				RouteTableName:      "my-subent_route_table",
 				SecurityGroupName:   "my-sg",
@@ -36,8 +36,8 @@ func NewService(scope *scope.ClusterScope, machineScope *scope.MachineScope) *Se
 	return &Service{
 		Scope:            scope,
 		MachineScope:     machineScope,
-		Client:           NewClient(scope.SubscriptionID, scope.Authorizer),
-		InterfacesClient: networkinterfaces.NewClient(scope.SubscriptionID, scope.Authorizer),
-		PublicIPsClient:  publicips.NewClient(scope.SubscriptionID, scope.Authorizer),
+		Client:           NewClient(scope.SubscriptionID, scope.ResourceManagerEndpoint, scope.Authorizer),
+		InterfacesClient: networkinterfaces.NewClient(scope.SubscriptionID, scope.ResourceManagerEndpoint, scope.Authorizer),
+		PublicIPsClient:  publicips.NewClient(scope.SubscriptionID, scope.ResourceManagerEndpoint, scope.Authorizer),
 	}
 }
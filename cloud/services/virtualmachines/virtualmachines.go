@@ -22,9 +22,10 @@ import (
 	"crypto/rsa"
 	"encoding/base64"
 	"fmt"
+	"strconv"
 	"strings"
 
-	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2019-12-01/compute"
+	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2020-06-01/compute"
 	"github.com/Azure/go-autorest/autorest/to"
 	"github.com/pkg/errors"
 	"golang.org/x/crypto/ssh"
@@ -38,13 +39,27 @@ import (
 // Spec input specification for Get/CreateOrUpdate/Delete calls
 type Spec struct {
 	Name       string
-	NICName    string
 	SSHKeyData string
 	Size       string
 	Zone       string
 	Image      *infrav1.Image
 	OSDisk     infrav1.OSDisk
+	DataDisks  []infrav1.DataDisk
 	CustomData string
+	// Identity is the type of identity assigned to the VM
+	Identity infrav1.VMIdentity
+	// UserAssignedIdentities are the user-assigned identities assigned to the VM when Identity is UserAssigned
+	UserAssignedIdentities []infrav1.UserAssignedIdentity
+	// SecurityProfile specifies the security settings of the VM
+	SecurityProfile *infrav1.SecurityProfile
+	// SpotVMOptions makes the VM a spot VM when set
+	SpotVMOptions *infrav1.SpotVMOptions
+	// AvailabilitySetID is the ID of the availability set of the VM, if any
+	AvailabilitySetID string
+	// NICNames are the names of the network interfaces of the VM, in order
+	NICNames []string
+	// PrimaryNICName is the name of the primary network interface, which defaults to the first one
+	PrimaryNICName string
 }
 
 // Get provides information about a virtual machine.
@@ -87,41 +102,37 @@ func (s *Service) Reconcile(ctx context.Context, spec interface{}) error {
 		return err
 	}
 
-	klog.V(2).Infof("getting nic %s", vmSpec.NICName)
-	nic, err := s.InterfacesClient.Get(ctx, s.Scope.ResourceGroup(), vmSpec.NICName)
+	nicRefs, err := s.getNetworkInterfaceReferences(ctx, *vmSpec)
 	if err != nil {
 		return err
 	}
-	klog.V(2).Infof("got nic %s", vmSpec.NICName)
 
 	klog.V(2).Infof("creating vm %s ", vmSpec.Name)
 
 	sshKeyData := vmSpec.SSHKeyData
 	if sshKeyData == "" {
-		privateKey, perr := rsa.GenerateKey(rand.Reader, 2048)
-		if perr != nil {
-			return errors.Wrap(perr, "Failed to generate private key")
-		}
-
-		publicRsaKey, perr := ssh.NewPublicKey(&privateKey.PublicKey)
-		if perr != nil {
-			return errors.Wrap(perr, "Failed to generate public key")
+		sshKeyData, err = GenerateSSHKey()
+		if err != nil {
+			return err
 		}
-		sshKeyData = string(ssh.MarshalAuthorizedKey(publicRsaKey))
 	}
 
-	// Make sure to use the MachineScope here to get the merger of AzureCluster and AzureMachine tags
-	additionalTags := s.MachineScope.AdditionalTags()
-	// Set the cloud provider tag
-	additionalTags[infrav1.ClusterAzureCloudProviderTagKey(s.MachineScope.Name())] = string(infrav1.ResourceLifecycleOwned)
+	// VMs without a MachineScope, such as bastion jumpboxes, belong to the cluster itself
+	name, role, additionalTags := vmSpec.Name, infrav1.BastionRoleTagValue, s.Scope.AdditionalTags()
+	if s.MachineScope != nil {
+		// Make sure to use the MachineScope here to get the merger of AzureCluster and AzureMachine tags
+		name, role, additionalTags = s.MachineScope.Name(), s.MachineScope.Role(), s.MachineScope.AdditionalTags()
+		// Set the cloud provider tag
+		additionalTags[infrav1.ClusterAzureCloudProviderTagKey(s.MachineScope.Name())] = string(infrav1.ResourceLifecycleOwned)
+	}
 
 	virtualMachine := compute.VirtualMachine{
 		Location: to.StringPtr(s.Scope.Location()),
 		Tags: converters.TagsToMap(infrav1.Build(infrav1.BuildParams{
 			ClusterName: s.Scope.Name(),
 			Lifecycle:   infrav1.ResourceLifecycleOwned,
-			Name:        to.StringPtr(s.MachineScope.Name()),
-			Role:        to.StringPtr(s.MachineScope.Role()),
+			Name:        to.StringPtr(name),
+			Role:        to.StringPtr(role),
 			Additional:  additionalTags,
 		})),
 		VirtualMachineProperties: &compute.VirtualMachineProperties{
@@ -146,18 +157,31 @@ func (s *Service) Reconcile(ctx context.Context, spec interface{}) error {
 				},
 			},
 			NetworkProfile: &compute.NetworkProfile{
-				NetworkInterfaces: &[]compute.NetworkInterfaceReference{
-					{
-						ID: nic.ID,
-						NetworkInterfaceReferenceProperties: &compute.NetworkInterfaceReferenceProperties{
-							Primary: to.BoolPtr(true),
-						},
-					},
-				},
+				NetworkInterfaces: &nicRefs,
 			},
 		},
 	}
 
+	if vmSpec.SecurityProfile != nil {
+		virtualMachine.SecurityProfile = &compute.SecurityProfile{
+			EncryptionAtHost: vmSpec.SecurityProfile.EncryptionAtHost,
+		}
+	}
+
+	priority, evictionPolicy, billingProfile, err := getSpotVMOptions(vmSpec.SpotVMOptions)
+	if err != nil {
+		return err
+	}
+	virtualMachine.Priority = priority
+	virtualMachine.EvictionPolicy = evictionPolicy
+	virtualMachine.BillingProfile = billingProfile
+
+	identity, err := generateIdentity(vmSpec.Identity, vmSpec.UserAssignedIdentities)
+	if err != nil {
+		return err
+	}
+	virtualMachine.Identity = identity
+
 	klog.V(2).Infof("Setting zone %s ", vmSpec.Zone)
 
 	if vmSpec.Zone != "" {
@@ -165,6 +189,10 @@ func (s *Service) Reconcile(ctx context.Context, spec interface{}) error {
 		virtualMachine.Zones = &zones
 	}
 
+	if vmSpec.AvailabilitySetID != "" {
+		virtualMachine.AvailabilitySet = &compute.SubResource{ID: to.StringPtr(vmSpec.AvailabilitySetID)}
+	}
+
 	err = s.Client.CreateOrUpdate(
 		ctx,
 		s.Scope.ResourceGroup(),
@@ -198,6 +226,57 @@ func (s *Service) Delete(ctx context.Context, spec interface{}) error {
 	return nil
 }
 
+// generateIdentity generates the VM identity from the identity type and user-assigned identities of the spec.
+func generateIdentity(identityType infrav1.VMIdentity, userAssignedIdentities []infrav1.UserAssignedIdentity) (*compute.VirtualMachineIdentity, error) {
+	switch identityType {
+	case "", infrav1.VMIdentityNone:
+		return nil, nil
+	case infrav1.VMIdentitySystemAssigned:
+		return &compute.VirtualMachineIdentity{
+			Type: compute.ResourceIdentityTypeSystemAssigned,
+		}, nil
+	case infrav1.VMIdentityUserAssigned:
+		if len(userAssignedIdentities) == 0 {
+			return nil, errors.New("cannot assign VM identity: at least one user-assigned identity is required")
+		}
+		identities := make(map[string]*compute.VirtualMachineIdentityUserAssignedIdentitiesValue, len(userAssignedIdentities))
+		for _, identity := range userAssignedIdentities {
+			identities[identity.ProviderID] = &compute.VirtualMachineIdentityUserAssignedIdentitiesValue{}
+		}
+		return &compute.VirtualMachineIdentity{
+			Type:                   compute.ResourceIdentityTypeUserAssigned,
+			UserAssignedIdentities: identities,
+		}, nil
+	default:
+		return nil, errors.Errorf("unsupported VM identity type %s", identityType)
+	}
+}
+
+// getSpotVMOptions returns the priority, eviction policy and billing profile of a VM from its spot options.
+// A nil spotVMOptions leaves them unset, which results in a regular VM.
+func getSpotVMOptions(spotVMOptions *infrav1.SpotVMOptions) (compute.VirtualMachinePriorityTypes, compute.VirtualMachineEvictionPolicyTypes, *compute.BillingProfile, error) {
+	if spotVMOptions == nil {
+		return "", "", nil, nil
+	}
+
+	evictionPolicy := compute.Deallocate
+	if spotVMOptions.EvictionPolicy != "" {
+		evictionPolicy = compute.VirtualMachineEvictionPolicyTypes(spotVMOptions.EvictionPolicy)
+	}
+
+	// a max price of -1 bills the VM up to the on-demand price
+	maxPrice := float64(-1)
+	if spotVMOptions.MaxPrice != nil {
+		var err error
+		maxPrice, err = strconv.ParseFloat(spotVMOptions.MaxPrice.AsDec().String(), 64)
+		if err != nil {
+			return "", "", nil, errors.Wrapf(err, "failed to parse spot VM max price %s", spotVMOptions.MaxPrice.String())
+		}
+	}
+
+	return compute.Spot, evictionPolicy, &compute.BillingProfile{MaxPrice: to.Float64Ptr(maxPrice)}, nil
+}
+
 func (s *Service) getAddresses(ctx context.Context, vm compute.VirtualMachine) ([]corev1.NodeAddress, error) {
 
 	addresses := []corev1.NodeAddress{}
@@ -251,6 +330,37 @@ func (s *Service) getAddresses(ctx context.Context, vm compute.VirtualMachine) (
 	return addresses, nil
 }
 
+// getNetworkInterfaceReferences gets the network interfaces of the VM spec and returns their references in order,
+// with only the primary network interface marked as primary.
+func (s *Service) getNetworkInterfaceReferences(ctx context.Context, vmSpec Spec) ([]compute.NetworkInterfaceReference, error) {
+	if len(vmSpec.NICNames) == 0 {
+		return nil, errors.Errorf("vm %s has no network interfaces", vmSpec.Name)
+	}
+	primaryNICName := vmSpec.PrimaryNICName
+	if primaryNICName == "" {
+		primaryNICName = vmSpec.NICNames[0]
+	}
+
+	nicRefs := make([]compute.NetworkInterfaceReference, 0, len(vmSpec.NICNames))
+	for _, nicName := range vmSpec.NICNames {
+		klog.V(2).Infof("getting nic %s", nicName)
+		nic, err := s.InterfacesClient.Get(ctx, s.Scope.ResourceGroup(), nicName)
+		if err != nil {
+			return nil, err
+		}
+		klog.V(2).Infof("got nic %s", nicName)
+
+		nicRefs = append(nicRefs, compute.NetworkInterfaceReference{
+			ID: nic.ID,
+			NetworkInterfaceReferenceProperties: &compute.NetworkInterfaceReferenceProperties{
+				Primary: to.BoolPtr(nicName == primaryNICName),
+			},
+		})
+	}
+
+	return nicRefs, nil
+}
+
 // getPublicIPAddress will fetch a public ip address resource by name and return a nodeaddresss representation
 func (s *Service) getPublicIPAddress(ctx context.Context, publicIPAddressName string) (corev1.NodeAddress, error) {
 	retAddress := corev1.NodeAddress{}
@@ -282,12 +392,32 @@ func generateStorageProfile(vmSpec Spec) (*compute.StorageProfile, error) {
 			OsType:       compute.OperatingSystemTypes(vmSpec.OSDisk.OSType),
 			CreateOption: compute.DiskCreateOptionTypesFromImage,
 			DiskSizeGB:   to.Int32Ptr(vmSpec.OSDisk.DiskSizeGB),
-			ManagedDisk: &compute.ManagedDiskParameters{
-				StorageAccountType: compute.StorageAccountTypes(vmSpec.OSDisk.ManagedDisk.StorageAccountType),
-			},
+			ManagedDisk:  GenerateManagedDiskParameters(vmSpec.OSDisk.ManagedDisk),
 		},
 	}
 
+	storageProfile.OsDisk.DiffDiskSettings, storageProfile.OsDisk.Caching = GenerateOSDiskSettings(vmSpec.OSDisk)
+
+	dataDisks := make([]compute.DataDisk, 0, len(vmSpec.DataDisks))
+	for i, disk := range vmSpec.DataDisks {
+		lun := int32(i)
+		if disk.Lun != nil {
+			lun = *disk.Lun
+		}
+		dataDisk := compute.DataDisk{
+			Name:         to.StringPtr(azure.GenerateDataDiskName(vmSpec.Name, disk.NameSuffix)),
+			Lun:          to.Int32Ptr(lun),
+			CreateOption: compute.DiskCreateOptionTypesEmpty,
+			DiskSizeGB:   to.Int32Ptr(disk.DiskSizeGB),
+			Caching:      compute.CachingTypes(disk.CachingType),
+		}
+		if disk.ManagedDisk != nil {
+			dataDisk.ManagedDisk = GenerateManagedDiskParameters(*disk.ManagedDisk)
+		}
+		dataDisks = append(dataDisks, dataDisk)
+	}
+	storageProfile.DataDisks = &dataDisks
+
 	imageRef, err := converters.ImageToSDK(vmSpec.Image)
 	if err != nil {
 		return nil, err
@@ -298,6 +428,50 @@ func generateStorageProfile(vmSpec Spec) (*compute.StorageProfile, error) {
 	return storageProfile, nil
 }
 
+// GenerateOSDiskSettings generates the ephemeral disk settings and the caching type of an OS disk.
+func GenerateOSDiskSettings(osDisk infrav1.OSDisk) (*compute.DiffDiskSettings, compute.CachingTypes) {
+	var diffDiskSettings *compute.DiffDiskSettings
+	var cachingType compute.CachingTypes
+	if osDisk.DiffDiskSettings != nil {
+		diffDiskSettings = &compute.DiffDiskSettings{
+			Option: compute.DiffDiskOptions(osDisk.DiffDiskSettings.Option),
+		}
+		// ephemeral OS disks only support read-only caching
+		cachingType = compute.CachingTypesReadOnly
+	}
+	if osDisk.CachingType != "" {
+		cachingType = compute.CachingTypes(osDisk.CachingType)
+	}
+	return diffDiskSettings, cachingType
+}
+
+// GenerateManagedDiskParameters generates the managed disk parameters of an OS or data disk.
+func GenerateManagedDiskParameters(managedDisk infrav1.ManagedDisk) *compute.ManagedDiskParameters {
+	managedDiskParameters := &compute.ManagedDiskParameters{
+		StorageAccountType: compute.StorageAccountTypes(managedDisk.StorageAccountType),
+	}
+	if managedDisk.DiskEncryptionSetID != "" {
+		managedDiskParameters.DiskEncryptionSet = &compute.DiskEncryptionSetParameters{
+			ID: to.StringPtr(managedDisk.DiskEncryptionSetID),
+		}
+	}
+	return managedDiskParameters
+}
+
+// GenerateSSHKey generates a random RSA key and returns its public key in the authorized_keys format.
+func GenerateSSHKey() (string, error) {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return "", errors.Wrap(err, "Failed to generate private key")
+	}
+
+	publicRsaKey, err := ssh.NewPublicKey(&privateKey.PublicKey)
+	if err != nil {
+		return "", errors.Wrap(err, "Failed to generate public key")
+	}
+	return string(ssh.MarshalAuthorizedKey(publicRsaKey)), nil
+}
+
 // GenerateRandomString returns a URL-safe, base64 encoded
 // securely generated random string.
 // It will return an error if the system's secure random
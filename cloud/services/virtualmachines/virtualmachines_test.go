@@ -30,9 +30,10 @@ import (
 	"github.com/Azure/go-autorest/autorest/to"
 	"github.com/golang/mock/gomock"
 
-	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2019-12-01/compute"
+	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2020-06-01/compute"
 	network "github.com/Azure/azure-sdk-for-go/services/network/mgmt/2019-06-01/network"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes/scheme"
 	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1alpha3"
@@ -342,6 +343,75 @@ func TestGetVM(t *testing.T) {
 	}
 }
 
+func TestGetAddresses(t *testing.T) {
+	g := NewWithT(t)
+
+	mockCtrl := gomock.NewController(t)
+	interfaceMock := mock_networkinterfaces.NewMockClient(mockCtrl)
+	publicIPMock := mock_publicips.NewMockClient(mockCtrl)
+
+	cluster := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-cluster"},
+	}
+
+	clusterScope, err := scope.NewClusterScope(scope.ClusterScopeParams{
+		AzureClients: scope.AzureClients{
+			SubscriptionID: "123",
+			Authorizer:     autorest.NullAuthorizer{},
+		},
+		Client:  fake.NewFakeClient(cluster),
+		Cluster: cluster,
+		AzureCluster: &infrav1.AzureCluster{
+			Spec: infrav1.AzureClusterSpec{
+				Location:      "test-location",
+				ResourceGroup: "my-rg",
+			},
+		},
+	})
+	g.Expect(err).NotTo(HaveOccurred())
+
+	interfaceMock.EXPECT().Get(context.TODO(), "my-rg", "my-nic").Return(network.Interface{
+		InterfacePropertiesFormat: &network.InterfacePropertiesFormat{
+			IPConfigurations: &[]network.InterfaceIPConfiguration{
+				{
+					Name: to.StringPtr("pipConfig"),
+					InterfaceIPConfigurationPropertiesFormat: &network.InterfaceIPConfigurationPropertiesFormat{
+						PrivateIPAddress: to.StringPtr("10.0.0.4"),
+					},
+				},
+				{
+					Name: to.StringPtr("ipConfigv6"),
+					InterfaceIPConfigurationPropertiesFormat: &network.InterfaceIPConfigurationPropertiesFormat{
+						PrivateIPAddress:        to.StringPtr("2001:1234:5678:9abc::4"),
+						PrivateIPAddressVersion: network.IPv6,
+					},
+				},
+			},
+		},
+	}, nil)
+
+	s := &Service{
+		Scope:            clusterScope,
+		InterfacesClient: interfaceMock,
+		PublicIPsClient:  publicIPMock,
+	}
+
+	addresses, err := s.getAddresses(context.TODO(), compute.VirtualMachine{
+		VirtualMachineProperties: &compute.VirtualMachineProperties{
+			NetworkProfile: &compute.NetworkProfile{
+				NetworkInterfaces: &[]compute.NetworkInterfaceReference{
+					{ID: to.StringPtr("/subscriptions/123/resourceGroups/my-rg/providers/Microsoft.Network/networkInterfaces/my-nic")},
+				},
+			},
+		},
+	})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(addresses).To(Equal([]corev1.NodeAddress{
+		{Type: corev1.NodeInternalIP, Address: "10.0.0.4"},
+		{Type: corev1.NodeInternalIP, Address: "2001:1234:5678:9abc::4"},
+	}))
+}
+
 func TestReconcileVM(t *testing.T) {
 	g := NewWithT(t)
 
@@ -551,7 +621,7 @@ func TestReconcileVM(t *testing.T) {
 
 			vmSpec := &Spec{
 				Name:       machineScope.Name(),
-				NICName:    "test-nic",
+				NICNames:   []string{"test-nic"},
 				SSHKeyData: "fake-key",
 				Size:       machineScope.AzureMachine.Spec.VMSize,
 				OSDisk:     machineScope.AzureMachine.Spec.OSDisk,
@@ -569,6 +639,122 @@ func TestReconcileVM(t *testing.T) {
 	}
 }
 
+func TestGetNetworkInterfaceReferences(t *testing.T) {
+	g := NewWithT(t)
+
+	nicID := func(name string) *string {
+		return to.StringPtr("/subscriptions/123/resourceGroups/my-rg/providers/Microsoft.Network/networkInterfaces/" + name)
+	}
+
+	testcases := []struct {
+		name          string
+		vmSpec        Spec
+		expected      []compute.NetworkInterfaceReference
+		expectedError string
+		expect        func(mnic *mock_networkinterfaces.MockClientMockRecorder)
+	}{
+		{
+			name: "first network interface is primary by default",
+			vmSpec: Spec{
+				Name:     "my-vm",
+				NICNames: []string{"my-vm-nic", "my-vm-nic-1"},
+			},
+			expected: []compute.NetworkInterfaceReference{
+				{ID: nicID("my-vm-nic"), NetworkInterfaceReferenceProperties: &compute.NetworkInterfaceReferenceProperties{Primary: to.BoolPtr(true)}},
+				{ID: nicID("my-vm-nic-1"), NetworkInterfaceReferenceProperties: &compute.NetworkInterfaceReferenceProperties{Primary: to.BoolPtr(false)}},
+			},
+			expect: func(mnic *mock_networkinterfaces.MockClientMockRecorder) {
+				gomock.InOrder(
+					mnic.Get(context.TODO(), "my-rg", "my-vm-nic").Return(network.Interface{ID: nicID("my-vm-nic")}, nil),
+					mnic.Get(context.TODO(), "my-rg", "my-vm-nic-1").Return(network.Interface{ID: nicID("my-vm-nic-1")}, nil),
+				)
+			},
+		},
+		{
+			name: "explicit primary network interface",
+			vmSpec: Spec{
+				Name:           "my-vm",
+				NICNames:       []string{"my-vm-nic", "my-vm-nic-1"},
+				PrimaryNICName: "my-vm-nic-1",
+			},
+			expected: []compute.NetworkInterfaceReference{
+				{ID: nicID("my-vm-nic"), NetworkInterfaceReferenceProperties: &compute.NetworkInterfaceReferenceProperties{Primary: to.BoolPtr(false)}},
+				{ID: nicID("my-vm-nic-1"), NetworkInterfaceReferenceProperties: &compute.NetworkInterfaceReferenceProperties{Primary: to.BoolPtr(true)}},
+			},
+			expect: func(mnic *mock_networkinterfaces.MockClientMockRecorder) {
+				gomock.InOrder(
+					mnic.Get(context.TODO(), "my-rg", "my-vm-nic").Return(network.Interface{ID: nicID("my-vm-nic")}, nil),
+					mnic.Get(context.TODO(), "my-rg", "my-vm-nic-1").Return(network.Interface{ID: nicID("my-vm-nic-1")}, nil),
+				)
+			},
+		},
+		{
+			name: "no network interfaces",
+			vmSpec: Spec{
+				Name: "my-vm",
+			},
+			expectedError: "vm my-vm has no network interfaces",
+			expect:        func(mnic *mock_networkinterfaces.MockClientMockRecorder) {},
+		},
+		{
+			name: "network interface not found",
+			vmSpec: Spec{
+				Name:     "my-vm",
+				NICNames: []string{"my-vm-nic"},
+			},
+			expectedError: "#: Not found: StatusCode=404",
+			expect: func(mnic *mock_networkinterfaces.MockClientMockRecorder) {
+				mnic.Get(context.TODO(), "my-rg", "my-vm-nic").
+					Return(network.Interface{}, autorest.NewErrorWithResponse("", "", &http.Response{StatusCode: 404}, "Not found"))
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			interfaceMock := mock_networkinterfaces.NewMockClient(mockCtrl)
+
+			cluster := &clusterv1.Cluster{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-cluster"},
+			}
+
+			client := fake.NewFakeClient(cluster)
+
+			tc.expect(interfaceMock.EXPECT())
+
+			clusterScope, err := scope.NewClusterScope(scope.ClusterScopeParams{
+				AzureClients: scope.AzureClients{
+					SubscriptionID: "123",
+					Authorizer:     autorest.NullAuthorizer{},
+				},
+				Client:  client,
+				Cluster: cluster,
+				AzureCluster: &infrav1.AzureCluster{
+					Spec: infrav1.AzureClusterSpec{
+						Location:      "test-location",
+						ResourceGroup: "my-rg",
+					},
+				},
+			})
+			g.Expect(err).NotTo(HaveOccurred())
+
+			s := &Service{
+				Scope:            clusterScope,
+				InterfacesClient: interfaceMock,
+			}
+
+			nicRefs, err := s.getNetworkInterfaceReferences(context.TODO(), tc.vmSpec)
+			if tc.expectedError != "" {
+				g.Expect(err).To(MatchError(tc.expectedError))
+			} else {
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(nicRefs).To(Equal(tc.expected))
+			}
+		})
+	}
+}
+
 func TestDeleteVM(t *testing.T) {
 	g := NewWithT(t)
 
@@ -659,3 +845,197 @@ func TestDeleteVM(t *testing.T) {
 		})
 	}
 }
+
+func TestGenerateIdentity(t *testing.T) {
+	g := NewWithT(t)
+
+	userAssignedID := "/subscriptions/123/resourceGroups/rg/providers/Microsoft.ManagedIdentity/userAssignedIdentities/id1"
+
+	testcases := []struct {
+		name                   string
+		identity               infrav1.VMIdentity
+		userAssignedIdentities []infrav1.UserAssignedIdentity
+		expected               *compute.VirtualMachineIdentity
+		expectedError          string
+	}{
+		{
+			name:     "no identity",
+			identity: infrav1.VMIdentityNone,
+			expected: nil,
+		},
+		{
+			name:     "system-assigned identity",
+			identity: infrav1.VMIdentitySystemAssigned,
+			expected: &compute.VirtualMachineIdentity{
+				Type: compute.ResourceIdentityTypeSystemAssigned,
+			},
+		},
+		{
+			name:                   "user-assigned identity",
+			identity:               infrav1.VMIdentityUserAssigned,
+			userAssignedIdentities: []infrav1.UserAssignedIdentity{{ProviderID: userAssignedID}},
+			expected: &compute.VirtualMachineIdentity{
+				Type: compute.ResourceIdentityTypeUserAssigned,
+				UserAssignedIdentities: map[string]*compute.VirtualMachineIdentityUserAssignedIdentitiesValue{
+					userAssignedID: {},
+				},
+			},
+		},
+		{
+			name:          "user-assigned identity without identities",
+			identity:      infrav1.VMIdentityUserAssigned,
+			expectedError: "cannot assign VM identity: at least one user-assigned identity is required",
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			identity, err := generateIdentity(tc.identity, tc.userAssignedIdentities)
+			if tc.expectedError != "" {
+				g.Expect(err).To(MatchError(tc.expectedError))
+			} else {
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(identity).To(Equal(tc.expected))
+			}
+		})
+	}
+}
+
+func TestGenerateStorageProfileDataDisks(t *testing.T) {
+	g := NewWithT(t)
+
+	vmSpec := Spec{
+		Name: "my-vm",
+		Image: &infrav1.Image{
+			ID: to.StringPtr("my-image"),
+		},
+		OSDisk: infrav1.OSDisk{
+			OSType:     "Linux",
+			DiskSizeGB: 30,
+			ManagedDisk: infrav1.ManagedDisk{
+				StorageAccountType: "Premium_LRS",
+			},
+		},
+		DataDisks: []infrav1.DataDisk{
+			{
+				NameSuffix:  "etcddisk",
+				DiskSizeGB:  256,
+				Lun:         to.Int32Ptr(2),
+				CachingType: "ReadWrite",
+				ManagedDisk: &infrav1.ManagedDisk{
+					StorageAccountType: "Premium_LRS",
+				},
+			},
+			{
+				NameSuffix: "containerdisk",
+				DiskSizeGB: 128,
+			},
+		},
+	}
+
+	storageProfile, err := generateStorageProfile(vmSpec)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(*storageProfile.DataDisks).To(Equal([]compute.DataDisk{
+		{
+			Name:         to.StringPtr("my-vm_etcddisk"),
+			Lun:          to.Int32Ptr(2),
+			CreateOption: compute.DiskCreateOptionTypesEmpty,
+			DiskSizeGB:   to.Int32Ptr(256),
+			Caching:      compute.CachingTypesReadWrite,
+			ManagedDisk: &compute.ManagedDiskParameters{
+				StorageAccountType: compute.StorageAccountTypesPremiumLRS,
+			},
+		},
+		{
+			Name:         to.StringPtr("my-vm_containerdisk"),
+			Lun:          to.Int32Ptr(1),
+			CreateOption: compute.DiskCreateOptionTypesEmpty,
+			DiskSizeGB:   to.Int32Ptr(128),
+		},
+	}))
+}
+
+func TestGenerateStorageProfileEphemeralOSDisk(t *testing.T) {
+	g := NewWithT(t)
+
+	vmSpec := Spec{
+		Name: "my-vm",
+		Image: &infrav1.Image{
+			ID: to.StringPtr("my-image"),
+		},
+		OSDisk: infrav1.OSDisk{
+			OSType:     "Linux",
+			DiskSizeGB: 30,
+			ManagedDisk: infrav1.ManagedDisk{
+				StorageAccountType: "Standard_LRS",
+			},
+			DiffDiskSettings: &infrav1.DiffDiskSettings{
+				Option: "Local",
+			},
+		},
+	}
+
+	storageProfile, err := generateStorageProfile(vmSpec)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(storageProfile.OsDisk.DiffDiskSettings).To(Equal(&compute.DiffDiskSettings{Option: compute.Local}))
+	g.Expect(storageProfile.OsDisk.Caching).To(Equal(compute.CachingTypesReadOnly))
+}
+
+func TestGenerateManagedDiskParameters(t *testing.T) {
+	g := NewWithT(t)
+
+	desID := "/subscriptions/123/resourceGroups/rg/providers/Microsoft.Compute/diskEncryptionSets/des"
+
+	g.Expect(GenerateManagedDiskParameters(infrav1.ManagedDisk{StorageAccountType: "Premium_LRS"})).To(Equal(&compute.ManagedDiskParameters{
+		StorageAccountType: compute.StorageAccountTypesPremiumLRS,
+	}))
+	g.Expect(GenerateManagedDiskParameters(infrav1.ManagedDisk{StorageAccountType: "Premium_LRS", DiskEncryptionSetID: desID})).To(Equal(&compute.ManagedDiskParameters{
+		StorageAccountType: compute.StorageAccountTypesPremiumLRS,
+		DiskEncryptionSet:  &compute.DiskEncryptionSetParameters{ID: to.StringPtr(desID)},
+	}))
+}
+
+func TestGetSpotVMOptions(t *testing.T) {
+	g := NewWithT(t)
+
+	maxPrice := resource.MustParse("0.01538")
+
+	testcases := []struct {
+		name                   string
+		spotVMOptions          *infrav1.SpotVMOptions
+		expectedPriority       compute.VirtualMachinePriorityTypes
+		expectedEvictionPolicy compute.VirtualMachineEvictionPolicyTypes
+		expectedBillingProfile *compute.BillingProfile
+	}{
+		{
+			name: "regular VM",
+		},
+		{
+			name:                   "spot VM with defaults",
+			spotVMOptions:          &infrav1.SpotVMOptions{},
+			expectedPriority:       compute.Spot,
+			expectedEvictionPolicy: compute.Deallocate,
+			expectedBillingProfile: &compute.BillingProfile{MaxPrice: to.Float64Ptr(-1)},
+		},
+		{
+			name: "spot VM with max price and delete policy",
+			spotVMOptions: &infrav1.SpotVMOptions{
+				MaxPrice:       &maxPrice,
+				EvictionPolicy: infrav1.SpotEvictionPolicyDelete,
+			},
+			expectedPriority:       compute.Spot,
+			expectedEvictionPolicy: compute.Delete,
+			expectedBillingProfile: &compute.BillingProfile{MaxPrice: to.Float64Ptr(0.01538)},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			priority, evictionPolicy, billingProfile, err := getSpotVMOptions(tc.spotVMOptions)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(priority).To(Equal(tc.expectedPriority))
+			g.Expect(evictionPolicy).To(Equal(tc.expectedEvictionPolicy))
+			g.Expect(billingProfile).To(Equal(tc.expectedBillingProfile))
+		})
+	}
+}
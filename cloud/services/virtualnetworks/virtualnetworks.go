@@ -32,7 +32,7 @@ import (
 type Spec struct {
 	ResourceGroup string
 	Name          string
-	CIDR          string
+	CIDRs         []string
 }
 
 // Get provides information about a virtual network.
@@ -48,18 +48,16 @@ func (s *Service) Get(ctx context.Context, spec interface{}) (*infrav1.VnetSpec,
 		}
 		return nil, errors.Wrapf(err, "failed to get vnet %s", vnetSpec.Name)
 	}
-	cidr := ""
+	var prefixes []string
 	if vnet.VirtualNetworkPropertiesFormat != nil && vnet.VirtualNetworkPropertiesFormat.AddressSpace != nil {
-		prefixes := to.StringSlice(vnet.VirtualNetworkPropertiesFormat.AddressSpace.AddressPrefixes)
-		if prefixes != nil && len(prefixes) > 0 {
-			cidr = prefixes[0]
-		}
+		prefixes = to.StringSlice(vnet.VirtualNetworkPropertiesFormat.AddressSpace.AddressPrefixes)
 	}
 	return &infrav1.VnetSpec{
 		ResourceGroup: vnetSpec.ResourceGroup,
 		ID:            to.String(vnet.ID),
 		Name:          to.String(vnet.Name),
-		CidrBlock:     cidr,
+		CidrBlock:     infrav1.IPv4CIDRBlock(prefixes),
+		CIDRBlocks:    prefixes,
 		Tags:          converters.MapToTags(vnet.Tags),
 	}, nil
 }
@@ -105,7 +103,7 @@ func (s *Service) Reconcile(ctx context.Context, spec interface{}) error {
 		Location: to.StringPtr(s.Scope.Location()),
 		VirtualNetworkPropertiesFormat: &network.VirtualNetworkPropertiesFormat{
 			AddressSpace: &network.AddressSpace{
-				AddressPrefixes: &[]string{vnetSpec.CIDR},
+				AddressPrefixes: &vnetSpec.CIDRs,
 			},
 		},
 	}
@@ -55,7 +55,7 @@ func TestReconcileVnet(t *testing.T) {
 		{
 			name:  "managed vnet exists",
 			input: &infrav1.VnetSpec{ResourceGroup: "my-rg", Name: "vnet-exists"},
-			output: &infrav1.VnetSpec{ResourceGroup: "my-rg", ID: "azure/fake/id", Name: "vnet-exists", CidrBlock: "10.0.0.0/8", Tags: infrav1.Tags{
+			output: &infrav1.VnetSpec{ResourceGroup: "my-rg", ID: "azure/fake/id", Name: "vnet-exists", CidrBlock: "10.0.0.0/8", CIDRBlocks: []string{"10.0.0.0/8"}, Tags: infrav1.Tags{
 				"Name": "vnet-exists",
 				"sigs.k8s.io_cluster-api-provider-azure_cluster_test-cluster": "owned",
 				"sigs.k8s.io_cluster-api-provider-azure_role":                 "common",
@@ -92,7 +92,7 @@ func TestReconcileVnet(t *testing.T) {
 		{
 			name:   "unmanaged vnet exists",
 			input:  &infrav1.VnetSpec{ResourceGroup: "custom-vnet-rg", Name: "custom-vnet", CidrBlock: "10.0.0.0/16"},
-			output: &infrav1.VnetSpec{ResourceGroup: "custom-vnet-rg", ID: "azure/custom-vnet/id", Name: "custom-vnet", CidrBlock: "10.0.0.0/16", Tags: infrav1.Tags{"Name": "my-custom-vnet"}},
+			output: &infrav1.VnetSpec{ResourceGroup: "custom-vnet-rg", ID: "azure/custom-vnet/id", Name: "custom-vnet", CidrBlock: "10.0.0.0/16", CIDRBlocks: []string{"10.0.0.0/16"}, Tags: infrav1.Tags{"Name": "my-custom-vnet"}},
 			expect: func(m *mock_virtualnetworks.MockClientMockRecorder) {
 				m.Get(context.TODO(), "custom-vnet-rg", "custom-vnet").
 					Return(network.VirtualNetwork{
@@ -109,6 +109,37 @@ func TestReconcileVnet(t *testing.T) {
 					}, nil)
 			},
 		},
+		{
+			name:   "dual-stack vnet does not exist",
+			input:  &infrav1.VnetSpec{ResourceGroup: "my-rg", Name: "vnet-new", CidrBlock: "10.0.0.0/8", CIDRBlocks: []string{"10.0.0.0/8", "2001:1234:5678:9a00::/56"}},
+			output: &infrav1.VnetSpec{ResourceGroup: "my-rg", Name: "vnet-new", CidrBlock: "10.0.0.0/8", CIDRBlocks: []string{"10.0.0.0/8", "2001:1234:5678:9a00::/56"}},
+			expect: func(m *mock_virtualnetworks.MockClientMockRecorder) {
+				m.Get(context.TODO(), "my-rg", "vnet-new").
+					Return(network.VirtualNetwork{}, autorest.NewErrorWithResponse("", "", &http.Response{StatusCode: 404}, "Not found"))
+
+				m.CreateOrUpdate(context.TODO(), "my-rg", "vnet-new", gomock.AssignableToTypeOf(network.VirtualNetwork{})).
+					Do(func(_ context.Context, _, _ string, vnet network.VirtualNetwork) {
+						g.Expect(*vnet.AddressSpace.AddressPrefixes).To(Equal([]string{"10.0.0.0/8", "2001:1234:5678:9a00::/56"}))
+					})
+			},
+		},
+		{
+			name:   "dual-stack vnet exists",
+			input:  &infrav1.VnetSpec{ResourceGroup: "my-rg", Name: "vnet-exists"},
+			output: &infrav1.VnetSpec{ResourceGroup: "my-rg", ID: "azure/fake/id", Name: "vnet-exists", CidrBlock: "10.0.0.0/8", CIDRBlocks: []string{"2001:1234:5678:9a00::/56", "10.0.0.0/8"}, Tags: infrav1.Tags{}},
+			expect: func(m *mock_virtualnetworks.MockClientMockRecorder) {
+				m.Get(context.TODO(), "my-rg", "vnet-exists").
+					Return(network.VirtualNetwork{
+						ID:   to.StringPtr("azure/fake/id"),
+						Name: to.StringPtr("vnet-exists"),
+						VirtualNetworkPropertiesFormat: &network.VirtualNetworkPropertiesFormat{
+							AddressSpace: &network.AddressSpace{
+								AddressPrefixes: to.StringSlicePtr([]string{"2001:1234:5678:9a00::/56", "10.0.0.0/8"}),
+							},
+						},
+					}, nil)
+			},
+		},
 		{
 			name:   "custom vnet not found",
 			input:  &infrav1.VnetSpec{ResourceGroup: "custom-vnet-rg", Name: "custom-vnet", CidrBlock: "10.0.0.0/16"},
@@ -162,7 +193,7 @@ func TestReconcileVnet(t *testing.T) {
 			vnetSpec := &Spec{
 				Name:          clusterScope.Vnet().Name,
 				ResourceGroup: clusterScope.Vnet().ResourceGroup,
-				CIDR:          clusterScope.Vnet().CidrBlock,
+				CIDRs:         clusterScope.Vnet().CIDRBlocks,
 			}
 
 			err = s.Reconcile(context.TODO(), vnetSpec)
@@ -254,7 +285,7 @@ func TestDeleteVnet(t *testing.T) {
 			vnetSpec := &Spec{
 				Name:          clusterScope.Vnet().Name,
 				ResourceGroup: clusterScope.Vnet().ResourceGroup,
-				CIDR:          clusterScope.Vnet().CidrBlock,
+				CIDRs:         clusterScope.Vnet().CIDRBlocks,
 			}
 
 			g.Expect(s.Delete(context.TODO(), vnetSpec)).To(Succeed())
@@ -26,6 +26,7 @@ import (
 	"k8s.io/client-go/tools/record"
 	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1alpha3"
 	"sigs.k8s.io/cluster-api-provider-azure/cloud/scope"
+	"sigs.k8s.io/cluster-api-provider-azure/cloud/services/resourceskus"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
 	"sigs.k8s.io/cluster-api/util"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -40,6 +41,8 @@ type AzureClusterReconciler struct {
 	client.Client
 	Log      logr.Logger
 	Recorder record.EventRecorder
+	// SKUCache is the resource SKU cache shared by the controllers of the manager.
+	SKUCache *resourceskus.Cache
 }
 
 func (r *AzureClusterReconciler) SetupWithManager(mgr ctrl.Manager, options controller.Options) error {
@@ -51,6 +54,8 @@ func (r *AzureClusterReconciler) SetupWithManager(mgr ctrl.Manager, options cont
 
 // +kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=azureclusters,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=azureclusters/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=azureclusteridentities,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch
 // +kubebuilder:rbac:groups=cluster.x-k8s.io,resources=clusters;clusters/status,verbs=get;list;watch
 
 func (r *AzureClusterReconciler) Reconcile(req ctrl.Request) (_ ctrl.Result, reterr error) {
@@ -118,21 +123,27 @@ func (r *AzureClusterReconciler) reconcileNormal(clusterScope *scope.ClusterScop
 		return reconcile.Result{}, err
 	}
 
-	err := newAzureClusterReconciler(clusterScope).Reconcile()
+	err := newAzureClusterReconciler(clusterScope, r.SKUCache).Reconcile()
 	if err != nil {
 		return reconcile.Result{}, errors.Wrap(err, "failed to reconcile cluster services")
 	}
 
-	// TODO: We may need to use azureCluster.Status.Network.APIServerIP.IPAddress
-	//       instead when we look at configuring private clusters.
-	if azureCluster.Status.Network.APIServerIP.DNSName == "" {
+	apiServerHost := azureCluster.Status.Network.APIServerIP.DNSName
+	if clusterScope.APIServerLB().IsInternal() {
+		// Private clusters are reached through the frontend IP of the internal load balancer.
+		apiServerHost = ""
+		if subnet := clusterScope.ControlPlaneSubnet(); subnet != nil {
+			apiServerHost = subnet.InternalLBIPAddress
+		}
+	}
+	if apiServerHost == "" {
 		clusterScope.Info("Waiting for API server endpoint to exist")
 		return reconcile.Result{RequeueAfter: 15 * time.Second}, nil
 	}
 
 	// Set APIEndpoints so the Cluster API Cluster Controller can pull them
 	azureCluster.Spec.ControlPlaneEndpoint = clusterv1.APIEndpoint{
-		Host: azureCluster.Status.Network.APIServerIP.DNSName,
+		Host: apiServerHost,
 		Port: clusterScope.APIServerPort(),
 	}
 
@@ -147,7 +158,7 @@ func (r *AzureClusterReconciler) reconcileDelete(clusterScope *scope.ClusterScop
 
 	azureCluster := clusterScope.AzureCluster
 
-	if err := newAzureClusterReconciler(clusterScope).Delete(); err != nil {
+	if err := newAzureClusterReconciler(clusterScope, r.SKUCache).Delete(); err != nil {
 		return reconcile.Result{}, errors.Wrapf(err, "error deleting AzureCluster %s/%s", azureCluster.Namespace, azureCluster.Name)
 	}
 
@@ -17,6 +17,7 @@ limitations under the License.
 package controllers
 
 import (
+	"encoding/base64"
 	"fmt"
 	"hash/fnv"
 
@@ -25,48 +26,74 @@ import (
 	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1alpha3"
 	azure "sigs.k8s.io/cluster-api-provider-azure/cloud"
 	"sigs.k8s.io/cluster-api-provider-azure/cloud/scope"
+	"sigs.k8s.io/cluster-api-provider-azure/cloud/services/availabilityzones"
+	"sigs.k8s.io/cluster-api-provider-azure/cloud/services/bastionhosts"
+	"sigs.k8s.io/cluster-api-provider-azure/cloud/services/disks"
 	"sigs.k8s.io/cluster-api-provider-azure/cloud/services/groups"
 	"sigs.k8s.io/cluster-api-provider-azure/cloud/services/internalloadbalancers"
+	"sigs.k8s.io/cluster-api-provider-azure/cloud/services/natgateways"
+	"sigs.k8s.io/cluster-api-provider-azure/cloud/services/networkinterfaces"
 	"sigs.k8s.io/cluster-api-provider-azure/cloud/services/publicips"
 	"sigs.k8s.io/cluster-api-provider-azure/cloud/services/publicloadbalancers"
+	"sigs.k8s.io/cluster-api-provider-azure/cloud/services/resourceskus"
 	"sigs.k8s.io/cluster-api-provider-azure/cloud/services/routetables"
 	"sigs.k8s.io/cluster-api-provider-azure/cloud/services/securitygroups"
 	"sigs.k8s.io/cluster-api-provider-azure/cloud/services/subnets"
+	"sigs.k8s.io/cluster-api-provider-azure/cloud/services/virtualmachines"
 	"sigs.k8s.io/cluster-api-provider-azure/cloud/services/virtualnetworks"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
 )
 
 // azureClusterReconciler are list of services required by cluster controller
 type azureClusterReconciler struct {
-	scope            *scope.ClusterScope
-	groupsSvc        azure.Service
-	vnetSvc          azure.Service
-	securityGroupSvc azure.Service
-	routeTableSvc    azure.Service
-	subnetsSvc       azure.Service
-	internalLBSvc    azure.Service
-	publicIPSvc      azure.Service
-	publicLBSvc      azure.Service
+	scope                *scope.ClusterScope
+	groupsSvc            azure.Service
+	vnetSvc              azure.Service
+	securityGroupSvc     azure.Service
+	routeTableSvc        azure.Service
+	subnetsSvc           azure.Service
+	natGatewaysSvc       azure.Service
+	internalLBSvc        azure.Service
+	publicIPSvc          azure.Service
+	publicLBSvc          azure.Service
+	availabilityZonesSvc azure.GetterService
+	bastionHostsSvc      azure.GetterService
+	jumpboxSvc           azure.GetterService
+	jumpboxNICSvc        azure.Service
+	disksSvc             azure.Service
 }
 
 // newAzureClusterReconciler populates all the services based on input scope
-func newAzureClusterReconciler(scope *scope.ClusterScope) *azureClusterReconciler {
+func newAzureClusterReconciler(scope *scope.ClusterScope, skuCache *resourceskus.Cache) *azureClusterReconciler {
 	return &azureClusterReconciler{
-		scope:            scope,
-		groupsSvc:        groups.NewService(scope),
-		vnetSvc:          virtualnetworks.NewService(scope),
-		securityGroupSvc: securitygroups.NewService(scope),
-		routeTableSvc:    routetables.NewService(scope),
-		subnetsSvc:       subnets.NewService(scope),
-		internalLBSvc:    internalloadbalancers.NewService(scope),
-		publicIPSvc:      publicips.NewService(scope),
-		publicLBSvc:      publicloadbalancers.NewService(scope),
+		scope:                scope,
+		groupsSvc:            groups.NewService(scope),
+		vnetSvc:              virtualnetworks.NewService(scope),
+		securityGroupSvc:     securitygroups.NewService(scope),
+		routeTableSvc:        routetables.NewService(scope),
+		subnetsSvc:           subnets.NewService(scope),
+		natGatewaysSvc:       natgateways.NewService(scope),
+		internalLBSvc:        internalloadbalancers.NewService(scope),
+		publicIPSvc:          publicips.NewService(scope),
+		publicLBSvc:          publicloadbalancers.NewService(scope),
+		availabilityZonesSvc: availabilityzones.NewService(scope, skuCache),
+		bastionHostsSvc:      bastionhosts.NewService(scope),
+		jumpboxSvc:           virtualmachines.NewService(scope, nil),
+		jumpboxNICSvc:        networkinterfaces.NewService(scope, nil),
+		disksSvc:             disks.NewService(scope),
 	}
 }
 
 // Reconcile reconciles all the services in pre determined order
 func (r *azureClusterReconciler) Reconcile() error {
 	klog.V(2).Infof("reconciling cluster %s", r.scope.Name())
-	r.createOrUpdateNetworkAPIServerIP()
+	if !r.scope.APIServerLB().IsInternal() {
+		r.createOrUpdateNetworkAPIServerIP()
+	}
+
+	if err := r.setFailureDomainsForLocation(); err != nil {
+		return errors.Wrapf(err, "failed to get availability zones for cluster %s", r.scope.Name())
+	}
 
 	if err := r.groupsSvc.Reconcile(r.scope.Context, nil); err != nil {
 		return errors.Wrapf(err, "failed to reconcile resource group for cluster %s", r.scope.Name())
@@ -78,9 +105,7 @@ func (r *azureClusterReconciler) Reconcile() error {
 	if r.scope.Vnet().Name == "" {
 		r.scope.Vnet().Name = azure.GenerateVnetName(r.scope.Name())
 	}
-	if r.scope.Vnet().CidrBlock == "" {
-		r.scope.Vnet().CidrBlock = azure.DefaultVnetCIDR
-	}
+	defaultCIDRBlocks(&r.scope.Vnet().CidrBlock, &r.scope.Vnet().CIDRBlocks, azure.DefaultVnetCIDR)
 
 	if len(r.scope.Subnets()) == 0 {
 		r.scope.AzureCluster.Spec.NetworkSpec.Subnets = infrav1.Subnets{&infrav1.SubnetSpec{}, &infrav1.SubnetSpec{}}
@@ -89,37 +114,54 @@ func (r *azureClusterReconciler) Reconcile() error {
 	vnetSpec := &virtualnetworks.Spec{
 		ResourceGroup: r.scope.Vnet().ResourceGroup,
 		Name:          r.scope.Vnet().Name,
-		CIDR:          r.scope.Vnet().CidrBlock,
+		CIDRs:         r.scope.Vnet().CIDRBlocks,
 	}
 	if err := r.vnetSvc.Reconcile(r.scope.Context, vnetSpec); err != nil {
 		return errors.Wrapf(err, "failed to reconcile virtual network for cluster %s", r.scope.Name())
 	}
 	sgName := azure.GenerateControlPlaneSecurityGroupName(r.scope.Name())
-	if r.scope.ControlPlaneSubnet() != nil && r.scope.ControlPlaneSubnet().SecurityGroup.Name != "" {
-		sgName = r.scope.ControlPlaneSubnet().SecurityGroup.Name
+	var sgRules infrav1.IngressRules
+	if r.scope.ControlPlaneSubnet() != nil {
+		if r.scope.ControlPlaneSubnet().SecurityGroup.Name != "" {
+			sgName = r.scope.ControlPlaneSubnet().SecurityGroup.Name
+		}
+		sgRules = r.scope.ControlPlaneSubnet().SecurityGroup.IngressRules
+	}
+	sshSourceCIDRs, err := r.sshSourceCIDRs()
+	if err != nil {
+		return errors.Wrapf(err, "failed to get SSH source CIDR blocks for cluster %s", r.scope.Name())
 	}
 	sgSpec := &securitygroups.Spec{
 		Name:           sgName,
 		IsControlPlane: true,
+		IngressRules:   sgRules,
+		SSHAccess:      r.scope.SSHAccess().Mode,
+		SSHSourceCIDRs: sshSourceCIDRs,
 	}
 	if err := r.securityGroupSvc.Reconcile(r.scope.Context, sgSpec); err != nil {
 		return errors.Wrapf(err, "failed to reconcile control plane network security group for cluster %s", r.scope.Name())
 	}
 
 	sgName = azure.GenerateNodeSecurityGroupName(r.scope.Name())
-	if r.scope.NodeSubnet() != nil && r.scope.NodeSubnet().SecurityGroup.Name != "" {
-		sgName = r.scope.NodeSubnet().SecurityGroup.Name
+	sgRules = nil
+	if r.scope.NodeSubnet() != nil {
+		if r.scope.NodeSubnet().SecurityGroup.Name != "" {
+			sgName = r.scope.NodeSubnet().SecurityGroup.Name
+		}
+		sgRules = r.scope.NodeSubnet().SecurityGroup.IngressRules
 	}
 	sgSpec = &securitygroups.Spec{
 		Name:           sgName,
 		IsControlPlane: false,
+		IngressRules:   sgRules,
 	}
 	if err := r.securityGroupSvc.Reconcile(r.scope.Context, sgSpec); err != nil {
 		return errors.Wrapf(err, "failed to reconcile node network security group for cluster %s", r.scope.Name())
 	}
 
 	rtSpec := &routetables.Spec{
-		Name: azure.GenerateNodeRouteTableName(r.scope.Name()),
+		Name:   azure.GenerateNodeRouteTableName(r.scope.Name()),
+		Routes: r.scope.Routes(),
 	}
 	if err := r.routeTableSvc.Reconcile(r.scope.Context, rtSpec); err != nil {
 		return errors.Wrapf(err, "failed to reconcile node route table for cluster %s", r.scope.Name())
@@ -135,16 +177,14 @@ func (r *azureClusterReconciler) Reconcile() error {
 	if cpSubnet.Name == "" {
 		cpSubnet.Name = azure.GenerateControlPlaneSubnetName(r.scope.Name())
 	}
-	if cpSubnet.CidrBlock == "" {
-		cpSubnet.CidrBlock = azure.DefaultControlPlaneSubnetCIDR
-	}
+	defaultCIDRBlocks(&cpSubnet.CidrBlock, &cpSubnet.CIDRBlocks, azure.DefaultControlPlaneSubnetCIDR)
 	if cpSubnet.SecurityGroup.Name == "" {
 		cpSubnet.SecurityGroup.Name = azure.GenerateControlPlaneSecurityGroupName(r.scope.Name())
 	}
 
 	subnetSpec := &subnets.Spec{
 		Name:                cpSubnet.Name,
-		CIDR:                cpSubnet.CidrBlock,
+		CIDRs:               cpSubnet.CIDRBlocks,
 		VnetName:            r.scope.Vnet().Name,
 		SecurityGroupName:   cpSubnet.SecurityGroup.Name,
 		RouteTableName:      azure.GenerateNodeRouteTableName(r.scope.Name()),
@@ -165,16 +205,14 @@ func (r *azureClusterReconciler) Reconcile() error {
 	if nodeSubnet.Name == "" {
 		nodeSubnet.Name = azure.GenerateNodeSubnetName(r.scope.Name())
 	}
-	if nodeSubnet.CidrBlock == "" {
-		nodeSubnet.CidrBlock = azure.DefaultNodeSubnetCIDR
-	}
+	defaultCIDRBlocks(&nodeSubnet.CidrBlock, &nodeSubnet.CIDRBlocks, azure.DefaultNodeSubnetCIDR)
 	if nodeSubnet.SecurityGroup.Name == "" {
 		nodeSubnet.SecurityGroup.Name = azure.GenerateNodeSecurityGroupName(r.scope.Name())
 	}
 
 	subnetSpec = &subnets.Spec{
 		Name:              nodeSubnet.Name,
-		CIDR:              nodeSubnet.CidrBlock,
+		CIDRs:             nodeSubnet.CIDRBlocks,
 		VnetName:          r.scope.Vnet().Name,
 		SecurityGroupName: nodeSubnet.SecurityGroup.Name,
 		RouteTableName:    azure.GenerateNodeRouteTableName(r.scope.Name()),
@@ -184,6 +222,18 @@ func (r *azureClusterReconciler) Reconcile() error {
 		return errors.Wrapf(err, "failed to reconcile node subnet for cluster %s", r.scope.Name())
 	}
 
+	if err := r.reconcileNatGateways(); err != nil {
+		return errors.Wrapf(err, "failed to reconcile NAT gateways for cluster %s", r.scope.Name())
+	}
+
+	if err := r.reconcileNodeOutboundLB(); err != nil {
+		return errors.Wrapf(err, "failed to reconcile node outbound load balancer for cluster %s", r.scope.Name())
+	}
+
+	if err := r.reconcileBastion(); err != nil {
+		return errors.Wrapf(err, "failed to reconcile bastion host for cluster %s", r.scope.Name())
+	}
+
 	internalLBSpec := &internalloadbalancers.Spec{
 		Name:       azure.GenerateInternalLBName(r.scope.Name()),
 		SubnetName: r.scope.ControlPlaneSubnet().Name,
@@ -195,6 +245,11 @@ func (r *azureClusterReconciler) Reconcile() error {
 		return errors.Wrapf(err, "failed to reconcile control plane internal load balancer for cluster %s", r.scope.Name())
 	}
 
+	if r.scope.APIServerLB().IsInternal() {
+		// private clusters expose the API server through the internal load balancer only
+		return nil
+	}
+
 	publicIPSpec := &publicips.Spec{
 		Name: r.scope.Network().APIServerIP.Name,
 	}
@@ -202,9 +257,20 @@ func (r *azureClusterReconciler) Reconcile() error {
 		return errors.Wrapf(err, "failed to reconcile control plane public ip for cluster %s", r.scope.Name())
 	}
 
+	if r.scope.Network().APIServerIPv6.Name != "" {
+		publicIPSpec := &publicips.Spec{
+			Name:   r.scope.Network().APIServerIPv6.Name,
+			IsIPv6: true,
+		}
+		if err := r.publicIPSvc.Reconcile(r.scope.Context, publicIPSpec); err != nil {
+			return errors.Wrapf(err, "failed to reconcile control plane IPv6 public ip for cluster %s", r.scope.Name())
+		}
+	}
+
 	publicLBSpec := &publicloadbalancers.Spec{
-		Name:         azure.GeneratePublicLBName(r.scope.Name()),
-		PublicIPName: r.scope.Network().APIServerIP.Name,
+		Name:           azure.GeneratePublicLBName(r.scope.Name()),
+		PublicIPName:   r.scope.Network().APIServerIP.Name,
+		PublicIPv6Name: r.scope.Network().APIServerIPv6.Name,
 	}
 	if err := r.publicLBSvc.Reconcile(r.scope.Context, publicLBSpec); err != nil {
 		return errors.Wrapf(err, "failed to reconcile control plane public load balancer for cluster %s", r.scope.Name())
@@ -222,6 +288,10 @@ func (r *azureClusterReconciler) Delete() error {
 		r.scope.Vnet().Name = azure.GenerateVnetName(r.scope.Name())
 	}
 
+	if err := r.deleteBastion(); err != nil {
+		return errors.Wrap(err, "failed to delete bastion host")
+	}
+
 	if err := r.deleteLB(); err != nil {
 		return errors.Wrap(err, "failed to delete load balancer")
 	}
@@ -230,6 +300,10 @@ func (r *azureClusterReconciler) Delete() error {
 		return errors.Wrap(err, "failed to delete subnets")
 	}
 
+	if err := r.deleteNatGateways(); err != nil {
+		return errors.Wrap(err, "failed to delete NAT gateways")
+	}
+
 	rtSpec := &routetables.Spec{
 		Name: azure.GenerateNodeRouteTableName(r.scope.Name()),
 	}
@@ -263,6 +337,32 @@ func (r *azureClusterReconciler) Delete() error {
 }
 
 func (r *azureClusterReconciler) deleteLB() error {
+	if err := r.deletePublicLB(); err != nil {
+		return err
+	}
+
+	if err := r.deleteNodeOutboundLB(); err != nil {
+		return err
+	}
+
+	internalLBSpec := &internalloadbalancers.Spec{
+		Name: azure.GenerateInternalLBName(r.scope.Name()),
+	}
+	if err := r.internalLBSvc.Delete(r.scope.Context, internalLBSpec); err != nil {
+		if !azure.ResourceNotFound(err) {
+			return errors.Wrapf(err, "failed to internal load balancer %s for cluster %s", azure.GenerateInternalLBName(r.scope.Name()), r.scope.Name())
+		}
+	}
+
+	return nil
+}
+
+// deletePublicLB deletes the public load balancer of the API server and its public IPs, which private clusters don't have.
+func (r *azureClusterReconciler) deletePublicLB() error {
+	if r.scope.APIServerLB().IsInternal() {
+		return nil
+	}
+
 	publicLBSpec := &publicloadbalancers.Spec{
 		Name: azure.GeneratePublicLBName(r.scope.Name()),
 	}
@@ -279,13 +379,14 @@ func (r *azureClusterReconciler) deleteLB() error {
 			return errors.Wrapf(err, "failed to delete public ip %s for cluster %s", r.scope.Network().APIServerIP.Name, r.scope.Name())
 		}
 	}
-
-	internalLBSpec := &internalloadbalancers.Spec{
-		Name: azure.GenerateInternalLBName(r.scope.Name()),
-	}
-	if err := r.internalLBSvc.Delete(r.scope.Context, internalLBSpec); err != nil {
-		if !azure.ResourceNotFound(err) {
-			return errors.Wrapf(err, "failed to internal load balancer %s for cluster %s", azure.GenerateInternalLBName(r.scope.Name()), r.scope.Name())
+	if r.scope.Network().APIServerIPv6.Name != "" {
+		publicIPSpec := &publicips.Spec{
+			Name: r.scope.Network().APIServerIPv6.Name,
+		}
+		if err := r.publicIPSvc.Delete(r.scope.Context, publicIPSpec); err != nil {
+			if !azure.ResourceNotFound(err) {
+				return errors.Wrapf(err, "failed to delete public ip %s for cluster %s", r.scope.Network().APIServerIPv6.Name, r.scope.Name())
+			}
 		}
 	}
 
@@ -328,6 +429,365 @@ func (r *azureClusterReconciler) deleteNSG() error {
 	return nil
 }
 
+// reconcileNodeOutboundLB reconciles the node outbound load balancer of the cluster, if any, and its public IP.
+func (r *azureClusterReconciler) reconcileNodeOutboundLB() error {
+	outboundLB := r.scope.NodeOutboundLB()
+	if outboundLB == nil {
+		return nil
+	}
+
+	publicIPSpec := &publicips.Spec{
+		Name: azure.GenerateNodeOutboundPublicIPName(r.scope.Name()),
+	}
+	if err := r.publicIPSvc.Reconcile(r.scope.Context, publicIPSpec); err != nil {
+		return errors.Wrap(err, "failed to reconcile node outbound public ip")
+	}
+
+	publicLBSpec := &publicloadbalancers.Spec{
+		Name:                   azure.GenerateNodeOutboundLBName(r.scope.Name()),
+		PublicIPName:           azure.GenerateNodeOutboundPublicIPName(r.scope.Name()),
+		IsNodeOutbound:         true,
+		AllocatedOutboundPorts: outboundLB.AllocatedOutboundPorts,
+		IdleTimeoutInMinutes:   outboundLB.IdleTimeoutInMinutes,
+	}
+	return r.publicLBSvc.Reconcile(r.scope.Context, publicLBSpec)
+}
+
+// deleteNodeOutboundLB deletes the node outbound load balancer of the cluster, if any, and its public IP.
+func (r *azureClusterReconciler) deleteNodeOutboundLB() error {
+	if r.scope.NodeOutboundLB() == nil {
+		return nil
+	}
+
+	publicLBSpec := &publicloadbalancers.Spec{
+		Name: azure.GenerateNodeOutboundLBName(r.scope.Name()),
+	}
+	if err := r.publicLBSvc.Delete(r.scope.Context, publicLBSpec); err != nil {
+		return errors.Wrapf(err, "failed to delete lb %s for cluster %s", publicLBSpec.Name, r.scope.Name())
+	}
+	publicIPSpec := &publicips.Spec{
+		Name: azure.GenerateNodeOutboundPublicIPName(r.scope.Name()),
+	}
+	if err := r.publicIPSvc.Delete(r.scope.Context, publicIPSpec); err != nil {
+		return errors.Wrapf(err, "failed to delete public ip %s for cluster %s", publicIPSpec.Name, r.scope.Name())
+	}
+
+	return nil
+}
+
+// reconcileNatGateways reconciles the NAT gateways of the control plane and node subnets, if any, and their public IPs.
+// The control plane of a private cluster has no public load balancer, so its subnet gets a NAT gateway by default.
+func (r *azureClusterReconciler) reconcileNatGateways() error {
+	if !r.scope.Vnet().IsManaged(r.scope.Name()) {
+		r.scope.V(4).Info("Skipping NAT gateway reconcile in custom vnet mode")
+		return nil
+	}
+	cpSubnet := r.scope.ControlPlaneSubnet()
+	if cpSubnet != nil && cpSubnet.NatGateway == nil && r.scope.APIServerLB().IsInternal() {
+		cpSubnet.NatGateway = &infrav1.NatGateway{}
+	}
+	if err := r.reconcileNatGateway(cpSubnet, azure.GenerateControlPlaneNatGatewayName(r.scope.Name())); err != nil {
+		return errors.Wrap(err, "failed to reconcile control plane NAT gateway")
+	}
+	if err := r.reconcileNatGateway(r.scope.NodeSubnet(), azure.GenerateNatGatewayName(r.scope.Name())); err != nil {
+		return errors.Wrap(err, "failed to reconcile node NAT gateway")
+	}
+	return nil
+}
+
+// reconcileNatGateway reconciles the NAT gateway of a subnet, if any, and its public IP.
+func (r *azureClusterReconciler) reconcileNatGateway(subnet *infrav1.SubnetSpec, defaultName string) error {
+	if subnet == nil || subnet.NatGateway == nil {
+		return nil
+	}
+	setNatGatewayDefaults(subnet.NatGateway, defaultName)
+
+	publicIPSpec := &publicips.Spec{
+		Name: subnet.NatGateway.PublicIP.Name,
+	}
+	if err := r.publicIPSvc.Reconcile(r.scope.Context, publicIPSpec); err != nil {
+		return errors.Wrap(err, "failed to reconcile NAT gateway public ip")
+	}
+
+	natGatewaySpec := &natgateways.Spec{
+		Name:                 subnet.NatGateway.Name,
+		PublicIPName:         subnet.NatGateway.PublicIP.Name,
+		IdleTimeoutInMinutes: subnet.NatGateway.IdleTimeoutInMinutes,
+		SubnetName:           subnet.Name,
+		VnetName:             r.scope.Vnet().Name,
+		Role:                 subnet.Role,
+	}
+	return r.natGatewaysSvc.Reconcile(r.scope.Context, natGatewaySpec)
+}
+
+// deleteNatGateways deletes the NAT gateways of the control plane and node subnets, if any, and their public IPs.
+// The subnets must be deleted first, as Azure doesn't delete NAT gateways that are still in use.
+func (r *azureClusterReconciler) deleteNatGateways() error {
+	if !r.scope.Vnet().IsManaged(r.scope.Name()) {
+		return nil
+	}
+	if err := r.deleteNatGateway(r.scope.ControlPlaneSubnet(), azure.GenerateControlPlaneNatGatewayName(r.scope.Name())); err != nil {
+		return err
+	}
+	return r.deleteNatGateway(r.scope.NodeSubnet(), azure.GenerateNatGatewayName(r.scope.Name()))
+}
+
+// deleteNatGateway deletes the NAT gateway of a subnet, if any, and its public IP.
+func (r *azureClusterReconciler) deleteNatGateway(subnet *infrav1.SubnetSpec, defaultName string) error {
+	if subnet == nil || subnet.NatGateway == nil {
+		return nil
+	}
+	setNatGatewayDefaults(subnet.NatGateway, defaultName)
+
+	natGatewaySpec := &natgateways.Spec{
+		Name: subnet.NatGateway.Name,
+	}
+	if err := r.natGatewaysSvc.Delete(r.scope.Context, natGatewaySpec); err != nil {
+		return errors.Wrapf(err, "failed to delete NAT gateway %s for cluster %s", natGatewaySpec.Name, r.scope.Name())
+	}
+
+	publicIPSpec := &publicips.Spec{
+		Name: subnet.NatGateway.PublicIP.Name,
+	}
+	if err := r.publicIPSvc.Delete(r.scope.Context, publicIPSpec); err != nil {
+		return errors.Wrapf(err, "failed to delete public ip %s for cluster %s", publicIPSpec.Name, r.scope.Name())
+	}
+
+	return nil
+}
+
+// setNatGatewayDefaults defaults the names of a NAT gateway and its public IP.
+func setNatGatewayDefaults(natGateway *infrav1.NatGateway, defaultName string) {
+	if natGateway.Name == "" {
+		natGateway.Name = defaultName
+	}
+	if natGateway.PublicIP.Name == "" {
+		natGateway.PublicIP.Name = azure.GenerateNatGatewayPublicIPName(natGateway.Name)
+	}
+}
+
+// reconcileBastion reconciles the bastion host of the cluster, if any, and reports it in the cluster status.
+func (r *azureClusterReconciler) reconcileBastion() error {
+	bastion := r.scope.Bastion()
+	if bastion == nil {
+		return nil
+	}
+	setBastionDefaults(bastion, r.scope.Name())
+
+	if bastion.Type == infrav1.BastionTypeJumpbox {
+		sgSpec := &securitygroups.Spec{
+			Name:         bastion.Subnet.SecurityGroup.Name,
+			IsBastion:    true,
+			IngressRules: bastion.Subnet.SecurityGroup.IngressRules,
+		}
+		if err := r.securityGroupSvc.Reconcile(r.scope.Context, sgSpec); err != nil {
+			return errors.Wrap(err, "failed to reconcile bastion network security group")
+		}
+	}
+
+	subnetSpec := &subnets.Spec{
+		Name:              bastion.Subnet.Name,
+		CIDRs:             bastion.Subnet.CIDRBlocks,
+		VnetName:          r.scope.Vnet().Name,
+		SecurityGroupName: bastion.Subnet.SecurityGroup.Name,
+		Role:              infrav1.SubnetBastion,
+	}
+	if err := r.subnetsSvc.Reconcile(r.scope.Context, subnetSpec); err != nil {
+		return errors.Wrap(err, "failed to reconcile bastion subnet")
+	}
+
+	publicIPSpec := &publicips.Spec{
+		Name: azure.GenerateBastionPublicIPName(r.scope.Name()),
+	}
+	if err := r.publicIPSvc.Reconcile(r.scope.Context, publicIPSpec); err != nil {
+		return errors.Wrap(err, "failed to reconcile bastion public ip")
+	}
+
+	var vmInterface interface{}
+	var err error
+	switch bastion.Type {
+	case infrav1.BastionTypeAzureBastion:
+		bastionSpec := &bastionhosts.Spec{
+			Name:         azure.GenerateBastionName(r.scope.Name()),
+			SubnetName:   bastion.Subnet.Name,
+			PublicIPName: azure.GenerateBastionPublicIPName(r.scope.Name()),
+			VnetName:     r.scope.Vnet().Name,
+		}
+		if err := r.bastionHostsSvc.Reconcile(r.scope.Context, bastionSpec); err != nil {
+			return errors.Wrap(err, "failed to reconcile azure bastion host")
+		}
+		vmInterface, err = r.bastionHostsSvc.Get(r.scope.Context, bastionSpec)
+	case infrav1.BastionTypeJumpbox:
+		vmInterface, err = r.reconcileJumpbox(bastion)
+	default:
+		return errors.Errorf("unknown bastion type %q", bastion.Type)
+	}
+	if err != nil {
+		return errors.Wrap(err, "failed to get bastion host")
+	}
+
+	vm, ok := vmInterface.(*infrav1.VM)
+	if !ok {
+		return errors.New("returned incorrect bastion host interface")
+	}
+	r.scope.AzureCluster.Status.Bastion = *vm
+	return nil
+}
+
+// reconcileJumpbox reconciles the network interface and VM of a bastion jumpbox and returns the VM.
+func (r *azureClusterReconciler) reconcileJumpbox(bastion *infrav1.BastionSpec) (interface{}, error) {
+	name := azure.GenerateBastionName(r.scope.Name())
+	nicSpec := &networkinterfaces.Spec{
+		Name:         azure.GenerateNICName(name),
+		SubnetName:   bastion.Subnet.Name,
+		VnetName:     r.scope.Vnet().Name,
+		PublicIPName: azure.GenerateBastionPublicIPName(r.scope.Name()),
+	}
+	if err := r.jumpboxNICSvc.Reconcile(r.scope.Context, nicSpec); err != nil {
+		return nil, errors.Wrap(err, "failed to reconcile jumpbox network interface")
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(bastion.SSHPublicKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode ssh public key")
+	}
+
+	vmSpec := &virtualmachines.Spec{
+		Name:       name,
+		SSHKeyData: string(decoded),
+		Size:       bastion.VMSize,
+		Image:      azure.GetBastionImage(),
+		OSDisk: infrav1.OSDisk{
+			OSType:     "Linux",
+			DiskSizeGB: 30,
+			ManagedDisk: infrav1.ManagedDisk{
+				StorageAccountType: "Standard_LRS",
+			},
+		},
+		NICNames: []string{nicSpec.Name},
+	}
+	// the SSH key of an existing VM can't be changed, so the jumpbox is only created once
+	if vmInterface, err := r.jumpboxSvc.Get(r.scope.Context, vmSpec); err == nil {
+		return vmInterface, nil
+	}
+	if err := r.jumpboxSvc.Reconcile(r.scope.Context, vmSpec); err != nil {
+		return nil, errors.Wrap(err, "failed to create jumpbox vm")
+	}
+
+	return r.jumpboxSvc.Get(r.scope.Context, vmSpec)
+}
+
+// deleteBastion deletes the bastion host of the cluster, if any, along with the resources created for it.
+func (r *azureClusterReconciler) deleteBastion() error {
+	bastion := r.scope.Bastion()
+	if bastion == nil {
+		return nil
+	}
+	setBastionDefaults(bastion, r.scope.Name())
+
+	name := azure.GenerateBastionName(r.scope.Name())
+	if bastion.Type == infrav1.BastionTypeAzureBastion {
+		if err := r.bastionHostsSvc.Delete(r.scope.Context, &bastionhosts.Spec{Name: name}); err != nil {
+			return errors.Wrapf(err, "failed to delete azure bastion host %s", name)
+		}
+	} else {
+		if err := r.jumpboxSvc.Delete(r.scope.Context, &virtualmachines.Spec{Name: name}); err != nil {
+			return errors.Wrapf(err, "failed to delete jumpbox vm %s", name)
+		}
+		if err := r.jumpboxNICSvc.Delete(r.scope.Context, &networkinterfaces.Spec{Name: azure.GenerateNICName(name)}); err != nil {
+			return errors.Wrapf(err, "failed to delete jumpbox network interface %s", azure.GenerateNICName(name))
+		}
+		if err := r.disksSvc.Delete(r.scope.Context, &disks.Spec{Name: azure.GenerateOSDiskName(name)}); err != nil {
+			return errors.Wrapf(err, "failed to delete jumpbox os disk %s", azure.GenerateOSDiskName(name))
+		}
+	}
+
+	publicIPSpec := &publicips.Spec{
+		Name: azure.GenerateBastionPublicIPName(r.scope.Name()),
+	}
+	if err := r.publicIPSvc.Delete(r.scope.Context, publicIPSpec); err != nil {
+		return errors.Wrapf(err, "failed to delete public ip %s", publicIPSpec.Name)
+	}
+
+	subnetSpec := &subnets.Spec{
+		Name:     bastion.Subnet.Name,
+		VnetName: r.scope.Vnet().Name,
+	}
+	if err := r.subnetsSvc.Delete(r.scope.Context, subnetSpec); err != nil {
+		return errors.Wrapf(err, "failed to delete subnet %s", bastion.Subnet.Name)
+	}
+
+	if bastion.Subnet.SecurityGroup.Name != "" {
+		sgSpec := &securitygroups.Spec{
+			Name: bastion.Subnet.SecurityGroup.Name,
+		}
+		if err := r.securityGroupSvc.Delete(r.scope.Context, sgSpec); err != nil {
+			return errors.Wrapf(err, "failed to delete security group %s", sgSpec.Name)
+		}
+	}
+
+	return nil
+}
+
+// sshSourceCIDRs returns the CIDR blocks SSH connections to the control plane machines are allowed from,
+// or none to allow them from any source.
+func (r *azureClusterReconciler) sshSourceCIDRs() ([]string, error) {
+	sshAccess := r.scope.SSHAccess()
+	if sshAccess.Mode != infrav1.SSHAccessBastionOnly {
+		return sshAccess.SourceCIDRs, nil
+	}
+	bastion := r.scope.Bastion()
+	if bastion == nil {
+		return nil, errors.New("the BastionOnly SSH access mode requires a bastion host")
+	}
+	setBastionDefaults(bastion, r.scope.Name())
+	return bastion.Subnet.CIDRBlocks, nil
+}
+
+// setBastionDefaults defaults the subnet, security group and VM size of a bastion host.
+// Azure Bastion hosts must use the AzureBastionSubnet subnet and get no security group.
+func setBastionDefaults(bastion *infrav1.BastionSpec, clusterName string) {
+	if bastion.Subnet.Name == "" {
+		bastion.Subnet.Name = azure.GenerateBastionSubnetName(clusterName)
+		if bastion.Type == infrav1.BastionTypeAzureBastion {
+			bastion.Subnet.Name = infrav1.AzureBastionSubnetName
+		}
+	}
+	bastion.Subnet.Role = infrav1.SubnetBastion
+	defaultCIDRBlocks(&bastion.Subnet.CidrBlock, &bastion.Subnet.CIDRBlocks, azure.DefaultBastionSubnetCIDR)
+	if bastion.Type == infrav1.BastionTypeJumpbox {
+		if bastion.Subnet.SecurityGroup.Name == "" {
+			bastion.Subnet.SecurityGroup.Name = azure.GenerateBastionSecurityGroupName(clusterName)
+		}
+		if bastion.VMSize == "" {
+			bastion.VMSize = azure.DefaultBastionVMSize
+		}
+	}
+}
+
+// setFailureDomainsForLocation sets the failure domains of the cluster to the availability zones of its location.
+func (r *azureClusterReconciler) setFailureDomainsForLocation() error {
+	zonesInterface, err := r.availabilityZonesSvc.Get(r.scope.Context, &availabilityzones.Spec{})
+	if err != nil {
+		return err
+	}
+	zones, ok := zonesInterface.([]string)
+	if !ok {
+		return errors.New("availability zones Get returned invalid interface")
+	}
+
+	// Rebuild the failure domains so that zones which are no longer available are removed.
+	r.scope.AzureCluster.Status.FailureDomains = nil
+	for _, zone := range zones {
+		// Subnets span all the zones of a region, so every zone can host control plane machines.
+		r.scope.SetFailureDomain(zone, clusterv1.FailureDomainSpec{
+			ControlPlane: true,
+		})
+	}
+
+	return nil
+}
+
 // CreateOrUpdateNetworkAPIServerIP creates or updates public ip name and dns name
 func (r *azureClusterReconciler) createOrUpdateNetworkAPIServerIP() {
 	if r.scope.Network().APIServerIP.Name == "" {
@@ -336,5 +796,27 @@ func (r *azureClusterReconciler) createOrUpdateNetworkAPIServerIP() {
 		r.scope.Network().APIServerIP.Name = azure.GeneratePublicIPName(r.scope.Name(), fmt.Sprintf("%x", h.Sum32()))
 	}
 
-	r.scope.Network().APIServerIP.DNSName = azure.GenerateFQDN(r.scope.Network().APIServerIP.Name, r.scope.Location())
+	r.scope.Network().APIServerIP.DNSName = azure.GenerateFQDN(r.scope.Network().APIServerIP.Name, r.scope.Location(), r.scope.ResourceManagerVMDNSSuffix)
+
+	if r.scope.Vnet().IsIPv6Enabled() {
+		if r.scope.Network().APIServerIPv6.Name == "" {
+			r.scope.Network().APIServerIPv6.Name = azure.GeneratePublicIPv6Name(r.scope.Network().APIServerIP.Name)
+		}
+		r.scope.Network().APIServerIPv6.DNSName = azure.GenerateFQDN(r.scope.Network().APIServerIPv6.Name, r.scope.Location(), r.scope.ResourceManagerVMDNSSuffix)
+	}
+}
+
+// defaultCIDRBlocks defaults the CIDR blocks of a vnet or subnet to its CIDR block, and its CIDR block
+// to the IPv4 CIDR block of its CIDR blocks, falling back to defaultCIDR when neither is set.
+func defaultCIDRBlocks(cidrBlock *string, cidrBlocks *[]string, defaultCIDR string) {
+	if len(*cidrBlocks) == 0 {
+		if *cidrBlock == "" {
+			*cidrBlock = defaultCIDR
+		}
+		*cidrBlocks = []string{*cidrBlock}
+		return
+	}
+	if *cidrBlock == "" {
+		*cidrBlock = infrav1.IPv4CIDRBlock(*cidrBlocks)
+	}
 }
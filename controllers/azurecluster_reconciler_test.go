@@ -0,0 +1,397 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/cluster-api-provider-azure/api/v1alpha3"
+	azure "sigs.k8s.io/cluster-api-provider-azure/cloud"
+	"sigs.k8s.io/cluster-api-provider-azure/cloud/scope"
+	"sigs.k8s.io/cluster-api-provider-azure/cloud/services/natgateways"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+)
+
+func TestSetFailureDomainsForLocation(t *testing.T) {
+	g := NewWithT(t)
+
+	cases := []struct {
+		name     string
+		zones    []string
+		existing clusterv1.FailureDomains
+		expected clusterv1.FailureDomains
+	}{
+		{
+			name:  "location with availability zones",
+			zones: []string{"1", "2", "3"},
+			expected: clusterv1.FailureDomains{
+				"1": clusterv1.FailureDomainSpec{ControlPlane: true},
+				"2": clusterv1.FailureDomainSpec{ControlPlane: true},
+				"3": clusterv1.FailureDomainSpec{ControlPlane: true},
+			},
+		},
+		{
+			name:     "location without availability zones",
+			zones:    []string{},
+			expected: nil,
+		},
+		{
+			name:  "availability zone removed from the location",
+			zones: []string{"1", "3"},
+			existing: clusterv1.FailureDomains{
+				"1": clusterv1.FailureDomainSpec{ControlPlane: true},
+				"2": clusterv1.FailureDomainSpec{ControlPlane: true},
+				"3": clusterv1.FailureDomainSpec{ControlPlane: true},
+			},
+			expected: clusterv1.FailureDomains{
+				"1": clusterv1.FailureDomainSpec{ControlPlane: true},
+				"3": clusterv1.FailureDomainSpec{ControlPlane: true},
+			},
+		},
+		{
+			name:  "all availability zones removed from the location",
+			zones: []string{},
+			existing: clusterv1.FailureDomains{
+				"1": clusterv1.FailureDomainSpec{ControlPlane: true},
+			},
+			expected: nil,
+		},
+	}
+
+	for _, c := range cases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			azureCluster := &v1alpha3.AzureCluster{
+				Status: v1alpha3.AzureClusterStatus{FailureDomains: c.existing},
+			}
+			r := &azureClusterReconciler{
+				scope:                &scope.ClusterScope{AzureCluster: azureCluster, Context: context.TODO()},
+				availabilityZonesSvc: &fakeZonesService{zones: c.zones},
+			}
+
+			g.Expect(r.setFailureDomainsForLocation()).To(Succeed())
+			g.Expect(azureCluster.Status.FailureDomains).To(Equal(c.expected))
+		})
+	}
+}
+
+func TestDefaultCIDRBlocks(t *testing.T) {
+	g := NewWithT(t)
+
+	cases := []struct {
+		name               string
+		cidrBlock          string
+		cidrBlocks         []string
+		expectedCIDRBlock  string
+		expectedCIDRBlocks []string
+	}{
+		{
+			name:               "nothing set",
+			expectedCIDRBlock:  "10.0.0.0/8",
+			expectedCIDRBlocks: []string{"10.0.0.0/8"},
+		},
+		{
+			name:               "only cidr block set",
+			cidrBlock:          "10.1.0.0/16",
+			expectedCIDRBlock:  "10.1.0.0/16",
+			expectedCIDRBlocks: []string{"10.1.0.0/16"},
+		},
+		{
+			name:               "only cidr blocks set",
+			cidrBlocks:         []string{"2001:1234:5678:9a00::/56", "10.1.0.0/16"},
+			expectedCIDRBlock:  "10.1.0.0/16",
+			expectedCIDRBlocks: []string{"2001:1234:5678:9a00::/56", "10.1.0.0/16"},
+		},
+		{
+			name:               "both set",
+			cidrBlock:          "10.1.0.0/16",
+			cidrBlocks:         []string{"10.1.0.0/16", "2001:1234:5678:9a00::/56"},
+			expectedCIDRBlock:  "10.1.0.0/16",
+			expectedCIDRBlocks: []string{"10.1.0.0/16", "2001:1234:5678:9a00::/56"},
+		},
+	}
+
+	for _, c := range cases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			defaultCIDRBlocks(&c.cidrBlock, &c.cidrBlocks, "10.0.0.0/8")
+			g.Expect(c.cidrBlock).To(Equal(c.expectedCIDRBlock))
+			g.Expect(c.cidrBlocks).To(Equal(c.expectedCIDRBlocks))
+		})
+	}
+}
+
+func TestSetBastionDefaults(t *testing.T) {
+	g := NewWithT(t)
+
+	cases := []struct {
+		name     string
+		bastion  v1alpha3.BastionSpec
+		expected v1alpha3.BastionSpec
+	}{
+		{
+			name:    "azure bastion",
+			bastion: v1alpha3.BastionSpec{Type: v1alpha3.BastionTypeAzureBastion},
+			expected: v1alpha3.BastionSpec{
+				Type: v1alpha3.BastionTypeAzureBastion,
+				Subnet: v1alpha3.SubnetSpec{
+					Role:       v1alpha3.SubnetBastion,
+					Name:       "AzureBastionSubnet",
+					CidrBlock:  "10.255.255.224/27",
+					CIDRBlocks: []string{"10.255.255.224/27"},
+				},
+			},
+		},
+		{
+			name:    "jumpbox",
+			bastion: v1alpha3.BastionSpec{Type: v1alpha3.BastionTypeJumpbox},
+			expected: v1alpha3.BastionSpec{
+				Type: v1alpha3.BastionTypeJumpbox,
+				Subnet: v1alpha3.SubnetSpec{
+					Role:          v1alpha3.SubnetBastion,
+					Name:          "my-cluster-bastion-subnet",
+					CidrBlock:     "10.255.255.224/27",
+					CIDRBlocks:    []string{"10.255.255.224/27"},
+					SecurityGroup: v1alpha3.SecurityGroup{Name: "my-cluster-bastion-nsg"},
+				},
+				VMSize: "Standard_B1s",
+			},
+		},
+		{
+			name: "jumpbox with custom subnet and size",
+			bastion: v1alpha3.BastionSpec{
+				Type: v1alpha3.BastionTypeJumpbox,
+				Subnet: v1alpha3.SubnetSpec{
+					Name:          "my-subnet",
+					CidrBlock:     "10.2.0.0/24",
+					SecurityGroup: v1alpha3.SecurityGroup{Name: "my-nsg"},
+				},
+				VMSize: "Standard_B2s",
+			},
+			expected: v1alpha3.BastionSpec{
+				Type: v1alpha3.BastionTypeJumpbox,
+				Subnet: v1alpha3.SubnetSpec{
+					Role:          v1alpha3.SubnetBastion,
+					Name:          "my-subnet",
+					CidrBlock:     "10.2.0.0/24",
+					CIDRBlocks:    []string{"10.2.0.0/24"},
+					SecurityGroup: v1alpha3.SecurityGroup{Name: "my-nsg"},
+				},
+				VMSize: "Standard_B2s",
+			},
+		},
+	}
+
+	for _, c := range cases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			setBastionDefaults(&c.bastion, "my-cluster")
+			g.Expect(c.bastion).To(Equal(c.expected))
+		})
+	}
+}
+
+func TestSetNatGatewayDefaults(t *testing.T) {
+	g := NewWithT(t)
+
+	cases := []struct {
+		name       string
+		natGateway v1alpha3.NatGateway
+		expected   v1alpha3.NatGateway
+	}{
+		{
+			name: "nothing set",
+			expected: v1alpha3.NatGateway{
+				Name:     "my-cluster-node-natgw",
+				PublicIP: v1alpha3.PublicIP{Name: "my-cluster-node-natgw-pip"},
+			},
+		},
+		{
+			name:       "custom name",
+			natGateway: v1alpha3.NatGateway{Name: "my-natgw"},
+			expected: v1alpha3.NatGateway{
+				Name:     "my-natgw",
+				PublicIP: v1alpha3.PublicIP{Name: "my-natgw-pip"},
+			},
+		},
+		{
+			name: "custom public ip name",
+			natGateway: v1alpha3.NatGateway{
+				PublicIP: v1alpha3.PublicIP{Name: "my-pip"},
+			},
+			expected: v1alpha3.NatGateway{
+				Name:     "my-cluster-node-natgw",
+				PublicIP: v1alpha3.PublicIP{Name: "my-pip"},
+			},
+		},
+	}
+
+	for _, c := range cases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			setNatGatewayDefaults(&c.natGateway, "my-cluster-node-natgw")
+			g.Expect(c.natGateway).To(Equal(c.expected))
+		})
+	}
+}
+
+// fakeNatGatewaysService is a NAT gateways service recording the specs it reconciles.
+type fakeNatGatewaysService struct {
+	azure.FakeSuccessService
+	specs []*natgateways.Spec
+}
+
+func (s *fakeNatGatewaysService) Reconcile(ctx context.Context, spec interface{}) error {
+	s.specs = append(s.specs, spec.(*natgateways.Spec))
+	return nil
+}
+
+func TestReconcileNatGateways(t *testing.T) {
+	g := NewWithT(t)
+
+	cases := []struct {
+		name        string
+		apiServerLB v1alpha3.LoadBalancerSpec
+		cpSubnet    *v1alpha3.SubnetSpec
+		nodeSubnet  *v1alpha3.SubnetSpec
+		expected    []string
+	}{
+		{
+			name:       "public cluster without NAT gateways",
+			cpSubnet:   &v1alpha3.SubnetSpec{Name: "cp-subnet", Role: v1alpha3.SubnetControlPlane},
+			nodeSubnet: &v1alpha3.SubnetSpec{Name: "node-subnet", Role: v1alpha3.SubnetNode},
+		},
+		{
+			name:       "public cluster with a node NAT gateway",
+			cpSubnet:   &v1alpha3.SubnetSpec{Name: "cp-subnet", Role: v1alpha3.SubnetControlPlane},
+			nodeSubnet: &v1alpha3.SubnetSpec{Name: "node-subnet", Role: v1alpha3.SubnetNode, NatGateway: &v1alpha3.NatGateway{}},
+			expected:   []string{"my-cluster-node-natgw"},
+		},
+		{
+			name:        "private cluster gets a control plane NAT gateway",
+			apiServerLB: v1alpha3.LoadBalancerSpec{Type: v1alpha3.LBTypeInternal},
+			cpSubnet:    &v1alpha3.SubnetSpec{Name: "cp-subnet", Role: v1alpha3.SubnetControlPlane},
+			nodeSubnet:  &v1alpha3.SubnetSpec{Name: "node-subnet", Role: v1alpha3.SubnetNode, NatGateway: &v1alpha3.NatGateway{}},
+			expected:    []string{"my-cluster-controlplane-natgw", "my-cluster-node-natgw"},
+		},
+		{
+			name:        "private cluster with its own control plane NAT gateway",
+			apiServerLB: v1alpha3.LoadBalancerSpec{Type: v1alpha3.LBTypeInternal},
+			cpSubnet:    &v1alpha3.SubnetSpec{Name: "cp-subnet", Role: v1alpha3.SubnetControlPlane, NatGateway: &v1alpha3.NatGateway{Name: "my-natgw"}},
+			nodeSubnet:  &v1alpha3.SubnetSpec{Name: "node-subnet", Role: v1alpha3.SubnetNode},
+			expected:    []string{"my-natgw"},
+		},
+	}
+
+	for _, c := range cases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			azureCluster := &v1alpha3.AzureCluster{
+				Spec: v1alpha3.AzureClusterSpec{
+					APIServerLB: c.apiServerLB,
+					NetworkSpec: v1alpha3.NetworkSpec{
+						Subnets: v1alpha3.Subnets{c.cpSubnet, c.nodeSubnet},
+					},
+				},
+			}
+			natGatewaysSvc := &fakeNatGatewaysService{}
+			r := &azureClusterReconciler{
+				scope: &scope.ClusterScope{
+					Cluster:      &clusterv1.Cluster{ObjectMeta: metav1.ObjectMeta{Name: "my-cluster"}},
+					AzureCluster: azureCluster,
+					Context:      context.TODO(),
+				},
+				natGatewaysSvc: natGatewaysSvc,
+				publicIPSvc:    &azure.FakeSuccessService{},
+			}
+
+			g.Expect(r.reconcileNatGateways()).To(Succeed())
+			names := []string{}
+			for _, spec := range natGatewaysSvc.specs {
+				names = append(names, spec.Name)
+			}
+			if c.expected == nil {
+				g.Expect(names).To(BeEmpty())
+			} else {
+				g.Expect(names).To(Equal(c.expected))
+			}
+		})
+	}
+}
+
+func TestSSHSourceCIDRs(t *testing.T) {
+	g := NewWithT(t)
+
+	cases := []struct {
+		name      string
+		sshAccess *v1alpha3.SSHAccessSpec
+		bastion   *v1alpha3.BastionSpec
+		expected  []string
+		wantErr   bool
+	}{
+		{
+			name:     "default SSH access",
+			expected: nil,
+		},
+		{
+			name:      "NAT from source CIDR blocks",
+			sshAccess: &v1alpha3.SSHAccessSpec{Mode: v1alpha3.SSHAccessNAT, SourceCIDRs: []string{"203.0.113.0/24"}},
+			expected:  []string{"203.0.113.0/24"},
+		},
+		{
+			name:      "bastion only with a default bastion subnet",
+			sshAccess: &v1alpha3.SSHAccessSpec{Mode: v1alpha3.SSHAccessBastionOnly},
+			bastion:   &v1alpha3.BastionSpec{Type: v1alpha3.BastionTypeJumpbox},
+			expected:  []string{"10.255.255.224/27"},
+		},
+		{
+			name:      "bastion only without a bastion",
+			sshAccess: &v1alpha3.SSHAccessSpec{Mode: v1alpha3.SSHAccessBastionOnly},
+			wantErr:   true,
+		},
+	}
+
+	for _, c := range cases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			azureCluster := &v1alpha3.AzureCluster{
+				Spec: v1alpha3.AzureClusterSpec{
+					SSHAccess: c.sshAccess,
+					Bastion:   c.bastion,
+				},
+			}
+			r := &azureClusterReconciler{
+				scope: &scope.ClusterScope{
+					Cluster:      &clusterv1.Cluster{ObjectMeta: metav1.ObjectMeta{Name: "my-cluster"}},
+					AzureCluster: azureCluster,
+				},
+			}
+
+			cidrs, err := r.sshSourceCIDRs()
+			if c.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(cidrs).To(Equal(c.expected))
+		})
+	}
+}
@@ -0,0 +1,160 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Azure/go-autorest/autorest/to"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/cluster-api-provider-azure/cloud/services/resourceskus"
+)
+
+const (
+	// minControlPlaneVCPUs is the minimum number of vCPUs kubeadm requires on control plane nodes.
+	minControlPlaneVCPUs = 2
+	// minControlPlaneMemoryMB is the minimum memory in MB kubeadm requires on control plane nodes.
+	minControlPlaneMemoryMB = 1700
+)
+
+// validateSKUCapabilities checks the AzureMachine spec against the capabilities of its VM size in the
+// location of the cluster, so that an unsupported spec fails before any Azure resource is created.
+func (s *azureMachineService) validateSKUCapabilities() (field.ErrorList, error) {
+	spec := s.machineScope.AzureMachine.Spec
+	specPath := field.NewPath("spec")
+	var errs field.ErrorList
+
+	skuSpec := &resourceskus.Spec{
+		ResourceType: resourceskus.VirtualMachines,
+		Name:         spec.VMSize,
+	}
+	skuInterface, err := s.resourceSKUSvc.Get(s.clusterScope.Context, skuSpec)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get the capabilities of VM size %s", spec.VMSize)
+	}
+	if skuInterface == nil {
+		errs = append(errs, field.Invalid(specPath.Child("vmSize"), spec.VMSize,
+			fmt.Sprintf("VM size is not available in location %s", s.clusterScope.Location())))
+		return errs, nil
+	}
+	sku, ok := skuInterface.(resourceskus.SKU)
+	if !ok {
+		return nil, errors.New("resource sku Get returned invalid interface")
+	}
+
+	if s.machineScope.IsControlPlane() {
+		vCPUs, ok, err := sku.GetCapabilityQuantity(resourceskus.VCPUs)
+		if err != nil {
+			return nil, err
+		}
+		if ok && vCPUs < minControlPlaneVCPUs {
+			errs = append(errs, field.Invalid(specPath.Child("vmSize"), spec.VMSize,
+				fmt.Sprintf("control plane machines need at least %d vCPUs, the VM size has %v", minControlPlaneVCPUs, vCPUs)))
+		}
+		memoryGB, ok, err := sku.GetCapabilityQuantity(resourceskus.MemoryGB)
+		if err != nil {
+			return nil, err
+		}
+		if ok && memoryGB*1024 < minControlPlaneMemoryMB {
+			errs = append(errs, field.Invalid(specPath.Child("vmSize"), spec.VMSize,
+				fmt.Sprintf("control plane machines need at least %d MB of memory, the VM size has %v GB", minControlPlaneMemoryMB, memoryGB)))
+		}
+	}
+
+	if isPremiumStorage(spec.OSDisk.ManagedDisk.StorageAccountType) && !sku.HasCapability(resourceskus.PremiumIO) {
+		errs = append(errs, field.Invalid(specPath.Child("osDisk", "managedDisk", "storageAccountType"), spec.OSDisk.ManagedDisk.StorageAccountType,
+			fmt.Sprintf("VM size %s does not support premium storage", spec.VMSize)))
+	}
+
+	if spec.OSDisk.DiffDiskSettings != nil && !sku.HasCapability(resourceskus.EphemeralOSDisk) {
+		errs = append(errs, field.Invalid(specPath.Child("osDisk", "diffDiskSettings", "option"), spec.OSDisk.DiffDiskSettings.Option,
+			fmt.Sprintf("VM size %s does not support ephemeral OS disks", spec.VMSize)))
+	}
+
+	for i, disk := range spec.DataDisks {
+		if disk.ManagedDisk != nil && isPremiumStorage(disk.ManagedDisk.StorageAccountType) && !sku.HasCapability(resourceskus.PremiumIO) {
+			errs = append(errs, field.Invalid(specPath.Child("dataDisks").Index(i).Child("managedDisk", "storageAccountType"), disk.ManagedDisk.StorageAccountType,
+				fmt.Sprintf("VM size %s does not support premium storage", spec.VMSize)))
+		}
+	}
+
+	maxDataDisks, ok, err := sku.GetCapabilityQuantity(resourceskus.MaxDataDiskCount)
+	if err != nil {
+		return nil, err
+	}
+	if ok && len(spec.DataDisks) > int(maxDataDisks) {
+		errs = append(errs, field.TooMany(specPath.Child("dataDisks"), len(spec.DataDisks), int(maxDataDisks)))
+	}
+
+	if to.Bool(spec.AcceleratedNetworking) && !sku.HasCapability(resourceskus.AcceleratedNetworking) {
+		errs = append(errs, field.Invalid(specPath.Child("acceleratedNetworking"), true,
+			fmt.Sprintf("VM size %s does not support accelerated networking", spec.VMSize)))
+	}
+
+	maxNICs, ok, err := sku.GetCapabilityQuantity(resourceskus.MaxNetworkInterfaces)
+	if err != nil {
+		return nil, err
+	}
+	if ok && len(spec.NetworkInterfaces) > int(maxNICs) {
+		errs = append(errs, field.TooMany(specPath.Child("networkInterfaces"), len(spec.NetworkInterfaces), int(maxNICs)))
+	}
+
+	for i, nic := range spec.NetworkInterfaces {
+		if to.Bool(nic.AcceleratedNetworking) && !sku.HasCapability(resourceskus.AcceleratedNetworking) {
+			errs = append(errs, field.Invalid(specPath.Child("networkInterfaces").Index(i).Child("acceleratedNetworking"), true,
+				fmt.Sprintf("VM size %s does not support accelerated networking", spec.VMSize)))
+		}
+	}
+
+	if spec.SecurityProfile != nil && to.Bool(spec.SecurityProfile.EncryptionAtHost) && !sku.HasCapability(resourceskus.EncryptionAtHost) {
+		errs = append(errs, field.Invalid(specPath.Child("securityProfile", "encryptionAtHost"), true,
+			fmt.Sprintf("VM size %s does not support encryption at host", spec.VMSize)))
+	}
+
+	return errs, nil
+}
+
+// acceleratedNetworking returns whether accelerated networking is enabled on the network interfaces of the machine.
+// It defaults to whether the VM size supports it when the AzureMachine doesn't set it.
+func (s *azureMachineService) acceleratedNetworking() (*bool, error) {
+	if s.machineScope.AzureMachine.Spec.AcceleratedNetworking != nil {
+		return s.machineScope.AzureMachine.Spec.AcceleratedNetworking, nil
+	}
+
+	skuSpec := &resourceskus.Spec{
+		ResourceType: resourceskus.VirtualMachines,
+		Name:         s.machineScope.AzureMachine.Spec.VMSize,
+	}
+	skuInterface, err := s.resourceSKUSvc.Get(s.clusterScope.Context, skuSpec)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get the capabilities of VM size %s", skuSpec.Name)
+	}
+	sku, ok := skuInterface.(resourceskus.SKU)
+	if !ok {
+		// the VM size is not available, leave it to Azure
+		return nil, nil
+	}
+
+	return to.BoolPtr(sku.HasCapability(resourceskus.AcceleratedNetworking)), nil
+}
+
+// isPremiumStorage returns true if the storage account type requires a VM size with premium storage support.
+func isPremiumStorage(storageAccountType string) bool {
+	return strings.HasPrefix(storageAccountType, "Premium")
+}
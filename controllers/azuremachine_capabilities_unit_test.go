@@ -0,0 +1,249 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2020-06-01/compute"
+	"github.com/Azure/go-autorest/autorest/to"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/klogr"
+	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1alpha3"
+	azure "sigs.k8s.io/cluster-api-provider-azure/cloud"
+	"sigs.k8s.io/cluster-api-provider-azure/cloud/scope"
+	"sigs.k8s.io/cluster-api-provider-azure/cloud/services/resourceskus"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+)
+
+// fakeSKUService is a resource SKU service returning a fixed SKU, or none when it is nil.
+type fakeSKUService struct {
+	azure.FakeSuccessService
+	sku *resourceskus.SKU
+}
+
+func (s *fakeSKUService) Get(ctx context.Context, spec interface{}) (interface{}, error) {
+	if s.sku == nil {
+		return nil, nil
+	}
+	return *s.sku, nil
+}
+
+func newVMSizeSKU(capabilities map[string]string) *resourceskus.SKU {
+	caps := []compute.ResourceSkuCapabilities{}
+	for name, value := range capabilities {
+		caps = append(caps, compute.ResourceSkuCapabilities{Name: to.StringPtr(name), Value: to.StringPtr(value)})
+	}
+	return &resourceskus.SKU{
+		Name:         to.StringPtr("Standard_D2s_v3"),
+		ResourceType: to.StringPtr(resourceskus.VirtualMachines),
+		Capabilities: &caps,
+	}
+}
+
+func TestValidateSKUCapabilities(t *testing.T) {
+	g := NewWithT(t)
+
+	standardSKU := newVMSizeSKU(map[string]string{
+		resourceskus.VCPUs:                "1",
+		resourceskus.MemoryGB:             "1",
+		resourceskus.PremiumIO:            "False",
+		resourceskus.MaxDataDiskCount:     "1",
+		resourceskus.MaxNetworkInterfaces: "1",
+	})
+	premiumSKU := newVMSizeSKU(map[string]string{
+		resourceskus.VCPUs:                "2",
+		resourceskus.MemoryGB:             "8",
+		resourceskus.PremiumIO:            "True",
+		resourceskus.EphemeralOSDisk:      "True",
+		resourceskus.EncryptionAtHost:     "True",
+		resourceskus.MaxDataDiskCount:     "4",
+		resourceskus.MaxNetworkInterfaces: "2",
+	})
+
+	cases := []struct {
+		name           string
+		sku            *resourceskus.SKU
+		controlPlane   bool
+		spec           infrav1.AzureMachineSpec
+		expectedFields []string
+	}{
+		{
+			name: "supported spec",
+			sku:  premiumSKU,
+			spec: infrav1.AzureMachineSpec{
+				VMSize: "Standard_D2s_v3",
+				OSDisk: infrav1.OSDisk{
+					ManagedDisk:      infrav1.ManagedDisk{StorageAccountType: "Premium_LRS"},
+					DiffDiskSettings: &infrav1.DiffDiskSettings{Option: "Local"},
+				},
+				DataDisks:         []infrav1.DataDisk{{NameSuffix: "etcd"}},
+				NetworkInterfaces: []infrav1.NetworkInterface{{SubnetName: "node-subnet"}, {SubnetName: "storage-subnet"}},
+				SecurityProfile:   &infrav1.SecurityProfile{EncryptionAtHost: to.BoolPtr(true)},
+			},
+			controlPlane: true,
+		},
+		{
+			name: "VM size not available",
+			sku:  nil,
+			spec: infrav1.AzureMachineSpec{
+				VMSize: "Standard_D2s_v3",
+			},
+			expectedFields: []string{"spec.vmSize"},
+		},
+		{
+			name: "control plane VM size too small",
+			sku:  standardSKU,
+			spec: infrav1.AzureMachineSpec{
+				VMSize: "Standard_D2s_v3",
+				OSDisk: infrav1.OSDisk{ManagedDisk: infrav1.ManagedDisk{StorageAccountType: "Standard_LRS"}},
+			},
+			controlPlane:   true,
+			expectedFields: []string{"spec.vmSize", "spec.vmSize"},
+		},
+		{
+			name: "node VM size with few vCPUs",
+			sku:  standardSKU,
+			spec: infrav1.AzureMachineSpec{
+				VMSize: "Standard_D2s_v3",
+				OSDisk: infrav1.OSDisk{ManagedDisk: infrav1.ManagedDisk{StorageAccountType: "Standard_LRS"}},
+			},
+		},
+		{
+			name: "unsupported capabilities",
+			sku:  standardSKU,
+			spec: infrav1.AzureMachineSpec{
+				VMSize: "Standard_D2s_v3",
+				OSDisk: infrav1.OSDisk{
+					ManagedDisk:      infrav1.ManagedDisk{StorageAccountType: "Premium_LRS"},
+					DiffDiskSettings: &infrav1.DiffDiskSettings{Option: "Local"},
+				},
+				DataDisks: []infrav1.DataDisk{
+					{NameSuffix: "etcd", ManagedDisk: &infrav1.ManagedDisk{StorageAccountType: "Premium_LRS"}},
+					{NameSuffix: "data"},
+				},
+				NetworkInterfaces: []infrav1.NetworkInterface{
+					{SubnetName: "node-subnet"},
+					{SubnetName: "storage-subnet", AcceleratedNetworking: to.BoolPtr(true)},
+				},
+				SecurityProfile:       &infrav1.SecurityProfile{EncryptionAtHost: to.BoolPtr(true)},
+				AcceleratedNetworking: to.BoolPtr(true),
+			},
+			expectedFields: []string{
+				"spec.osDisk.managedDisk.storageAccountType",
+				"spec.osDisk.diffDiskSettings.option",
+				"spec.dataDisks[0].managedDisk.storageAccountType",
+				"spec.dataDisks",
+				"spec.securityProfile.encryptionAtHost",
+				"spec.acceleratedNetworking",
+				"spec.networkInterfaces",
+				"spec.networkInterfaces[1].acceleratedNetworking",
+			},
+		},
+	}
+
+	for _, c := range cases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			machine := &clusterv1.Machine{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{}}}
+			if c.controlPlane {
+				machine.Labels[clusterv1.MachineControlPlaneLabelName] = "true"
+			}
+			s := azureMachineService{
+				machineScope: &scope.MachineScope{
+					Logger:       klogr.New(),
+					Machine:      machine,
+					AzureMachine: &infrav1.AzureMachine{Spec: c.spec},
+				},
+				clusterScope: &scope.ClusterScope{
+					AzureCluster: &infrav1.AzureCluster{Spec: infrav1.AzureClusterSpec{Location: "eastus"}},
+					Context:      context.TODO(),
+				},
+				resourceSKUSvc: &fakeSKUService{sku: c.sku},
+			}
+
+			errs, err := s.validateSKUCapabilities()
+			g.Expect(err).NotTo(HaveOccurred())
+			fields := []string{}
+			for _, e := range errs {
+				fields = append(fields, e.Field)
+			}
+			g.Expect(fields).To(ConsistOf(c.expectedFields))
+		})
+	}
+}
+
+func TestAcceleratedNetworking(t *testing.T) {
+	g := NewWithT(t)
+
+	supportedSKU := newVMSizeSKU(map[string]string{resourceskus.AcceleratedNetworking: "True"})
+	unsupportedSKU := newVMSizeSKU(map[string]string{resourceskus.AcceleratedNetworking: "False"})
+
+	cases := []struct {
+		name                  string
+		sku                   *resourceskus.SKU
+		acceleratedNetworking *bool
+		expected              *bool
+	}{
+		{
+			name:     "defaults to enabled when the VM size supports it",
+			sku:      supportedSKU,
+			expected: to.BoolPtr(true),
+		},
+		{
+			name:     "defaults to disabled when the VM size doesn't support it",
+			sku:      unsupportedSKU,
+			expected: to.BoolPtr(false),
+		},
+		{
+			name:                  "disabled by the spec",
+			sku:                   supportedSKU,
+			acceleratedNetworking: to.BoolPtr(false),
+			expected:              to.BoolPtr(false),
+		},
+		{
+			name:     "unknown VM size",
+			sku:      nil,
+			expected: nil,
+		},
+	}
+
+	for _, c := range cases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			s := azureMachineService{
+				machineScope: &scope.MachineScope{
+					Logger: klogr.New(),
+					AzureMachine: &infrav1.AzureMachine{
+						Spec: infrav1.AzureMachineSpec{
+							VMSize:                "Standard_D2s_v3",
+							AcceleratedNetworking: c.acceleratedNetworking,
+						},
+					},
+				},
+				clusterScope:   &scope.ClusterScope{Context: context.TODO()},
+				resourceSKUSvc: &fakeSKUService{sku: c.sku},
+			}
+
+			acceleratedNetworking, err := s.acceleratedNetworking()
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(acceleratedNetworking).To(Equal(c.expected))
+		})
+	}
+}
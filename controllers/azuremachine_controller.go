@@ -29,6 +29,7 @@ import (
 	"k8s.io/client-go/tools/record"
 	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1alpha3"
 	"sigs.k8s.io/cluster-api-provider-azure/cloud/scope"
+	"sigs.k8s.io/cluster-api-provider-azure/cloud/services/resourceskus"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
 	capierrors "sigs.k8s.io/cluster-api/errors"
 	"sigs.k8s.io/cluster-api/util"
@@ -46,6 +47,8 @@ type AzureMachineReconciler struct {
 	client.Client
 	Log      logr.Logger
 	Recorder record.EventRecorder
+	// SKUCache is the resource SKU cache shared by the controllers of the manager.
+	SKUCache *resourceskus.Cache
 }
 
 func (r *AzureMachineReconciler) SetupWithManager(mgr ctrl.Manager, options controller.Options) error {
@@ -209,13 +212,36 @@ func (r *AzureMachineReconciler) reconcileNormal(ctx context.Context, machineSco
 		}
 	}
 
-	ams := newAzureMachineService(machineScope, clusterScope)
+	ams := newAzureMachineService(machineScope, clusterScope, r.SKUCache)
+
+	// Check the spec against the capabilities of its VM size and the static IP addresses of the other machines
+	// before any Azure resource is created.
+	if machineScope.GetVMID() == nil {
+		errs, err := ams.validateSKUCapabilities()
+		if err != nil {
+			return reconcile.Result{}, err
+		}
+		ipErrs, err := ams.validateStaticIPAddresses(ctx, r.Client)
+		if err != nil {
+			return reconcile.Result{}, err
+		}
+		errs = append(errs, ipErrs...)
+		if len(errs) > 0 {
+			r.setInvalidConfiguration(machineScope, errs.ToAggregate())
+			return reconcile.Result{}, nil
+		}
+	}
 
 	// Get or create the virtual machine.
 	vm, err := r.getOrCreate(machineScope, ams)
 	if err != nil {
 		return reconcile.Result{}, err
 	}
+	if vm == nil {
+		// Spot VMs with the Delete eviction policy are removed by Azure when evicted.
+		r.setSpotVMEvicted(machineScope)
+		return reconcile.Result{}, nil
+	}
 
 	// Set an error message if we couldn't find the VM.
 	// TODO: Nodes are getting marked failed. Need to debug this.
@@ -245,6 +271,12 @@ func (r *AzureMachineReconciler) reconcileNormal(ctx context.Context, machineSco
 	machineScope.SetAnnotation("cluster-api-provider-azure", "true")
 
 	machineScope.SetAddresses(vm.Addresses)
+	machineScope.SetPrincipalID(vm.PrincipalID)
+
+	if vm.Evicted && machineScope.AzureMachine.Spec.SpotVMOptions != nil {
+		r.setSpotVMEvicted(machineScope)
+		return reconcile.Result{}, nil
+	}
 
 	switch vm.State {
 	case infrav1.VMStateSucceeded:
@@ -273,20 +305,50 @@ func (r *AzureMachineReconciler) getOrCreate(scope *scope.MachineScope, ams *azu
 	}
 
 	if vm == nil {
+		// A spot VM that was already provisioned but no longer exists has been evicted,
+		// don't recreate it so that the machine gets replaced instead.
+		if scope.AzureMachine.Spec.SpotVMOptions != nil && scope.GetVMID() != nil {
+			return nil, nil
+		}
+
 		// Create a new AzureMachine VM if we couldn't find a running VM.
 		vm, err = ams.Create()
 		if err != nil {
 			return nil, errors.Wrapf(err, "failed to create AzureMachine VM")
 		}
+		return vm, nil
+	}
+
+	// The SSH access mode of the cluster can change after the VM is created.
+	if err := ams.ReconcileSSHAccess(); err != nil {
+		return nil, errors.Wrapf(err, "failed to reconcile SSH access of AzureMachine VM")
 	}
 
 	return vm, nil
 }
 
+// setSpotVMEvicted marks the AzureMachine as failed after the eviction of its spot VM,
+// so that a MachineHealthCheck can replace the machine.
+func (r *AzureMachineReconciler) setSpotVMEvicted(machineScope *scope.MachineScope) {
+	machineScope.Info("Machine spot VM was evicted")
+	machineScope.SetNotReady()
+	machineScope.SetFailureReason(infrav1.SpotVMEvictedMachineError)
+	machineScope.SetFailureMessage(errors.New("Azure spot VM was evicted"))
+	r.Recorder.Eventf(machineScope.AzureMachine, corev1.EventTypeWarning, "SpotVMEvicted", "Azure spot VM %s was evicted", machineScope.Name())
+}
+
+// setInvalidConfiguration marks the AzureMachine as failed because its spec can't be fulfilled.
+func (r *AzureMachineReconciler) setInvalidConfiguration(machineScope *scope.MachineScope, err error) {
+	machineScope.Info("Invalid AzureMachine configuration", "error", err.Error())
+	machineScope.SetFailureReason(capierrors.InvalidConfigurationMachineError)
+	machineScope.SetFailureMessage(err)
+	r.Recorder.Eventf(machineScope.AzureMachine, corev1.EventTypeWarning, "InvalidConfiguration", "Invalid configuration: %s", err.Error())
+}
+
 func (r *AzureMachineReconciler) reconcileDelete(machineScope *scope.MachineScope, clusterScope *scope.ClusterScope) (_ reconcile.Result, reterr error) {
 	machineScope.Info("Handling deleted AzureMachine")
 
-	if err := newAzureMachineService(machineScope, clusterScope).Delete(); err != nil {
+	if err := newAzureMachineService(machineScope, clusterScope, r.SKUCache).Delete(); err != nil {
 		return reconcile.Result{}, errors.Wrapf(err, "error deleting AzureCluster %s/%s", clusterScope.Namespace(), clusterScope.Name())
 	}
 
@@ -24,9 +24,13 @@ import (
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/klog/klogr"
 	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1alpha3"
+	"sigs.k8s.io/cluster-api-provider-azure/cloud/scope"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+	capierrors "sigs.k8s.io/cluster-api/errors"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 )
@@ -113,3 +117,50 @@ func TestAzureMachineReconciler_AzureClusterToAzureMachines(t *testing.T) {
 
 	g.Expect(requests).To(HaveLen(2))
 }
+
+func TestAzureMachineReconciler_SetSpotVMEvicted(t *testing.T) {
+	g := NewWithT(t)
+
+	recorder := record.NewFakeRecorder(1)
+	reconciler := &AzureMachineReconciler{
+		Log:      klogr.New(),
+		Recorder: recorder,
+	}
+	machineScope := &scope.MachineScope{
+		Logger: klogr.New(),
+		AzureMachine: &infrav1.AzureMachine{
+			ObjectMeta: metav1.ObjectMeta{Name: "my-machine", Namespace: "default"},
+			Status:     infrav1.AzureMachineStatus{Ready: true},
+		},
+	}
+
+	reconciler.setSpotVMEvicted(machineScope)
+
+	g.Expect(machineScope.AzureMachine.Status.Ready).To(BeFalse())
+	g.Expect(*machineScope.AzureMachine.Status.FailureReason).To(Equal(infrav1.SpotVMEvictedMachineError))
+	g.Expect(machineScope.AzureMachine.Status.FailureMessage).NotTo(BeNil())
+	g.Expect(recorder.Events).To(Receive(ContainSubstring("SpotVMEvicted")))
+}
+
+func TestAzureMachineReconciler_SetInvalidConfiguration(t *testing.T) {
+	g := NewWithT(t)
+
+	recorder := record.NewFakeRecorder(1)
+	reconciler := &AzureMachineReconciler{
+		Log:      klogr.New(),
+		Recorder: recorder,
+	}
+	machineScope := &scope.MachineScope{
+		Logger: klogr.New(),
+		AzureMachine: &infrav1.AzureMachine{
+			ObjectMeta: metav1.ObjectMeta{Name: "my-machine", Namespace: "default"},
+		},
+	}
+
+	errs := field.ErrorList{field.Invalid(field.NewPath("spec", "vmSize"), "Standard_Unknown", "VM size is not available in location eastus")}
+	reconciler.setInvalidConfiguration(machineScope, errs.ToAggregate())
+
+	g.Expect(*machineScope.AzureMachine.Status.FailureReason).To(Equal(capierrors.InvalidConfigurationMachineError))
+	g.Expect(*machineScope.AzureMachine.Status.FailureMessage).To(ContainSubstring("spec.vmSize"))
+	g.Expect(recorder.Events).To(Receive(And(ContainSubstring("InvalidConfiguration"), ContainSubstring("spec.vmSize"))))
+}
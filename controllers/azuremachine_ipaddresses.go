@@ -0,0 +1,108 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1alpha3"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// staticIPAddress is a static private IP address of an AzureMachine.
+type staticIPAddress struct {
+	address string
+	path    *field.Path
+}
+
+// staticIPAddresses returns the static private IP addresses of a machine.
+func staticIPAddresses(m *infrav1.AzureMachine) []staticIPAddress {
+	specPath := field.NewPath("spec")
+	var ips []staticIPAddress
+	if m.Spec.PrivateIPAddress != "" {
+		ips = append(ips, staticIPAddress{address: m.Spec.PrivateIPAddress, path: specPath.Child("privateIPAddress")})
+	}
+	for i, nic := range m.Spec.NetworkInterfaces {
+		if nic.StaticIPAddress != "" {
+			ips = append(ips, staticIPAddress{
+				address: nic.StaticIPAddress,
+				path:    specPath.Child("networkInterfaces").Index(i).Child("staticIPAddress"),
+			})
+		}
+	}
+	return ips
+}
+
+// validateStaticIPAddresses checks that the static private IP addresses of the machine are not used by the internal
+// load balancer or claimed by another machine of the cluster. The subnets of the addresses are checked by the webhook.
+// A machine claims its addresses once its VM exists, or when it was created before the other machines, so that of
+// two machines created with the same address only the later one fails.
+func (s *azureMachineService) validateStaticIPAddresses(ctx context.Context, c client.Reader) (field.ErrorList, error) {
+	var errs field.ErrorList
+
+	machine := s.machineScope.AzureMachine
+	ips := staticIPAddresses(machine)
+	if len(ips) == 0 {
+		return errs, nil
+	}
+
+	usedBy := map[string]string{}
+	if cpSubnet := s.clusterScope.ControlPlaneSubnet(); cpSubnet != nil && cpSubnet.InternalLBIPAddress != "" {
+		usedBy[cpSubnet.InternalLBIPAddress] = "the internal load balancer"
+	}
+
+	machines := &infrav1.AzureMachineList{}
+	if err := c.List(ctx, machines, client.InNamespace(machine.Namespace), client.MatchingLabels{clusterv1.ClusterLabelName: s.clusterScope.Cluster.Name}); err != nil {
+		return nil, errors.Wrapf(err, "failed to list the AzureMachines of cluster %s", s.clusterScope.Cluster.Name)
+	}
+	for i := range machines.Items {
+		other := &machines.Items[i]
+		if other.Name == machine.Name || !claimsStaticIPAddresses(other, machine) {
+			continue
+		}
+		for _, ip := range staticIPAddresses(other) {
+			usedBy[ip.address] = fmt.Sprintf("AzureMachine %s", other.Name)
+		}
+	}
+
+	for _, ip := range ips {
+		if owner, ok := usedBy[ip.address]; ok {
+			errs = append(errs, field.Invalid(ip.path, ip.address, fmt.Sprintf("IP address is already used by %s", owner)))
+		}
+	}
+
+	return errs, nil
+}
+
+// claimsStaticIPAddresses returns true if the static IP addresses of other take precedence over those of machine.
+// Machines that are deleted or failed give up their addresses.
+func claimsStaticIPAddresses(other, machine *infrav1.AzureMachine) bool {
+	if !other.DeletionTimestamp.IsZero() || other.Status.FailureReason != nil {
+		return false
+	}
+	if other.Spec.ProviderID != nil {
+		return true
+	}
+	if !other.CreationTimestamp.Equal(&machine.CreationTimestamp) {
+		return other.CreationTimestamp.Before(&machine.CreationTimestamp)
+	}
+	return other.Name < machine.Name
+}
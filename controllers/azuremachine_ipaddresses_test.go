@@ -0,0 +1,173 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Azure/go-autorest/autorest/to"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1alpha3"
+	"sigs.k8s.io/cluster-api-provider-azure/cloud/scope"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+	capierrors "sigs.k8s.io/cluster-api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newStaticIPMachine(name string, created time.Time, privateIPAddress string) *infrav1.AzureMachine {
+	return &infrav1.AzureMachine{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			Namespace:         "default",
+			Labels:            map[string]string{clusterv1.ClusterLabelName: "my-cluster"},
+			CreationTimestamp: metav1.NewTime(created),
+		},
+		Spec: infrav1.AzureMachineSpec{PrivateIPAddress: privateIPAddress},
+	}
+}
+
+func TestValidateStaticIPAddresses(t *testing.T) {
+	g := NewWithT(t)
+
+	scheme := runtime.NewScheme()
+	g.Expect(infrav1.AddToScheme(scheme)).To(Succeed())
+
+	now := time.Now()
+	earlier := now.Add(-time.Minute)
+	later := now.Add(time.Minute)
+
+	azureCluster := &infrav1.AzureCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-cluster", Namespace: "default"},
+		Spec: infrav1.AzureClusterSpec{
+			NetworkSpec: infrav1.NetworkSpec{
+				Subnets: infrav1.Subnets{
+					{Role: infrav1.SubnetControlPlane, Name: "cp-subnet", CidrBlock: "10.0.0.0/16", InternalLBIPAddress: "10.0.0.100"},
+					{Role: infrav1.SubnetNode, Name: "node-subnet", CidrBlock: "10.1.0.0/16"},
+					{Name: "storage-subnet", CidrBlock: "10.2.0.0/24"},
+				},
+			},
+		},
+	}
+
+	running := newStaticIPMachine("running", later, "10.1.0.10")
+	running.Spec.ProviderID = to.StringPtr("azure:////running")
+	older := newStaticIPMachine("older", earlier, "10.1.0.11")
+	newer := newStaticIPMachine("newer", later, "10.1.0.12")
+	failed := newStaticIPMachine("failed", earlier, "10.1.0.13")
+	reason := capierrors.InvalidConfigurationMachineError
+	failed.Status.FailureReason = &reason
+	sameTime := newStaticIPMachine("a-machine", now, "10.1.0.14")
+	otherCluster := newStaticIPMachine("other", earlier, "10.1.0.20")
+	otherCluster.Labels[clusterv1.ClusterLabelName] = "other-cluster"
+
+	tests := []struct {
+		name           string
+		machine        *infrav1.AzureMachine
+		controlPlane   bool
+		expectedFields []string
+	}{
+		{
+			name:         "control plane IP address in the control plane subnet",
+			machine:      newStaticIPMachine("cp-0", now, "10.0.0.10"),
+			controlPlane: true,
+		},
+		{
+			name:           "IP address of a machine with a VM",
+			machine:        newStaticIPMachine("node-0", now, "10.1.0.10"),
+			expectedFields: []string{"spec.privateIPAddress"},
+		},
+		{
+			name:           "IP address of a machine created earlier",
+			machine:        newStaticIPMachine("node-0", now, "10.1.0.11"),
+			expectedFields: []string{"spec.privateIPAddress"},
+		},
+		{
+			name:    "IP address of a machine created later",
+			machine: newStaticIPMachine("node-0", now, "10.1.0.12"),
+		},
+		{
+			name:    "IP address of a failed machine",
+			machine: newStaticIPMachine("node-0", now, "10.1.0.13"),
+		},
+		{
+			name:           "IP address of a machine created at the same time with a lower name",
+			machine:        newStaticIPMachine("b-machine", now, "10.1.0.14"),
+			expectedFields: []string{"spec.privateIPAddress"},
+		},
+		{
+			name:    "IP address of a machine of another cluster",
+			machine: newStaticIPMachine("node-0", now, "10.1.0.20"),
+		},
+		{
+			name:    "IP address of the machine itself",
+			machine: newStaticIPMachine("older", earlier, "10.1.0.11"),
+		},
+		{
+			name:           "IP address of the internal load balancer",
+			machine:        newStaticIPMachine("cp-0", now, "10.0.0.100"),
+			controlPlane:   true,
+			expectedFields: []string{"spec.privateIPAddress"},
+		},
+		{
+			name: "static IP addresses of network interfaces",
+			machine: func() *infrav1.AzureMachine {
+				m := newStaticIPMachine("node-0", now, "")
+				m.Spec.NetworkInterfaces = []infrav1.NetworkInterface{
+					{SubnetName: "node-subnet", StaticIPAddress: "10.1.0.10"},
+					{SubnetName: "storage-subnet", StaticIPAddress: "10.2.0.10"},
+				}
+				return m
+			}(),
+			expectedFields: []string{"spec.networkInterfaces[0].staticIPAddress"},
+		},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			c := fake.NewFakeClientWithScheme(scheme, running.DeepCopy(), older.DeepCopy(), newer.DeepCopy(), failed.DeepCopy(), sameTime.DeepCopy(), otherCluster.DeepCopy())
+			labels := map[string]string{}
+			if tc.controlPlane {
+				labels[clusterv1.MachineControlPlaneLabelName] = "true"
+			}
+			cluster := &clusterv1.Cluster{ObjectMeta: metav1.ObjectMeta{Name: "my-cluster", Namespace: "default"}}
+			s := azureMachineService{
+				machineScope: &scope.MachineScope{
+					Cluster:      cluster,
+					Machine:      &clusterv1.Machine{ObjectMeta: metav1.ObjectMeta{Labels: labels}},
+					AzureMachine: tc.machine,
+					AzureCluster: azureCluster,
+				},
+				clusterScope: &scope.ClusterScope{
+					Cluster:      cluster,
+					AzureCluster: azureCluster,
+				},
+			}
+
+			errs, err := s.validateStaticIPAddresses(context.TODO(), c)
+			g.Expect(err).NotTo(HaveOccurred())
+			fields := []string{}
+			for _, e := range errs {
+				fields = append(fields, e.Field)
+			}
+			g.Expect(fields).To(ConsistOf(tc.expectedFields))
+		})
+	}
+}
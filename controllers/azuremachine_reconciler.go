@@ -26,10 +26,13 @@ import (
 	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1alpha3"
 	azure "sigs.k8s.io/cluster-api-provider-azure/cloud"
 	"sigs.k8s.io/cluster-api-provider-azure/cloud/scope"
+	"sigs.k8s.io/cluster-api-provider-azure/cloud/services/availabilitysets"
 	"sigs.k8s.io/cluster-api-provider-azure/cloud/services/availabilityzones"
 	"sigs.k8s.io/cluster-api-provider-azure/cloud/services/disks"
 	"sigs.k8s.io/cluster-api-provider-azure/cloud/services/networkinterfaces"
 	"sigs.k8s.io/cluster-api-provider-azure/cloud/services/publicips"
+	"sigs.k8s.io/cluster-api-provider-azure/cloud/services/resourceskus"
+	"sigs.k8s.io/cluster-api-provider-azure/cloud/services/roleassignments"
 	"sigs.k8s.io/cluster-api-provider-azure/cloud/services/virtualmachineextensions"
 	"sigs.k8s.io/cluster-api-provider-azure/cloud/services/virtualmachines"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
@@ -47,45 +50,100 @@ type azureMachineService struct {
 	machineScope          *scope.MachineScope
 	clusterScope          *scope.ClusterScope
 	availabilityZonesSvc  azure.GetterService
+	availabilitySetsSvc   azure.Service
 	networkInterfacesSvc  azure.Service
 	publicIPSvc           azure.GetterService
+	resourceSKUSvc        azure.GetterService
 	virtualMachinesSvc    azure.GetterService
 	virtualMachinesExtSvc azure.GetterService
 	disksSvc              azure.GetterService
+	roleAssignmentsSvc    azure.Service
 }
 
 // newAzureMachineService populates all the services based on input scope
-func newAzureMachineService(machineScope *scope.MachineScope, clusterScope *scope.ClusterScope) *azureMachineService {
+func newAzureMachineService(machineScope *scope.MachineScope, clusterScope *scope.ClusterScope, skuCache *resourceskus.Cache) *azureMachineService {
 	return &azureMachineService{
 		machineScope:          machineScope,
 		clusterScope:          clusterScope,
-		availabilityZonesSvc:  availabilityzones.NewService(clusterScope),
+		availabilityZonesSvc:  availabilityzones.NewService(clusterScope, skuCache),
+		availabilitySetsSvc:   availabilitysets.NewService(clusterScope),
 		networkInterfacesSvc:  networkinterfaces.NewService(clusterScope, machineScope),
 		publicIPSvc:           publicips.NewService(clusterScope),
+		resourceSKUSvc:        resourceskus.NewService(clusterScope, skuCache),
 		virtualMachinesSvc:    virtualmachines.NewService(clusterScope, machineScope),
 		virtualMachinesExtSvc: virtualmachineextensions.NewService(clusterScope),
 		disksSvc:              disks.NewService(clusterScope),
+		roleAssignmentsSvc:    roleassignments.NewService(clusterScope),
 	}
 }
 
 // Create creates machine if and only if machine exists, handled by cluster-api
 func (s *azureMachineService) Create() (*infrav1.VM, error) {
-	nicName := azure.GenerateNICName(s.machineScope.Name())
-	nicErr := s.reconcileNetworkInterface(nicName)
-	if nicErr != nil {
-		return nil, errors.Wrapf(nicErr, "failed to create nic %s for machine %s", nicName, s.machineScope.Name())
+	nics := s.networkInterfaces()
+	nicNames := make([]string, len(nics))
+	var primaryNICName string
+	for i, nic := range nics {
+		nicName := azure.GenerateIndexedNICName(s.machineScope.Name(), i)
+		nicErr := s.reconcileNetworkInterface(nicName, nic)
+		if nicErr != nil {
+			return nil, errors.Wrapf(nicErr, "failed to create nic %s for machine %s", nicName, s.machineScope.Name())
+		}
+		nicNames[i] = nicName
+		if nic.Primary {
+			primaryNICName = nicName
+		}
 	}
 
-	vm, vmErr := s.createVirtualMachine(nicName)
+	vm, vmErr := s.createVirtualMachine(nicNames, primaryNICName)
 	if vmErr != nil {
 		return nil, errors.Wrapf(vmErr, "failed to create vm %s ", s.machineScope.Name())
 	}
 
+	s.machineScope.SetPrincipalID(vm.PrincipalID)
+	roleErr := s.reconcileRoleAssignments(vm.PrincipalID)
+	if roleErr != nil {
+		return nil, errors.Wrapf(roleErr, "failed to create role assignments for machine %s", s.machineScope.Name())
+	}
+
 	return vm, nil
 }
 
+// ReconcileSSHAccess adds or removes the SSH NAT rule on the primary network interface of an existing control plane
+// machine when the SSH access mode of the cluster changes. The rest of the network interface is left as it is.
+func (s *azureMachineService) ReconcileSSHAccess() error {
+	if s.machineScope.Role() != infrav1.ControlPlane || s.clusterScope.APIServerLB().IsInternal() {
+		return nil
+	}
+	for i, nic := range s.networkInterfaces() {
+		if !nic.Primary {
+			continue
+		}
+		nicName := azure.GenerateIndexedNICName(s.machineScope.Name(), i)
+		natRuleSpec := &networkinterfaces.SSHNATRuleSpec{
+			NICName:                nicName,
+			PublicLoadBalancerName: azure.GeneratePublicLBName(s.clusterScope.Name()),
+			Enabled:                s.clusterScope.SSHAccess().Mode == infrav1.SSHAccessNAT,
+		}
+		if err := s.networkInterfacesSvc.Reconcile(s.clusterScope.Context, natRuleSpec); err != nil {
+			return errors.Wrapf(err, "failed to update SSH NAT rule of nic %s for machine %s", nicName, s.machineScope.Name())
+		}
+	}
+	return nil
+}
+
 // Delete reconciles all the services in pre determined order
 func (s *azureMachineService) Delete() error {
+	for _, roleAssignment := range s.machineScope.AzureMachine.Spec.RoleAssignments {
+		roleAssignmentSpec := &roleassignments.Spec{
+			PrincipalID:      s.machineScope.AzureMachine.Status.PrincipalID,
+			RoleDefinitionID: roleAssignment.RoleDefinitionID,
+			Scope:            roleAssignment.Scope,
+		}
+		if err := s.roleAssignmentsSvc.Delete(s.clusterScope.Context, roleAssignmentSpec); err != nil {
+			return errors.Wrapf(err, "failed to delete role assignment of machine %s", s.machineScope.Name())
+		}
+	}
+
 	vmSpec := &virtualmachines.Spec{
 		Name: s.machineScope.Name(),
 	}
@@ -95,22 +153,28 @@ func (s *azureMachineService) Delete() error {
 		return errors.Wrapf(err, "failed to delete machine")
 	}
 
-	networkInterfaceSpec := &networkinterfaces.Spec{
-		Name:     azure.GenerateNICName(s.machineScope.Name()),
-		VnetName: azure.GenerateVnetName(s.clusterScope.Name()),
-	}
+	var primaryNICName string
+	for i, nic := range s.networkInterfaces() {
+		networkInterfaceSpec := &networkinterfaces.Spec{
+			Name:     azure.GenerateIndexedNICName(s.machineScope.Name(), i),
+			VnetName: azure.GenerateVnetName(s.clusterScope.Name()),
+		}
 
-	if s.machineScope.Role() == infrav1.ControlPlane {
-		networkInterfaceSpec.PublicLoadBalancerName = azure.GeneratePublicLBName(s.clusterScope.Name())
-	}
+		if nic.Primary {
+			primaryNICName = networkInterfaceSpec.Name
+			if s.machineScope.Role() == infrav1.ControlPlane && !s.clusterScope.APIServerLB().IsInternal() {
+				networkInterfaceSpec.PublicLoadBalancerName = azure.GeneratePublicLBName(s.clusterScope.Name())
+			}
+		}
 
-	err = s.networkInterfacesSvc.Delete(s.clusterScope.Context, networkInterfaceSpec)
-	if err != nil {
-		return errors.Wrapf(err, "Unable to delete network interface")
+		err = s.networkInterfacesSvc.Delete(s.clusterScope.Context, networkInterfaceSpec)
+		if err != nil {
+			return errors.Wrapf(err, "Unable to delete network interface %s", networkInterfaceSpec.Name)
+		}
 	}
 
 	publicIPSpec := &publicips.Spec{
-		Name: azure.GenerateNICName(s.machineScope.Name()) + "-public-ip",
+		Name: primaryNICName + "-public-ip",
 	}
 
 	err = s.publicIPSvc.Delete(s.clusterScope.Context, publicIPSpec)
@@ -126,6 +190,24 @@ func (s *azureMachineService) Delete() error {
 		return errors.Wrapf(err, "Failed to delete OS disk of machine %s", s.machineScope.Name())
 	}
 
+	for _, dataDisk := range s.machineScope.AzureMachine.Spec.DataDisks {
+		dataDiskSpec := &disks.Spec{
+			Name: azure.GenerateDataDiskName(s.machineScope.Name(), dataDisk.NameSuffix),
+		}
+		err = s.disksSvc.Delete(s.clusterScope.Context, dataDiskSpec)
+		if err != nil {
+			return errors.Wrapf(err, "Failed to delete data disk %s of machine %s", dataDiskSpec.Name, s.machineScope.Name())
+		}
+	}
+
+	// The availability set is only deleted with the last VM that uses it.
+	if asName, ok := s.machineScope.AvailabilitySet(); ok {
+		err = s.availabilitySetsSvc.Delete(s.clusterScope.Context, &availabilitysets.Spec{Name: asName})
+		if err != nil {
+			return errors.Wrapf(err, "failed to delete availability set %s", asName)
+		}
+	}
+
 	return nil
 }
 
@@ -158,8 +240,8 @@ func (s *azureMachineService) VMIfExists(id *string) (*infrav1.VM, error) {
 	return vm, nil
 }
 
-// getVirtualMachineZone gets a random availability zones from available set,
-// this will hopefully be an input from upstream machinesets so all the vms are balanced
+// getVirtualMachineZone gets the availability zone of the machine, which is the failure domain chosen by
+// Cluster API or the availability zone of the AzureMachine. It defaults to the first available zone.
 func (s *azureMachineService) getVirtualMachineZone() (string, error) {
 	vmName := s.machineScope.AzureMachine.Name
 	vmSize := s.machineScope.AzureMachine.Spec.VMSize
@@ -185,21 +267,19 @@ func (s *azureMachineService) getVirtualMachineZone() (string, error) {
 		return "", nil
 	}
 
-	var zone string
 	var selectedZone string
-	if s.machineScope.AzureMachine.Spec.AvailabilityZone.ID != nil {
-		zone = *s.machineScope.AzureMachine.Spec.AvailabilityZone.ID
-	}
-
-	if zone != "" {
+	if zone := s.machineScope.AvailabilityZone(); zone != "" {
 		for _, allowedZone := range zones {
 			if allowedZone == zone {
 				selectedZone = zone
 				break
 			}
 		}
+		if selectedZone == "" {
+			return "", errors.Errorf("availability zone %s is not available for VM size %s in location %s", zone, vmSize, location)
+		}
 	} else {
-		klog.Infof("Selecting first available AZ as no availability zone was set or user-provided availability zone is not supported for VM size %s in location %s", vmSize, location)
+		klog.Infof("Selecting first available AZ as no availability zone was set for VM size %s in location %s", vmSize, location)
 		selectedZone = zones[0]
 	}
 
@@ -220,12 +300,65 @@ func (s *azureMachineService) reconcilePublicIP(publicIPName string) error {
 	return nil
 }
 
-func (s *azureMachineService) reconcileNetworkInterface(nicName string) error {
+// networkInterfaces returns the network interfaces of the machine in order, with exactly one of them primary.
+// A machine without network interfaces gets a single network interface with the private IP address of the machine,
+// whose empty subnet name stands for the subnet of the machine role.
+func (s *azureMachineService) networkInterfaces() []infrav1.NetworkInterface {
+	if len(s.machineScope.AzureMachine.Spec.NetworkInterfaces) == 0 {
+		return []infrav1.NetworkInterface{{
+			StaticIPAddress: s.machineScope.AzureMachine.Spec.PrivateIPAddress,
+			Primary:         true,
+		}}
+	}
+
+	nics := make([]infrav1.NetworkInterface, len(s.machineScope.AzureMachine.Spec.NetworkInterfaces))
+	copy(nics, s.machineScope.AzureMachine.Spec.NetworkInterfaces)
+	for _, nic := range nics {
+		if nic.Primary {
+			return nics
+		}
+	}
+	nics[0].Primary = true
+	return nics
+}
+
+func (s *azureMachineService) reconcileNetworkInterface(nicName string, nic infrav1.NetworkInterface) error {
+	acceleratedNetworking := nic.AcceleratedNetworking
+	if acceleratedNetworking == nil {
+		var err error
+		acceleratedNetworking, err = s.acceleratedNetworking()
+		if err != nil {
+			return err
+		}
+	}
+
 	networkInterfaceSpec := &networkinterfaces.Spec{
-		Name:     nicName,
-		VnetName: s.clusterScope.Vnet().Name,
+		Name:                  nicName,
+		SubnetName:            nic.SubnetName,
+		VnetName:              s.clusterScope.Vnet().Name,
+		StaticIPAddress:       nic.StaticIPAddress,
+		AcceleratedNetworking: acceleratedNetworking,
 	}
 
+	if nic.Primary {
+		if err := s.reconcilePrimaryNetworkInterface(networkInterfaceSpec); err != nil {
+			return err
+		}
+	}
+	networkInterfaceSpec.IPv6Enabled = s.isSubnetIPv6Enabled(networkInterfaceSpec.SubnetName)
+
+	err := s.networkInterfacesSvc.Reconcile(s.clusterScope.Context, networkInterfaceSpec)
+	if err != nil {
+		return errors.Wrap(err, "unable to create VM network interface")
+	}
+
+	return err
+}
+
+// reconcilePrimaryNetworkInterface attaches the public IP of the machine and the load balancers of its role
+// to the primary network interface, and defaults its subnet to the subnet of the machine role.
+func (s *azureMachineService) reconcilePrimaryNetworkInterface(networkInterfaceSpec *networkinterfaces.Spec) error {
+	nicName := networkInterfaceSpec.Name
 	if s.machineScope.AzureMachine.Spec.AllocatePublicIP == true {
 		publicIPName := nicName + "-public-ip"
 		err := s.reconcilePublicIP(publicIPName)
@@ -235,26 +368,56 @@ func (s *azureMachineService) reconcileNetworkInterface(nicName string) error {
 		networkInterfaceSpec.PublicIPName = publicIPName
 	}
 
+	var roleSubnetName string
 	switch role := s.machineScope.Role(); role {
 	case infrav1.Node:
-		networkInterfaceSpec.SubnetName = s.clusterScope.NodeSubnet().Name
+		roleSubnetName = s.clusterScope.NodeSubnet().Name
+		if s.clusterScope.NodeOutboundLB() != nil {
+			networkInterfaceSpec.NodeOutboundLBName = azure.GenerateNodeOutboundLBName(s.clusterScope.Name())
+		}
 	case infrav1.ControlPlane:
-		networkInterfaceSpec.SubnetName = s.clusterScope.ControlPlaneSubnet().Name
-		networkInterfaceSpec.PublicLoadBalancerName = azure.GeneratePublicLBName(s.clusterScope.Name())
+		roleSubnetName = s.clusterScope.ControlPlaneSubnet().Name
+		if !s.clusterScope.APIServerLB().IsInternal() {
+			networkInterfaceSpec.PublicLoadBalancerName = azure.GeneratePublicLBName(s.clusterScope.Name())
+			networkInterfaceSpec.SSHNATRule = s.clusterScope.SSHAccess().Mode == infrav1.SSHAccessNAT
+		}
 		networkInterfaceSpec.InternalLoadBalancerName = azure.GenerateInternalLBName(s.clusterScope.Name())
 	default:
 		return errors.Errorf("unknown value %s for label `set` on machine %s, skipping machine creation", role, s.machineScope.Name())
 	}
+	if networkInterfaceSpec.SubnetName == "" {
+		networkInterfaceSpec.SubnetName = roleSubnetName
+	}
 
-	err := s.networkInterfacesSvc.Reconcile(s.clusterScope.Context, networkInterfaceSpec)
-	if err != nil {
-		return errors.Wrap(err, "unable to create VM network interface")
+	return nil
+}
+
+// isSubnetIPv6Enabled returns true if the subnet with the given name is listed in the cluster spec with an IPv6 CIDR block.
+func (s *azureMachineService) isSubnetIPv6Enabled(subnetName string) bool {
+	for _, subnet := range s.clusterScope.Subnets() {
+		if subnet != nil && subnet.Name == subnetName {
+			return subnet.IsIPv6Enabled()
+		}
 	}
+	return false
+}
 
-	return err
+func (s *azureMachineService) reconcileRoleAssignments(principalID string) error {
+	for _, roleAssignment := range s.machineScope.AzureMachine.Spec.RoleAssignments {
+		roleAssignmentSpec := &roleassignments.Spec{
+			PrincipalID:      principalID,
+			RoleDefinitionID: roleAssignment.RoleDefinitionID,
+			Scope:            roleAssignment.Scope,
+		}
+		if err := s.roleAssignmentsSvc.Reconcile(s.clusterScope.Context, roleAssignmentSpec); err != nil {
+			return errors.Wrap(err, "unable to create role assignment")
+		}
+	}
+
+	return nil
 }
 
-func (s *azureMachineService) createVirtualMachine(nicName string) (*infrav1.VM, error) {
+func (s *azureMachineService) createVirtualMachine(nicNames []string, primaryNICName string) (*infrav1.VM, error) {
 	var vm *infrav1.VM
 	decoded, err := base64.StdEncoding.DecodeString(s.machineScope.AzureMachine.Spec.SSHPublicKey)
 	if err != nil {
@@ -269,7 +432,10 @@ func (s *azureMachineService) createVirtualMachine(nicName string) (*infrav1.VM,
 	if err != nil && vmInterface == nil {
 		var vmZone string
 
-		azSupported := s.isAvailabilityZoneSupported()
+		azSupported, err := s.isAvailabilityZoneSupported()
+		if err != nil {
+			return nil, err
+		}
 
 		if azSupported {
 			useAZ := true
@@ -287,6 +453,15 @@ func (s *azureMachineService) createVirtualMachine(nicName string) (*infrav1.VM,
 			}
 		}
 
+		// Spread the VMs across fault domains with an availability set in regions without availability zones.
+		var availabilitySetID string
+		if !azSupported {
+			availabilitySetID, err = s.reconcileAvailabilitySet()
+			if err != nil {
+				return nil, err
+			}
+		}
+
 		image, err := getVMImage(s.machineScope)
 		if err != nil {
 			return nil, errors.Wrap(err, "failed to get VM image")
@@ -298,14 +473,22 @@ func (s *azureMachineService) createVirtualMachine(nicName string) (*infrav1.VM,
 		}
 
 		vmSpec = &virtualmachines.Spec{
-			Name:       s.machineScope.Name(),
-			NICName:    nicName,
-			SSHKeyData: string(decoded),
-			Size:       s.machineScope.AzureMachine.Spec.VMSize,
-			OSDisk:     s.machineScope.AzureMachine.Spec.OSDisk,
-			Image:      image,
-			CustomData: bootstrapData,
-			Zone:       vmZone,
+			Name:           s.machineScope.Name(),
+			NICNames:       nicNames,
+			PrimaryNICName: primaryNICName,
+			SSHKeyData:     string(decoded),
+			Size:           s.machineScope.AzureMachine.Spec.VMSize,
+			OSDisk:         s.machineScope.AzureMachine.Spec.OSDisk,
+			DataDisks:      s.machineScope.AzureMachine.Spec.DataDisks,
+			Image:          image,
+			CustomData:     bootstrapData,
+			Zone:           vmZone,
+
+			Identity:               s.machineScope.AzureMachine.Spec.Identity,
+			UserAssignedIdentities: s.machineScope.AzureMachine.Spec.UserAssignedIdentities,
+			SecurityProfile:        s.machineScope.AzureMachine.Spec.SecurityProfile,
+			SpotVMOptions:          s.machineScope.AzureMachine.Spec.SpotVMOptions,
+			AvailabilitySetID:      availabilitySetID,
 		}
 
 		err = s.virtualMachinesSvc.Reconcile(s.clusterScope.Context, vmSpec)
@@ -354,21 +537,41 @@ func GetControlPlaneMachines(machineList *clusterv1.MachineList) []*clusterv1.Ma
 	return cpm
 }
 
-// isAvailabilityZoneSupported determines if Availability Zones are supported in a selected location
-// based on SupportedAvailabilityZoneLocations. Returns true if supported.
-func (s *azureMachineService) isAvailabilityZoneSupported() bool {
-	azSupported := false
+// reconcileAvailabilitySet creates the availability set of the machine and returns its ID.
+// The ID is empty when the machine doesn't belong to an availability set.
+func (s *azureMachineService) reconcileAvailabilitySet() (string, error) {
+	asName, ok := s.machineScope.AvailabilitySet()
+	if !ok {
+		return "", nil
+	}
 
-	for _, supportedLocation := range azure.SupportedAvailabilityZoneLocations {
-		if s.machineScope.Location() == supportedLocation {
-			azSupported = true
+	asSpec := &availabilitysets.Spec{
+		Name: asName,
+	}
+	if err := s.availabilitySetsSvc.Reconcile(s.clusterScope.Context, asSpec); err != nil {
+		return "", errors.Wrapf(err, "failed to create availability set %s", asName)
+	}
 
-			return azSupported
-		}
+	return azure.AvailabilitySetID(s.clusterScope.SubscriptionID, s.clusterScope.ResourceGroup(), asName), nil
+}
+
+// isAvailabilityZoneSupported determines if Availability Zones are supported in a selected location
+// based on the resource SKUs available in the location. Returns true if supported.
+func (s *azureMachineService) isAvailabilityZoneSupported() (bool, error) {
+	zonesInterface, err := s.availabilityZonesSvc.Get(s.clusterScope.Context, &availabilityzones.Spec{})
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to check availability zones in location %s", s.machineScope.Location())
+	}
+	zones, ok := zonesInterface.([]string)
+	if !ok {
+		return false, errors.New("availability zones Get returned invalid interface")
 	}
 
-	s.machineScope.V(2).Info("Availability Zones are not supported in the selected location", "location", s.machineScope.Location())
-	return azSupported
+	if len(zones) == 0 {
+		s.machineScope.V(2).Info("Availability Zones are not supported in the selected location", "location", s.machineScope.Location())
+		return false, nil
+	}
+	return true, nil
 }
 
 // Pick image from the machine configuration, or use a default one.
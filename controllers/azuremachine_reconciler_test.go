@@ -17,14 +17,17 @@ limitations under the License.
 package controllers
 
 import (
+	"context"
 	"testing"
 
+	"github.com/Azure/go-autorest/autorest/to"
 	. "github.com/onsi/gomega"
 
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/cluster-api-provider-azure/api/v1alpha3"
 	azure "sigs.k8s.io/cluster-api-provider-azure/cloud"
 	"sigs.k8s.io/cluster-api-provider-azure/cloud/scope"
+	"sigs.k8s.io/cluster-api-provider-azure/cloud/services/networkinterfaces"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 )
@@ -117,29 +120,548 @@ func TestGetControlPlaneMachines(t *testing.T) {
 func TestIsAvailabilityZoneSupported(t *testing.T) {
 	g := NewWithT(t)
 
+	cases := []struct {
+		name     string
+		zones    []string
+		expected bool
+	}{
+		{
+			name:     "location with availability zones",
+			zones:    []string{"1", "2", "3"},
+			expected: true,
+		},
+		{
+			name:     "location without availability zones",
+			zones:    []string{},
+			expected: false,
+		},
+	}
+
+	for _, c := range cases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			s := azureMachineService{
+				machineScope: &scope.MachineScope{
+					Logger: log.Log.Logger,
+					AzureCluster: &v1alpha3.AzureCluster{
+						Spec: v1alpha3.AzureClusterSpec{
+							Location: "chinanorth2",
+						},
+					},
+				},
+				clusterScope:         &scope.ClusterScope{Context: context.TODO()},
+				availabilityZonesSvc: &fakeZonesService{zones: c.zones},
+			}
+
+			supported, err := s.isAvailabilityZoneSupported()
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(supported).To(Equal(c.expected))
+		})
+	}
+
 	s := azureMachineService{
 		machineScope: &scope.MachineScope{
-			Logger: log.Log.Logger,
-			AzureCluster: &v1alpha3.AzureCluster{
+			Logger:       log.Log.Logger,
+			AzureCluster: &v1alpha3.AzureCluster{},
+		},
+		clusterScope:         &scope.ClusterScope{Context: context.TODO()},
+		availabilityZonesSvc: &azure.FakeFailureService{},
+	}
+	_, err := s.isAvailabilityZoneSupported()
+	g.Expect(err).To(HaveOccurred())
+}
+
+func TestReconcileAvailabilitySet(t *testing.T) {
+	g := NewWithT(t)
+
+	cases := []struct {
+		name       string
+		labels     map[string]string
+		expectedID string
+		expectedAS string
+	}{
+		{
+			name:       "control plane machine",
+			labels:     map[string]string{clusterv1.MachineControlPlaneLabelName: "true"},
+			expectedID: "/subscriptions/123/resourceGroups/my-rg/providers/Microsoft.Compute/availabilitySets/my-cluster_control-plane-as",
+			expectedAS: "my-cluster_control-plane-as",
+		},
+		{
+			name:       "machine deployment machine",
+			labels:     map[string]string{clusterv1.MachineDeploymentLabelName: "md-0"},
+			expectedID: "/subscriptions/123/resourceGroups/my-rg/providers/Microsoft.Compute/availabilitySets/my-cluster_md-0-as",
+			expectedAS: "my-cluster_md-0-as",
+		},
+		{
+			name:   "standalone machine",
+			labels: map[string]string{},
+		},
+	}
+
+	for _, c := range cases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			cache := map[string]int{}
+			azureCluster := &v1alpha3.AzureCluster{
+				Spec: v1alpha3.AzureClusterSpec{ResourceGroup: "my-rg"},
+			}
+			cluster := &clusterv1.Cluster{ObjectMeta: v1.ObjectMeta{Name: "my-cluster"}}
+			s := azureMachineService{
+				machineScope: &scope.MachineScope{
+					Logger:       log.Log.Logger,
+					Cluster:      cluster,
+					Machine:      &clusterv1.Machine{ObjectMeta: v1.ObjectMeta{Labels: c.labels}},
+					AzureCluster: azureCluster,
+				},
+				clusterScope: &scope.ClusterScope{
+					AzureClients: scope.AzureClients{SubscriptionID: "123"},
+					Cluster:      cluster,
+					AzureCluster: azureCluster,
+				},
+				availabilitySetsSvc: &azure.FakeCachedService{Cache: &cache},
+			}
+
+			id, err := s.reconcileAvailabilitySet()
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(id).To(Equal(c.expectedID))
+			if c.expectedAS != "" {
+				g.Expect(cache).To(HaveKeyWithValue(c.expectedAS, 1))
+			} else {
+				g.Expect(cache).To(BeEmpty())
+			}
+		})
+	}
+}
+
+// fakeNICService is a network interfaces service recording the specs it reconciles.
+type fakeNICService struct {
+	azure.FakeSuccessService
+	specs        []*networkinterfaces.Spec
+	natRuleSpecs []*networkinterfaces.SSHNATRuleSpec
+}
+
+func (s *fakeNICService) Reconcile(ctx context.Context, spec interface{}) error {
+	switch spec := spec.(type) {
+	case *networkinterfaces.SSHNATRuleSpec:
+		s.natRuleSpecs = append(s.natRuleSpecs, spec)
+	default:
+		s.specs = append(s.specs, spec.(*networkinterfaces.Spec))
+	}
+	return nil
+}
+
+func TestReconcileNetworkInterfaces(t *testing.T) {
+	g := NewWithT(t)
+
+	cases := []struct {
+		name              string
+		privateIPAddress  string
+		networkInterfaces []v1alpha3.NetworkInterface
+		apiServerLB       v1alpha3.LoadBalancerSpec
+		nodeOutboundLB    *v1alpha3.OutboundLBSpec
+		sshAccess         *v1alpha3.SSHAccessSpec
+		isNode            bool
+		expected          []*networkinterfaces.Spec
+	}{
+		{
+			name:             "default network interface with a private IP address",
+			privateIPAddress: "10.0.0.10",
+			expected: []*networkinterfaces.Spec{
+				{
+					Name:                     "my-vm-nic",
+					SubnetName:               "cp-subnet",
+					VnetName:                 "my-vnet",
+					StaticIPAddress:          "10.0.0.10",
+					PublicLoadBalancerName:   "my-cluster-public-lb",
+					InternalLoadBalancerName: "my-cluster-internal-lb",
+					AcceleratedNetworking:    to.BoolPtr(false),
+					SSHNATRule:               true,
+				},
+			},
+		},
+		{
+			name: "default network interface",
+			expected: []*networkinterfaces.Spec{
+				{
+					Name:                     "my-vm-nic",
+					SubnetName:               "cp-subnet",
+					VnetName:                 "my-vnet",
+					PublicLoadBalancerName:   "my-cluster-public-lb",
+					InternalLoadBalancerName: "my-cluster-internal-lb",
+					AcceleratedNetworking:    to.BoolPtr(false),
+					SSHNATRule:               true,
+				},
+			},
+		},
+		{
+			name:        "default network interface of a private cluster",
+			apiServerLB: v1alpha3.LoadBalancerSpec{Type: v1alpha3.LBTypeInternal},
+			expected: []*networkinterfaces.Spec{
+				{
+					Name:                     "my-vm-nic",
+					SubnetName:               "cp-subnet",
+					VnetName:                 "my-vnet",
+					InternalLoadBalancerName: "my-cluster-internal-lb",
+					AcceleratedNetworking:    to.BoolPtr(false),
+				},
+			},
+		},
+		{
+			name:           "default network interface of a node with a node outbound LB",
+			nodeOutboundLB: &v1alpha3.OutboundLBSpec{},
+			isNode:         true,
+			expected: []*networkinterfaces.Spec{
+				{
+					Name:                  "my-vm-nic",
+					SubnetName:            "node-subnet",
+					VnetName:              "my-vnet",
+					AcceleratedNetworking: to.BoolPtr(false),
+					NodeOutboundLBName:    "my-cluster",
+				},
+			},
+		},
+		{
+			name:           "default network interface of a control plane with a node outbound LB",
+			nodeOutboundLB: &v1alpha3.OutboundLBSpec{},
+			expected: []*networkinterfaces.Spec{
+				{
+					Name:                     "my-vm-nic",
+					SubnetName:               "cp-subnet",
+					VnetName:                 "my-vnet",
+					PublicLoadBalancerName:   "my-cluster-public-lb",
+					InternalLoadBalancerName: "my-cluster-internal-lb",
+					AcceleratedNetworking:    to.BoolPtr(false),
+					SSHNATRule:               true,
+				},
+			},
+		},
+		{
+			name:      "default network interface with SSH access disabled",
+			sshAccess: &v1alpha3.SSHAccessSpec{Mode: v1alpha3.SSHAccessDisabled},
+			expected: []*networkinterfaces.Spec{
+				{
+					Name:                     "my-vm-nic",
+					SubnetName:               "cp-subnet",
+					VnetName:                 "my-vnet",
+					PublicLoadBalancerName:   "my-cluster-public-lb",
+					InternalLoadBalancerName: "my-cluster-internal-lb",
+					AcceleratedNetworking:    to.BoolPtr(false),
+				},
+			},
+		},
+		{
+			name:      "default network interface with SSH access through the bastion only",
+			sshAccess: &v1alpha3.SSHAccessSpec{Mode: v1alpha3.SSHAccessBastionOnly},
+			expected: []*networkinterfaces.Spec{
+				{
+					Name:                     "my-vm-nic",
+					SubnetName:               "cp-subnet",
+					VnetName:                 "my-vnet",
+					PublicLoadBalancerName:   "my-cluster-public-lb",
+					InternalLoadBalancerName: "my-cluster-internal-lb",
+					AcceleratedNetworking:    to.BoolPtr(false),
+				},
+			},
+		},
+		{
+			name: "multiple network interfaces",
+			networkInterfaces: []v1alpha3.NetworkInterface{
+				{SubnetName: "storage-subnet", StaticIPAddress: "10.2.0.10", AcceleratedNetworking: to.BoolPtr(true)},
+				{SubnetName: "cp-subnet", Primary: true},
+			},
+			expected: []*networkinterfaces.Spec{
+				{
+					Name:                  "my-vm-nic",
+					SubnetName:            "storage-subnet",
+					VnetName:              "my-vnet",
+					StaticIPAddress:       "10.2.0.10",
+					AcceleratedNetworking: to.BoolPtr(true),
+				},
+				{
+					Name:                     "my-vm-nic-1",
+					SubnetName:               "cp-subnet",
+					VnetName:                 "my-vnet",
+					PublicLoadBalancerName:   "my-cluster-public-lb",
+					InternalLoadBalancerName: "my-cluster-internal-lb",
+					AcceleratedNetworking:    to.BoolPtr(false),
+					SSHNATRule:               true,
+				},
+			},
+		},
+		{
+			name: "first network interface is primary by default",
+			networkInterfaces: []v1alpha3.NetworkInterface{
+				{SubnetName: "cp-subnet"},
+				{SubnetName: "storage-subnet"},
+			},
+			expected: []*networkinterfaces.Spec{
+				{
+					Name:                     "my-vm-nic",
+					SubnetName:               "cp-subnet",
+					VnetName:                 "my-vnet",
+					PublicLoadBalancerName:   "my-cluster-public-lb",
+					InternalLoadBalancerName: "my-cluster-internal-lb",
+					AcceleratedNetworking:    to.BoolPtr(false),
+					SSHNATRule:               true,
+				},
+				{
+					Name:                  "my-vm-nic-1",
+					SubnetName:            "storage-subnet",
+					VnetName:              "my-vnet",
+					AcceleratedNetworking: to.BoolPtr(false),
+				},
+			},
+		},
+		{
+			name: "network interface in a dual-stack subnet",
+			networkInterfaces: []v1alpha3.NetworkInterface{
+				{SubnetName: "cp-subnet"},
+				{SubnetName: "dual-stack-subnet"},
+			},
+			expected: []*networkinterfaces.Spec{
+				{
+					Name:                     "my-vm-nic",
+					SubnetName:               "cp-subnet",
+					VnetName:                 "my-vnet",
+					PublicLoadBalancerName:   "my-cluster-public-lb",
+					InternalLoadBalancerName: "my-cluster-internal-lb",
+					AcceleratedNetworking:    to.BoolPtr(false),
+					SSHNATRule:               true,
+				},
+				{
+					Name:                  "my-vm-nic-1",
+					SubnetName:            "dual-stack-subnet",
+					VnetName:              "my-vnet",
+					AcceleratedNetworking: to.BoolPtr(false),
+					IPv6Enabled:           true,
+				},
+			},
+		},
+	}
+
+	for _, c := range cases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			azureCluster := &v1alpha3.AzureCluster{
 				Spec: v1alpha3.AzureClusterSpec{
-					Location: "",
+					APIServerLB:    c.apiServerLB,
+					NodeOutboundLB: c.nodeOutboundLB,
+					SSHAccess:      c.sshAccess,
+					NetworkSpec: v1alpha3.NetworkSpec{
+						Vnet: v1alpha3.VnetSpec{Name: "my-vnet"},
+						Subnets: v1alpha3.Subnets{
+							{Role: v1alpha3.SubnetControlPlane, Name: "cp-subnet"},
+							{Role: v1alpha3.SubnetNode, Name: "node-subnet"},
+							{Name: "dual-stack-subnet", CIDRBlocks: []string{"10.2.0.0/16", "2001:1234:5678:9abc::/64"}},
+						},
+					},
+				},
+			}
+			cluster := &clusterv1.Cluster{ObjectMeta: v1.ObjectMeta{Name: "my-cluster"}}
+			nicSvc := &fakeNICService{}
+			labels := map[string]string{clusterv1.MachineControlPlaneLabelName: "true"}
+			if c.isNode {
+				labels = map[string]string{}
+			}
+			s := azureMachineService{
+				machineScope: &scope.MachineScope{
+					Logger:  log.Log.Logger,
+					Cluster: cluster,
+					Machine: &clusterv1.Machine{ObjectMeta: v1.ObjectMeta{
+						Labels: labels,
+					}},
+					AzureMachine: &v1alpha3.AzureMachine{
+						ObjectMeta: v1.ObjectMeta{Name: "my-vm"},
+						Spec: v1alpha3.AzureMachineSpec{
+							AcceleratedNetworking: to.BoolPtr(false),
+							NetworkInterfaces:     c.networkInterfaces,
+							PrivateIPAddress:      c.privateIPAddress,
+						},
+					},
+					AzureCluster: azureCluster,
+				},
+				clusterScope: &scope.ClusterScope{
+					Cluster:      cluster,
+					AzureCluster: azureCluster,
+				},
+				networkInterfacesSvc: nicSvc,
+			}
+
+			for i, nic := range s.networkInterfaces() {
+				g.Expect(s.reconcileNetworkInterface(azure.GenerateIndexedNICName("my-vm", i), nic)).To(Succeed())
+			}
+			g.Expect(nicSvc.specs).To(Equal(c.expected))
+		})
+	}
+}
+
+func TestReconcileSSHAccess(t *testing.T) {
+	g := NewWithT(t)
+
+	cases := []struct {
+		name        string
+		apiServerLB v1alpha3.LoadBalancerSpec
+		sshAccess   *v1alpha3.SSHAccessSpec
+		isNode      bool
+		expected    []*networkinterfaces.SSHNATRuleSpec
+	}{
+		{
+			name: "existing control plane keeps its NAT rule",
+			expected: []*networkinterfaces.SSHNATRuleSpec{
+				{
+					NICName:                "my-vm-nic",
+					PublicLoadBalancerName: "my-cluster-public-lb",
+					Enabled:                true,
 				},
 			},
 		},
+		{
+			name:      "existing control plane loses its NAT rule",
+			sshAccess: &v1alpha3.SSHAccessSpec{Mode: v1alpha3.SSHAccessBastionOnly},
+			expected: []*networkinterfaces.SSHNATRuleSpec{
+				{
+					NICName:                "my-vm-nic",
+					PublicLoadBalancerName: "my-cluster-public-lb",
+				},
+			},
+		},
+		{
+			name:        "existing control plane behind an internal load balancer is left alone",
+			apiServerLB: v1alpha3.LoadBalancerSpec{Type: v1alpha3.LBTypeInternal},
+		},
+		{
+			name:      "existing node is left alone",
+			sshAccess: &v1alpha3.SSHAccessSpec{Mode: v1alpha3.SSHAccessDisabled},
+			isNode:    true,
+		},
 	}
 
-	for _, l := range azure.SupportedAvailabilityZoneLocations {
-		s.machineScope.AzureCluster.Spec.Location = l
-		g.Expect(s.isAvailabilityZoneSupported()).To(BeTrue())
+	for _, c := range cases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			azureCluster := &v1alpha3.AzureCluster{
+				Spec: v1alpha3.AzureClusterSpec{
+					APIServerLB: c.apiServerLB,
+					SSHAccess:   c.sshAccess,
+					NetworkSpec: v1alpha3.NetworkSpec{
+						Vnet: v1alpha3.VnetSpec{Name: "my-vnet"},
+						Subnets: v1alpha3.Subnets{
+							{Role: v1alpha3.SubnetControlPlane, Name: "cp-subnet"},
+							{Role: v1alpha3.SubnetNode, Name: "node-subnet"},
+						},
+					},
+				},
+			}
+			cluster := &clusterv1.Cluster{ObjectMeta: v1.ObjectMeta{Name: "my-cluster"}}
+			nicSvc := &fakeNICService{}
+			labels := map[string]string{clusterv1.MachineControlPlaneLabelName: "true"}
+			if c.isNode {
+				labels = map[string]string{}
+			}
+			s := azureMachineService{
+				machineScope: &scope.MachineScope{
+					Logger:  log.Log.Logger,
+					Cluster: cluster,
+					Machine: &clusterv1.Machine{ObjectMeta: v1.ObjectMeta{
+						Labels: labels,
+					}},
+					AzureMachine: &v1alpha3.AzureMachine{
+						ObjectMeta: v1.ObjectMeta{Name: "my-vm"},
+						Spec: v1alpha3.AzureMachineSpec{
+							AcceleratedNetworking: to.BoolPtr(false),
+						},
+					},
+					AzureCluster: azureCluster,
+				},
+				clusterScope: &scope.ClusterScope{
+					Cluster:      cluster,
+					AzureCluster: azureCluster,
+				},
+				networkInterfacesSvc: nicSvc,
+			}
+
+			g.Expect(s.ReconcileSSHAccess()).To(Succeed())
+			g.Expect(nicSvc.specs).To(BeEmpty())
+			g.Expect(nicSvc.natRuleSpecs).To(Equal(c.expected))
+		})
 	}
+}
+
+// fakeZonesService is an availability zones service returning a fixed list of zones.
+type fakeZonesService struct {
+	azure.FakeSuccessService
+	zones []string
+}
+
+func (s *fakeZonesService) Get(ctx context.Context, spec interface{}) (interface{}, error) {
+	return s.zones, nil
+}
+
+func TestGetVirtualMachineZone(t *testing.T) {
+	g := NewWithT(t)
 
-	unSupportedLocations := []string{
-		"randomregion",
-		"unsupportedregion",
+	cases := []struct {
+		name          string
+		failureDomain *string
+		zoneID        *string
+		expectedZone  string
+		expectedError string
+	}{
+		{
+			name:         "no zone requested",
+			expectedZone: "1",
+		},
+		{
+			name:          "machine failure domain",
+			failureDomain: to.StringPtr("2"),
+			expectedZone:  "2",
+		},
+		{
+			name:         "azure machine availability zone",
+			zoneID:       to.StringPtr("3"),
+			expectedZone: "3",
+		},
+		{
+			name:          "machine failure domain takes precedence",
+			failureDomain: to.StringPtr("2"),
+			zoneID:        to.StringPtr("3"),
+			expectedZone:  "2",
+		},
+		{
+			name:          "unavailable zone",
+			failureDomain: to.StringPtr("4"),
+			expectedError: "availability zone 4 is not available for VM size Standard_D2s_v3 in location eastus",
+		},
 	}
 
-	for _, l := range unSupportedLocations {
-		s.machineScope.AzureCluster.Spec.Location = l
-		g.Expect(s.isAvailabilityZoneSupported()).To(BeFalse())
+	for _, c := range cases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			s := azureMachineService{
+				machineScope: &scope.MachineScope{
+					Logger: log.Log.Logger,
+					Machine: &clusterv1.Machine{
+						Spec: clusterv1.MachineSpec{FailureDomain: c.failureDomain},
+					},
+					AzureMachine: &v1alpha3.AzureMachine{
+						Spec: v1alpha3.AzureMachineSpec{
+							Location:         "eastus",
+							VMSize:           "Standard_D2s_v3",
+							AvailabilityZone: v1alpha3.AvailabilityZone{ID: c.zoneID},
+						},
+					},
+				},
+				clusterScope:         &scope.ClusterScope{Context: context.TODO()},
+				availabilityZonesSvc: &fakeZonesService{zones: []string{"1", "2", "3"}},
+			}
+
+			zone, err := s.getVirtualMachineZone()
+			if c.expectedError != "" {
+				g.Expect(err).To(MatchError(c.expectedError))
+			} else {
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(zone).To(Equal(c.expectedZone))
+			}
+		})
 	}
 }
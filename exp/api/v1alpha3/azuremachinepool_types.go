@@ -0,0 +1,167 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha3
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1alpha3"
+	capierrors "sigs.k8s.io/cluster-api/errors"
+)
+
+const (
+	// MachinePoolFinalizer allows ReconcileAzureMachinePool to clean up Azure resources associated with AzureMachinePool before
+	// removing it from the apiserver.
+	MachinePoolFinalizer = "azuremachinepool.infrastructure.cluster.x-k8s.io"
+)
+
+// AzureMachinePoolMachineTemplate defines the template for the virtual machines of an AzureMachinePool.
+type AzureMachinePoolMachineTemplate struct {
+	// VMSize is the size of the virtual machines in the scale set.
+	VMSize string `json:"vmSize"`
+
+	// Image is used to provide details of an image to use during VM creation.
+	// If image details are omitted the image will default the Azure Marketplace "capi" offer,
+	// which is based on Ubuntu.
+	// +kubebuilder:validation:nullable
+	// +optional
+	Image *infrav1.Image `json:"image,omitempty"`
+
+	// OSDisk contains the operating system disk information for the virtual machines.
+	OSDisk infrav1.OSDisk `json:"osDisk"`
+
+	// SSHPublicKey is the base64 encoded SSH public key authorized on the virtual machines.
+	// A random key is generated when it is empty.
+	// +optional
+	SSHPublicKey string `json:"sshPublicKey,omitempty"`
+}
+
+// AzureMachinePoolSpec defines the desired state of AzureMachinePool
+type AzureMachinePoolSpec struct {
+	// Location is the Azure region location e.g. westus2
+	Location string `json:"location"`
+
+	// Template contains the details used to build the virtual machines of the scale set.
+	Template AzureMachinePoolMachineTemplate `json:"template"`
+
+	// AdditionalTags is an optional set of tags to add to the scale set, in addition to the ones added by default by the
+	// Azure provider. If both the AzureCluster and the AzureMachinePool specify the same tag name with different values, the
+	// AzureMachinePool's value takes precedence.
+	// +optional
+	AdditionalTags infrav1.Tags `json:"additionalTags,omitempty"`
+
+	// ProviderID is the identification ID of the Virtual Machine Scale Set
+	// +optional
+	ProviderID string `json:"providerID,omitempty"`
+
+	// ProviderIDList are the identification IDs of machine instances provided by the provider.
+	// This field must match the provider IDs as seen on the node objects corresponding to a machine pool's machine instances.
+	// +optional
+	ProviderIDList []string `json:"providerIDList,omitempty"`
+}
+
+// AzureMachinePoolInstanceStatus provides status information for each instance in the scale set.
+type AzureMachinePoolInstanceStatus struct {
+	// InstanceID is the identification of the virtual machine within the scale set.
+	// +optional
+	InstanceID string `json:"instanceID,omitempty"`
+
+	// InstanceName is the name of the virtual machine.
+	// +optional
+	InstanceName string `json:"instanceName,omitempty"`
+
+	// ProviderID is the provider identification of the virtual machine.
+	// +optional
+	ProviderID string `json:"providerID,omitempty"`
+
+	// ProvisioningState is the provisioning state of the virtual machine.
+	// +optional
+	ProvisioningState *infrav1.VMState `json:"provisioningState,omitempty"`
+
+	// LatestModelApplied indicates whether the latest model of the scale set has been applied to the virtual machine.
+	// +optional
+	LatestModelApplied bool `json:"latestModelApplied"`
+}
+
+// AzureMachinePoolStatus defines the observed state of AzureMachinePool
+type AzureMachinePoolStatus struct {
+	// Ready is true when the provider resource is ready.
+	// +optional
+	Ready bool `json:"ready"`
+
+	// Replicas is the most recently observed number of replicas.
+	// +optional
+	Replicas int32 `json:"replicas"`
+
+	// Instances is the status of the virtual machines of the scale set.
+	// +optional
+	Instances []*AzureMachinePoolInstanceStatus `json:"instances,omitempty"`
+
+	// ProvisioningState is the provisioning state of the Azure Virtual Machine Scale Set.
+	// +optional
+	ProvisioningState *infrav1.VMState `json:"provisioningState,omitempty"`
+
+	// FailureReason will be set in the event that there is a terminal problem
+	// reconciling the MachinePool and will contain a succinct value suitable
+	// for machine interpretation.
+	//
+	// This field should not be set for transitive errors that a controller
+	// faces that are expected to be fixed automatically over
+	// time (like service outages), but instead indicate that something is
+	// fundamentally wrong with the MachinePool's spec or the configuration of
+	// the controller, and that manual intervention is required.
+	// +optional
+	FailureReason *capierrors.MachineStatusError `json:"failureReason,omitempty"`
+
+	// FailureMessage will be set in the event that there is a terminal problem
+	// reconciling the MachinePool and will contain a more verbose string suitable
+	// for logging and human consumption.
+	//
+	// This field should not be set for transitive errors that a controller
+	// faces that are expected to be fixed automatically over
+	// time (like service outages), but instead indicate that something is
+	// fundamentally wrong with the MachinePool's spec or the configuration of
+	// the controller, and that manual intervention is required.
+	// +optional
+	FailureMessage *string `json:"failureMessage,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:path=azuremachinepools,scope=Namespaced,categories=cluster-api,shortName=amp
+// +kubebuilder:storageversion
+// +kubebuilder:subresource:status
+
+// AzureMachinePool is the Schema for the azuremachinepools API
+type AzureMachinePool struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   AzureMachinePoolSpec   `json:"spec,omitempty"`
+	Status AzureMachinePoolStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// AzureMachinePoolList contains a list of AzureMachinePool
+type AzureMachinePoolList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []AzureMachinePool `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&AzureMachinePool{}, &AzureMachinePoolList{})
+}
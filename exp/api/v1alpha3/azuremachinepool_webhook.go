@@ -0,0 +1,106 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha3
+
+import (
+	"reflect"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1alpha3"
+	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+)
+
+// log is for logging in this package.
+var machinepoollog = logf.Log.WithName("azuremachinepool-resource")
+
+// SetupWebhookWithManager will setup and register the webhook with the controller manager
+func (amp *AzureMachinePool) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(amp).
+		Complete()
+}
+
+// +kubebuilder:webhook:verbs=create;update,path=/validate-infrastructure-cluster-x-k8s-io-v1alpha3-azuremachinepool,mutating=false,failurePolicy=fail,matchPolicy=Equivalent,groups=infrastructure.cluster.x-k8s.io,resources=azuremachinepools,versions=v1alpha3,name=validation.azuremachinepool.infrastructure.cluster.x-k8s.io
+
+var _ webhook.Validator = &AzureMachinePool{}
+
+// ValidateCreate implements webhook.Validator so a webhook will be registered for the type
+func (amp *AzureMachinePool) ValidateCreate() error {
+	machinepoollog.Info("validate create", "name", amp.Name)
+
+	var allErrs field.ErrorList
+
+	templatePath := field.NewPath("spec", "template")
+	if amp.Spec.Template.Image != nil {
+		allErrs = append(allErrs, infrav1.ValidateImage(amp.Spec.Template.Image, templatePath.Child("image"))...)
+	}
+	allErrs = append(allErrs, infrav1.ValidateOSDisk(amp.Spec.Template.OSDisk, templatePath.Child("osDisk"))...)
+
+	if len(allErrs) > 0 {
+		return apierrors.NewInvalid(
+			GroupVersion.WithKind("AzureMachinePool").GroupKind(),
+			amp.Name, allErrs)
+	}
+
+	return nil
+}
+
+// ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
+func (amp *AzureMachinePool) ValidateUpdate(old runtime.Object) error {
+	machinepoollog.Info("validate update", "name", amp.Name)
+
+	var allErrs field.ErrorList
+
+	// the model of the scale set is updated with the template, except for the settings Azure can't change in place.
+	oldMachinePool := old.(*AzureMachinePool)
+	templatePath := field.NewPath("spec", "template")
+	if amp.Spec.Location != oldMachinePool.Spec.Location {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("spec", "location"), amp.Spec.Location, "field is immutable"))
+	}
+	if amp.Spec.Template.SSHPublicKey != oldMachinePool.Spec.Template.SSHPublicKey {
+		allErrs = append(allErrs, field.Invalid(templatePath.Child("sshPublicKey"), amp.Spec.Template.SSHPublicKey, "field is immutable"))
+	}
+	if amp.Spec.Template.OSDisk.OSType != oldMachinePool.Spec.Template.OSDisk.OSType {
+		allErrs = append(allErrs, field.Invalid(templatePath.Child("osDisk", "osType"), amp.Spec.Template.OSDisk.OSType, "field is immutable"))
+	}
+	if !reflect.DeepEqual(amp.Spec.Template.OSDisk.DiffDiskSettings, oldMachinePool.Spec.Template.OSDisk.DiffDiskSettings) {
+		allErrs = append(allErrs, field.Forbidden(templatePath.Child("osDisk", "diffDiskSettings"), "field is immutable"))
+	}
+	if amp.Spec.Template.Image != nil {
+		allErrs = append(allErrs, infrav1.ValidateImage(amp.Spec.Template.Image, templatePath.Child("image"))...)
+	}
+	allErrs = append(allErrs, infrav1.ValidateOSDisk(amp.Spec.Template.OSDisk, templatePath.Child("osDisk"))...)
+
+	if len(allErrs) > 0 {
+		return apierrors.NewInvalid(
+			GroupVersion.WithKind("AzureMachinePool").GroupKind(),
+			amp.Name, allErrs)
+	}
+
+	return nil
+}
+
+// ValidateDelete implements webhook.Validator so a webhook will be registered for the type
+func (amp *AzureMachinePool) ValidateDelete() error {
+	machinepoollog.Info("validate delete", "name", amp.Name)
+
+	return nil
+}
@@ -0,0 +1,149 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha3
+
+import (
+	"testing"
+
+	"github.com/Azure/go-autorest/autorest/to"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1alpha3"
+)
+
+func createMachinePool(template AzureMachinePoolMachineTemplate) *AzureMachinePool {
+	return &AzureMachinePool{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-pool", Namespace: "default"},
+		Spec: AzureMachinePoolSpec{
+			Location: "westus2",
+			Template: template,
+		},
+	}
+}
+
+func validTemplate() AzureMachinePoolMachineTemplate {
+	return AzureMachinePoolMachineTemplate{
+		VMSize: "Standard_D2s_v3",
+		OSDisk: infrav1.OSDisk{
+			OSType:      "Linux",
+			DiskSizeGB:  30,
+			ManagedDisk: infrav1.ManagedDisk{StorageAccountType: "Premium_LRS"},
+		},
+		SSHPublicKey: "c3NoLXJzYSBBQUFB",
+	}
+}
+
+func TestAzureMachinePool_ValidateCreate(t *testing.T) {
+	g := NewWithT(t)
+
+	invalidDisk := validTemplate()
+	invalidDisk.OSDisk.ManagedDisk.DiskEncryptionSetID = "my-disk-encryption-set"
+
+	g.Expect(createMachinePool(validTemplate()).ValidateCreate()).To(Succeed())
+	g.Expect(createMachinePool(invalidDisk).ValidateCreate()).NotTo(Succeed())
+}
+
+func TestAzureMachinePool_ValidateUpdate(t *testing.T) {
+	g := NewWithT(t)
+
+	largerSize := validTemplate()
+	largerSize.VMSize = "Standard_D4s_v3"
+	otherImage := validTemplate()
+	otherImage.Image = &infrav1.Image{ID: to.StringPtr("/subscriptions/123/images/my-image")}
+	otherKey := validTemplate()
+	otherKey.SSHPublicKey = "c3NoLXJzYSBCQkJC"
+	otherDisk := validTemplate()
+	otherDisk.OSDisk.DiskSizeGB = 60
+	ephemeralDisk := validTemplate()
+	ephemeralDisk.OSDisk.DiffDiskSettings = &infrav1.DiffDiskSettings{Option: "Local"}
+	otherOSType := validTemplate()
+	otherOSType.OSDisk.OSType = "Windows"
+	invalidImage := validTemplate()
+	invalidImage.Image = &infrav1.Image{Marketplace: &infrav1.AzureMarketplaceImage{Offer: "offer", SKU: "sku", Version: "1.0.0"}}
+	otherLocation := createMachinePool(validTemplate())
+	otherLocation.Spec.Location = "eastus"
+	otherTags := createMachinePool(validTemplate())
+	otherTags.Spec.AdditionalTags = infrav1.Tags{"team": "a"}
+
+	tests := []struct {
+		name        string
+		machinePool *AzureMachinePool
+		wantErr     bool
+	}{
+		{
+			name:        "unchanged template",
+			machinePool: createMachinePool(validTemplate()),
+			wantErr:     false,
+		},
+		{
+			name:        "changed additional tags",
+			machinePool: otherTags,
+			wantErr:     false,
+		},
+		{
+			name:        "changed vm size",
+			machinePool: createMachinePool(largerSize),
+			wantErr:     false,
+		},
+		{
+			name:        "changed image",
+			machinePool: createMachinePool(otherImage),
+			wantErr:     false,
+		},
+		{
+			name:        "invalid image",
+			machinePool: createMachinePool(invalidImage),
+			wantErr:     true,
+		},
+		{
+			name:        "changed os disk size",
+			machinePool: createMachinePool(otherDisk),
+			wantErr:     false,
+		},
+		{
+			name:        "changed ssh public key",
+			machinePool: createMachinePool(otherKey),
+			wantErr:     true,
+		},
+		{
+			name:        "changed os type",
+			machinePool: createMachinePool(otherOSType),
+			wantErr:     true,
+		},
+		{
+			name:        "changed to an ephemeral os disk",
+			machinePool: createMachinePool(ephemeralDisk),
+			wantErr:     true,
+		},
+		{
+			name:        "changed location",
+			machinePool: otherLocation,
+			wantErr:     true,
+		},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.machinePool.ValidateUpdate(createMachinePool(validTemplate()))
+			if tc.wantErr {
+				g.Expect(err).To(HaveOccurred())
+			} else {
+				g.Expect(err).NotTo(HaveOccurred())
+			}
+		})
+	}
+}
@@ -0,0 +1,43 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha3
+
+import (
+	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1alpha3"
+)
+
+// VMSS describes an Azure Virtual Machine Scale Set.
+type VMSS struct {
+	ID       string `json:"id,omitempty"`
+	Name     string `json:"name,omitempty"`
+	Sku      string `json:"sku,omitempty"`
+	Capacity int64  `json:"capacity,omitempty"`
+	// State - The provisioning state, which only appears in the response.
+	State     infrav1.VMState `json:"vmState,omitempty"`
+	Tags      infrav1.Tags    `json:"tags,omitempty"`
+	Instances []VMSSVM        `json:"instances,omitempty"`
+}
+
+// VMSSVM describes a virtual machine of an Azure Virtual Machine Scale Set.
+type VMSSVM struct {
+	ID         string `json:"id,omitempty"`
+	InstanceID string `json:"instanceID,omitempty"`
+	Name       string `json:"name,omitempty"`
+	// State - The provisioning state, which only appears in the response.
+	State              infrav1.VMState `json:"vmState,omitempty"`
+	LatestModelApplied bool            `json:"latestModelApplied"`
+}
@@ -0,0 +1,322 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/tools/record"
+	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1alpha3"
+	"sigs.k8s.io/cluster-api-provider-azure/cloud/scope"
+	"sigs.k8s.io/cluster-api-provider-azure/cloud/services/resourceskus"
+	infrav1exp "sigs.k8s.io/cluster-api-provider-azure/exp/api/v1alpha3"
+	capierrors "sigs.k8s.io/cluster-api/errors"
+	expv1 "sigs.k8s.io/cluster-api/exp/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/util"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+// AzureMachinePoolReconciler reconciles a AzureMachinePool object
+type AzureMachinePoolReconciler struct {
+	client.Client
+	Log      logr.Logger
+	Recorder record.EventRecorder
+	// SKUCache is the resource SKU cache shared by the controllers of the manager.
+	SKUCache *resourceskus.Cache
+}
+
+func (r *AzureMachinePoolReconciler) SetupWithManager(mgr ctrl.Manager, options controller.Options) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		WithOptions(options).
+		For(&infrav1exp.AzureMachinePool{}).
+		Watches(
+			&source.Kind{Type: &expv1.MachinePool{}},
+			&handler.EnqueueRequestsFromMapFunc{
+				ToRequests: machinePoolToInfrastructureMapFunc(infrav1exp.GroupVersion.WithKind("AzureMachinePool")),
+			},
+		).
+		Complete(r)
+}
+
+// +kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=azuremachinepools,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=azuremachinepools/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=exp.cluster.x-k8s.io,resources=machinepools;machinepools/status,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=events,verbs=get;list;watch;create;update;patch
+// +kubebuilder:rbac:groups="",resources=secrets;,verbs=get;list;watch
+
+func (r *AzureMachinePoolReconciler) Reconcile(req ctrl.Request) (_ ctrl.Result, reterr error) {
+	ctx := context.TODO()
+	logger := r.Log.WithValues("namespace", req.Namespace, "azureMachinePool", req.Name)
+
+	// Fetch the AzureMachinePool.
+	azureMachinePool := &infrav1exp.AzureMachinePool{}
+	err := r.Get(ctx, req.NamespacedName, azureMachinePool)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return reconcile.Result{}, nil
+		}
+		return reconcile.Result{}, err
+	}
+
+	// Fetch the MachinePool.
+	machinePool, err := getOwnerMachinePool(ctx, r.Client, azureMachinePool.ObjectMeta)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	if machinePool == nil {
+		logger.Info("MachinePool Controller has not yet set OwnerRef")
+		return reconcile.Result{}, nil
+	}
+
+	logger = logger.WithValues("machinePool", machinePool.Name)
+
+	// Fetch the Cluster.
+	cluster, err := util.GetClusterFromMetadata(ctx, r.Client, machinePool.ObjectMeta)
+	if err != nil {
+		logger.Info("MachinePool is missing cluster label or cluster does not exist")
+		return reconcile.Result{}, nil
+	}
+
+	logger = logger.WithValues("cluster", cluster.Name)
+
+	azureCluster := &infrav1.AzureCluster{}
+
+	azureClusterName := client.ObjectKey{
+		Namespace: azureMachinePool.Namespace,
+		Name:      cluster.Spec.InfrastructureRef.Name,
+	}
+	if err := r.Client.Get(ctx, azureClusterName, azureCluster); err != nil {
+		logger.Info("AzureCluster is not available yet")
+		return reconcile.Result{}, nil
+	}
+
+	logger = logger.WithValues("azureCluster", azureCluster.Name)
+
+	// Create the cluster scope
+	clusterScope, err := scope.NewClusterScope(scope.ClusterScopeParams{
+		Client:       r.Client,
+		Logger:       logger,
+		Cluster:      cluster,
+		AzureCluster: azureCluster,
+	})
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	// Create the machine pool scope
+	machinePoolScope, err := scope.NewMachinePoolScope(scope.MachinePoolScopeParams{
+		Logger:           logger,
+		Client:           r.Client,
+		Cluster:          cluster,
+		MachinePool:      machinePool,
+		AzureCluster:     azureCluster,
+		AzureMachinePool: azureMachinePool,
+	})
+	if err != nil {
+		return reconcile.Result{}, errors.Errorf("failed to create scope: %+v", err)
+	}
+
+	// Always close the scope when exiting this function so we can persist any AzureMachinePool changes.
+	defer func() {
+		if err := machinePoolScope.Close(); err != nil && reterr == nil {
+			reterr = err
+		}
+	}()
+
+	// Handle deleted machine pools
+	if !azureMachinePool.ObjectMeta.DeletionTimestamp.IsZero() {
+		return r.reconcileDelete(machinePoolScope, clusterScope)
+	}
+
+	// Handle non-deleted machine pools
+	return r.reconcileNormal(machinePoolScope, clusterScope)
+}
+
+func (r *AzureMachinePoolReconciler) reconcileNormal(machinePoolScope *scope.MachinePoolScope, clusterScope *scope.ClusterScope) (reconcile.Result, error) {
+	machinePoolScope.Info("Reconciling AzureMachinePool")
+	// If the AzureMachinePool is in an error state, return early.
+	if machinePoolScope.AzureMachinePool.Status.FailureReason != nil || machinePoolScope.AzureMachinePool.Status.FailureMessage != nil {
+		machinePoolScope.Info("Error state detected, skipping reconciliation")
+		return reconcile.Result{}, nil
+	}
+
+	// If the AzureMachinePool doesn't have our finalizer, add it.
+	controllerutil.AddFinalizer(machinePoolScope.AzureMachinePool, infrav1exp.MachinePoolFinalizer)
+	// Register the finalizer immediately to avoid orphaning Azure resources on delete
+	if err := machinePoolScope.PatchObject(); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	if !machinePoolScope.Cluster.Status.InfrastructureReady {
+		machinePoolScope.Info("Cluster infrastructure is not ready yet")
+		return reconcile.Result{}, nil
+	}
+
+	// Make sure bootstrap data is available and populated.
+	if machinePoolScope.MachinePool.Spec.Template.Spec.Bootstrap.DataSecretName == nil {
+		machinePoolScope.Info("Bootstrap data secret reference is not yet available")
+		return reconcile.Result{}, nil
+	}
+
+	return r.reconcileScaleSet(machinePoolScope, newAzureMachinePoolService(machinePoolScope, clusterScope, r.SKUCache))
+}
+
+// reconcileScaleSet creates or scales the scale set of the machine pool, and reports its instances in the AzureMachinePool.
+func (r *AzureMachinePoolReconciler) reconcileScaleSet(machinePoolScope *scope.MachinePoolScope, ams *azureMachinePoolService) (reconcile.Result, error) {
+	// Check the template against the capabilities of its VM size before the scale set is created.
+	if machinePoolScope.AzureMachinePool.Spec.ProviderID == "" {
+		errs, err := ams.validateSKUCapabilities()
+		if err != nil {
+			return reconcile.Result{}, err
+		}
+		if len(errs) > 0 {
+			err := errs.ToAggregate()
+			machinePoolScope.Info("Invalid AzureMachinePool configuration", "error", err.Error())
+			machinePoolScope.SetFailureReason(capierrors.InvalidConfigurationMachineError)
+			machinePoolScope.SetFailureMessage(err)
+			r.Recorder.Eventf(machinePoolScope.AzureMachinePool, corev1.EventTypeWarning, "InvalidConfiguration", "Invalid configuration: %s", err.Error())
+			return reconcile.Result{}, nil
+		}
+	}
+
+	vmss, err := ams.CreateOrUpdate()
+	if err != nil {
+		r.Recorder.Eventf(machinePoolScope.AzureMachinePool, corev1.EventTypeWarning, "FailedCreateOrUpdate", "Failed to create or update scale set: %s", err.Error())
+		return reconcile.Result{}, err
+	}
+
+	// Make sure Spec.ProviderID and Spec.ProviderIDList are always set.
+	machinePoolScope.SetProviderID(fmt.Sprintf("azure:////%s", vmss.ID))
+	providerIDs := make([]string, len(vmss.Instances))
+	instances := make([]*infrav1exp.AzureMachinePoolInstanceStatus, len(vmss.Instances))
+	for i, vm := range vmss.Instances {
+		state := vm.State
+		providerIDs[i] = fmt.Sprintf("azure:////%s", vm.ID)
+		instances[i] = &infrav1exp.AzureMachinePoolInstanceStatus{
+			InstanceID:         vm.InstanceID,
+			InstanceName:       vm.Name,
+			ProviderID:         providerIDs[i],
+			ProvisioningState:  &state,
+			LatestModelApplied: vm.LatestModelApplied,
+		}
+	}
+	machinePoolScope.SetProviderIDList(providerIDs)
+	machinePoolScope.SetInstances(instances)
+
+	// Proceed to reconcile the AzureMachinePool state.
+	machinePoolScope.SetProvisioningState(vmss.State)
+
+	switch vmss.State {
+	case infrav1.VMStateSucceeded:
+		machinePoolScope.Info("Scale set is running", "id", vmss.ID)
+		machinePoolScope.SetReady()
+	case infrav1.VMStateCreating, infrav1.VMStateUpdating:
+		machinePoolScope.Info("Scale set is updating", "id", vmss.ID)
+		machinePoolScope.SetNotReady()
+	default:
+		machinePoolScope.SetNotReady()
+		machinePoolScope.SetFailureReason(capierrors.UpdateMachineError)
+		machinePoolScope.SetFailureMessage(errors.Errorf("Azure VMSS state %q is unexpected", vmss.State))
+	}
+
+	return reconcile.Result{}, nil
+}
+
+func (r *AzureMachinePoolReconciler) reconcileDelete(machinePoolScope *scope.MachinePoolScope, clusterScope *scope.ClusterScope) (_ reconcile.Result, reterr error) {
+	machinePoolScope.Info("Handling deleted AzureMachinePool")
+
+	if err := newAzureMachinePoolService(machinePoolScope, clusterScope, r.SKUCache).Delete(); err != nil {
+		return reconcile.Result{}, errors.Wrapf(err, "error deleting AzureMachinePool %s/%s", machinePoolScope.Namespace(), machinePoolScope.Name())
+	}
+
+	defer func() {
+		if reterr == nil {
+			// VMSS is deleted so remove the finalizer.
+			controllerutil.RemoveFinalizer(machinePoolScope.AzureMachinePool, infrav1exp.MachinePoolFinalizer)
+		}
+	}()
+
+	return reconcile.Result{}, nil
+}
+
+// getOwnerMachinePool returns the MachinePool object owning the current resource.
+func getOwnerMachinePool(ctx context.Context, c client.Client, obj metav1.ObjectMeta) (*expv1.MachinePool, error) {
+	for _, ref := range obj.OwnerReferences {
+		if ref.Kind != "MachinePool" {
+			continue
+		}
+		gv, err := schema.ParseGroupVersion(ref.APIVersion)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		if gv.Group == expv1.GroupVersion.Group {
+			return getMachinePoolByName(ctx, c, obj.Namespace, ref.Name)
+		}
+	}
+	return nil, nil
+}
+
+// getMachinePoolByName finds and returns a MachinePool object using the specified params.
+func getMachinePoolByName(ctx context.Context, c client.Client, namespace, name string) (*expv1.MachinePool, error) {
+	m := &expv1.MachinePool{}
+	key := client.ObjectKey{Name: name, Namespace: namespace}
+	if err := c.Get(ctx, key, m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// machinePoolToInfrastructureMapFunc returns a handler.ToRequestsFunc that watches for
+// MachinePool events and returns reconciliation requests for an infrastructure provider object.
+func machinePoolToInfrastructureMapFunc(gvk schema.GroupVersionKind) handler.ToRequestsFunc {
+	return func(o handler.MapObject) []reconcile.Request {
+		m, ok := o.Object.(*expv1.MachinePool)
+		if !ok {
+			return nil
+		}
+
+		gk := gvk.GroupKind()
+		ref := m.Spec.Template.Spec.InfrastructureRef
+		// Return early if the GroupKind doesn't match what we expect.
+		infraGK := ref.GroupVersionKind().GroupKind()
+		if gk != infraGK {
+			return nil
+		}
+
+		return []reconcile.Request{
+			{
+				NamespacedName: client.ObjectKey{
+					Namespace: m.Namespace,
+					Name:      ref.Name,
+				},
+			},
+		}
+	}
+}
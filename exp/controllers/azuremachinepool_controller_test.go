@@ -0,0 +1,94 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	infrav1exp "sigs.k8s.io/cluster-api-provider-azure/exp/api/v1alpha3"
+	expv1 "sigs.k8s.io/cluster-api/exp/api/v1alpha3"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+)
+
+func newMachinePool(name string, infrastructureRef corev1.ObjectReference) *expv1.MachinePool {
+	mp := &expv1.MachinePool{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "default",
+		},
+	}
+	mp.Spec.Template.Spec.InfrastructureRef = infrastructureRef
+	return mp
+}
+
+func TestMachinePoolToInfrastructureMapFunc(t *testing.T) {
+	g := NewWithT(t)
+
+	mapFunc := machinePoolToInfrastructureMapFunc(infrav1exp.GroupVersion.WithKind("AzureMachinePool"))
+
+	requests := mapFunc(handler.MapObject{
+		Object: newMachinePool("my-pool", corev1.ObjectReference{
+			Kind:       "AzureMachinePool",
+			Name:       "azure-my-pool",
+			APIVersion: infrav1exp.GroupVersion.String(),
+		}),
+	})
+	g.Expect(requests).To(HaveLen(1))
+	g.Expect(requests[0].Namespace).To(Equal("default"))
+	g.Expect(requests[0].Name).To(Equal("azure-my-pool"))
+
+	requests = mapFunc(handler.MapObject{
+		Object: newMachinePool("my-pool", corev1.ObjectReference{
+			Kind:       "AWSMachinePool",
+			Name:       "aws-my-pool",
+			APIVersion: "infrastructure.cluster.x-k8s.io/v1alpha3",
+		}),
+	})
+	g.Expect(requests).To(BeEmpty())
+}
+
+func TestGetOwnerMachinePool(t *testing.T) {
+	g := NewWithT(t)
+
+	scheme := runtime.NewScheme()
+	g.Expect(expv1.AddToScheme(scheme)).To(Succeed())
+	client := fake.NewFakeClientWithScheme(scheme, newMachinePool("my-pool", corev1.ObjectReference{}))
+
+	machinePool, err := getOwnerMachinePool(context.TODO(), client, metav1.ObjectMeta{
+		Namespace: "default",
+		OwnerReferences: []metav1.OwnerReference{
+			{
+				Kind:       "MachinePool",
+				Name:       "my-pool",
+				APIVersion: expv1.GroupVersion.String(),
+			},
+		},
+	})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(machinePool).NotTo(BeNil())
+	g.Expect(machinePool.Name).To(Equal("my-pool"))
+
+	machinePool, err = getOwnerMachinePool(context.TODO(), client, metav1.ObjectMeta{Namespace: "default"})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(machinePool).To(BeNil())
+}
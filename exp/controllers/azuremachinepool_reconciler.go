@@ -0,0 +1,160 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"encoding/base64"
+	"fmt"
+
+	"github.com/Azure/go-autorest/autorest/to"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1alpha3"
+	azure "sigs.k8s.io/cluster-api-provider-azure/cloud"
+	"sigs.k8s.io/cluster-api-provider-azure/cloud/scope"
+	"sigs.k8s.io/cluster-api-provider-azure/cloud/services/resourceskus"
+	"sigs.k8s.io/cluster-api-provider-azure/cloud/services/scalesets"
+	infrav1exp "sigs.k8s.io/cluster-api-provider-azure/exp/api/v1alpha3"
+)
+
+// azureMachinePoolService are list of services required by the machine pool reconciler
+type azureMachinePoolService struct {
+	machinePoolScope *scope.MachinePoolScope
+	clusterScope     *scope.ClusterScope
+	scaleSetsSvc     azure.GetterService
+	resourceSKUSvc   azure.GetterService
+}
+
+// newAzureMachinePoolService populates all the services based on input scope
+func newAzureMachinePoolService(machinePoolScope *scope.MachinePoolScope, clusterScope *scope.ClusterScope, skuCache *resourceskus.Cache) *azureMachinePoolService {
+	return &azureMachinePoolService{
+		machinePoolScope: machinePoolScope,
+		clusterScope:     clusterScope,
+		scaleSetsSvc:     scalesets.NewService(clusterScope, machinePoolScope),
+		resourceSKUSvc:   resourceskus.NewService(clusterScope, skuCache),
+	}
+}
+
+// validateSKUCapabilities checks the template of the AzureMachinePool against the capabilities of its VM size
+// in the location of the cluster, so that an unsupported template fails before the scale set is created.
+func (s *azureMachinePoolService) validateSKUCapabilities() (field.ErrorList, error) {
+	template := s.machinePoolScope.AzureMachinePool.Spec.Template
+	templatePath := field.NewPath("spec", "template")
+	var errs field.ErrorList
+
+	skuSpec := &resourceskus.Spec{
+		ResourceType: resourceskus.VirtualMachines,
+		Name:         template.VMSize,
+	}
+	skuInterface, err := s.resourceSKUSvc.Get(s.clusterScope.Context, skuSpec)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get the capabilities of VM size %s", template.VMSize)
+	}
+	if skuInterface == nil {
+		errs = append(errs, field.Invalid(templatePath.Child("vmSize"), template.VMSize,
+			fmt.Sprintf("VM size is not available in location %s", s.clusterScope.Location())))
+		return errs, nil
+	}
+	sku, ok := skuInterface.(resourceskus.SKU)
+	if !ok {
+		return nil, errors.New("resource sku Get returned invalid interface")
+	}
+
+	if template.OSDisk.DiffDiskSettings != nil && !sku.HasCapability(resourceskus.EphemeralOSDisk) {
+		errs = append(errs, field.Invalid(templatePath.Child("osDisk", "diffDiskSettings", "option"), template.OSDisk.DiffDiskSettings.Option,
+			fmt.Sprintf("VM size %s does not support ephemeral OS disks", template.VMSize)))
+	}
+
+	return errs, nil
+}
+
+// CreateOrUpdate creates the scale set of the machine pool, or scales it to the replicas of the machine pool.
+func (s *azureMachinePoolService) CreateOrUpdate() (*infrav1exp.VMSS, error) {
+	template := s.machinePoolScope.AzureMachinePool.Spec.Template
+
+	decoded, err := base64.StdEncoding.DecodeString(template.SSHPublicKey)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to decode ssh public key")
+	}
+
+	image, err := getVMImage(s.machinePoolScope)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get VM image")
+	}
+
+	bootstrapData, err := s.machinePoolScope.GetBootstrapData()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to retrieve bootstrap data")
+	}
+
+	nodeSubnet := s.clusterScope.NodeSubnet()
+	if nodeSubnet == nil {
+		return nil, errors.New("failed to find the node subnet of the cluster")
+	}
+
+	vmssSpec := &scalesets.Spec{
+		Name:       s.machinePoolScope.Name(),
+		Sku:        template.VMSize,
+		Capacity:   s.machinePoolScope.Replicas(),
+		SSHKeyData: string(decoded),
+		Image:      image,
+		OSDisk:     template.OSDisk,
+		CustomData: bootstrapData,
+		SubnetID:   azure.SubnetID(s.clusterScope.SubscriptionID, s.clusterScope.Vnet().ResourceGroup, s.clusterScope.Vnet().Name, nodeSubnet.Name),
+	}
+
+	err = s.scaleSetsSvc.Reconcile(s.clusterScope.Context, vmssSpec)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to create or update scale set")
+	}
+
+	vmssInterface, err := s.scaleSetsSvc.Get(s.clusterScope.Context, vmssSpec)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get scale set")
+	}
+
+	vmss, ok := vmssInterface.(*infrav1exp.VMSS)
+	if !ok {
+		return nil, errors.New("returned incorrect vmss interface")
+	}
+
+	return vmss, nil
+}
+
+// Delete deletes the scale set of the machine pool.
+func (s *azureMachinePoolService) Delete() error {
+	vmssSpec := &scalesets.Spec{
+		Name: s.machinePoolScope.Name(),
+	}
+
+	err := s.scaleSetsSvc.Delete(s.clusterScope.Context, vmssSpec)
+	if err != nil {
+		return errors.Wrapf(err, "failed to delete scale set")
+	}
+
+	return nil
+}
+
+// Pick image from the machine pool configuration, or use a default one.
+func getVMImage(scope *scope.MachinePoolScope) (*infrav1.Image, error) {
+	// Use custom Marketplace image, Image ID or a Shared Image Gallery image if provided
+	if scope.AzureMachinePool.Spec.Template.Image != nil {
+		return scope.AzureMachinePool.Spec.Template.Image, nil
+	}
+	scope.Info("No image specified for machine pool, using default", "machinePool", scope.AzureMachinePool.GetName())
+	return azure.GetDefaultUbuntuImage(to.String(scope.MachinePool.Spec.Template.Spec.Version))
+}
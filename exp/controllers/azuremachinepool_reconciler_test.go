@@ -0,0 +1,314 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2020-06-01/compute"
+	"github.com/Azure/go-autorest/autorest/to"
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1alpha3"
+	azure "sigs.k8s.io/cluster-api-provider-azure/cloud"
+	"sigs.k8s.io/cluster-api-provider-azure/cloud/scope"
+	"sigs.k8s.io/cluster-api-provider-azure/cloud/services/resourceskus"
+	"sigs.k8s.io/cluster-api-provider-azure/cloud/services/scalesets"
+	infrav1exp "sigs.k8s.io/cluster-api-provider-azure/exp/api/v1alpha3"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+	capierrors "sigs.k8s.io/cluster-api/errors"
+	expv1 "sigs.k8s.io/cluster-api/exp/api/v1alpha3"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// fakeScaleSetsService is a scale sets service that scales a fake scale set to the capacity of the specs it reconciles.
+type fakeScaleSetsService struct {
+	azure.FakeSuccessService
+	specs    []*scalesets.Spec
+	capacity int64
+	state    infrav1.VMState
+	err      error
+}
+
+func (s *fakeScaleSetsService) Reconcile(ctx context.Context, spec interface{}) error {
+	if s.err != nil {
+		return s.err
+	}
+	vmssSpec := spec.(*scalesets.Spec)
+	s.specs = append(s.specs, vmssSpec)
+	s.capacity = vmssSpec.Capacity
+	return nil
+}
+
+func (s *fakeScaleSetsService) Get(ctx context.Context, spec interface{}) (interface{}, error) {
+	vmssSpec := spec.(*scalesets.Spec)
+	id := "/subscriptions/123/resourceGroups/my-rg/providers/Microsoft.Compute/virtualMachineScaleSets/" + vmssSpec.Name
+	vmss := &infrav1exp.VMSS{
+		ID:       id,
+		Name:     vmssSpec.Name,
+		Sku:      vmssSpec.Sku,
+		Capacity: s.capacity,
+		State:    s.state,
+	}
+	for i := int64(0); i < s.capacity; i++ {
+		vmss.Instances = append(vmss.Instances, infrav1exp.VMSSVM{
+			ID:                 fmt.Sprintf("%s/virtualMachines/%d", id, i),
+			InstanceID:         fmt.Sprintf("%d", i),
+			Name:               fmt.Sprintf("%s%06d", vmssSpec.Name, i),
+			State:              infrav1.VMStateSucceeded,
+			LatestModelApplied: true,
+		})
+	}
+	return vmss, nil
+}
+
+// fakeSKUService is a resource SKU service returning a VM size SKU with or without ephemeral OS disk support.
+type fakeSKUService struct {
+	azure.FakeSuccessService
+	ephemeralOSDisk bool
+}
+
+func (s *fakeSKUService) Get(ctx context.Context, spec interface{}) (interface{}, error) {
+	skuSpec := spec.(*resourceskus.Spec)
+	ephemeralOSDisk := "False"
+	if s.ephemeralOSDisk {
+		ephemeralOSDisk = "True"
+	}
+	return resourceskus.SKU{
+		Name:         to.StringPtr(skuSpec.Name),
+		ResourceType: to.StringPtr(resourceskus.VirtualMachines),
+		Capabilities: &[]compute.ResourceSkuCapabilities{
+			{Name: to.StringPtr(resourceskus.EphemeralOSDisk), Value: to.StringPtr(ephemeralOSDisk)},
+		},
+	}, nil
+}
+
+func newTestMachinePoolScopes(t *testing.T, replicas int32) (*scope.MachinePoolScope, *scope.ClusterScope) {
+	g := NewWithT(t)
+
+	scheme := runtime.NewScheme()
+	g.Expect(corev1.AddToScheme(scheme)).To(Succeed())
+	g.Expect(infrav1exp.AddToScheme(scheme)).To(Succeed())
+
+	cluster := &clusterv1.Cluster{ObjectMeta: metav1.ObjectMeta{Name: "my-cluster", Namespace: "default"}}
+	azureCluster := &infrav1.AzureCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-cluster", Namespace: "default"},
+		Spec: infrav1.AzureClusterSpec{
+			Location:      "westus2",
+			ResourceGroup: "my-rg",
+			NetworkSpec: infrav1.NetworkSpec{
+				Vnet: infrav1.VnetSpec{Name: "my-vnet", ResourceGroup: "my-rg"},
+				Subnets: infrav1.Subnets{
+					{Role: infrav1.SubnetControlPlane, Name: "cp-subnet"},
+					{Role: infrav1.SubnetNode, Name: "node-subnet"},
+				},
+			},
+		},
+	}
+	machinePool := &expv1.MachinePool{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-pool", Namespace: "default"},
+		Spec:       expv1.MachinePoolSpec{Replicas: &replicas},
+	}
+	machinePool.Spec.Template.Spec.Version = to.StringPtr("v1.17.4")
+	machinePool.Spec.Template.Spec.Bootstrap.DataSecretName = to.StringPtr("my-pool-bootstrap")
+	azureMachinePool := &infrav1exp.AzureMachinePool{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-pool", Namespace: "default"},
+		Spec: infrav1exp.AzureMachinePoolSpec{
+			Location: "westus2",
+			Template: infrav1exp.AzureMachinePoolMachineTemplate{
+				VMSize: "Standard_D2s_v3",
+				OSDisk: infrav1.OSDisk{OSType: "Linux", DiskSizeGB: 30},
+			},
+		},
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-pool-bootstrap", Namespace: "default"},
+		Data:       map[string][]byte{"value": []byte("bootstrap-data")},
+	}
+
+	machinePoolScope, err := scope.NewMachinePoolScope(scope.MachinePoolScopeParams{
+		Client:           fake.NewFakeClientWithScheme(scheme, azureMachinePool, secret),
+		Logger:           log.Log,
+		Cluster:          cluster,
+		MachinePool:      machinePool,
+		AzureCluster:     azureCluster,
+		AzureMachinePool: azureMachinePool,
+	})
+	g.Expect(err).NotTo(HaveOccurred())
+
+	clusterScope := &scope.ClusterScope{
+		AzureClients: scope.AzureClients{SubscriptionID: "123"},
+		Cluster:      cluster,
+		AzureCluster: azureCluster,
+		Context:      context.TODO(),
+	}
+	return machinePoolScope, clusterScope
+}
+
+func TestReconcileScaleSet(t *testing.T) {
+	g := NewWithT(t)
+
+	cases := []struct {
+		name             string
+		replicas         int32
+		existingCapacity int64
+		state            infrav1.VMState
+		err              error
+		expectedError    string
+		expectedReady    bool
+	}{
+		{
+			name:          "create a scale set",
+			replicas:      2,
+			state:         infrav1.VMStateCreating,
+			expectedReady: false,
+		},
+		{
+			name:             "scale up a scale set",
+			replicas:         3,
+			existingCapacity: 1,
+			state:            infrav1.VMStateSucceeded,
+			expectedReady:    true,
+		},
+		{
+			name:             "scale down a scale set",
+			replicas:         1,
+			existingCapacity: 3,
+			state:            infrav1.VMStateSucceeded,
+			expectedReady:    true,
+		},
+		{
+			name:             "fail to reconcile the scale set",
+			replicas:         2,
+			existingCapacity: 1,
+			err:              errors.New("quota exceeded"),
+			expectedError:    "failed to create or update scale set: quota exceeded",
+		},
+	}
+
+	for _, c := range cases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			machinePoolScope, clusterScope := newTestMachinePoolScopes(t, c.replicas)
+			scaleSetsSvc := &fakeScaleSetsService{capacity: c.existingCapacity, state: c.state, err: c.err}
+			recorder := record.NewFakeRecorder(1)
+			r := &AzureMachinePoolReconciler{Recorder: recorder}
+
+			_, err := r.reconcileScaleSet(machinePoolScope, &azureMachinePoolService{
+				machinePoolScope: machinePoolScope,
+				clusterScope:     clusterScope,
+				scaleSetsSvc:     scaleSetsSvc,
+				resourceSKUSvc:   &fakeSKUService{},
+			})
+			amp := machinePoolScope.AzureMachinePool
+			if c.expectedError != "" {
+				g.Expect(err).To(MatchError(c.expectedError))
+				g.Expect(recorder.Events).To(Receive(ContainSubstring("FailedCreateOrUpdate")))
+				g.Expect(amp.Spec.ProviderIDList).To(BeEmpty())
+				g.Expect(scaleSetsSvc.capacity).To(Equal(c.existingCapacity))
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+
+			g.Expect(scaleSetsSvc.specs).To(HaveLen(1))
+			spec := scaleSetsSvc.specs[0]
+			g.Expect(spec.Name).To(Equal("my-pool"))
+			g.Expect(spec.Sku).To(Equal("Standard_D2s_v3"))
+			g.Expect(spec.Capacity).To(Equal(int64(c.replicas)))
+			g.Expect(spec.CustomData).To(Equal("Ym9vdHN0cmFwLWRhdGE="))
+			g.Expect(spec.SubnetID).To(Equal("/subscriptions/123/resourceGroups/my-rg/providers/Microsoft.Network/virtualNetworks/my-vnet/subnets/node-subnet"))
+
+			vmssID := "/subscriptions/123/resourceGroups/my-rg/providers/Microsoft.Compute/virtualMachineScaleSets/my-pool"
+			g.Expect(amp.Spec.ProviderID).To(Equal("azure:////" + vmssID))
+			g.Expect(amp.Spec.ProviderIDList).To(HaveLen(int(c.replicas)))
+			g.Expect(amp.Status.Replicas).To(Equal(c.replicas))
+			g.Expect(amp.Status.Instances).To(HaveLen(int(c.replicas)))
+			for i, instance := range amp.Status.Instances {
+				providerID := fmt.Sprintf("azure:////%s/virtualMachines/%d", vmssID, i)
+				g.Expect(amp.Spec.ProviderIDList[i]).To(Equal(providerID))
+				g.Expect(instance.InstanceID).To(Equal(fmt.Sprintf("%d", i)))
+				g.Expect(instance.InstanceName).To(Equal(fmt.Sprintf("my-pool%06d", i)))
+				g.Expect(instance.ProviderID).To(Equal(providerID))
+				g.Expect(*instance.ProvisioningState).To(Equal(infrav1.VMStateSucceeded))
+				g.Expect(instance.LatestModelApplied).To(BeTrue())
+			}
+			g.Expect(*amp.Status.ProvisioningState).To(Equal(c.state))
+			g.Expect(amp.Status.Ready).To(Equal(c.expectedReady))
+		})
+	}
+}
+
+func TestReconcileScaleSetEphemeralOSDisk(t *testing.T) {
+	g := NewWithT(t)
+
+	cases := []struct {
+		name            string
+		providerID      string
+		ephemeralOSDisk bool
+		expectedInvalid bool
+	}{
+		{
+			name:            "create a scale set with an ephemeral OS disk",
+			ephemeralOSDisk: true,
+		},
+		{
+			name:            "reject an ephemeral OS disk the VM size doesn't support",
+			expectedInvalid: true,
+		},
+		{
+			name:       "leave the VM size of an existing scale set to Azure",
+			providerID: "azure:////subscriptions/123/resourceGroups/my-rg/providers/Microsoft.Compute/virtualMachineScaleSets/my-pool",
+		},
+	}
+
+	for _, c := range cases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			machinePoolScope, clusterScope := newTestMachinePoolScopes(t, 1)
+			amp := machinePoolScope.AzureMachinePool
+			amp.Spec.ProviderID = c.providerID
+			amp.Spec.Template.OSDisk.DiffDiskSettings = &infrav1.DiffDiskSettings{Option: "Local"}
+			scaleSetsSvc := &fakeScaleSetsService{state: infrav1.VMStateCreating}
+			recorder := record.NewFakeRecorder(1)
+			r := &AzureMachinePoolReconciler{Recorder: recorder}
+
+			_, err := r.reconcileScaleSet(machinePoolScope, &azureMachinePoolService{
+				machinePoolScope: machinePoolScope,
+				clusterScope:     clusterScope,
+				scaleSetsSvc:     scaleSetsSvc,
+				resourceSKUSvc:   &fakeSKUService{ephemeralOSDisk: c.ephemeralOSDisk},
+			})
+			g.Expect(err).NotTo(HaveOccurred())
+			if c.expectedInvalid {
+				g.Expect(scaleSetsSvc.specs).To(BeEmpty())
+				g.Expect(*amp.Status.FailureReason).To(Equal(capierrors.InvalidConfigurationMachineError))
+				g.Expect(*amp.Status.FailureMessage).To(ContainSubstring("spec.template.osDisk.diffDiskSettings.option"))
+				g.Expect(recorder.Events).To(Receive(ContainSubstring("InvalidConfiguration")))
+				return
+			}
+			g.Expect(scaleSetsSvc.specs).To(HaveLen(1))
+			g.Expect(amp.Status.FailureReason).To(BeNil())
+		})
+	}
+}
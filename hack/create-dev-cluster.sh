@@ -30,6 +30,7 @@ export AZURE_VNET_NAME=${CLUSTER_NAME}-vnet
 
 # Azure settings.
 export AZURE_LOCATION="${AZURE_LOCATION:-southcentralus}"
+export AZURE_ENVIRONMENT="${AZURE_ENVIRONMENT:-AzurePublicCloud}"
 export AZURE_RESOURCE_GROUP=${CLUSTER_NAME}
 export AZURE_SUBSCRIPTION_ID_B64="$(echo -n "$AZURE_SUBSCRIPTION_ID" | base64 | tr -d '\n')"
 export AZURE_TENANT_ID_B64="$(echo -n "$AZURE_TENANT_ID" | base64 | tr -d '\n')"
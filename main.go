@@ -34,8 +34,12 @@ import (
 	infrav1alpha2 "sigs.k8s.io/cluster-api-provider-azure/api/v1alpha2"
 	infrastructurev1alpha3 "sigs.k8s.io/cluster-api-provider-azure/api/v1alpha3"
 	infrav1alpha3 "sigs.k8s.io/cluster-api-provider-azure/api/v1alpha3"
+	"sigs.k8s.io/cluster-api-provider-azure/cloud/services/resourceskus"
 	"sigs.k8s.io/cluster-api-provider-azure/controllers"
+	infrav1alpha3exp "sigs.k8s.io/cluster-api-provider-azure/exp/api/v1alpha3"
+	expcontrollers "sigs.k8s.io/cluster-api-provider-azure/exp/controllers"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+	clusterv1exp "sigs.k8s.io/cluster-api/exp/api/v1alpha3"
 	"sigs.k8s.io/cluster-api/feature"
 	"sigs.k8s.io/cluster-api/util/record"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -56,19 +60,23 @@ func init() {
 	_ = infrav1alpha3.AddToScheme(scheme)
 	_ = clusterv1.AddToScheme(scheme)
 	_ = infrastructurev1alpha3.AddToScheme(scheme)
+	_ = infrav1alpha3exp.AddToScheme(scheme)
+	_ = clusterv1exp.AddToScheme(scheme)
 	// +kubebuilder:scaffold:scheme
 }
 
 var (
-	metricsAddr             string
-	enableLeaderElection    bool
-	watchNamespace          string
-	profilerAddress         string
-	azureClusterConcurrency int
-	azureMachineConcurrency int
-	syncPeriod              time.Duration
-	healthAddr              string
-	webhookPort             int
+	metricsAddr                 string
+	enableLeaderElection        bool
+	watchNamespace              string
+	profilerAddress             string
+	azureClusterConcurrency     int
+	azureMachineConcurrency     int
+	azureMachinePoolConcurrency int
+	syncPeriod                  time.Duration
+	resourceSKUCacheTTL         time.Duration
+	healthAddr                  string
+	webhookPort                 int
 )
 
 func InitFlags(fs *pflag.FlagSet) {
@@ -112,12 +120,24 @@ func InitFlags(fs *pflag.FlagSet) {
 		"Number of AzureMachines to process simultaneously",
 	)
 
+	fs.IntVar(&azureMachinePoolConcurrency,
+		"azuremachinepool-concurrency",
+		10,
+		"Number of AzureMachinePools to process simultaneously",
+	)
+
 	fs.DurationVar(&syncPeriod,
 		"sync-period",
 		10*time.Minute,
 		"The minimum interval at which watched resources are reconciled (e.g. 15m)",
 	)
 
+	fs.DurationVar(&resourceSKUCacheTTL,
+		"resource-sku-cache-ttl",
+		resourceskus.DefaultCacheTTL,
+		"The interval at which the Azure resource SKUs available in a location are refreshed (e.g. 1h)",
+	)
+
 	fs.StringVar(&healthAddr,
 		"health-addr",
 		":9440",
@@ -177,10 +197,14 @@ func main() {
 	record.InitFromRecorder(mgr.GetEventRecorderFor("azure-controller"))
 
 	if webhookPort == 0 {
+		// The resource SKUs are shared by all the controllers.
+		skuCache := resourceskus.NewCache(resourceSKUCacheTTL)
+
 		if err = (&controllers.AzureMachineReconciler{
 			Client:   mgr.GetClient(),
 			Log:      ctrl.Log.WithName("controllers").WithName("AzureMachine"),
 			Recorder: mgr.GetEventRecorderFor("azuremachine-reconciler"),
+			SKUCache: skuCache,
 		}).SetupWithManager(mgr, controller.Options{MaxConcurrentReconciles: azureMachineConcurrency}); err != nil {
 			setupLog.Error(err, "unable to create controller", "controller", "AzureMachine")
 			os.Exit(1)
@@ -189,15 +213,31 @@ func main() {
 			Client:   mgr.GetClient(),
 			Log:      ctrl.Log.WithName("controllers").WithName("AzureCluster"),
 			Recorder: mgr.GetEventRecorderFor("azurecluster-reconciler"),
+			SKUCache: skuCache,
 		}).SetupWithManager(mgr, controller.Options{MaxConcurrentReconciles: azureClusterConcurrency}); err != nil {
 			setupLog.Error(err, "unable to create controller", "controller", "AzureCluster")
 			os.Exit(1)
 		}
+		if feature.Gates.Enabled(feature.MachinePool) {
+			if err = (&expcontrollers.AzureMachinePoolReconciler{
+				Client:   mgr.GetClient(),
+				Log:      ctrl.Log.WithName("controllers").WithName("AzureMachinePool"),
+				Recorder: mgr.GetEventRecorderFor("azuremachinepool-reconciler"),
+				SKUCache: skuCache,
+			}).SetupWithManager(mgr, controller.Options{MaxConcurrentReconciles: azureMachinePoolConcurrency}); err != nil {
+				setupLog.Error(err, "unable to create controller", "controller", "AzureMachinePool")
+				os.Exit(1)
+			}
+		}
 	} else {
 		if err = (&infrastructurev1alpha3.AzureCluster{}).SetupWebhookWithManager(mgr); err != nil {
 			setupLog.Error(err, "unable to create webhook", "webhook", "AzureCluster")
 			os.Exit(1)
 		}
+		if err = (&infrastructurev1alpha3.AzureClusterIdentity{}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "AzureClusterIdentity")
+			os.Exit(1)
+		}
 		if err = (&infrastructurev1alpha3.AzureMachine{}).SetupWebhookWithManager(mgr); err != nil {
 			setupLog.Error(err, "unable to create webhook", "webhook", "AzureMachine")
 			os.Exit(1)
@@ -206,6 +246,12 @@ func main() {
 			setupLog.Error(err, "unable to create webhook", "webhook", "AzureMachineTemplate")
 			os.Exit(1)
 		}
+		if feature.Gates.Enabled(feature.MachinePool) {
+			if err = (&infrav1alpha3exp.AzureMachinePool{}).SetupWebhookWithManager(mgr); err != nil {
+				setupLog.Error(err, "unable to create webhook", "webhook", "AzureMachinePool")
+				os.Exit(1)
+			}
+		}
 	}
 	// +kubebuilder:scaffold:builder
 
@@ -131,6 +131,7 @@ func (c *ClusterGenerator) GenerateCluster(namespace string) (*capiv1.Cluster, *
 		},
 		Spec: infrav1.AzureClusterSpec{
 			Location:      location,
+			Environment:   "AzurePublicCloud",
 			ResourceGroup: name,
 			NetworkSpec: infrav1.NetworkSpec{
 				Vnet: infrav1.VnetSpec{Name: vnetName},